@@ -13,6 +13,15 @@ type Config struct {
 	Storage  StorageStack `json:"storage" yaml:"storage"`
 	NodeKeys NodeInfoKeys `json:"nodeInfoKeys" yaml:"nodeInfoKeys"`
 	NodeInfo v1.NodeInfo  `json:"nodeInfo,omitempty"`
+	// Transport configures NVMe-oF transports of SPDK target
+	Transport TransportConfig `json:"transport" yaml:"transport"`
+}
+
+type TransportConfig struct {
+	// EnableRDMA creates RDMA transport in SPDK target. NICs of the node must support RDMA, e.g. RoCE or soft-RoCE (rxe)
+	EnableRDMA bool `json:"enableRDMA" yaml:"enableRDMA"`
+	// MultipathAddresses are additional IPs of the node. SPDK targets listen on them as well for NVMe multipath.
+	MultipathAddresses []string `json:"multipathAddresses" yaml:"multipathAddresses"`
 }
 
 type NodeInfoKeys struct {
@@ -20,9 +20,15 @@ storage:
     type: aioBdev
     name: aio-bdev-xxx
 nodeInfoKeys:
-  ipLabelKey: liteio.io/ip`
+  ipLabelKey: liteio.io/ip
+transport:
+  enableRDMA: true
+  multipathAddresses:
+  - 10.0.1.1`
 
 	cfg, err := Load([]byte(cfgStr))
 	assert.NoError(t, err)
+	assert.True(t, cfg.Transport.EnableRDMA)
+	assert.Equal(t, []string{"10.0.1.1"}, cfg.Transport.MultipathAddresses)
 	t.Log(cfg, *cfg.Storage.Bdev)
 }
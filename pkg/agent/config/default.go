@@ -1,15 +1,44 @@
 package config
 
+import (
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+)
+
 const (
 	SigmaLabelKeyNodeIP   = "lite.io/ip"
 	SigmaLabelKeyHostname = "lite.io/hostname"
-	SigmaLabelKeyRack     = "lite.io/rack"
-	SigmaLabelKeyRoom     = "lite.io/room"
 )
 
 func SetDefaults(cfg *Config) {
 	// set label key
 	SetNodeInfoDefaults(&cfg.NodeKeys)
+	SetThinPoolDefaults(cfg.Storage.Pooling.ThinPool)
+
+	if cfg.Storage.Pooling.UsageWarningPct <= 0 {
+		cfg.Storage.Pooling.UsageWarningPct = DefaultUsageWarningPct
+	}
+}
+
+func SetThinPoolDefaults(cfg *LvmThinPool) {
+	if cfg == nil {
+		return
+	}
+
+	if cfg.Name == "" {
+		cfg.Name = DefaultThinPoolName
+	}
+
+	if cfg.SizePct <= 0 || cfg.SizePct > 100 {
+		cfg.SizePct = DefaultThinPoolSizePct
+	}
+
+	if cfg.DataWarningPct <= 0 {
+		cfg.DataWarningPct = DefaultThinPoolWarningPct
+	}
+
+	if cfg.MetadataWarningPct <= 0 {
+		cfg.MetadataWarningPct = DefaultThinPoolWarningPct
+	}
 }
 
 func SetNodeInfoDefaults(cfg *NodeInfoKeys) {
@@ -22,10 +51,10 @@ func SetNodeInfoDefaults(cfg *NodeInfoKeys) {
 	}
 
 	if cfg.RackLabelKey == "" {
-		cfg.RackLabelKey = SigmaLabelKeyRack
+		cfg.RackLabelKey = v1.NodeLabelKeyRack
 	}
 
 	if cfg.RoomLabelKey == "" {
-		cfg.RoomLabelKey = SigmaLabelKeyRoom
+		cfg.RoomLabelKey = v1.NodeLabelKeyRoom
 	}
 }
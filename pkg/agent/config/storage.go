@@ -13,6 +13,11 @@ const (
 
 	DefaultMallocBdevName = "antstor_malloc"
 	DefaultAioBdevName    = "antstor_aio"
+
+	DefaultThinPoolName       = "antstor-thinpool"
+	DefaultThinPoolSizePct    = 90
+	DefaultThinPoolWarningPct = 80
+	DefaultUsageWarningPct    = 80
 )
 
 var (
@@ -46,6 +51,23 @@ type StorageStack struct {
 type Pooling struct {
 	Mode v1.PoolMode `json:"mode" yaml:"mode"`
 	Name string      `json:"name" yaml:"name"`
+	// ThinPool enables thin provisioning for KernelLVM pool. A thin pool LV is created in the VG if it does not exist.
+	ThinPool *LvmThinPool `json:"thinPool,omitempty" yaml:"thinPool"`
+	// ThinOvercommitPct is reported to StoragePool spec. If it is 0, the value in StoragePool is kept.
+	ThinOvercommitPct int `json:"thinOvercommitPct,omitempty" yaml:"thinOvercommitPct"`
+	// UsageWarningPct is the threshold of physical usage percentage of SpdkLVStore. LVStoreUsage condition is Error above it
+	UsageWarningPct int `json:"usageWarningPct,omitempty" yaml:"usageWarningPct"`
+}
+
+type LvmThinPool struct {
+	// Name of the thin pool LV
+	Name string `json:"name" yaml:"name"`
+	// SizePct is the percentage of VG size for the thin pool when creating it
+	SizePct int `json:"sizePct" yaml:"sizePct"`
+	// DataWarningPct is the threshold of data usage percentage. ThinPoolData condition is Error above it
+	DataWarningPct int `json:"dataWarningPct" yaml:"dataWarningPct"`
+	// MetadataWarningPct is the threshold of metadata usage percentage. ThinPoolMetadata condition is Error above it
+	MetadataWarningPct int `json:"metadataWarningPct" yaml:"metadataWarningPct"`
 }
 
 type LvmPV struct {
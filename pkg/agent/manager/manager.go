@@ -156,7 +156,7 @@ func (spm *StoragePoolManager) newPoolService() (ps pool.StoragePoolServiceIface
 	}
 	klog.Infof("storage config is %+v", spm.cfg.Storage)
 
-	ps, err = pool.NewPoolService(spm.cfg.Storage)
+	ps, err = pool.NewPoolService(spm.cfg.Storage, spm.cfg.Transport)
 
 	// For lvstore pool mode, spdk service is necessary. The error should be returned and panic it.
 	if spm.cfg.Storage.Pooling.Mode == v1.PoolModeSpdkLVStore {
@@ -176,9 +176,11 @@ func (spm *StoragePoolManager) Start() {
 	var ctx = context.Background()
 	spm.runnableGroup = runnable.NewRunnableGroup(errCh)
 	spm.runnableGroup.AddDefault(agentsync.NewMigrationReconciler(spm.Opt.NodeID, spm.storeCli, spm.PoolService.SpdkService()))
-	spm.runnableGroup.AddDefault(agentsync.NewSnapshotSyncer(spm.storeCli, spm.PoolService))
-	spm.runnableGroup.AddDefault(agentsync.NewVolumeSyncer(spm.storeCli, spm.PoolService, spm.lister))
+	spm.runnableGroup.AddDefault(agentsync.NewSnapshotSyncer(spm.storeCli, spm.kubeCli, spm.PoolService))
+	spm.runnableGroup.AddDefault(agentsync.NewVolumeSyncer(spm.storeCli, spm.kubeCli, spm.PoolService, spm.lister))
+	spm.runnableGroup.AddDefault(agentsync.NewReplicaSyncer(spm.storeCli, spm.PoolService))
 	spm.runnableGroup.AddDefault(agentsync.NewDataControlReconciler(spm.Opt.NodeID, spm.storeCli))
+	spm.runnableGroup.AddDefault(agentsync.NewGroupSnapshotReconciler(spm.Opt.NodeID, spm.storeCli))
 
 	spm.runnableGroup.AddDefault(&HeartbeatService{
 		Interval: spm.Opt.HeartbeatInterval,
@@ -186,10 +188,14 @@ func (spm *StoragePoolManager) Start() {
 		kubeCli:  spm.kubeCli,
 		storeCli: spm.storeCli,
 	})
+	// track IO load of pool, which is published in StoragePool status for scheduling
+	loadTracker := metric.NewPoolLoadTracker(metric.DefaultLoadSampleInterval, metric.DefaultLoadWindow, spm.sp, spm.PoolService.SpdkService())
+	spm.runnableGroup.AddDefault(loadTracker)
 	spm.runnableGroup.AddDefault(agentsync.NewPoolSyncer(spm.PoolService,
 		spm.storeCli,
 		kubeutil.NewKubeNodeInfoGetter(spm.kubeCli),
-		spm.cfg))
+		spm.cfg,
+		loadTracker))
 
 	// init exporter collector
 	if spm.Opt.MetricListenAddr != "" {
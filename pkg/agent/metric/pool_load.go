@@ -0,0 +1,256 @@
+package metric
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/spdk"
+	"lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"lite.io/liteio/pkg/util/lvm"
+	"github.com/toolkits/nux"
+	"golang.org/x/sys/unix"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultLoadSampleInterval is the interval of sampling IO counters of pool
+	DefaultLoadSampleInterval = 10 * time.Second
+	// DefaultLoadWindow is the length of rolling window, in which IO load is averaged
+	DefaultLoadWindow = 5 * time.Minute
+	// qdSamplingPeriodUs is the period of sampling queue depth of SPDK bdev, in microseconds
+	qdSamplingPeriodUs = 10000
+)
+
+type PoolLoadGetterIface interface {
+	// IOLoad returns the summary of IO load in the rolling window. It is nil if there are not enough samples.
+	IOLoad() *v1.PoolIOLoad
+}
+
+// ioCounters are cumulative IO counters of the pool
+type ioCounters struct {
+	time       time.Time
+	readOps    uint64
+	writeOps   uint64
+	readBytes  uint64
+	writeBytes uint64
+	// total latency of read and write requests in microseconds
+	latencyUs uint64
+	// time during which disks are busy, in microseconds
+	busyUs uint64
+}
+
+// lessThan returns true if any counter decreases, e.g. SPDK restarts or PVs are changed
+func (c ioCounters) lessThan(last ioCounters) bool {
+	return c.readOps < last.readOps || c.writeOps < last.writeOps ||
+		c.readBytes < last.readBytes || c.writeBytes < last.writeBytes ||
+		c.latencyUs < last.latencyUs || c.busyUs < last.busyUs
+}
+
+// PoolLoadTracker samples IO counters of the pool periodically, and summarizes IO load in a rolling window.
+// For KernelLVM pool, counters are read from diskstats of PVs in VG.
+// For SpdkLVStore pool, counters are read from iostat of the base bdev of lvstore.
+type PoolLoadTracker struct {
+	interval time.Duration
+	window   time.Duration
+	pool     *v1.StoragePool
+	spdkSvc  spdk.SpdkServiceIface
+
+	lock    sync.Mutex
+	samples []ioCounters
+}
+
+func NewPoolLoadTracker(interval, window time.Duration, pool *v1.StoragePool, spdkSvc spdk.SpdkServiceIface) *PoolLoadTracker {
+	return &PoolLoadTracker{
+		interval: interval,
+		window:   window,
+		pool:     pool,
+		spdkSvc:  spdkSvc,
+	}
+}
+
+func (t *PoolLoadTracker) Start(ctx context.Context) (err error) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			counters, errSample := t.sample()
+			if errSample != nil {
+				klog.Error(errSample)
+				continue
+			}
+			t.addSample(counters)
+		case <-ctx.Done():
+			klog.Info("pool load tracker quit")
+			return
+		}
+	}
+}
+
+func (t *PoolLoadTracker) IOLoad() *v1.PoolIOLoad {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.samples) < 2 {
+		return nil
+	}
+	return summarizeIOLoad(t.samples[0], t.samples[len(t.samples)-1])
+}
+
+// addSample appends the sample, and removes the samples out of window
+func (t *PoolLoadTracker) addSample(counters ioCounters) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.samples) > 0 && counters.lessThan(t.samples[len(t.samples)-1]) {
+		klog.Info("IO counters of pool are reset, clear samples")
+		t.samples = nil
+	}
+	t.samples = append(t.samples, counters)
+
+	var idx int
+	for idx < len(t.samples)-1 && counters.time.Sub(t.samples[idx].time) > t.window {
+		idx++
+	}
+	t.samples = t.samples[idx:]
+}
+
+func (t *PoolLoadTracker) sample() (counters ioCounters, err error) {
+	switch t.pool.Mode() {
+	case v1.PoolModeKernelLVM:
+		return sampleDiskStats(t.pool.Spec.KernelLVM.Name)
+	case v1.PoolModeSpdkLVStore:
+		return sampleBdevIostat(t.spdkSvc, t.pool.Spec.SpdkLVStore.BaseBdev)
+	}
+	err = fmt.Errorf("unknown pool mode %q", t.pool.Mode())
+	return
+}
+
+// sampleDiskStats sums up diskstats of PVs in VG. Busy time is averaged by the number of PVs.
+func sampleDiskStats(vgName string) (counters ioCounters, err error) {
+	var (
+		pvs       []lvm.PV
+		diskStats []*nux.DiskStats
+		devIDs    = make(map[uint64]bool)
+		busyMs    uint64
+	)
+
+	pvs, err = lvm.LvmUtil.ListPV()
+	if err != nil {
+		return
+	}
+	for _, pv := range pvs {
+		if pv.VgName != vgName {
+			continue
+		}
+		var stat unix.Stat_t
+		if errStat := unix.Stat(pv.PvName, &stat); errStat != nil {
+			klog.Errorf("get stat on %s failed: %v", pv.PvName, errStat)
+			continue
+		}
+		devIDs[uint64(stat.Rdev)] = true
+	}
+	if len(devIDs) == 0 {
+		err = fmt.Errorf("not found PVs of VG %s", vgName)
+		return
+	}
+
+	counters.time = time.Now()
+	diskStats, err = nux.ListDiskStats()
+	if err != nil {
+		return
+	}
+	for _, item := range diskStats {
+		if !devIDs[unix.Mkdev(uint32(item.Major), uint32(item.Minor))] {
+			continue
+		}
+		counters.readOps += item.ReadRequests
+		counters.writeOps += item.WriteRequests
+		// sector is 512 bytes in diskstats
+		counters.readBytes += item.ReadSectors * 512
+		counters.writeBytes += item.WriteSectors * 512
+		counters.latencyUs += (item.MsecRead + item.MsecWrite) * 1000
+		busyMs += item.MsecTotal
+	}
+	counters.busyUs = busyMs * 1000 / uint64(len(devIDs))
+
+	return
+}
+
+// sampleBdevIostat reads iostat of the bdev. Busy time is io_time of bdev, which is the time when queue depth is not zero.
+// io_time is only counted if queue depth sampling is enabled, so it is enabled on the bdev if not yet.
+func sampleBdevIostat(spdkSvc spdk.SpdkServiceIface, bdevName string) (counters ioCounters, err error) {
+	var iostats client.BdevIostats
+	if spdkSvc == nil || bdevName == "" {
+		err = fmt.Errorf("spdk service or base bdev of lvstore is empty")
+		return
+	}
+
+	counters.time = time.Now()
+	iostats, err = spdkSvc.BdevGetIostat(client.BdevGetIostatReq{BdevName: bdevName})
+	if err != nil {
+		return
+	}
+	if iostats.TickRate == 0 {
+		err = fmt.Errorf("invalid tick rate of bdev iostat")
+		return
+	}
+	for _, item := range iostats.Bdevs {
+		counters.readOps += item.NumReadOps
+		counters.writeOps += item.NumWriteOps
+		counters.readBytes += item.BytesRead
+		counters.writeBytes += item.BytesWritten
+		counters.latencyUs += (item.ReadLatencyTicks + item.WriteLatencyTicks) * 1000000 / iostats.TickRate
+		counters.busyUs += item.IoTime
+
+		if item.QueueDepthPollingPeriod == 0 {
+			klog.Infof("enable queue depth sampling of bdev %s", item.Name)
+			err = spdkSvc.BdevSetQdSamplingPeriod(client.BdevSetQdSamplingPeriodReq{
+				Name:   bdevName,
+				Period: qdSamplingPeriodUs,
+			})
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// summarizeIOLoad calculates average IO load between two samples
+func summarizeIOLoad(first, last ioCounters) (load *v1.PoolIOLoad) {
+	var dur = last.time.Sub(first.time)
+	if dur <= 0 {
+		return nil
+	}
+
+	var (
+		sec      = dur.Seconds()
+		readOps  = last.readOps - first.readOps
+		writeOps = last.writeOps - first.writeOps
+		util     = int(float64(last.busyUs-first.busyUs) * 100 / float64(dur.Microseconds()))
+	)
+	if util > 100 {
+		util = 100
+	}
+
+	load = &v1.PoolIOLoad{
+		UtilPct:       util,
+		ReadIOPS:      int64(float64(readOps) / sec),
+		WriteIOPS:     int64(float64(writeOps) / sec),
+		ReadBps:       int64(float64(last.readBytes-first.readBytes) / sec),
+		WriteBps:      int64(float64(last.writeBytes-first.writeBytes) / sec),
+		WindowSeconds: int(sec),
+		UpdateTime:    metav1.NewTime(last.time),
+	}
+	if readOps+writeOps > 0 {
+		load.AvgLatencyUs = int64((last.latencyUs - first.latencyUs) / (readOps + writeOps))
+	}
+
+	return
+}
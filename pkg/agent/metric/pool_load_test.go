@@ -0,0 +1,125 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	spdkmock "lite.io/liteio/pkg/generated/mocks/spdk"
+	"lite.io/liteio/pkg/spdk"
+	"lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSummarizeIOLoad(t *testing.T) {
+	var now = time.Now()
+	first := ioCounters{
+		time:       now,
+		readOps:    1000,
+		writeOps:   2000,
+		readBytes:  1 << 20,
+		writeBytes: 2 << 20,
+		latencyUs:  10000,
+		busyUs:     1000000,
+	}
+	last := ioCounters{
+		time:       now.Add(10 * time.Second),
+		readOps:    2000,
+		writeOps:   4000,
+		readBytes:  11 << 20,
+		writeBytes: 22 << 20,
+		latencyUs:  40000,
+		busyUs:     6000000,
+	}
+
+	load := summarizeIOLoad(first, last)
+	assert.Equal(t, 50, load.UtilPct)
+	assert.Equal(t, int64(100), load.ReadIOPS)
+	assert.Equal(t, int64(200), load.WriteIOPS)
+	assert.Equal(t, int64(1<<20), load.ReadBps)
+	assert.Equal(t, int64(2<<20), load.WriteBps)
+	assert.Equal(t, int64(10), load.AvgLatencyUs)
+	assert.Equal(t, 10, load.WindowSeconds)
+
+	// util is capped
+	last.busyUs = 100000000
+	load = summarizeIOLoad(first, last)
+	assert.Equal(t, 100, load.UtilPct)
+
+	// invalid duration
+	assert.Nil(t, summarizeIOLoad(last, last))
+}
+
+func TestPoolLoadTrackerWindow(t *testing.T) {
+	var (
+		now     = time.Now()
+		tracker = NewPoolLoadTracker(time.Second, time.Minute, nil, nil)
+	)
+
+	assert.Nil(t, tracker.IOLoad())
+
+	for i := 0; i < 10; i++ {
+		tracker.addSample(ioCounters{
+			time:    now.Add(time.Duration(i) * 20 * time.Second),
+			readOps: uint64(i * 100),
+		})
+	}
+	// samples older than 1 min are removed
+	assert.Len(t, tracker.samples, 4)
+	load := tracker.IOLoad()
+	assert.Equal(t, 60, load.WindowSeconds)
+	assert.Equal(t, int64(5), load.ReadIOPS)
+
+	// counters are reset
+	tracker.addSample(ioCounters{
+		time:    now.Add(200 * time.Second),
+		readOps: 1,
+	})
+	assert.Len(t, tracker.samples, 1)
+	assert.Nil(t, tracker.IOLoad())
+}
+
+func TestSampleBdevIostat(t *testing.T) {
+	fakeCli := spdkmock.NewSPDKClientIface(t)
+	fakeCli.On("NVMFGetTransports").Return(nil, nil).
+		On("NVMFCreateTransport", mock.Anything).Return(true, nil).
+		On("NVMFGetSubsystems", mock.Anything).Return(nil, nil)
+	svc, err := spdk.NewSpdkService(spdk.SpdkServiceConfig{
+		CliGenFn: func() (client.SPDKClientIface, error) {
+			return fakeCli, nil
+		},
+	})
+	assert.NoError(t, err)
+
+	var (
+		req  = client.BdevGetIostatReq{BdevName: "nvme0n1"}
+		stat = client.BdevIostat{
+			Name:              "nvme0n1",
+			NumReadOps:        100,
+			NumWriteOps:       300,
+			BytesRead:         4096,
+			BytesWritten:      8192,
+			ReadLatencyTicks:  1000,
+			WriteLatencyTicks: 3000,
+		}
+	)
+
+	// queue depth sampling is not enabled, busy time is not counted
+	fakeCli.On("BdevGetIostat", req).Return(client.BdevIostats{TickRate: 1000000, Bdevs: []client.BdevIostat{stat}}, nil).Once().
+		On("BdevSetQdSamplingPeriod", client.BdevSetQdSamplingPeriodReq{Name: "nvme0n1", Period: qdSamplingPeriodUs}).Return(true, nil).Once()
+	counters, err := sampleBdevIostat(svc, "nvme0n1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), counters.readOps)
+	assert.Equal(t, uint64(300), counters.writeOps)
+	assert.Equal(t, uint64(4000), counters.latencyUs)
+	assert.Zero(t, counters.busyUs)
+
+	// busy time is io_time, regardless of latency of requests
+	stat.QueueDepthPollingPeriod = qdSamplingPeriodUs
+	stat.IoTime = 2500
+	fakeCli.On("BdevGetIostat", req).Return(client.BdevIostats{TickRate: 1000000, Bdevs: []client.BdevIostat{stat}}, nil).Once()
+	counters, err = sampleBdevIostat(svc, "nvme0n1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4000), counters.latencyUs)
+	assert.Equal(t, uint64(2500), counters.busyUs)
+}
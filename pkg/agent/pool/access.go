@@ -18,6 +18,55 @@ type Access struct {
 	OpenAccess spdk.Target
 	// allow host nqn
 	AllowHostNQN []string
+	// hosts in RevokeHostNQN but not in AllowHostNQN will be removed from the subsystem.
+	// Other hosts which are not in AllowHostNQN, e.g. the node of replica or migration, are kept.
+	RevokeHostNQN []string
+	// optional, DH-HMAC-CHAP keys which are used when adding hosts
+	DHChap *DHChapKeys
+	// optional, the bdev of AIO or LVol is wrapped by a crypto bdev, which is exposed instead
+	Crypto *CryptoBdev
+	// optional, the bdev of LVol is mirrored with remote replicas by a RAID1 bdev. Crypto bdev is created over the RAID1 bdev.
+	Raid *RaidBdev
+}
+
+// DHChapKeys are names of keys in SPDK keyring
+type DHChapKeys struct {
+	// key of host
+	Key string
+	// key of controller, for bidirectional authentication
+	CtrlrKey string
+}
+
+// CryptoBdev is the crypto bdev encrypted by AES-XTS
+type CryptoBdev struct {
+	BdevName string
+	// name of key in SPDK accel framework
+	KeyName string
+	// keys in hex. They are not required for removing access
+	Key  string
+	Key2 string
+}
+
+// RaidBdev is the RAID1 bdev over LVol and replicas
+type RaidBdev struct {
+	BdevName string
+	// Replicas are remote bdevs attached over NVMe-oF
+	Replicas []RemoteBdev
+}
+
+type RemoteBdev struct {
+	ControllerName string
+	// Target is not required for removing access
+	Target spdk.SpdkTargetInfo
+	// HostNQN is the NQN which SPDK connects to Target with
+	HostNQN string
+	// Rebuild is true if data of the replica may be stale. It is rebuilt from the other base bdevs of RAID1 bdev.
+	Rebuild bool
+}
+
+// BdevName returns name of the bdev attached by controller
+func (rb RemoteBdev) BdevName() string {
+	return rb.ControllerName + "n1"
 }
 
 type AioVolume struct {
@@ -69,6 +118,53 @@ func (sa *SpdkAccess) ExposeAccess(a Access) (tgt spdk.Target, err error) {
 		bdevName = fmt.Sprintf("%s/%s", a.LVol.LvsName, a.LVol.LvolName)
 	}
 
+	// mirror the bdev with replicas
+	if a.Raid != nil && bdevName != "" {
+		var baseBdevs = []string{bdevName}
+		for _, item := range a.Raid.Replicas {
+			err = sa.spdk.EnsureMigrationDestBdev(spdk.AttachDestBdevRequest{
+				ControllerName: item.ControllerName,
+				Target:         item.Target,
+				HostNQN:        item.HostNQN,
+			})
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+			baseBdevs = append(baseBdevs, item.BdevName())
+		}
+		err = sa.spdk.CreateRaid1Bdev(spdk.CreateBdevRaidReq{
+			RaidName:  a.Raid.BdevName,
+			BdevNames: baseBdevs,
+		})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+		err = sa.rebuildReplicas(a.Raid)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+		bdevName = a.Raid.BdevName
+	}
+
+	// encrypt the bdev before exposing it
+	if a.Crypto != nil && bdevName != "" {
+		err = sa.spdk.CreateCryptoBdev(spdk.CryptoBdevCreateRequest{
+			BdevName:     a.Crypto.BdevName,
+			BaseBdevName: bdevName,
+			KeyName:      a.Crypto.KeyName,
+			Key:          a.Crypto.Key,
+			Key2:         a.Crypto.Key2,
+		})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+		bdevName = a.Crypto.BdevName
+	}
+
 	// create the socket directory for VFIOUSER local volume,
 	if a.OpenAccess.TransType == client.TransportTypeVFIOUSER {
 		var exist bool
@@ -114,9 +210,36 @@ func (sa *SpdkAccess) ExposeAccess(a Access) (tgt spdk.Target, err error) {
 	if len(a.AllowHostNQN) > 0 {
 		for _, item := range a.AllowHostNQN {
 			if !allowHostSet.Contains(item) {
-				err = sa.spdk.SubsysAddHost(spdk.SubsystemAddHostRequest{
+				var req = spdk.SubsystemAddHostRequest{
 					NQN:     resp.NQN,
 					HostNQN: item,
+				}
+				if a.DHChap != nil {
+					req.DHChapKey = a.DHChap.Key
+					req.DHChapCtrlrKey = a.DHChap.CtrlrKey
+				}
+				err = sa.spdk.SubsysAddHost(req)
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	// remove host if it is revoked
+	if len(a.RevokeHostNQN) > 0 {
+		var revokeHostSet = misc.NewEmptySet()
+		for _, item := range a.RevokeHostNQN {
+			if !misc.InSliceString(item, a.AllowHostNQN) {
+				revokeHostSet.Add(item)
+			}
+		}
+		for _, host := range subsys.Hosts {
+			if revokeHostSet.Contains(host.NQN) {
+				klog.Infof("revoke access of host %s to target %s", host.NQN, resp.NQN)
+				err = sa.spdk.SubsysRemoveHost(spdk.SubsystemRemoveHostRequest{
+					NQN:     resp.NQN,
+					HostNQN: host.NQN,
 				})
 				if err != nil {
 					return
@@ -128,6 +251,42 @@ func (sa *SpdkAccess) ExposeAccess(a Access) (tgt spdk.Target, err error) {
 	return
 }
 
+// rebuildReplicas removes stale replicas from RAID1 bdev and adds them to their slots again, so that data is rebuilt to them.
+// RAID1 bdev has a fixed slot for each base bdev, so stale replicas are attached when it is created, and rebuilt before it is exposed.
+func (sa *SpdkAccess) rebuildReplicas(rb *RaidBdev) (err error) {
+	raid, _, err := sa.spdk.GetRaidBdev(rb.BdevName)
+	if err != nil {
+		return
+	}
+
+	for _, item := range rb.Replicas {
+		var (
+			bdevName = item.BdevName()
+			inRaid   bool
+		)
+		if !item.Rebuild || (raid.Process != nil && raid.Process.Target == bdevName) {
+			continue
+		}
+		for _, base := range raid.BaseBdevsList {
+			if base.Name == bdevName {
+				inRaid = true
+			}
+		}
+
+		if inRaid {
+			err = sa.spdk.RemoveRaidBaseBdev(bdevName)
+			if err != nil {
+				return
+			}
+		}
+		err = sa.spdk.AddRaidBaseBdev(rb.BdevName, bdevName)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
 func (sa *SpdkAccess) RemoveAccces(a Access) (err error) {
 	if a.OpenAccess.NQN != "" {
 		klog.Infof("deleting target %s", a.OpenAccess.NQN)
@@ -150,6 +309,34 @@ func (sa *SpdkAccess) RemoveAccces(a Access) (err error) {
 		}
 	}
 
+	// crypto bdev must be deleted before its base bdev
+	if a.Crypto != nil {
+		err = sa.spdk.DeleteCryptoBdev(spdk.CryptoBdevDeleteRequest{
+			BdevName: a.Crypto.BdevName,
+			KeyName:  a.Crypto.KeyName,
+		})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
+	// raid bdev must be deleted before its base bdevs
+	if a.Raid != nil {
+		err = sa.spdk.DeleteRaidBdev(a.Raid.BdevName)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+		for _, item := range a.Raid.Replicas {
+			err = sa.spdk.DetachMigrationDestBdev(item.ControllerName)
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+		}
+	}
+
 	var bdevName string
 
 	if a.AIO != nil {
@@ -0,0 +1,57 @@
+package pool
+
+import (
+	"testing"
+
+	spdkmock "lite.io/liteio/pkg/generated/mocks/spdk"
+	"lite.io/liteio/pkg/spdk"
+	"lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRebuildReplicas(t *testing.T) {
+	fakeCli := spdkmock.NewSPDKClientIface(t)
+	fakeCli.On("NVMFGetTransports").Return(nil, nil).
+		On("NVMFCreateTransport", mock.Anything).Return(true, nil).
+		On("NVMFGetSubsystems", mock.Anything).Return(nil, nil)
+	svc, err := spdk.NewSpdkService(spdk.SpdkServiceConfig{
+		CliGenFn: func() (client.SPDKClientIface, error) {
+			return fakeCli, nil
+		},
+	})
+	assert.NoError(t, err)
+
+	var (
+		sa   = &SpdkAccess{spdk: svc}
+		raid = &RaidBdev{
+			BdevName: "raid1-vol",
+			Replicas: []RemoteBdev{
+				{ControllerName: "replica-online"},
+				{ControllerName: "replica-stale", Rebuild: true},
+				{ControllerName: "replica-removed", Rebuild: true},
+				{ControllerName: "replica-rebuilding", Rebuild: true},
+			},
+		}
+	)
+	// stale replica is removed and added again, removed replica is added to the empty slot,
+	// and the replica which is being rebuilt is kept
+	fakeCli.On("GetBdevRaids", mock.Anything).Return([]client.RaidBdevInfo{
+		{
+			Name:    "raid1-vol",
+			Process: &client.RaidProcess{Type: spdk.RaidProcessRebuild, Target: "replica-rebuildingn1"},
+			BaseBdevsList: []client.RaidBaseBdev{
+				{Name: "lvs/vol", IsConfigured: true},
+				{Name: "replica-onlinen1", IsConfigured: true},
+				{Name: "replica-stalen1", IsConfigured: true},
+				{Name: ""},
+				{Name: "replica-rebuildingn1", IsConfigured: true},
+			},
+		},
+	}, nil).Once().
+		On("RemoveBaseBdevRaid", client.RemoveBaseBdevRaidRequest{Name: "replica-stalen1"}).Return(true, nil).Once().
+		On("AddBaseBdevRaid", client.AddBaseBdevRaidRequest{RaidBdev: "raid1-vol", BaseBdev: "replica-stalen1"}).Return(true, nil).Once().
+		On("AddBaseBdevRaid", client.AddBaseBdevRaidRequest{RaidBdev: "raid1-vol", BaseBdev: "replica-removedn1"}).Return(true, nil).Once()
+
+	assert.NoError(t, sa.rebuildReplicas(raid))
+}
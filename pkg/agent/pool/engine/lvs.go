@@ -9,6 +9,11 @@ import (
 	"k8s.io/klog/v2"
 )
 
+const (
+	// max depth of snapshot chain to look for base snapshot
+	maxSnapshotChainDepth = 1024
+)
+
 type SpdkLvsPoolEngine struct {
 	// LvsName is the name of LVS
 	LvsName string
@@ -60,9 +65,10 @@ func (pe *SpdkLvsPoolEngine) TotalAndFreeSize() (total, free uint64, err error)
 func (pe *SpdkLvsPoolEngine) CreateVolume(req CreateVolumeRequest) (resp CreateVolumeResponse, err error) {
 	klog.Info("creating spdk lvol ", req)
 	resp.UUID, err = pe.spdk.CreateLvol(spdk.CreateLvolReq{
-		LVStore:  pe.LvsName,
-		LvolName: req.VolName,
-		SizeByte: int(req.SizeByte),
+		LVStore:       pe.LvsName,
+		LvolName:      req.VolName,
+		SizeByte:      int(req.SizeByte),
+		ThinProvision: req.Thin,
 	})
 	if err != nil {
 		return
@@ -102,9 +108,10 @@ func (pe *SpdkLvsPoolEngine) GetVolume(volName string) (vol VolumeInfo, err erro
 				Lvol: v1.SpdkLvol{
 					Name:    volName,
 					LvsName: pe.LvsName,
-					Thin:    false,
+					Thin:    list[0].Driver.Lvol.ThinProvision,
 				},
-				SizeByte: uint64(list[0].BlockSize * list[0].NumBlocks),
+				SizeByte:          uint64(list[0].BlockSize * list[0].NumBlocks),
+				AllocatedClusters: list[0].Driver.Lvol.NumAllocatedClusters,
 			},
 		}
 	}
@@ -124,8 +131,274 @@ func (pe *SpdkLvsPoolEngine) CreateSnapshot(req CreateSnapshotRequest) (err erro
 	return
 }
 
-func (pe *SpdkLvsPoolEngine) RestoreSnapshot(snapshotName string) (err error) {
-	err = fmt.Errorf("SPDK LVS not support RestoreSnapshot")
+// RestoreSnapshot rolls back the origin lvol to the snapshot. SPDK cannot revert a lvol in place, so the snapshot is cloned
+// to a temporary lvol, which is inflated and renamed to the origin lvol after the origin is deleted.
+// Like merged LVM snapshot, the snapshot is deleted after restoring. The origin lvol must not be in use,
+// so caller must remove the target of origin volume first and expose it again after restoring.
+func (pe *SpdkLvsPoolEngine) RestoreSnapshot(req RestoreSnapshotRequest) (err error) {
+	klog.Info("restoring snapshot of Spdk lvol ", req)
+	var (
+		tmpName        = GetRestoreTmpVolName(req.OriginName)
+		snapFullName   = fmt.Sprintf("%s/%s", pe.LvsName, req.SnapshotName)
+		originFullName = fmt.Sprintf("%s/%s", pe.LvsName, req.OriginName)
+		tmpFullName    = fmt.Sprintf("%s/%s", pe.LvsName, tmpName)
+		snap, origin   spdk.Bdev
+		snapFound      bool
+		originFound    bool
+		tmpFound       bool
+	)
+
+	if req.OriginName == "" {
+		err = fmt.Errorf("origin name of snapshot %s is empty", req.SnapshotName)
+		return
+	}
+
+	snap, snapFound, err = pe.spdk.GetLvol(snapFullName)
+	if err != nil {
+		return
+	}
+	origin, originFound, err = pe.spdk.GetLvol(originFullName)
+	if err != nil {
+		return
+	}
+	_, tmpFound, err = pe.spdk.GetLvol(tmpFullName)
+	if err != nil {
+		return
+	}
+
+	// the temporary lvol exists only when restoring is in progress
+	if !tmpFound {
+		if !snapFound {
+			klog.Infof("snapshot %s is already restored to %s", snapFullName, originFullName)
+			return
+		}
+		if !originFound {
+			err = fmt.Errorf("origin lvol %s of snapshot %s not found", originFullName, snapFullName)
+			klog.Error(err)
+			return
+		}
+		// the snapshot will be deleted after restoring, which is refused by SPDK if it has other clones
+		for _, clone := range snap.Driver.Lvol.Clones {
+			if clone != req.OriginName {
+				err = fmt.Errorf("snapshot %s has clones %v, which should be deleted or inflated before restoring", snapFullName, snap.Driver.Lvol.Clones)
+				klog.Error(err)
+				return
+			}
+		}
+
+		_, err = pe.spdk.CreateLvolClone(spdk.CreateLvolCloneReq{
+			LVStore:   pe.LvsName,
+			SnapName:  req.SnapshotName,
+			CloneName: tmpName,
+		})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
+	// origin may be expanded after the snapshot is created
+	if originFound {
+		err = pe.spdk.ResizeLvol(spdk.ResizeLvolReq{
+			LvolFullName: tmpFullName,
+			TargetSize:   uint64(origin.BlockSize * origin.NumBlocks),
+		})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
+	err = pe.spdk.InflateLvol(spdk.InflateLvolReq{
+		LVStore:  pe.LvsName,
+		LvolName: tmpName,
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	err = pe.DeleteVolume(req.OriginName)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	err = pe.DeleteVolume(req.SnapshotName)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	err = pe.spdk.RenameLvol(spdk.RenameLvolReq{
+		LVStore: pe.LvsName,
+		OldName: tmpName,
+		NewName: req.OriginName,
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	return
+}
+
+// DeleteSnapshot deletes the snapshot lvol. SPDK refuses to delete a snapshot which has more than one clone,
+// so the clones should be deleted or inflated first.
+func (pe *SpdkLvsPoolEngine) DeleteSnapshot(snapshotName string) (err error) {
+	var (
+		snapFullName = fmt.Sprintf("%s/%s", pe.LvsName, snapshotName)
+		snap         spdk.Bdev
+		found        bool
+	)
+	snap, found, err = pe.spdk.GetLvol(snapFullName)
+	if err != nil || !found {
+		return
+	}
+
+	if clones := snap.Driver.Lvol.Clones; len(clones) > 1 {
+		err = fmt.Errorf("snapshot %s has clones %v, which should be deleted or inflated before deleting the snapshot", snapFullName, clones)
+		klog.Error(err)
+		return
+	}
+
+	return pe.DeleteVolume(snapshotName)
+}
+
+// ChangedBlocks returns clusters allocated by the snapshot and its ancestors which are newer than the base snapshot.
+// Each snapshot lvol only allocates clusters written after its parent snapshot is taken.
+func (pe *SpdkLvsPoolEngine) ChangedBlocks(req ChangedBlocksRequest) (extents []Extent, err error) {
+	var curName = req.SnapshotName
+	// limit the depth of snapshot chain
+	for depth := 0; depth < maxSnapshotChainDepth; depth++ {
+		var (
+			fullName = fmt.Sprintf("%s/%s", pe.LvsName, curName)
+			lvol     client.Bdev
+			found    bool
+			fragmap  client.LvolFragmap
+		)
+		lvol, found, err = pe.spdk.GetLvol(fullName)
+		if err != nil {
+			return
+		}
+		if !found {
+			err = fmt.Errorf("snapshot lvol %s not found", fullName)
+			return
+		}
+
+		fragmap, err = pe.spdk.GetLvolFragmap(fullName)
+		if err != nil {
+			return
+		}
+		for cluster := uint64(0); cluster < fragmap.NumClusters; cluster++ {
+			if fragmap.IsAllocated(cluster) {
+				extents = append(extents, Extent{
+					Offset: int64(cluster * fragmap.ClusterSize),
+					Length: int64(fragmap.ClusterSize),
+				})
+			}
+		}
+
+		curName = lvol.Driver.Lvol.BaseSnapshot
+		if curName == req.BaseSnapshotName {
+			extents = mergeExtents(extents)
+			return
+		}
+		if curName == "" {
+			break
+		}
+	}
+
+	err = fmt.Errorf("%s is not an ancestor of snapshot %s", req.BaseSnapshotName, req.SnapshotName)
+	return
+}
+
+// CloneVolume creates a temporary snapshot of the source lvol, and then clones the snapshot to a new lvol.
+// If req.Inflate is true, the new lvol is inflated and the temporary snapshot is deleted.
+// Otherwise, the temporary snapshot is kept until the new lvol is deleted.
+func (pe *SpdkLvsPoolEngine) CloneVolume(req CloneVolumeRequest) (resp CreateVolumeResponse, err error) {
+	klog.Info("cloning spdk lvol ", req)
+	var (
+		snapName      = GetCloneSnapshotName(req.VolName)
+		cloneFullName = fmt.Sprintf("%s/%s", pe.LvsName, req.VolName)
+		snapFullName  = fmt.Sprintf("%s/%s", pe.LvsName, snapName)
+		list          []spdk.Bdev
+	)
+
+	// check if clone lvol exists. The temporary snapshot may be already deleted after inflation,
+	// so the snapshot must not be created again if the clone exists.
+	list, err = pe.spdk.BdevGetBdevs(spdk.BdevGetBdevsReq{BdevName: cloneFullName})
+	if err != nil && !spdk.IsNotFoundDeviceError(err) {
+		klog.Error(err)
+		return
+	}
+
+	if len(list) == 0 {
+		// reuse the snapshot left by a failed attempt, which is taken before the clone is created
+		list, err = pe.spdk.BdevGetBdevs(spdk.BdevGetBdevsReq{BdevName: snapFullName})
+		if err != nil && !spdk.IsNotFoundDeviceError(err) {
+			klog.Error(err)
+			return
+		}
+		if len(list) == 0 {
+			_, err = pe.spdk.CreateLvolSnapshot(spdk.CreateLvolSnapReq{
+				LvolFullName: fmt.Sprintf("%s/%s", pe.LvsName, req.SourceName),
+				SnapName:     snapName,
+			})
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+		}
+
+		resp.UUID, err = pe.spdk.CreateLvolClone(spdk.CreateLvolCloneReq{
+			LVStore:   pe.LvsName,
+			SnapName:  snapName,
+			CloneName: req.VolName,
+		})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
+	// the size of clone is the same as the source, resize it to the requested size
+	err = pe.spdk.ResizeLvol(spdk.ResizeLvolReq{
+		LvolFullName: cloneFullName,
+		TargetSize:   req.SizeByte,
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	if req.Inflate {
+		list, err = pe.spdk.BdevGetBdevs(spdk.BdevGetBdevsReq{BdevName: snapFullName})
+		if err != nil && !spdk.IsNotFoundDeviceError(err) {
+			klog.Error(err)
+			return
+		}
+		// snapshot is deleted, which means the clone is already inflated
+		if len(list) == 0 {
+			return resp, nil
+		}
+
+		err = pe.spdk.InflateLvol(spdk.InflateLvolReq{
+			LVStore:  pe.LvsName,
+			LvolName: req.VolName,
+		})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+
+		err = pe.DeleteVolume(snapName)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
 	return
 }
 
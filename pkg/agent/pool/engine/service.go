@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"sort"
+
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
 )
 
@@ -9,8 +11,10 @@ type VolumeServiceIface interface {
 	DeleteVolume(volName string) (err error)
 	GetVolume(volName string) (vol VolumeInfo, err error)
 	CreateSnapshot(req CreateSnapshotRequest) (err error)
-	RestoreSnapshot(snapshotName string) (err error)
+	RestoreSnapshot(req RestoreSnapshotRequest) (err error)
+	DeleteSnapshot(snapshotName string) (err error)
 	ExpandVolume(req ExpandVolumeRequest) (err error)
+	CloneVolume(req CloneVolumeRequest) (resp CreateVolumeResponse, err error)
 }
 
 type PoolingInfoIface interface {
@@ -23,6 +27,41 @@ type PoolEngineIface interface {
 	VolumeServiceIface
 }
 
+// ThinPoolIface is implemented by PoolEngine which supports thin provisioning
+type ThinPoolIface interface {
+	// ThinPoolUsage returns data and metadata usage of thin pool. enabled is false if thin provisioning is not enabled.
+	ThinPoolUsage() (usage ThinPoolUsage, enabled bool, err error)
+}
+
+// VolumeActivatorIface is implemented by PoolEngine whose volume should be activated after snapshot is restored
+type VolumeActivatorIface interface {
+	ActivateVolume(volName string) (err error)
+}
+
+// ChangedBlocksIface is implemented by PoolEngine which could compute blocks of volume changed between two snapshots
+type ChangedBlocksIface interface {
+	// ChangedBlocks returns extents of the volume which may be changed after the base snapshot is taken until the snapshot is taken.
+	// The result could be a superset of actually changed blocks.
+	ChangedBlocks(req ChangedBlocksRequest) (extents []Extent, err error)
+}
+
+type ChangedBlocksRequest struct {
+	SnapshotName     string
+	BaseSnapshotName string
+}
+
+// Extent is a range of volume in bytes
+type Extent struct {
+	Offset int64
+	Length int64
+}
+
+type ThinPoolUsage struct {
+	// percentage, e.g. 45.20
+	DataPercent     float64
+	MetadataPercent float64
+}
+
 type VolumeInfo struct {
 	Type     v1.VolumeType
 	LvmLV    *v1.KernelLVol
@@ -32,6 +71,8 @@ type VolumeInfo struct {
 type SpdkLvolBdev struct {
 	Lvol     v1.SpdkLvol
 	SizeByte uint64
+	// number of clusters allocated by the lvol
+	AllocatedClusters int
 }
 
 type StaticInfo struct {
@@ -47,6 +88,8 @@ type CreateVolumeRequest struct {
 	FsType string
 	// LvLayout of lv to create. Optional for LVM
 	LvLayout v1.LVLayout
+	// Thin provisioned volume. For LVM, the LV is created in the thin pool. For SpdkLVS, the lvol is thin provisioned
+	Thin bool
 }
 
 type CreateVolumeResponse struct {
@@ -62,8 +105,54 @@ type CreateSnapshotRequest struct {
 	SizeByte     uint64
 }
 
+type RestoreSnapshotRequest struct {
+	SnapshotName string
+	// name of the origin volume, which is rolled back to the snapshot. Required for SpdkLVS
+	OriginName string
+}
+
+type CloneVolumeRequest struct {
+	// name of the new volume
+	VolName string
+	// name of the source volume in the same pool
+	SourceName string
+	// size of the new volume, must not be smaller than the source volume
+	SizeByte uint64
+	// LvLayout of lv to create. Optional for LVM
+	LvLayout v1.LVLayout
+	// Inflate the cloned lvol so that it does not depend on the temporary snapshot. Only for SpdkLVS
+	Inflate bool
+}
+
 type ExpandVolumeRequest struct {
 	VolName    string
 	TargetSize uint64
 	OriginSize uint64
 }
+
+// GetRestoreTmpVolName returns the name of the temporary volume for restoring snapshot
+func GetRestoreTmpVolName(volName string) string {
+	return volName + "_restore"
+}
+
+// GetCloneSnapshotName returns the name of the temporary snapshot for cloning volume
+func GetCloneSnapshotName(volName string) string {
+	return volName + "_clone_snap"
+}
+
+// mergeExtents sorts extents by offset, and merges overlapping or adjacent extents
+func mergeExtents(extents []Extent) (merged []Extent) {
+	sort.Slice(extents, func(i, j int) bool {
+		return extents[i].Offset < extents[j].Offset
+	})
+	for _, item := range extents {
+		if last := len(merged) - 1; last >= 0 && merged[last].Offset+merged[last].Length >= item.Offset {
+			if end := item.Offset + item.Length; end > merged[last].Offset+merged[last].Length {
+				merged[last].Length = end - merged[last].Offset
+			}
+			continue
+		}
+		merged = append(merged, item)
+	}
+	return
+}
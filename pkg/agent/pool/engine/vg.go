@@ -3,11 +3,14 @@ package engine
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/util"
 	"lite.io/liteio/pkg/util/lvm"
 	"lite.io/liteio/pkg/util/misc"
 	"lite.io/liteio/pkg/util/mount"
+	"lite.io/liteio/pkg/util/osutil"
 	"k8s.io/klog/v2"
 )
 
@@ -18,16 +21,41 @@ const (
 
 var (
 	ErrNotFoundVG = fmt.Errorf("NotFoundVG")
+	// ErrCloneInProgress means data is being copied to the new LV in background. Caller should retry later.
+	ErrCloneInProgress = fmt.Errorf("CloneInProgress")
+
+	// cmdExec is used to copy data between LVs
+	cmdExec = osutil.NewCommandExec()
 )
 
 type LvmPoolEngine struct {
 	VgName  string
 	VgCache v1.KernelLVM
+	// ThinPool is not nil if thin provisioning is enabled
+	ThinPool *ThinPoolOption
+
+	// cloneJobs are background copies of CloneVolume. Key is name of the new LV.
+	cloneLock sync.Mutex
+	cloneJobs map[string]*cloneJob
+}
+
+type cloneJob struct {
+	done    bool
+	err     error
+	devPath string
+}
+
+type ThinPoolOption struct {
+	// Name of the thin pool LV
+	Name string
+	// SizePct is the percentage of VG size for the thin pool when creating it
+	SizePct int
 }
 
 func NewLvmPoolEngine(vgName string) (pe *LvmPoolEngine) {
 	pe = &LvmPoolEngine{
-		VgName: vgName,
+		VgName:    vgName,
+		cloneJobs: make(map[string]*cloneJob),
 	}
 
 	return
@@ -93,7 +121,11 @@ func (pe *LvmPoolEngine) CreateVolume(req CreateVolumeRequest) (resp CreateVolum
 	klog.Info("creating lvm vol ", req)
 	var vol v1.KernelLvol
 
-	vol, err = pe.allocate(req.VolName, req.SizeByte, req.LvLayout)
+	if req.Thin {
+		vol, err = pe.allocateThin(req.VolName, req.SizeByte)
+	} else {
+		vol, err = pe.allocate(req.VolName, req.SizeByte, req.LvLayout)
+	}
 	if err != nil {
 		return
 	}
@@ -152,13 +184,64 @@ func (pe *LvmPoolEngine) CreateSnapshot(req CreateSnapshotRequest) (err error) {
 	return
 }
 
-func (pe *LvmPoolEngine) RestoreSnapshot(snapshotName string) (err error) {
-	klog.Info("restoring snapshot of LVM ", snapshotName)
-	err = pe.mergeSnapshot(snapshotName)
+func (pe *LvmPoolEngine) RestoreSnapshot(req RestoreSnapshotRequest) (err error) {
+	klog.Info("restoring snapshot of LVM ", req.SnapshotName)
+	err = pe.mergeSnapshot(req.SnapshotName)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (pe *LvmPoolEngine) ActivateVolume(volName string) (err error) {
+	klog.Info("activating LVM vol ", volName)
+	return lvm.LvmUtil.ActivateLV(pe.VgName, volName)
+}
+
+func (pe *LvmPoolEngine) DeleteSnapshot(snapshotName string) (err error) {
+	return pe.DeleteVolume(snapshotName)
+}
+
+// ChangedBlocks returns chunks of origin copied to the COW of base snapshot. Chunks changed after the snapshot is taken are included.
+func (pe *LvmPoolEngine) ChangedBlocks(req ChangedBlocksRequest) (extents []Extent, err error) {
+	var (
+		snapExists, baseExists bool
+		snap, base             lvm.LV
+		chunkSize              int64
+		chunks                 []uint64
+	)
+	snapExists, _, snap, err = isVolumeExistent(pe.VgName, req.SnapshotName)
+	if err != nil {
+		return
+	}
+	baseExists, _, base, err = isVolumeExistent(pe.VgName, req.BaseSnapshotName)
+	if err != nil {
+		return
+	}
+	if !snapExists || !baseExists {
+		err = fmt.Errorf("snapshot %s or base snapshot %s not found", req.SnapshotName, req.BaseSnapshotName)
+		return
+	}
+	if snap.Origin == "" || snap.Origin != base.Origin {
+		err = fmt.Errorf("snapshot %s and base snapshot %s have different origins %q and %q", req.SnapshotName, req.BaseSnapshotName, snap.Origin, base.Origin)
+		return
+	}
+
+	chunkSize, chunks, err = lvm.ReadSnapshotExceptions(lvm.CowDevPath(pe.VgName, req.BaseSnapshotName))
 	if err != nil {
+		klog.Error(err)
 		return
 	}
 
+	extents = make([]Extent, 0, len(chunks))
+	for _, chunk := range chunks {
+		extents = append(extents, Extent{
+			Offset: int64(chunk) * chunkSize,
+			Length: chunkSize,
+		})
+	}
+	extents = mergeExtents(extents)
 	return
 }
 
@@ -172,6 +255,134 @@ func (pe *LvmPoolEngine) ExpandVolume(req ExpandVolumeRequest) (err error) {
 	return
 }
 
+// CloneVolume allocates a new LV in the same VG, and copies all data of the source LV to it in background.
+// It returns ErrCloneInProgress until the copy is finished, then the result of the copy is returned once.
+// If the source LV is opened, a temporary snapshot of the source LV is created to get consistent data, and removed after copying.
+func (pe *LvmPoolEngine) CloneVolume(req CloneVolumeRequest) (resp CreateVolumeResponse, err error) {
+	var (
+		vgName   = pe.VgName
+		snapName = GetCloneSnapshotName(req.VolName)
+		srcPath  string
+		srcExist bool
+		srcVol   lvm.LV
+		vol      v1.KernelLvol
+		job      *cloneJob
+	)
+
+	if job = pe.popCloneJob(req.VolName); job != nil {
+		if !job.done {
+			return resp, ErrCloneInProgress
+		}
+		if job.err != nil {
+			return resp, job.err
+		}
+		resp.DevPath = job.devPath
+		return
+	}
+
+	klog.Info("cloning lvm vol ", req)
+	srcExist, _, srcVol, err = isVolumeExistent(vgName, req.SourceName)
+	if err != nil {
+		return
+	}
+	if !srcExist {
+		err = fmt.Errorf("source LV %s not exists in vg %s", req.SourceName, vgName)
+		klog.Error(err)
+		return
+	}
+	if req.SizeByte < srcVol.SizeByte {
+		err = fmt.Errorf("size of new LV %d is smaller than source LV %s %d", req.SizeByte, req.SourceName, srcVol.SizeByte)
+		klog.Error(err)
+		return
+	}
+
+	// use the same layout as source LV, so that striped LV will not be rounded down to a smaller size than source LV
+	if req.LvLayout == "" {
+		switch v1.LVLayout(srcVol.LvLayout) {
+		case v1.LVLayoutLinear, v1.LVLayoutStriped:
+			req.LvLayout = v1.LVLayout(srcVol.LvLayout)
+		}
+	}
+
+	vol, err = pe.allocate(req.VolName, req.SizeByte, req.LvLayout)
+	if err != nil {
+		return
+	}
+
+	// no copy is running, so the snapshot is left by a failed attempt or a restart of agent. Its data may be stale.
+	if snapExist, _, _, errSnap := isVolumeExistent(vgName, snapName); errSnap != nil {
+		return resp, errSnap
+	} else if snapExist {
+		klog.Infof("removing stale clone snapshot %s", snapName)
+		err = pe.DeleteVolume(snapName)
+		if err != nil {
+			return
+		}
+	}
+
+	srcPath = srcVol.DevPath
+	if srcVol.LvDeviceOpen == lvm.LvDeviceOpen {
+		// COW size of snapshot is a quarter of the source LV, at least 4MiB
+		snapSize := srcVol.SizeByte / 4 / uint64(util.FourMiB) * uint64(util.FourMiB)
+		if snapSize < uint64(util.FourMiB) {
+			snapSize = uint64(util.FourMiB)
+		}
+		err = pe.createSnapshot(snapName, req.SourceName, snapSize)
+		if err != nil {
+			return
+		}
+		srcPath = fmt.Sprintf("/dev/%s/%s", vgName, snapName)
+	}
+
+	job = &cloneJob{devPath: vol.DevPath}
+	pe.cloneLock.Lock()
+	pe.cloneJobs[req.VolName] = job
+	pe.cloneLock.Unlock()
+
+	go func() {
+		klog.Infof("copying data from %s to %s", srcPath, vol.DevPath)
+		_, errCopy := cmdExec.ExecCmd("dd", []string{
+			"if=" + srcPath,
+			"of=" + vol.DevPath,
+			"bs=4M",
+			"iflag=direct",
+			"oflag=direct",
+			"conv=fsync",
+		})
+		if errCopy != nil {
+			klog.Error(errCopy)
+		}
+		if srcPath != srcVol.DevPath {
+			if rmErr := pe.DeleteVolume(snapName); rmErr != nil {
+				klog.Error(rmErr)
+			}
+		}
+
+		pe.cloneLock.Lock()
+		job.done = true
+		job.err = errCopy
+		pe.cloneLock.Unlock()
+	}()
+
+	return resp, ErrCloneInProgress
+}
+
+// popCloneJob returns a copy of the clone job of the LV. The job is removed if it is done.
+func (pe *LvmPoolEngine) popCloneJob(volName string) (job *cloneJob) {
+	pe.cloneLock.Lock()
+	defer pe.cloneLock.Unlock()
+
+	running, has := pe.cloneJobs[volName]
+	if !has {
+		return nil
+	}
+	if running.done {
+		delete(pe.cloneJobs, volName)
+	}
+	copied := *running
+	return &copied
+}
+
 func (pe *LvmPoolEngine) allocate(name string, size uint64, lvLayout v1.LVLayout) (vol v1.KernelLvol, err error) {
 	var vgName = pe.VgName
 	var volExists, hasLinearLV bool
@@ -242,6 +453,105 @@ func (pe *LvmPoolEngine) allocate(name string, size uint64, lvLayout v1.LVLayout
 	return
 }
 
+// allocateThin creates a thin LV in the thin pool. The thin pool could be overcommitted.
+func (pe *LvmPoolEngine) allocateThin(name string, size uint64) (vol v1.KernelLvol, err error) {
+	var vgName = pe.VgName
+	var volExists bool
+	var target lvm.LV
+
+	if pe.ThinPool == nil {
+		err = fmt.Errorf("thin provisioning is not enabled in vg %s", vgName)
+		klog.Error(err)
+		return
+	}
+
+	volExists, _, target, err = isVolumeExistent(vgName, name)
+	if err != nil {
+		return
+	}
+
+	if !volExists {
+		klog.Infof("create thin lv %s %d in pool %s", name, size, pe.ThinPool.Name)
+		_, err = lvm.LvmUtil.CreateThinLV(vgName, pe.ThinPool.Name, name, size)
+		if err != nil {
+			klog.Errorf("failed to create thin LV %s, err %+v", name, err)
+			return
+		}
+	} else {
+		klog.Infof("thin LV %s already exists", name)
+		if target.SizeByte != size {
+			err = fmt.Errorf("LV %s size is %d, but want %d", name, target.SizeByte, size)
+			return
+		}
+	}
+
+	vol.DevPath = fmt.Sprintf("/dev/%s/%s", vgName, name)
+	vol.Name = name
+
+	return
+}
+
+func (pe *LvmPoolEngine) ThinPoolUsage() (usage ThinPoolUsage, enabled bool, err error) {
+	if pe.ThinPool == nil {
+		return
+	}
+	enabled = true
+
+	var poolExists bool
+	var target lvm.LV
+	poolExists, _, target, err = isVolumeExistent(pe.VgName, pe.ThinPool.Name)
+	if err != nil {
+		return
+	}
+	if !poolExists {
+		err = fmt.Errorf("thin pool %s not exists in vg %s", pe.ThinPool.Name, pe.VgName)
+		return
+	}
+
+	usage.DataPercent = target.DataPercent
+	usage.MetadataPercent = target.MetadataPercent
+	return
+}
+
+// ensureThinPool creates the thin pool in VG if it does not exist
+func (pe *LvmPoolEngine) ensureThinPool(vg lvm.VG) (pool *v1.KernelThinPool, err error) {
+	var poolExists bool
+	var target lvm.LV
+	poolExists, _, target, err = isVolumeExistent(vg.Name, pe.ThinPool.Name)
+	if err != nil {
+		return
+	}
+
+	if !poolExists {
+		size := vg.TotalByte * uint64(pe.ThinPool.SizePct) / 100
+		// thin pool needs extra space for metadata LV
+		if size > vg.FreeByte {
+			size = vg.FreeByte * uint64(pe.ThinPool.SizePct) / 100
+		}
+		if vg.ExtendSize > 0 {
+			size = size / vg.ExtendSize * vg.ExtendSize
+		}
+		if size == 0 {
+			err = fmt.Errorf("no free space in vg %s for thin pool %s", vg.Name, pe.ThinPool.Name)
+			klog.Error(err)
+			return
+		}
+
+		klog.Infof("create thin pool %s %d in vg %s", pe.ThinPool.Name, size, vg.Name)
+		target, err = lvm.LvmUtil.CreateThinPool(vg.Name, pe.ThinPool.Name, size)
+		if err != nil {
+			klog.Errorf("failed to create thin pool %s, err %+v", pe.ThinPool.Name, err)
+			return
+		}
+	}
+
+	pool = &v1.KernelThinPool{
+		Name:  pe.ThinPool.Name,
+		Bytes: target.SizeByte,
+	}
+	return
+}
+
 func (pe *LvmPoolEngine) createSnapshot(snapVol, originVol string, size uint64) (err error) {
 	klog.Info("creating snapshot in vg %s", pe.VgName)
 
@@ -350,6 +660,13 @@ func (pe *LvmPoolEngine) initialize(vgName string) (result v1.KernelLVM, err err
 			}
 
 			klog.Infof("found VG %s as StoragePool. TotalSpace: %d, FreeSpace: %d", item.Name, totalBytes, freeBytes)
+
+			if pe.ThinPool != nil {
+				result.ThinPool, err = pe.ensureThinPool(item)
+				if err != nil {
+					return
+				}
+			}
 		}
 	}
 
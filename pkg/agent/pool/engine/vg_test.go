@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	lvmmock "lite.io/liteio/pkg/generated/mocks/lvm"
+	utilmock "lite.io/liteio/pkg/generated/mocks/util"
+	"lite.io/liteio/pkg/util/lvm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLvmCloneVolume(t *testing.T) {
+	var (
+		pe       = NewLvmPoolEngine("vg-1")
+		lvmMock  = lvmmock.NewLvmIface(t)
+		execMock = utilmock.NewShellExec(t)
+		origLvm  = lvm.LvmUtil
+		origExec = cmdExec
+		snapName = GetCloneSnapshotName("vol-1")
+		req      = CloneVolumeRequest{VolName: "vol-1", SourceName: "src", SizeByte: 1 << 30}
+		copying  = make(chan struct{})
+		lock     sync.Mutex
+		lvs      = []lvm.LV{
+			{Name: "src", DevPath: "/dev/vg-1/src", SizeByte: 1 << 30, LvLayout: string(v1.LVLayoutLinear), LvDeviceOpen: lvm.LvDeviceOpen},
+			// snapshot left by a failed attempt
+			{Name: snapName, DevPath: "/dev/vg-1/" + snapName},
+		}
+		hasLV = func(name string) bool {
+			lock.Lock()
+			defer lock.Unlock()
+			for _, lv := range lvs {
+				if lv.Name == name {
+					return true
+				}
+			}
+			return false
+		}
+		ddArgs = func(src string) []string {
+			return []string{"if=" + src, "of=/dev/vg-1/vol-1", "bs=4M", "iflag=direct", "oflag=direct", "conv=fsync"}
+		}
+		resp CreateVolumeResponse
+		err  error
+	)
+	lvm.LvmUtil = lvmMock
+	cmdExec = execMock
+	defer func() {
+		lvm.LvmUtil = origLvm
+		cmdExec = origExec
+	}()
+
+	lvmMock.On("ListLVInVG", "vg-1").Return(func(string) ([]lvm.LV, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]lvm.LV(nil), lvs...), nil
+	}).
+		On("CreateLinearLV", "vg-1", "vol-1", lvm.LvOption{Size: 1 << 30}).Run(func(args mock.Arguments) {
+		lock.Lock()
+		defer lock.Unlock()
+		lvs = append(lvs, lvm.LV{Name: "vol-1", DevPath: "/dev/vg-1/vol-1", SizeByte: 1 << 30, LvLayout: string(v1.LVLayoutLinear)})
+	}).Return(lvm.LV{}, nil).Once().
+		On("CreateSnapshotLinear", "vg-1", snapName, "src", uint64(256<<20)).Run(func(args mock.Arguments) {
+		lock.Lock()
+		defer lock.Unlock()
+		lvs = append(lvs, lvm.LV{Name: snapName, DevPath: "/dev/vg-1/" + snapName})
+	}).Return(nil).Once().
+		On("RemoveLV", "vg-1", snapName).Run(func(args mock.Arguments) {
+		lock.Lock()
+		defer lock.Unlock()
+		for idx, lv := range lvs {
+			if lv.Name == snapName {
+				lvs = append(lvs[:idx], lvs[idx+1:]...)
+				break
+			}
+		}
+	}).Return(nil).Twice()
+	execMock.On("ExecCmd", "dd", ddArgs("/dev/vg-1/"+snapName)).Run(func(args mock.Arguments) {
+		<-copying
+	}).Return(nil, nil).Once()
+
+	// stale snapshot is replaced, and data is copied from the new snapshot of the opened source in background
+	_, err = pe.CloneVolume(req)
+	assert.Equal(t, ErrCloneInProgress, err)
+	_, err = pe.CloneVolume(req)
+	assert.Equal(t, ErrCloneInProgress, err)
+	assert.True(t, hasLV(snapName))
+
+	// result of the copy is returned once, and the snapshot is removed
+	close(copying)
+	assert.Eventually(t, func() bool {
+		resp, err = pe.CloneVolume(req)
+		return err != ErrCloneInProgress
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/vg-1/vol-1", resp.DevPath)
+	assert.False(t, hasLV(snapName))
+
+	// the next call copies again. failed copy is returned, and the new LV is kept for retrying
+	lock.Lock()
+	lvs[0].LvDeviceOpen = ""
+	lock.Unlock()
+	execMock.On("ExecCmd", "dd", ddArgs("/dev/vg-1/src")).Return(nil, fmt.Errorf("io error")).Once()
+	_, err = pe.CloneVolume(req)
+	assert.Equal(t, ErrCloneInProgress, err)
+	assert.Eventually(t, func() bool {
+		_, err = pe.CloneVolume(req)
+		return err != ErrCloneInProgress
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.EqualError(t, err, "io error")
+	assert.True(t, hasLV("vol-1"))
+}
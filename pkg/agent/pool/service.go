@@ -29,7 +29,7 @@ type PoolService struct {
 	access AccessIface
 }
 
-func NewPoolService(cfg config.StorageStack) (ps *PoolService, err error) {
+func NewPoolService(cfg config.StorageStack, trans config.TransportConfig) (ps *PoolService, err error) {
 	var (
 		mode     v1.PoolMode = cfg.Pooling.Mode
 		spdkSvc  spdk.SpdkServiceIface
@@ -42,6 +42,7 @@ func NewPoolService(cfg config.StorageStack) (ps *PoolService, err error) {
 	spdkSvc, err = spdk.NewSpdkService(spdk.SpdkServiceConfig{
 		CliGenFn:     spdk.NewWithDefaultSock,
 		AllowAnyHost: false,
+		EnableRDMA:   trans.EnableRDMA,
 	})
 	if err != nil {
 		// For LVM pool mode, spdk service is used to create Target subsystem. Without spdk service, local disk could still work.
@@ -57,7 +58,14 @@ func NewPoolService(cfg config.StorageStack) (ps *PoolService, err error) {
 
 	switch mode {
 	case v1.PoolModeKernelLVM:
-		poolEng = engine.NewLvmPoolEngine(cfg.Pooling.Name)
+		lvmEng := engine.NewLvmPoolEngine(cfg.Pooling.Name)
+		if cfg.Pooling.ThinPool != nil {
+			lvmEng.ThinPool = &engine.ThinPoolOption{
+				Name:    cfg.Pooling.ThinPool.Name,
+				SizePct: cfg.Pooling.ThinPool.SizePct,
+			}
+		}
+		poolEng = lvmEng
 	case v1.PoolModeSpdkLVStore:
 		poolEng = engine.NewSpdkLvsPoolEngine(cfg.Pooling.Name, spdkSvc)
 	}
@@ -10,6 +10,7 @@ import (
 	"lite.io/liteio/pkg/controller/kubeutil"
 	"lite.io/liteio/pkg/generated/clientset/versioned"
 	antstorinformers "lite.io/liteio/pkg/generated/informers/externalversions"
+	"lite.io/liteio/pkg/spdk/hostnqn"
 	"lite.io/liteio/pkg/spdk/jsonrpc/nvme"
 	"lite.io/liteio/pkg/util/lvm"
 	"lite.io/liteio/pkg/util/misc"
@@ -73,9 +74,9 @@ func (r *DataControlReconciler) Reconcile(ctx context.Context, req reconcile.Req
 		return r.handleDeletion(ctx, dataControl)
 	}
 
-	// skip ready datacontrol
+	// check raid members of ready datacontrol
 	if dataControl.Status.Status == v1.VolumeStatusReady {
-		return reconcile.Result{}, nil
+		return r.syncRaidMembers(ctx, dataControl)
 	}
 
 	// Step-1: VolumeGroup must be ready
@@ -153,6 +154,7 @@ func (r *DataControlReconciler) Reconcile(ctx context.Context, req reconcile.Req
 					out, err = nvmeCli.ConnectTarget(transType, target.Address, target.SvcID, target.SubsysNQN, nvme.ConnectTargetOpts{
 						ReconnectDelaySec: 2,
 						CtrlLossTMO:       10,
+						HostNQN:           hostnqn.HostNQNValue,
 					})
 					if err != nil {
 						klog.Error(err)
@@ -229,7 +231,7 @@ func (r *DataControlReconciler) Reconcile(ctx context.Context, req reconcile.Req
 				lvmControl.VG = vgName
 			}
 
-			// 5. create linear lvol
+			// 5. create linear or raid lvol
 			lvs, err = lvm.LvmUtil.ListLVInVG(vgName)
 			if err != nil {
 				klog.Error(err)
@@ -243,9 +245,17 @@ func (r *DataControlReconciler) Reconcile(ctx context.Context, req reconcile.Req
 				}
 			}
 			if !foundLV {
-				_, err = lvm.LvmUtil.CreateLinearLV(vgName, lvName, lvm.LvOption{
-					LogicSize: "100%FREE",
-				})
+				if dataControl.Spec.Raid.IsRedundant() {
+					_, err = lvm.LvmUtil.CreateRaidLV(vgName, lvName, lvm.RaidLvOption{
+						LvOption: lvm.LvOption{LogicSize: "100%FREE"},
+						Level:    string(dataControl.Spec.Raid.Level),
+						PVCount:  len(devs),
+					})
+				} else {
+					_, err = lvm.LvmUtil.CreateLinearLV(vgName, lvName, lvm.LvOption{
+						LogicSize: "100%FREE",
+					})
+				}
 				if err != nil {
 					klog.Error(err)
 					return reconcile.Result{RequeueAfter: twentySec}, nil
@@ -337,7 +347,12 @@ func (r *DataControlReconciler) handleDeletion(ctx context.Context, dataControl
 		}
 
 		// 3. disconnect target by nqn
-		for _, item := range dataControl.Spec.LVM.PVs {
+		var pvsToDisconnect = dataControl.Spec.LVM.PVs
+		if rebuild := dataControl.Status.Rebuild; rebuild != nil && rebuild.Phase != v1.RebuildPhaseFinished {
+			// the missing member may be still connected
+			pvsToDisconnect = append([]v1.LVMControlPV{rebuild.OldPV}, pvsToDisconnect...)
+		}
+		for _, item := range pvsToDisconnect {
 			if item.TargetInfo.SubsysNQN != "" {
 				// "nvme disconnect" command is reentrant. if NQN device is not connected, the command return 0 exit-code.
 				var out []byte
@@ -0,0 +1,249 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/spdk/hostnqn"
+	"lite.io/liteio/pkg/spdk/jsonrpc/nvme"
+	"lite.io/liteio/pkg/util/lvm"
+	"lite.io/liteio/pkg/util/misc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// interval of checking members of raid LV
+	raidCheckInterval = time.Minute
+)
+
+// syncRaidMembers checks PVs of the redundant raid LV of datacontrol.
+// Missing PVs are reported to status, and controller allocates replacement volumes for them.
+// Replacement PVs in spec are added to VG, and the raid LV is repaired with them.
+// After data is rebuilt, the missing PVs are removed from VG and the old target is disconnected.
+func (r *DataControlReconciler) syncRaidMembers(ctx context.Context, dataControl *v1.AntstorDataControl) (result reconcile.Result, err error) {
+	var (
+		cli        = r.storeCli.VolumeV1().AntstorDataControls(dataControl.Namespace)
+		lvmControl = dataControl.Spec.LVM
+		statusCopy = dataControl.Status.DeepCopy()
+		pvs        []lvm.PV
+		lvs        []lvm.LV
+		nvmeList   []nvme.NvmeDevice
+	)
+
+	if dataControl.Spec.EngineType != v1.PoolModeKernelLVM || lvmControl == nil || lvmControl.VG == "" || !dataControl.Spec.Raid.IsRedundant() {
+		return
+	}
+
+	// 1. add replacement PVs to VG and repair raid LV
+	if hasReplacementPV(lvmControl) {
+		err = repairRaidLV(lvmControl)
+		if err != nil {
+			klog.Error(err)
+			return reconcile.Result{RequeueAfter: twentySec}, nil
+		}
+		// save device path of new PVs
+		_, err = cli.Update(ctx, dataControl, metav1.UpdateOptions{})
+		if err != nil {
+			klog.Error(err)
+		}
+		return reconcile.Result{RequeueAfter: twentySec}, nil
+	}
+
+	// 2. find missing PVs
+	pvs, err = lvm.LvmUtil.ListPV()
+	if err != nil {
+		klog.Error(err)
+		return reconcile.Result{RequeueAfter: twentySec}, nil
+	}
+	nvmeList, err = nvme.NewClientWithCmdPath(nvmeClientFilePath).ListNvmeDisk()
+	if err != nil {
+		klog.Error(err)
+		return reconcile.Result{RequeueAfter: twentySec}, nil
+	}
+	dataControl.Status.MissingPVs = findMissingPVs(lvmControl, pvs, nvmeList)
+
+	// 3. update rebuilding progress
+	if rebuild := dataControl.Status.Rebuild; rebuild != nil && rebuild.Phase == v1.RebuildPhaseRebuilding {
+		lvs, err = lvm.LvmUtil.ListLVInVG(lvmControl.VG)
+		if err != nil {
+			klog.Error(err)
+			return reconcile.Result{RequeueAfter: twentySec}, nil
+		}
+		for _, item := range lvs {
+			if item.Name == lvmControl.LVol {
+				rebuild.SyncPercent = int(item.CopyPercent)
+			}
+		}
+
+		if rebuild.SyncPercent >= 100 {
+			klog.Infof("raid LV %s/%s is rebuilt, removing missing PVs", lvmControl.VG, lvmControl.LVol)
+			err = lvm.LvmUtil.ReduceVGMissing(lvmControl.VG)
+			if err != nil {
+				klog.Error(err)
+				return reconcile.Result{RequeueAfter: twentySec}, nil
+			}
+
+			if nqn := rebuild.OldPV.TargetInfo.SubsysNQN; nqn != "" {
+				var out []byte
+				out, err = nvme.NewClientWithCmdPath(nvmeClientFilePath).DisconnectTarget(nvme.DisconnectTargetRequest{
+					NQN: nqn,
+				})
+				if err != nil {
+					klog.Error(err, string(out))
+					return reconcile.Result{RequeueAfter: twentySec}, nil
+				}
+			}
+			rebuild.Phase = v1.RebuildPhaseSynced
+		}
+	}
+
+	if !reflect.DeepEqual(statusCopy, &dataControl.Status) {
+		_, err = cli.UpdateStatus(ctx, dataControl, metav1.UpdateOptions{})
+		if err != nil {
+			klog.Error(err)
+			return reconcile.Result{RequeueAfter: twentySec}, nil
+		}
+	}
+
+	return reconcile.Result{RequeueAfter: raidCheckInterval}, nil
+}
+
+// findMissingPVs returns members of raid whose PV is lost in VG.
+// Device path of a PV may change after the target is reconnected, so the device is found by serial number of the target.
+func findMissingPVs(lvmControl *v1.LVMControl, pvs []lvm.PV, nvmeList []nvme.NvmeDevice) (missing []v1.EntityIdentity) {
+	var (
+		pvInVG      = misc.NewEmptySet()
+		devBySerial = make(map[string]string, len(nvmeList))
+	)
+	for _, pv := range pvs {
+		if pv.VgName == lvmControl.VG {
+			pvInVG.Add(pv.PvName)
+		}
+	}
+	for _, dev := range nvmeList {
+		devBySerial[dev.SerialNumber] = dev.DevicePath
+	}
+
+	for _, item := range lvmControl.PVs {
+		var devPath = item.DevPath
+		if sn := item.TargetInfo.SerialNum; sn != "" {
+			devPath = devBySerial[sn]
+		}
+		if devPath == "" || !pvInVG.Contains(devPath) {
+			klog.Warningf("PV of volume %s (serial %q, device %q) is missing in VG %s", item.VolId.Name, item.TargetInfo.SerialNum, devPath, lvmControl.VG)
+			missing = append(missing, item.VolId)
+		}
+	}
+	return
+}
+
+// hasReplacementPV returns true if there is any PV without device path, which is a replacement added by controller
+func hasReplacementPV(lvmControl *v1.LVMControl) bool {
+	for _, item := range lvmControl.PVs {
+		if item.DevPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// repairRaidLV connects targets of replacement PVs, adds them to VG, and repairs raid LV with them.
+// DevPath of the replacement PVs are set if it succeeds.
+func repairRaidLV(lvmControl *v1.LVMControl) (err error) {
+	var (
+		nvmeCli      = nvme.NewClientWithCmdPath(nvmeClientFilePath)
+		subsysList   nvme.SubsystemList
+		nvmeList     []nvme.NvmeDevice
+		pvs          []lvm.PV
+		out          []byte
+		connectedNQN []string
+		devs         []string
+		devByIdx     = make(map[int]string)
+		pvByName     = make(map[string]lvm.PV)
+	)
+
+	subsysList, err = nvmeCli.ListSubsystems()
+	if err != nil {
+		return
+	}
+	for _, subsys := range subsysList.Subsystems {
+		connectedNQN = append(connectedNQN, subsys.NQN)
+	}
+
+	for _, item := range lvmControl.PVs {
+		target := item.TargetInfo
+		if item.DevPath != "" || misc.InSliceString(target.SubsysNQN, connectedNQN) {
+			continue
+		}
+		out, err = nvmeCli.ConnectTarget(strings.ToLower(target.TransType), target.Address, target.SvcID, target.SubsysNQN, nvme.ConnectTargetOpts{
+			ReconnectDelaySec: 2,
+			CtrlLossTMO:       10,
+			HostNQN:           hostnqn.HostNQNValue,
+		})
+		if err != nil {
+			return
+		}
+		klog.Infof("connect spdk target %+v, output %s", target, string(out))
+	}
+
+	nvmeList, err = nvmeCli.ListNvmeDisk()
+	if err != nil {
+		return
+	}
+	pvs, err = lvm.LvmUtil.ListPV()
+	if err != nil {
+		return
+	}
+	for _, pv := range pvs {
+		pvByName[pv.PvName] = pv
+	}
+
+	for idx, item := range lvmControl.PVs {
+		if item.DevPath != "" {
+			continue
+		}
+		var devPath string
+		for _, dev := range nvmeList {
+			if dev.SerialNumber == item.TargetInfo.SerialNum {
+				devPath = dev.DevicePath
+			}
+		}
+		if devPath == "" {
+			err = fmt.Errorf("not found device of target %+v", item.TargetInfo)
+			return
+		}
+
+		pv, has := pvByName[devPath]
+		if !has {
+			err = lvm.LvmUtil.CreatePV([]string{devPath})
+			if err != nil {
+				return
+			}
+		}
+		if pv.VgName != lvmControl.VG {
+			err = lvm.LvmUtil.ExtendVG(lvmControl.VG, []string{devPath})
+			if err != nil {
+				return
+			}
+		}
+		devs = append(devs, devPath)
+		devByIdx[idx] = devPath
+	}
+
+	klog.Infof("repair raid LV %s/%s with PVs %+v", lvmControl.VG, lvmControl.LVol, devs)
+	err = lvm.LvmUtil.RepairLV(lvmControl.VG, lvmControl.LVol, devs)
+	if err != nil {
+		return
+	}
+
+	for idx, devPath := range devByIdx {
+		lvmControl.PVs[idx].DevPath = devPath
+	}
+	return
+}
@@ -0,0 +1,49 @@
+package sync
+
+import (
+	"testing"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/spdk/jsonrpc/nvme"
+	"lite.io/liteio/pkg/util/lvm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMissingPVs(t *testing.T) {
+	var (
+		newPV = func(name, devPath, sn string) v1.LVMControlPV {
+			return v1.LVMControlPV{
+				DevPath:    devPath,
+				VolId:      v1.EntityIdentity{Name: name, UUID: "uuid-" + name},
+				TargetInfo: v1.SpdkTarget{SerialNum: sn},
+			}
+		}
+		lvmControl = &v1.LVMControl{
+			VG: "vg-dc",
+			PVs: []v1.LVMControlPV{
+				// device path is changed after reconnecting
+				newPV("renamed", "/dev/nvme0n1", "sn-renamed"),
+				// target is disconnected, and the old device path is taken by another target
+				newPV("lost", "/dev/nvme1n1", "sn-lost"),
+				// device is not a PV of VG
+				newPV("not-in-vg", "/dev/nvme2n1", "sn-not-in-vg"),
+				// no serial number, device path is used
+				newPV("no-sn", "/dev/sdb", ""),
+			},
+		}
+		pvs = []lvm.PV{
+			{PvName: "/dev/nvme3n1", VgName: "vg-dc"},
+			{PvName: "/dev/nvme1n1", VgName: "vg-dc"},
+			{PvName: "/dev/nvme2n1", VgName: "vg-other"},
+			{PvName: "/dev/sdb", VgName: "vg-dc"},
+		}
+		nvmeList = []nvme.NvmeDevice{
+			{DevicePath: "/dev/nvme3n1", SerialNumber: "sn-renamed"},
+			{DevicePath: "/dev/nvme1n1", SerialNumber: "sn-other"},
+			{DevicePath: "/dev/nvme2n1", SerialNumber: "sn-not-in-vg"},
+		}
+	)
+
+	missing := findMissingPVs(lvmControl, pvs, nvmeList)
+	assert.Equal(t, []v1.EntityIdentity{lvmControl.PVs[1].VolId, lvmControl.PVs[2].VolId}, missing)
+}
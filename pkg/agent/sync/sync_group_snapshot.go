@@ -0,0 +1,135 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/kubeutil"
+	"lite.io/liteio/pkg/generated/clientset/versioned"
+	antstorinformers "lite.io/liteio/pkg/generated/informers/externalversions"
+	"lite.io/liteio/pkg/util/lvm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// GroupSnapshotReconciler freezes and thaws IO of the host LV of DataControl for AntstorVolumeGroupSnapshot.
+// Member snapshots are created by controller while IO is frozen.
+type GroupSnapshotReconciler struct {
+	nodeID   string
+	storeCli versioned.Interface
+}
+
+func NewGroupSnapshotReconciler(nodeID string, storeCli versioned.Interface) *GroupSnapshotReconciler {
+	return &GroupSnapshotReconciler{
+		nodeID:   nodeID,
+		storeCli: storeCli,
+	}
+}
+
+func (r *GroupSnapshotReconciler) Start(ctx context.Context) (err error) {
+	informerFactory := antstorinformers.NewFilteredSharedInformerFactory(r.storeCli, time.Hour, v1.DefaultNamespace, func(lo *metav1.ListOptions) {
+		lo.LabelSelector = fmt.Sprintf("%s=%s", v1.TargetNodeIdLabelKey, r.nodeID)
+	})
+
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+	informer := informerFactory.Volume().V1().AntstorVolumeGroupSnapshots().Informer()
+	informer.AddEventHandler(kubeutil.CommonResourceEventHandlerFuncs(queue))
+
+	go informer.Run(ctx.Done())
+
+	kubeutil.NewSimpleController("agent-groupsnapshot", queue, r).Start(ctx)
+	return
+}
+
+func (r *GroupSnapshotReconciler) Reconcile(ctx context.Context, req reconcile.Request) (result reconcile.Result, err error) {
+	var (
+		cli     = r.storeCli.VolumeV1().AntstorVolumeGroupSnapshots(req.Namespace)
+		gs      *v1.AntstorVolumeGroupSnapshot
+		dc      *v1.AntstorDataControl
+		timeout time.Duration
+	)
+
+	gs, err = cli.Get(ctx, req.Name, metav1.GetOptions{})
+	if err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	timeout = time.Duration(gs.Spec.FreezeTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = v1.DefaultFreezeTimeoutSeconds * time.Second
+	}
+
+	switch gs.Status.Phase {
+	case v1.GroupSnapshotFreezing, v1.GroupSnapshotSnapshotting, v1.GroupSnapshotThawing:
+	default:
+		return
+	}
+
+	dc, err = r.storeCli.VolumeV1().AntstorDataControls(gs.Namespace).Get(ctx, gs.Spec.DataControlName, metav1.GetOptions{})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	if dc.Spec.LVM == nil {
+		err = fmt.Errorf("DataControl %s has no LVM", dc.Name)
+		return
+	}
+	var vgName, lvName = dc.Spec.LVM.VG, dc.Spec.LVM.LVol
+
+	switch gs.Status.Phase {
+	case v1.GroupSnapshotFreezing:
+		klog.Infof("freeze IO of %s/%s for group snapshot %s", vgName, lvName, gs.Name)
+		err = lvm.LvmUtil.SuspendLV(vgName, lvName)
+		if err != nil {
+			// nothing is frozen, controller does not need to thaw
+			gs.Status.Phase = v1.GroupSnapshotFailed
+			gs.Status.Message = fmt.Sprintf("freeze IO failed, %s", err.Error())
+			_, err = cli.UpdateStatus(ctx, gs, metav1.UpdateOptions{})
+			return
+		}
+
+		var now = metav1.Now()
+		gs.Status.Phase = v1.GroupSnapshotSnapshotting
+		gs.Status.FreezeTime = &now
+		_, err = cli.UpdateStatus(ctx, gs, metav1.UpdateOptions{})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+		// check the deadline of freezing
+		return reconcile.Result{RequeueAfter: 2 * timeout}, nil
+
+	case v1.GroupSnapshotSnapshotting:
+		// controller requests thawing after timeout. If it does not, thaw IO anyway to avoid hanging applications.
+		if gs.Status.FreezeTime != nil {
+			if remain := 2*timeout - time.Since(gs.Status.FreezeTime.Time); remain > 0 {
+				return reconcile.Result{RequeueAfter: remain}, nil
+			}
+		}
+		klog.Warningf("group snapshot %s is not finished in %s, thaw IO of %s/%s", gs.Name, 2*timeout, vgName, lvName)
+		gs.Status.Message = fmt.Sprintf("IO is frozen more than %s", 2*timeout)
+
+	case v1.GroupSnapshotThawing:
+		klog.Infof("thaw IO of %s/%s for group snapshot %s", vgName, lvName, gs.Name)
+	}
+
+	// resuming a device which is not suspended is a no-op
+	err = lvm.LvmUtil.ResumeLV(vgName, lvName)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	gs.Status.Phase = v1.GroupSnapshotReady
+	if gs.Status.Message != "" {
+		gs.Status.Phase = v1.GroupSnapshotFailed
+	}
+	_, err = cli.UpdateStatus(ctx, gs, metav1.UpdateOptions{})
+	return
+}
@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/generated/clientset/versioned/fake"
+	lvmmock "lite.io/liteio/pkg/generated/mocks/lvm"
+	"lite.io/liteio/pkg/util/lvm"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestGroupSnapshotFreezeAndThaw(t *testing.T) {
+	var (
+		ctx = context.Background()
+		dc  = &v1.AntstorDataControl{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "dc-1"},
+			Spec: v1.AntstorDataControlSpec{
+				LVM: &v1.LVMControl{VG: "vg-1", LVol: "lv-1"},
+			},
+		}
+		newGroupSnapshot = func(name string, phase v1.GroupSnapshotPhase) *v1.AntstorVolumeGroupSnapshot {
+			return &v1.AntstorVolumeGroupSnapshot{
+				ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: name},
+				Spec: v1.AntstorVolumeGroupSnapshotSpec{
+					DataControlName:      "dc-1",
+					FreezeTimeoutSeconds: 10,
+				},
+				Status: v1.AntstorVolumeGroupSnapshotStatus{Phase: phase},
+			}
+		}
+		storeCli = fake.NewSimpleClientset(dc,
+			newGroupSnapshot("gs-ok", v1.GroupSnapshotFreezing),
+			newGroupSnapshot("gs-suspend-failed", v1.GroupSnapshotFreezing))
+		r       = NewGroupSnapshotReconciler("node-1", storeCli)
+		lvmMock = lvmmock.NewLvmIface(t)
+		origLvm = lvm.LvmUtil
+	)
+	lvm.LvmUtil = lvmMock
+	defer func() { lvm.LvmUtil = origLvm }()
+
+	reconcileGs := func(name string) (result reconcile.Result, gs *v1.AntstorVolumeGroupSnapshot) {
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v1.DefaultNamespace, Name: name}})
+		assert.NoError(t, err)
+		gs, err = storeCli.VolumeV1().AntstorVolumeGroupSnapshots(v1.DefaultNamespace).Get(ctx, name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		return
+	}
+
+	// Freezing: host LV is suspended, and deadline of freezing is checked later
+	lvmMock.On("SuspendLV", "vg-1", "lv-1").Return(nil).Once()
+	result, gs := reconcileGs("gs-ok")
+	assert.Equal(t, v1.GroupSnapshotSnapshotting, gs.Status.Phase)
+	assert.NotNil(t, gs.Status.FreezeTime)
+	assert.Equal(t, 20*time.Second, result.RequeueAfter)
+
+	// Snapshotting before deadline: waits for controller
+	result, gs = reconcileGs("gs-ok")
+	assert.Equal(t, v1.GroupSnapshotSnapshotting, gs.Status.Phase)
+	assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter <= 20*time.Second)
+
+	// Thawing: host LV is resumed and group snapshot is ready
+	gs.Status.Phase = v1.GroupSnapshotThawing
+	_, err := storeCli.VolumeV1().AntstorVolumeGroupSnapshots(v1.DefaultNamespace).UpdateStatus(ctx, gs, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	lvmMock.On("ResumeLV", "vg-1", "lv-1").Return(nil).Once()
+	_, gs = reconcileGs("gs-ok")
+	assert.Equal(t, v1.GroupSnapshotReady, gs.Status.Phase)
+	assert.Empty(t, gs.Status.Message)
+
+	// Ready is not handled again
+	_, gs = reconcileGs("gs-ok")
+	assert.Equal(t, v1.GroupSnapshotReady, gs.Status.Phase)
+
+	// Thawing with message: host LV is resumed and group snapshot is failed
+	gs.Status.Phase = v1.GroupSnapshotThawing
+	gs.Status.Message = "member snapshots are not ready in 10s"
+	_, err = storeCli.VolumeV1().AntstorVolumeGroupSnapshots(v1.DefaultNamespace).UpdateStatus(ctx, gs, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	lvmMock.On("ResumeLV", "vg-1", "lv-1").Return(nil).Once()
+	_, gs = reconcileGs("gs-ok")
+	assert.Equal(t, v1.GroupSnapshotFailed, gs.Status.Phase)
+
+	// Snapshotting after deadline: IO is resumed by agent even if controller does not request thawing
+	var frozen = metav1.NewTime(time.Now().Add(-time.Minute))
+	gs.Status.Phase = v1.GroupSnapshotSnapshotting
+	gs.Status.Message = ""
+	gs.Status.FreezeTime = &frozen
+	_, err = storeCli.VolumeV1().AntstorVolumeGroupSnapshots(v1.DefaultNamespace).UpdateStatus(ctx, gs, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	lvmMock.On("ResumeLV", "vg-1", "lv-1").Return(nil).Once()
+	_, gs = reconcileGs("gs-ok")
+	assert.Equal(t, v1.GroupSnapshotFailed, gs.Status.Phase)
+	assert.Contains(t, gs.Status.Message, "IO is frozen more than 20s")
+
+	// resume failure is retried
+	gs.Status.Phase = v1.GroupSnapshotThawing
+	gs.Status.Message = ""
+	_, err = storeCli.VolumeV1().AntstorVolumeGroupSnapshots(v1.DefaultNamespace).UpdateStatus(ctx, gs, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	lvmMock.On("ResumeLV", "vg-1", "lv-1").Return(errors.New("dmsetup failed")).Once()
+	_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v1.DefaultNamespace, Name: "gs-ok"}})
+	assert.Error(t, err)
+
+	// suspend failure: nothing is frozen, so the group snapshot fails directly
+	lvmMock.On("SuspendLV", "vg-1", "lv-1").Return(errors.New("dmsetup failed")).Once()
+	_, gs = reconcileGs("gs-suspend-failed")
+	assert.Equal(t, v1.GroupSnapshotFailed, gs.Status.Phase)
+	assert.Contains(t, gs.Status.Message, "freeze IO failed")
+}
@@ -0,0 +1,324 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"lite.io/liteio/pkg/agent/pool"
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/generated/clientset/versioned"
+	"lite.io/liteio/pkg/spdk"
+	"lite.io/liteio/pkg/spdk/hostnqn"
+	"lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"lite.io/liteio/pkg/util/misc"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// interval of checking RAID1 bdevs of replicated volumes
+	replicaSyncInterval = 30 * time.Second
+
+	replicaControllerPrefix = "replica-"
+)
+
+// ReplicaSyncer checks RAID1 bdevs of replicated volumes on the node periodically,
+// so that failures and rebuilding progress of replica legs are reported to volume status.
+type ReplicaSyncer struct {
+	nodeID      string
+	poolService pool.StoragePoolServiceIface
+	storeCli    versioned.Interface
+}
+
+func NewReplicaSyncer(storeCli versioned.Interface, poolSvc pool.StoragePoolServiceIface) *ReplicaSyncer {
+	return &ReplicaSyncer{
+		nodeID:      poolSvc.GetStoragePool().Name,
+		poolService: poolSvc,
+		storeCli:    storeCli,
+	}
+}
+
+func (rs *ReplicaSyncer) Start(ctx context.Context) (err error) {
+	wait.Until(rs.syncAll, replicaSyncInterval, ctx.Done())
+	return
+}
+
+func (rs *ReplicaSyncer) syncAll() {
+	list, err := rs.storeCli.VolumeV1().AntstorVolumes(v1.DefaultNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", v1.TargetNodeIdLabelKey, rs.nodeID),
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	for i := range list.Items {
+		vol := &list.Items[i]
+		if vol.Spec.Replicas <= 1 || vol.DeletionTimestamp != nil || vol.Status.Status != v1.VolumeStatusReady {
+			continue
+		}
+		err = syncReplicas(rs.storeCli, rs.poolService.SpdkService(), vol)
+		if err != nil {
+			klog.Errorf("sync replicas of volume %s failed, %+v", vol.Name, err)
+		}
+	}
+}
+
+// syncReplicas adds new replica legs to the RAID1 bdev of volume, removes the replaced legs, and updates states of legs in volume status.
+// A leg which is removed from RAID1 bdev by SPDK is not added again. It is replaced by controller.
+func syncReplicas(storeCli versioned.Interface, spdkSvc spdk.SpdkServiceIface, volume *v1.AntstorVolume) (err error) {
+	if volume.Spec.Replicas <= 1 || !misc.InSliceString(v1.SpdkTargetFinalizer, volume.Finalizers) {
+		return
+	}
+
+	raid, found, err := spdkSvc.GetRaidBdev(GetRaidBdevName(volume.Spec.Uuid))
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	if !found {
+		err = fmt.Errorf("not found RAID1 bdev of volume %s", volume.Name)
+		klog.Error(err)
+		return
+	}
+
+	var (
+		legBdevs  = make(map[string]bool, len(volume.Spec.ReplicaLegs))
+		baseBdevs = make(map[string]client.RaidBaseBdev, len(raid.BaseBdevsList))
+		states    = make([]v1.ReplicaStatus, 0, len(volume.Spec.ReplicaLegs)+1)
+		local     = v1.ReplicaStatus{
+			Name:         volume.Name,
+			TargetNodeId: volume.Spec.TargetNodeId,
+			State:        v1.ReplicaStateFailed,
+			Message:      "lvol is not configured in RAID1 bdev",
+		}
+	)
+	for _, item := range volume.Spec.ReplicaLegs {
+		legBdevs[pool.RemoteBdev{ControllerName: GetReplicaControllerName(item.UUID)}.BdevName()] = true
+	}
+
+	for _, item := range raid.BaseBdevsList {
+		switch {
+		case item.Name == "":
+			// slot of the removed base bdev
+		case strings.HasPrefix(item.Name, replicaControllerPrefix):
+			if legBdevs[item.Name] {
+				baseBdevs[item.Name] = item
+				continue
+			}
+			// leg is replaced by controller
+			klog.Infof("removing replaced replica %s from RAID1 bdev %s", item.Name, raid.Name)
+			err = spdkSvc.RemoveRaidBaseBdev(item.Name)
+			if err != nil {
+				return
+			}
+			err = spdkSvc.DetachMigrationDestBdev(strings.TrimSuffix(item.Name, "n1"))
+			if err != nil {
+				return
+			}
+		default:
+			if item.IsConfigured {
+				local.State = v1.ReplicaStateOnline
+				local.Message = ""
+			}
+		}
+	}
+	states = append(states, local)
+
+	for _, item := range volume.Spec.ReplicaLegs {
+		var (
+			leg    *v1.AntstorVolume
+			remote pool.RemoteBdev
+			errLeg error
+			state  = v1.ReplicaStatus{Name: item.Name}
+		)
+		leg, err = storeCli.VolumeV1().AntstorVolumes(item.Namespace).Get(context.Background(), item.Name, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				klog.Error(err)
+				return
+			}
+			err = nil
+			state.State = v1.ReplicaStateFailed
+			state.Message = "leg volume is not found"
+			states = append(states, state)
+			continue
+		}
+		state.TargetNodeId = leg.Spec.TargetNodeId
+
+		remote, errLeg = newReplicaRemoteBdev(leg)
+		if errLeg != nil {
+			state.State = v1.ReplicaStatePending
+			state.Message = errLeg.Error()
+			states = append(states, state)
+			continue
+		}
+
+		var (
+			bdevName     = remote.BdevName()
+			base, inRaid = baseBdevs[bdevName]
+			prev         = findReplicaStatus(volume.Status.Replicas, item.Name)
+		)
+		switch {
+		case raid.Process != nil && raid.Process.Type == spdk.RaidProcessRebuild && raid.Process.Target == bdevName:
+			state.State = v1.ReplicaStateRebuilding
+			state.RebuildPercent = raid.Process.Progress.Percent
+		case inRaid && base.IsConfigured:
+			state.State = v1.ReplicaStateOnline
+		case inRaid:
+			state.State = v1.ReplicaStateFailed
+			state.Message = "base bdev is not configured in RAID1 bdev"
+		case prev != nil && prev.State != v1.ReplicaStatePending:
+			// SPDK removes the base bdev on IO errors or lost connection
+			state.State = v1.ReplicaStateFailed
+			state.Message = "base bdev is removed from RAID1 bdev"
+		default:
+			// new leg, data is rebuilt to it
+			klog.Infof("adding replica %s to RAID1 bdev %s", bdevName, raid.Name)
+			err = spdkSvc.EnsureMigrationDestBdev(spdk.AttachDestBdevRequest{
+				ControllerName: remote.ControllerName,
+				Target:         remote.Target,
+				HostNQN:        remote.HostNQN,
+			})
+			if err != nil {
+				return
+			}
+			err = spdkSvc.AddRaidBaseBdev(raid.Name, bdevName)
+			if err != nil {
+				return
+			}
+			state.State = v1.ReplicaStateRebuilding
+		}
+		states = append(states, state)
+	}
+
+	if !reflect.DeepEqual(states, volume.Status.Replicas) {
+		volume.Status.Replicas = states
+		_, err = storeCli.VolumeV1().AntstorVolumes(volume.Namespace).UpdateStatus(context.Background(), volume, metav1.UpdateOptions{})
+	}
+	return
+}
+
+// prepareRaidBdev returns the RAID1 bdev over lvol and replica legs of volume. It is nil if the volume is not replicated.
+// All legs must be ready before the RAID1 bdev is created. Only online legs are in sync with the lvol, the others are rebuilt.
+func (vs *VolumeSyncer) prepareRaidBdev(volume *v1.AntstorVolume) (raid *pool.RaidBdev, err error) {
+	if volume.Spec.Replicas <= 1 {
+		return
+	}
+	if len(volume.Spec.ReplicaLegs) < volume.Spec.Replicas-1 {
+		err = fmt.Errorf("volume %s has %d replica legs, waiting for %d legs", volume.Name, len(volume.Spec.ReplicaLegs), volume.Spec.Replicas-1)
+		return
+	}
+
+	raid = &pool.RaidBdev{
+		BdevName: GetRaidBdevName(volume.Spec.Uuid),
+	}
+	// lvol and legs of a new volume are all empty before the RAID1 bdev is created for the first time
+	var hasData = len(volume.Status.Replicas) > 0 ||
+		volume.Labels[v1.VolumeSourceSnapNameLabelKey] != "" || volume.Labels[v1.VolumeSourceVolNameLabelKey] != ""
+	for _, item := range volume.Spec.ReplicaLegs {
+		var (
+			leg    *v1.AntstorVolume
+			remote pool.RemoteBdev
+		)
+		leg, err = vs.storeCli.VolumeV1().AntstorVolumes(item.Namespace).Get(context.Background(), item.Name, metav1.GetOptions{})
+		if err != nil {
+			klog.Error(err)
+			return nil, err
+		}
+		remote, err = newReplicaRemoteBdev(leg)
+		if err != nil {
+			return nil, err
+		}
+		if state := findReplicaStatus(volume.Status.Replicas, item.Name); hasData && (state == nil || state.State != v1.ReplicaStateOnline) {
+			remote.Rebuild = true
+		}
+		raid.Replicas = append(raid.Replicas, remote)
+	}
+	return
+}
+
+// newReplicaRaidBdev returns names of RAID1 bdev and replicas for removing access
+func newReplicaRaidBdev(volume *v1.AntstorVolume) (raid *pool.RaidBdev) {
+	if volume.Spec.Replicas <= 1 {
+		return
+	}
+	raid = &pool.RaidBdev{
+		BdevName: GetRaidBdevName(volume.Spec.Uuid),
+	}
+	for _, item := range volume.Spec.ReplicaLegs {
+		raid.Replicas = append(raid.Replicas, pool.RemoteBdev{
+			ControllerName: GetReplicaControllerName(item.UUID),
+		})
+	}
+	return
+}
+
+func newReplicaRemoteBdev(leg *v1.AntstorVolume) (remote pool.RemoteBdev, err error) {
+	if leg.Status.Status != v1.VolumeStatusReady || leg.Spec.SpdkTarget == nil || leg.Spec.SpdkTarget.SvcID == "" {
+		err = fmt.Errorf("replica leg %s is not ready", leg.Name)
+		return
+	}
+
+	var tgt = leg.Spec.SpdkTarget
+	remote = pool.RemoteBdev{
+		// leg allows its HostNode, which is the node of RAID1 bdev, to connect
+		HostNQN:        hostnqn.HostNQNValue,
+		ControllerName: GetReplicaControllerName(leg.Spec.Uuid),
+		Target: spdk.SpdkTargetInfo{
+			NQN:       tgt.SubsysNQN,
+			AddrFam:   tgt.AddrFam,
+			IPAddr:    tgt.Address,
+			TransType: tgt.TransType,
+			SvcID:     tgt.SvcID,
+		},
+	}
+	return
+}
+
+// markReplicasStale sets legs to pending in volume status, so that data is rebuilt to them when RAID1 bdev is created again.
+// It is required when the lvol is changed without RAID1 bdev, e.g. rolled back to a snapshot.
+func markReplicasStale(volume *v1.AntstorVolume, reason string) {
+	if len(volume.Spec.ReplicaLegs) == 0 {
+		return
+	}
+
+	var states = make([]v1.ReplicaStatus, 0, len(volume.Spec.ReplicaLegs)+1)
+	if local := findReplicaStatus(volume.Status.Replicas, volume.Name); local != nil {
+		states = append(states, *local)
+	}
+	for _, item := range volume.Spec.ReplicaLegs {
+		state := v1.ReplicaStatus{
+			Name:    item.Name,
+			State:   v1.ReplicaStatePending,
+			Message: reason,
+		}
+		if prev := findReplicaStatus(volume.Status.Replicas, item.Name); prev != nil {
+			state.TargetNodeId = prev.TargetNodeId
+		}
+		states = append(states, state)
+	}
+	volume.Status.Replicas = states
+}
+
+func findReplicaStatus(list []v1.ReplicaStatus, name string) *v1.ReplicaStatus {
+	for i := range list {
+		if list[i].Name == name {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func GetRaidBdevName(uuid string) (name string) {
+	return "raid1-" + uuid
+}
+
+func GetReplicaControllerName(uuid string) (name string) {
+	return replicaControllerPrefix + uuid
+}
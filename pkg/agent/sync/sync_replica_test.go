@@ -0,0 +1,244 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"lite.io/liteio/pkg/agent/pool"
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/generated/clientset/versioned/fake"
+	spdkmock "lite.io/liteio/pkg/generated/mocks/spdk"
+	"lite.io/liteio/pkg/spdk"
+	"lite.io/liteio/pkg/spdk/hostnqn"
+	"lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newReplicaLeg(name, nodeID string, ready bool) *v1.AntstorVolume {
+	leg := &v1.AntstorVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: v1.DefaultNamespace,
+			Name:      name,
+			Labels:    map[string]string{v1.ReplicaOfLabelKey: "vol-1"},
+		},
+		Spec: v1.AntstorVolumeSpec{
+			Uuid:         "uuid-" + name,
+			Type:         v1.VolumeTypeSpdkLVol,
+			TargetNodeId: nodeID,
+		},
+		Status: v1.AntstorVolumeStatus{Status: v1.VolumeStatusCreating},
+	}
+	if ready {
+		leg.Status.Status = v1.VolumeStatusReady
+		leg.Spec.SpdkTarget = &v1.SpdkTarget{
+			SubsysNQN: "nqn-" + name,
+			TransType: "TCP",
+			AddrFam:   "IPv4",
+			Address:   "ip-" + nodeID,
+			SvcID:     "4420",
+		}
+	}
+	return leg
+}
+
+func newReplicatedVolume(legs ...string) *v1.AntstorVolume {
+	vol := &v1.AntstorVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  v1.DefaultNamespace,
+			Name:       "vol-1",
+			Finalizers: []string{v1.SpdkTargetFinalizer},
+		},
+		Spec: v1.AntstorVolumeSpec{
+			Uuid:         "uuid-vol-1",
+			Type:         v1.VolumeTypeSpdkLVol,
+			Replicas:     len(legs) + 1,
+			TargetNodeId: "node-1",
+		},
+		Status: v1.AntstorVolumeStatus{Status: v1.VolumeStatusReady},
+	}
+	for _, name := range legs {
+		vol.Spec.ReplicaLegs = append(vol.Spec.ReplicaLegs, v1.EntityIdentity{Namespace: v1.DefaultNamespace, Name: name, UUID: "uuid-" + name})
+	}
+	return vol
+}
+
+func newSpdkServiceWithFakeClient(t *testing.T) (*spdk.SpdkService, *spdkmock.SPDKClientIface) {
+	fakeCli := spdkmock.NewSPDKClientIface(t)
+	fakeCli.On("NVMFGetTransports").Return(nil, nil).Maybe().
+		On("NVMFCreateTransport", mock.Anything).Return(true, nil).Maybe().
+		On("NVMFGetSubsystems", mock.Anything).Return(nil, nil).Maybe()
+
+	svc, err := spdk.NewSpdkService(spdk.SpdkServiceConfig{
+		CliGenFn: func() (client.SPDKClientIface, error) {
+			return fakeCli, nil
+		},
+	})
+	assert.NoError(t, err)
+	return svc, fakeCli
+}
+
+func TestPrepareRaidBdev(t *testing.T) {
+	var (
+		origHostNQN = hostnqn.HostNQNValue
+		vs          = &VolumeSyncer{
+			storeCli: fake.NewSimpleClientset(
+				newReplicaLeg("leg-1", "node-2", true),
+				newReplicaLeg("leg-2", "node-3", true),
+				newReplicaLeg("leg-creating", "node-4", false)),
+		}
+		names = func(raid *pool.RaidBdev) (rebuild map[string]bool) {
+			rebuild = make(map[string]bool)
+			for _, item := range raid.Replicas {
+				rebuild[item.ControllerName] = item.Rebuild
+			}
+			return
+		}
+	)
+	hostnqn.HostNQNValue = "nqn-host-1"
+	defer func() { hostnqn.HostNQNValue = origHostNQN }()
+
+	// volume is not replicated
+	raid, err := vs.prepareRaidBdev(&v1.AntstorVolume{})
+	assert.NoError(t, err)
+	assert.Nil(t, raid)
+
+	// waiting for legs
+	vol := newReplicatedVolume("leg-1")
+	vol.Spec.Replicas = 3
+	_, err = vs.prepareRaidBdev(vol)
+	assert.Error(t, err)
+
+	vol = newReplicatedVolume("leg-1", "leg-creating")
+	_, err = vs.prepareRaidBdev(vol)
+	assert.Error(t, err)
+
+	// new volume is empty, so legs are not rebuilt
+	vol = newReplicatedVolume("leg-1", "leg-2")
+	raid, err = vs.prepareRaidBdev(vol)
+	assert.NoError(t, err)
+	if assert.NotNil(t, raid) {
+		assert.Equal(t, "raid1-uuid-vol-1", raid.BdevName)
+		assert.Equal(t, map[string]bool{"replica-uuid-leg-1": false, "replica-uuid-leg-2": false}, names(raid))
+		assert.Equal(t, "nqn-host-1", raid.Replicas[0].HostNQN)
+		assert.Equal(t, spdk.SpdkTargetInfo{NQN: "nqn-leg-1", AddrFam: "IPv4", IPAddr: "ip-node-2", TransType: "TCP", SvcID: "4420"}, raid.Replicas[0].Target)
+	}
+
+	// only online legs are in sync
+	vol.Status.Replicas = []v1.ReplicaStatus{
+		{Name: "vol-1", State: v1.ReplicaStateOnline},
+		{Name: "leg-1", State: v1.ReplicaStateOnline},
+		{Name: "leg-2", State: v1.ReplicaStateRebuilding},
+	}
+	raid, err = vs.prepareRaidBdev(vol)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"replica-uuid-leg-1": false, "replica-uuid-leg-2": true}, names(raid))
+
+	// data of volume is cloned from snapshot
+	vol = newReplicatedVolume("leg-1", "leg-2")
+	vol.Labels = map[string]string{v1.VolumeSourceSnapNameLabelKey: "snap-1"}
+	raid, err = vs.prepareRaidBdev(vol)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"replica-uuid-leg-1": true, "replica-uuid-leg-2": true}, names(raid))
+}
+
+func TestSyncReplicas(t *testing.T) {
+	var (
+		origHostNQN = hostnqn.HostNQNValue
+		vol         = newReplicatedVolume("leg-1", "leg-2", "leg-new")
+		storeCli    = fake.NewSimpleClientset(vol,
+			newReplicaLeg("leg-1", "node-2", true),
+			newReplicaLeg("leg-2", "node-3", true),
+			newReplicaLeg("leg-new", "node-4", true))
+		svc, fakeCli = newSpdkServiceWithFakeClient(t)
+	)
+	hostnqn.HostNQNValue = "nqn-host-1"
+	defer func() { hostnqn.HostNQNValue = origHostNQN }()
+
+	vol.Status.Replicas = []v1.ReplicaStatus{
+		{Name: "vol-1", State: v1.ReplicaStateOnline},
+		{Name: "leg-1", State: v1.ReplicaStateOnline},
+		{Name: "leg-2", State: v1.ReplicaStateOnline},
+	}
+	// leg-old is replaced by leg-new, and leg-2 is removed by SPDK
+	fakeCli.On("GetBdevRaids", mock.Anything).Return([]client.RaidBdevInfo{
+		{
+			Name: "raid1-uuid-vol-1",
+			BaseBdevsList: []client.RaidBaseBdev{
+				{Name: "lvs/vol-1", IsConfigured: true},
+				{Name: "replica-uuid-leg-1n1", IsConfigured: true},
+				{Name: ""},
+				{Name: "replica-uuid-leg-oldn1", IsConfigured: true},
+			},
+		},
+	}, nil).Once()
+	fakeCli.On("RemoveBaseBdevRaid", client.RemoveBaseBdevRaidRequest{Name: "replica-uuid-leg-oldn1"}).Return(true, nil).Once().
+		On("BdevGetBdevs", client.BdevGetBdevsReq{BdevName: "replica-uuid-leg-oldn1"}).Return([]client.Bdev{{Name: "replica-uuid-leg-oldn1"}}, nil).Once().
+		On("DetachController", client.DetachControllerRequest{Name: "replica-uuid-leg-old"}).Return(nil).Once().
+		// new leg is attached with hostnqn, and added to RAID1 bdev
+		On("BdevGetBdevs", client.BdevGetBdevsReq{BdevName: "replica-uuid-leg-newn1"}).Return(nil, nil).Once().
+		On("AttachController", client.AttachControllerRequest{
+			Name:    "replica-uuid-leg-new",
+			TrType:  "TCP",
+			TrAddr:  "ip-node-4",
+			AdrFam:  "IPv4",
+			TrSvcId: "4420",
+			SubNQN:  "nqn-leg-new",
+			HostNQN: "nqn-host-1",
+		}).Return([]string{"replica-uuid-leg-newn1"}, nil).Once().
+		On("AddBaseBdevRaid", client.AddBaseBdevRaidRequest{RaidBdev: "raid1-uuid-vol-1", BaseBdev: "replica-uuid-leg-newn1"}).Return(true, nil).Once()
+
+	assert.NoError(t, syncReplicas(storeCli, svc, vol))
+	updated, err := storeCli.VolumeV1().AntstorVolumes(v1.DefaultNamespace).Get(context.Background(), "vol-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []v1.ReplicaStatus{
+		{Name: "vol-1", TargetNodeId: "node-1", State: v1.ReplicaStateOnline},
+		{Name: "leg-1", TargetNodeId: "node-2", State: v1.ReplicaStateOnline},
+		{Name: "leg-2", TargetNodeId: "node-3", State: v1.ReplicaStateFailed, Message: "base bdev is removed from RAID1 bdev"},
+		{Name: "leg-new", TargetNodeId: "node-4", State: v1.ReplicaStateRebuilding},
+	}, updated.Status.Replicas)
+
+	// rebuilding progress of the new leg
+	fakeCli.On("GetBdevRaids", mock.Anything).Return([]client.RaidBdevInfo{
+		{
+			Name: "raid1-uuid-vol-1",
+			Process: &client.RaidProcess{
+				Type:     spdk.RaidProcessRebuild,
+				Target:   "replica-uuid-leg-newn1",
+				Progress: client.RaidProcessProgress{Percent: 40},
+			},
+			BaseBdevsList: []client.RaidBaseBdev{
+				{Name: "lvs/vol-1", IsConfigured: true},
+				{Name: "replica-uuid-leg-1n1", IsConfigured: true},
+				{Name: ""},
+				{Name: "replica-uuid-leg-newn1", IsConfigured: true},
+			},
+		},
+	}, nil).Once()
+	assert.NoError(t, syncReplicas(storeCli, svc, updated))
+	updated, err = storeCli.VolumeV1().AntstorVolumes(v1.DefaultNamespace).Get(context.Background(), "vol-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, v1.ReplicaStateRebuilding, updated.Status.Replicas[3].State)
+	assert.Equal(t, 40, updated.Status.Replicas[3].RebuildPercent)
+}
+
+func TestMarkReplicasStale(t *testing.T) {
+	vol := newReplicatedVolume("leg-1", "leg-2")
+	vol.Status.Replicas = []v1.ReplicaStatus{
+		{Name: "vol-1", TargetNodeId: "node-1", State: v1.ReplicaStateOnline},
+		{Name: "leg-1", TargetNodeId: "node-2", State: v1.ReplicaStateOnline},
+		{Name: "leg-old", TargetNodeId: "node-3", State: v1.ReplicaStateFailed},
+	}
+	markReplicasStale(vol, "rolled back")
+	assert.Equal(t, []v1.ReplicaStatus{
+		{Name: "vol-1", TargetNodeId: "node-1", State: v1.ReplicaStateOnline},
+		{Name: "leg-1", TargetNodeId: "node-2", State: v1.ReplicaStatePending, Message: "rolled back"},
+		{Name: "leg-2", State: v1.ReplicaStatePending, Message: "rolled back"},
+	}, vol.Status.Replicas)
+
+	// volume without legs
+	vol = newReplicatedVolume()
+	markReplicasStale(vol, "rolled back")
+	assert.Empty(t, vol.Status.Replicas)
+}
@@ -3,6 +3,8 @@ package sync
 import (
 	"context"
 	"fmt"
+	"os"
+	"path"
 	"strconv"
 	"time"
 
@@ -10,10 +12,14 @@ import (
 	"lite.io/liteio/pkg/agent/pool/engine"
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
 	"lite.io/liteio/pkg/generated/clientset/versioned"
+	"lite.io/liteio/pkg/spdk"
 	"lite.io/liteio/pkg/util/misc"
+	"lite.io/liteio/pkg/util/objstore"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 )
 
@@ -21,12 +27,18 @@ type SnapshotSyncer struct {
 	poolService pool.StoragePoolServiceIface
 	// storeCli is used to read/write StoragePool, AntstorVolumes from APIServer
 	storeCli versioned.Interface
+	// kubeCli is used to read Secrets of object storage credentials
+	kubeCli kubernetes.Interface
+	// exports are running tasks of exporting snapshots
+	exports *transferTasks
 }
 
-func NewSnapshotSyncer(storeCli versioned.Interface, poolSvc pool.StoragePoolServiceIface) *SnapshotSyncer {
+func NewSnapshotSyncer(storeCli versioned.Interface, kubeCli kubernetes.Interface, poolSvc pool.StoragePoolServiceIface) *SnapshotSyncer {
 	return &SnapshotSyncer{
 		poolService: poolSvc,
 		storeCli:    storeCli,
+		kubeCli:     kubeCli,
+		exports:     newTransferTasks(),
 	}
 }
 
@@ -63,7 +75,11 @@ func (ss *SnapshotSyncer) syncOneSnapshot(nsName string) (err error) {
 	if snapshot.DeletionTimestamp != nil {
 		klog.Infof("deleting snapshot %s", name)
 
-		// TODO: recondier snapshot deletion constraint
+		// snapshot lvol is being read by export
+		if ss.exports.cancel(nsName) {
+			err = fmt.Errorf("waiting for export of snapshot %s to stop", nsName)
+			return
+		}
 
 		if misc.InSliceString(v1.SnapshotFinalizer, snapshot.Finalizers) {
 			klog.Infof("deleting snapshot %s lvol, vol type %s", name, string(snapshot.Spec.VolType))
@@ -75,9 +91,10 @@ func (ss *SnapshotSyncer) syncOneSnapshot(nsName string) (err error) {
 				volName = snapshot.Spec.SpdkLvol.Name
 			}
 
-			err = ss.poolService.PoolEngine().DeleteVolume(volName)
+			err = ss.poolService.PoolEngine().DeleteSnapshot(volName)
 			if err != nil {
 				klog.Error(err)
+				ss.updateStatusMessage(snapshot, err.Error())
 				return
 			}
 			// remove v1.SnapshotFinalizer
@@ -100,6 +117,20 @@ func (ss *SnapshotSyncer) syncOneSnapshot(nsName string) (err error) {
 
 	klog.Infof("start syncing snapshot %s lvol", name)
 	if snapshot.Status.Status == v1.SnapshotStatusReady || snapshot.Status.Status == v1.SnapshotStatusMerged {
+		// snapshot created by previous version has no consumed size
+		if snapshot.Status.Status == v1.SnapshotStatusReady && snapshot.Spec.VolType == v1.VolumeTypeSpdkLVol &&
+			snapshot.Status.ConsumedClusters == 0 {
+			if ss.setConsumedSize(snapshot) {
+				_, err = snapCli.UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
+				if err != nil {
+					klog.Error(err)
+				}
+				return
+			}
+		}
+		if snapshot.Status.Status == v1.SnapshotStatusReady {
+			return ss.syncExport(snapshot)
+		}
 		klog.Infof("snapshot %s is already Ready, stop syncing", name)
 		return
 	}
@@ -112,6 +143,9 @@ func (ss *SnapshotSyncer) syncOneSnapshot(nsName string) (err error) {
 		if misc.InSliceString(v1.SnapshotFinalizer, snapshot.Finalizers) {
 			klog.Infof("update snapshot %s to ready", name)
 			snapshot.Status.Status = v1.SnapshotStatusReady
+			if snapshot.Spec.VolType == v1.VolumeTypeSpdkLVol {
+				ss.setConsumedSize(snapshot)
+			}
 			_, err = snapCli.UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
 			if err != nil {
 				klog.Error(err)
@@ -119,11 +153,16 @@ func (ss *SnapshotSyncer) syncOneSnapshot(nsName string) (err error) {
 			return
 		}
 
+		// SpdkLVol snapshots of a volume form a chain, so they are created one by one in the order of Sequence
+		if ss.poolService.Mode() == v1.PoolModeSpdkLVStore && snapshot.Spec.Sequence == 0 {
+			return ss.assignSequence(snapshot)
+		}
+
 		// do create
-		var originName, snapName string
+		var originName string
+		var snapName = snapshotLvolName(snapshot)
 		var sp = ss.poolService.GetStoragePool()
 		if ss.poolService.Mode() == v1.PoolModeKernelLVM {
-			snapName = fmt.Sprintf("%s_snap", snapshot.Spec.OriginVolName)
 			vgName := sp.Spec.KernelLVM.Name
 			originName = snapshot.Spec.OriginVolName
 
@@ -133,7 +172,6 @@ func (ss *SnapshotSyncer) syncOneSnapshot(nsName string) (err error) {
 
 		} else if ss.poolService.Mode() == v1.PoolModeSpdkLVStore {
 			lvsName := sp.Spec.SpdkLVStore.Name
-			snapName = fmt.Sprintf("%s_snap", snapshot.Spec.OriginVolName)
 			originName = fmt.Sprintf("%s/%s", lvsName, snapshot.Spec.OriginVolName)
 
 			snapshot.Spec.SpdkLvol.Name = snapName
@@ -161,13 +199,27 @@ func (ss *SnapshotSyncer) syncOneSnapshot(nsName string) (err error) {
 		return
 	}
 
-	// merge snapshot lvol
-	if snapshot.Spec.VolType == v1.VolumeTypeKernelLVol && snapshot.Status.Status == v1.SnapshotStatusMerging {
+	// snapshot lvol is removed after merging, wait for the export to finish
+	if snapshot.Status.Status == v1.SnapshotStatusMerging && ss.exports.isRunning(nsName) {
+		err = fmt.Errorf("snapshot %s is being exported, wait for it to finish before merging", nsName)
+		klog.Error(err)
+		ss.updateStatusMessage(snapshot, err.Error())
+		return
+	}
+
+	// rollback is requested by Spec.Rollback, and started by controller after checking the origin volume is not mounted
+	if snapshot.Status.Status == v1.SnapshotStatusMerging && snapshot.Status.Rollback != nil {
+		return ss.rollbackSnapshot(snapshot)
+	}
+
+	// merge snapshot lvol. For SpdkLVol, origin lvol is rolled back to the snapshot.
+	if snapshot.Status.Status == v1.SnapshotStatusMerging {
 		klog.Info("start merging snapshot")
 
 		// merge is finished
 		if _, has := snapshot.Labels[v1.MergeFinishTimestampLabelKey]; has {
 			snapshot.Status.Status = v1.SnapshotStatusMerged
+			snapshot.Status.Message = ""
 			_, err = snapCli.UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
 			if err != nil {
 				klog.Error(err)
@@ -175,9 +227,22 @@ func (ss *SnapshotSyncer) syncOneSnapshot(nsName string) (err error) {
 			return
 		}
 
-		err = ss.poolService.PoolEngine().RestoreSnapshot(snapshot.Spec.KernelLvol.Name)
+		// origin lvol is deleted in restoring, which must not be exposed by the target
+		if snapshot.Spec.VolType == v1.VolumeTypeSpdkLVol {
+			err = fmt.Errorf("SpdkLVol snapshot %s should be merged by rolling back, which removes the target of origin volume first", nsName)
+			klog.Error(err)
+			ss.updateStatusMessage(snapshot, err.Error())
+			return
+		}
+
+		var req = engine.RestoreSnapshotRequest{
+			OriginName:   snapshot.Spec.OriginVolName,
+			SnapshotName: snapshot.Spec.KernelLvol.Name,
+		}
+		err = ss.poolService.PoolEngine().RestoreSnapshot(req)
 		if err != nil {
 			klog.Error(err)
+			ss.updateStatusMessage(snapshot, err.Error())
 			return
 		}
 
@@ -197,3 +262,452 @@ func (ss *SnapshotSyncer) syncOneSnapshot(nsName string) (err error) {
 
 	return
 }
+
+// snapshotLvolName returns name of the snapshot lvol. Uuid makes names unique if the volume has more than one snapshot.
+func snapshotLvolName(snapshot *v1.AntstorSnapshot) string {
+	if len(snapshot.Spec.Uuid) >= 8 {
+		return fmt.Sprintf("%s_snap_%s", snapshot.Spec.OriginVolName, snapshot.Spec.Uuid[:8])
+	}
+	return fmt.Sprintf("%s_snap", snapshot.Spec.OriginVolName)
+}
+
+// assignSequence sets Spec.Sequence of the snapshot larger than other snapshots of the origin volume.
+// It fails if the snapshot lvol of another assigned sequence is not created yet, so that Sequence is the order of snapshot lvols.
+func (ss *SnapshotSyncer) assignSequence(snapshot *v1.AntstorSnapshot) (err error) {
+	var (
+		snapCli = ss.storeCli.VolumeV1().AntstorSnapshots(snapshot.Namespace)
+		list    *v1.AntstorSnapshotList
+		seq     int64
+	)
+	list, err = snapCli.List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", v1.OriginVolumeNameLabelKey, snapshot.Spec.OriginVolName),
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	for _, item := range list.Items {
+		if item.Name == snapshot.Name || item.Spec.OriginVolNamespace != snapshot.Spec.OriginVolNamespace {
+			continue
+		}
+		if item.Spec.Sequence > 0 && item.DeletionTimestamp == nil && !misc.InSliceString(v1.SnapshotFinalizer, item.Finalizers) {
+			err = fmt.Errorf("snapshot %s of the same volume is being created", item.Name)
+			klog.Error(err)
+			return
+		}
+		if item.Spec.Sequence > seq {
+			seq = item.Spec.Sequence
+		}
+	}
+
+	snapshot.Spec.Sequence = seq + 1
+	klog.Infof("set sequence of snapshot %s to %d", snapshot.Name, snapshot.Spec.Sequence)
+	_, err = snapCli.Update(context.Background(), snapshot, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Error(err)
+	}
+	return
+}
+
+// setConsumedSize sets the clusters and bytes consumed by the SPDK snapshot lvol. It returns true if the status is changed.
+func (ss *SnapshotSyncer) setConsumedSize(snapshot *v1.AntstorSnapshot) (changed bool) {
+	vol, err := ss.poolService.PoolEngine().GetVolume(snapshot.Spec.SpdkLvol.Name)
+	if err != nil || vol.SpdkLvol == nil {
+		klog.Errorf("get snapshot lvol %s failed, %+v", snapshot.Spec.SpdkLvol.Name, err)
+		return
+	}
+
+	var clusterSize = ss.poolService.GetStoragePool().Spec.SpdkLVStore.ClusterSize
+	changed = snapshot.Status.ConsumedClusters != vol.SpdkLvol.AllocatedClusters
+	snapshot.Status.ConsumedClusters = vol.SpdkLvol.AllocatedClusters
+	snapshot.Status.ConsumedBytes = int64(vol.SpdkLvol.AllocatedClusters) * int64(clusterSize)
+	return
+}
+
+// updateStatusMessage records the reason why the snapshot cannot be restored or deleted
+func (ss *SnapshotSyncer) updateStatusMessage(snapshot *v1.AntstorSnapshot, msg string) {
+	if snapshot.Status.Message == msg {
+		return
+	}
+	snapshot.Status.Message = msg
+	_, err := ss.storeCli.VolumeV1().AntstorSnapshots(snapshot.Namespace).UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Error(err)
+	}
+}
+
+// rollbackSnapshot rolls back the origin volume to the snapshot step by step. Each step is recorded in Status.Rollback.Phase:
+// 1. Unstaging: remove the target of the origin volume, so that no host could access it
+// 2. Restoring: merge LVM snapshot or revert SPDK lvol
+// 3. Activating: reactivate the LV, and set the origin volume to creating, so that VolumeSyncer exposes it again
+// and the host could stage and mount it.
+func (ss *SnapshotSyncer) rollbackSnapshot(snapshot *v1.AntstorSnapshot) (err error) {
+	var (
+		rb      = snapshot.Status.Rollback
+		snapCli = ss.storeCli.VolumeV1().AntstorSnapshots(snapshot.Namespace)
+		volCli  = ss.storeCli.VolumeV1().AntstorVolumes(snapshot.Spec.OriginVolNamespace)
+		vol     *v1.AntstorVolume
+		now     = metav1.Now()
+	)
+
+	klog.Infof("rolling back volume %s to snapshot %s, phase %s", snapshot.Spec.OriginVolName, snapshot.Name, rb.Phase)
+	if rb.Phase == v1.SnapshotRollbackFinished || rb.Phase == v1.SnapshotRollbackFailed {
+		return
+	}
+
+	vol, err = volCli.Get(context.Background(), snapshot.Spec.OriginVolName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			rb.Phase = v1.SnapshotRollbackFailed
+			rb.Message = fmt.Sprintf("origin volume %s not found", snapshot.Spec.OriginVolName)
+			_, err = snapCli.UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
+		}
+		klog.Error(err)
+		return
+	}
+
+	switch rb.Phase {
+	case v1.SnapshotRollbackUnstaging:
+		err = ss.unstageVolume(vol)
+		if err != nil {
+			break
+		}
+		rb.Phase = v1.SnapshotRollbackRestoring
+
+	case v1.SnapshotRollbackRestoring:
+		var req = engine.RestoreSnapshotRequest{
+			OriginName: snapshot.Spec.OriginVolName,
+		}
+		switch snapshot.Spec.VolType {
+		case v1.VolumeTypeKernelLVol:
+			req.SnapshotName = snapshot.Spec.KernelLvol.Name
+		case v1.VolumeTypeSpdkLVol:
+			req.SnapshotName = snapshot.Spec.SpdkLvol.Name
+		}
+		err = ss.poolService.PoolEngine().RestoreSnapshot(req)
+		if err != nil {
+			break
+		}
+		rb.Phase = v1.SnapshotRollbackActivating
+
+	case v1.SnapshotRollbackActivating:
+		if activator, ok := ss.poolService.PoolEngine().(engine.VolumeActivatorIface); ok {
+			err = activator.ActivateVolume(vol.Name)
+			if err != nil {
+				break
+			}
+		}
+		// requeue the origin volume. VolumeSyncer exposes it again and sets it to ready,
+		// then NodeStageVolume, which is retried by kubelet, could connect and mount it.
+		if vol.Status.Status != v1.VolumeStatusCreating {
+			vol.Status.Status = v1.VolumeStatusCreating
+			vol.Status.Message = fmt.Sprintf("rolled back to snapshot %s", snapshot.Name)
+			// only the lvol is rolled back, data of replica legs is rebuilt from it
+			markReplicasStale(vol, vol.Status.Message)
+			_, err = volCli.UpdateStatus(context.Background(), vol, metav1.UpdateOptions{})
+			if err != nil {
+				break
+			}
+		}
+		rb.Phase = v1.SnapshotRollbackFinished
+		rb.FinishTime = &now
+		snapshot.Status.Status = v1.SnapshotStatusMerged
+	}
+
+	if err != nil {
+		klog.Error(err)
+		if rb.Message == err.Error() {
+			return
+		}
+		rb.Message = err.Error()
+	} else {
+		rb.Message = ""
+	}
+
+	_, errUpdate := snapCli.UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
+	if errUpdate != nil {
+		klog.Error(errUpdate)
+		if err == nil {
+			err = errUpdate
+		}
+	}
+	return
+}
+
+// unstageVolume removes the target of the volume. Spec.SpdkTarget is reset, so that VolumeSyncer creates a new one
+// when the volume is requeued.
+func (ss *SnapshotSyncer) unstageVolume(vol *v1.AntstorVolume) (err error) {
+	if vol.Spec.SpdkTarget == nil {
+		klog.Infof("volume %s has no target, no need to unstage", vol.Name)
+		return
+	}
+
+	var access = pool.Access{
+		OpenAccess: spdk.Target{
+			TransAddr: vol.Spec.SpdkTarget.Address,
+			TransType: vol.Spec.SpdkTarget.TransType,
+			NQN:       vol.Spec.SpdkTarget.SubsysNQN,
+		},
+	}
+	switch vol.Spec.Type {
+	case v1.VolumeTypeKernelLVol:
+		access.AIO = &pool.AioVolume{
+			BdevName: vol.Spec.SpdkTarget.BdevName,
+		}
+	case v1.VolumeTypeSpdkLVol:
+		if vol.Spec.Encryption != nil {
+			access.Crypto = &pool.CryptoBdev{
+				BdevName: GetCryptoBdevName(vol.Spec.Uuid),
+				KeyName:  GetCryptoKeyName(vol.Spec.Uuid),
+			}
+		}
+		// RAID1 bdev holds the lvol, so it is deleted and replicas are detached
+		access.Raid = newReplicaRaidBdev(vol)
+	}
+
+	klog.Infof("unstaging volume %s, removing target %s", vol.Name, vol.Spec.SpdkTarget.SubsysNQN)
+	err = ss.poolService.Access().RemoveAccces(access)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	var newFinalizers = make([]string, 0, len(vol.Finalizers))
+	for _, item := range vol.Finalizers {
+		if item != v1.SpdkTargetFinalizer {
+			newFinalizers = append(newFinalizers, item)
+		}
+	}
+	vol.Finalizers = newFinalizers
+	vol.Spec.SpdkTarget = nil
+	_, err = ss.storeCli.VolumeV1().AntstorVolumes(vol.Namespace).Update(context.Background(), vol, metav1.UpdateOptions{})
+	return
+}
+
+// syncExport starts exporting the snapshot in background if Spec.Export is set.
+// An export interrupted by restarting agent is started again. A failed export is not retried until Spec.Export is removed.
+func (ss *SnapshotSyncer) syncExport(snapshot *v1.AntstorSnapshot) (err error) {
+	var (
+		key     = fmt.Sprintf("%s/%s", snapshot.Namespace, snapshot.Name)
+		st      = snapshot.Status.Export
+		snapCli = ss.storeCli.VolumeV1().AntstorSnapshots(snapshot.Namespace)
+	)
+
+	if snapshot.Spec.Export == nil {
+		if st != nil && !ss.exports.cancel(key) {
+			klog.Infof("export of snapshot %s is removed, reset export status", key)
+			snapshot.Status.Export = nil
+			_, err = snapCli.UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
+		}
+		return
+	}
+
+	if st != nil && (st.Phase == v1.TransferFinished || st.Phase == v1.TransferFailed) {
+		klog.Infof("export of snapshot %s is %s", key, st.Phase)
+		return
+	}
+	if ss.exports.isRunning(key) {
+		klog.Infof("snapshot %s is being exported", key)
+		return
+	}
+
+	// incremental export waits for the export of base snapshot
+	var base *v1.AntstorSnapshot
+	if baseName := snapshot.Spec.Export.BaseSnapshot; baseName != "" {
+		var msg string
+		base, msg, err = ss.getExportBase(snapshot)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+		if msg != "" {
+			var now = metav1.Now()
+			snapshot.Status.Export = &v1.SnapshotExportStatus{
+				Phase:      v1.TransferFailed,
+				Message:    msg,
+				FinishTime: &now,
+			}
+			_, err = snapCli.UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
+			return
+		}
+		if base.Status.Export == nil || base.Status.Export.Phase != v1.TransferFinished {
+			err = fmt.Errorf("export of base snapshot %s/%s is not finished, wait for it", base.Namespace, base.Name)
+			return
+		}
+	}
+
+	var now = metav1.Now()
+	snapshot.Status.Export = &v1.SnapshotExportStatus{
+		Phase:     v1.TransferRunning,
+		StartTime: &now,
+	}
+	snapshot, err = snapCli.UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	klog.Infof("start exporting snapshot %s to %s/%s", key, snapshot.Spec.Export.Endpoint, snapshot.Spec.Export.Bucket)
+	ss.exports.start(key, func(ctx context.Context) {
+		st := ss.exportSnapshot(ctx, snapshot, base)
+		ss.updateExportStatus(snapshot.Namespace, snapshot.Name, st)
+	})
+	return
+}
+
+// getExportBase returns the base snapshot of incremental export.
+// msg is not empty if the base snapshot could never be used, e.g. it is not found or has a different origin volume.
+func (ss *SnapshotSyncer) getExportBase(snapshot *v1.AntstorSnapshot) (base *v1.AntstorSnapshot, msg string, err error) {
+	var baseName = snapshot.Spec.Export.BaseSnapshot
+	base, err = ss.storeCli.VolumeV1().AntstorSnapshots(snapshot.Namespace).Get(context.Background(), baseName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Sprintf("base snapshot %s not found", baseName), nil
+		}
+		return
+	}
+
+	switch {
+	case base.Name == snapshot.Name:
+		msg = "base snapshot should not be itself"
+	case base.Spec.OriginVolName != snapshot.Spec.OriginVolName || base.Spec.OriginVolNamespace != snapshot.Spec.OriginVolNamespace:
+		msg = fmt.Sprintf("base snapshot %s has a different origin volume", baseName)
+	case base.Spec.Export == nil:
+		msg = fmt.Sprintf("base snapshot %s is not exported", baseName)
+	case base.Spec.Export.Endpoint != snapshot.Spec.Export.Endpoint || base.Spec.Export.Bucket != snapshot.Spec.Export.Bucket:
+		msg = fmt.Sprintf("base snapshot %s is exported to a different object storage", baseName)
+	case base.Status.Export != nil && base.Status.Export.Phase == v1.TransferFailed:
+		msg = fmt.Sprintf("export of base snapshot %s is failed", baseName)
+	}
+	return
+}
+
+// changedExtents returns extents of snapshot changed since the base snapshot
+func (ss *SnapshotSyncer) changedExtents(snapshot, base *v1.AntstorSnapshot) (extents []objstore.Extent, err error) {
+	cb, ok := ss.poolService.PoolEngine().(engine.ChangedBlocksIface)
+	if !ok {
+		err = fmt.Errorf("pool engine does not support incremental export")
+		return
+	}
+
+	var req engine.ChangedBlocksRequest
+	switch snapshot.Spec.VolType {
+	case v1.VolumeTypeKernelLVol:
+		req.SnapshotName = snapshot.Spec.KernelLvol.Name
+		req.BaseSnapshotName = base.Spec.KernelLvol.Name
+	case v1.VolumeTypeSpdkLVol:
+		req.SnapshotName = snapshot.Spec.SpdkLvol.Name
+		req.BaseSnapshotName = base.Spec.SpdkLvol.Name
+	}
+
+	changed, err := cb.ChangedBlocks(req)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	extents = make([]objstore.Extent, 0, len(changed))
+	for _, item := range changed {
+		extents = append(extents, objstore.Extent{Offset: item.Offset, Length: item.Length})
+	}
+	return
+}
+
+// exportSnapshot copies data of snapshot lvol to object storage, and returns the final status.
+// If base is not nil, only blocks changed since base are exported.
+func (ss *SnapshotSyncer) exportSnapshot(ctx context.Context, snapshot, base *v1.AntstorSnapshot) (st *v1.SnapshotExportStatus) {
+	var (
+		exp      = snapshot.Spec.Export
+		prefix   = exp.Prefix
+		store    objstore.ObjectStoreIface
+		manifest objstore.Manifest
+		throttle progressThrottle
+		baseKey  string
+		changed  []objstore.Extent
+		err      error
+	)
+	st = snapshot.Status.Export.DeepCopy()
+	defer func() {
+		var now = metav1.Now()
+		st.FinishTime = &now
+		if err != nil {
+			klog.Errorf("export snapshot %s/%s failed, %+v", snapshot.Namespace, snapshot.Name, err)
+			st.Phase = v1.TransferFailed
+			st.Message = err.Error()
+			return
+		}
+		st.Phase = v1.TransferFinished
+		st.Message = ""
+	}()
+
+	if prefix == "" {
+		prefix = path.Join(snapshot.Namespace, snapshot.Name, snapshot.Spec.Uuid)
+	}
+
+	store, err = objstore.NewObjectStore(ss.kubeCli, exp.ObjectStore)
+	if err != nil {
+		return
+	}
+
+	if base != nil {
+		baseKey = base.Status.Export.ManifestKey
+		changed, err = ss.changedExtents(snapshot, base)
+		if err != nil {
+			return
+		}
+		klog.Infof("export snapshot %s/%s incrementally, base %s, %d changed extents", snapshot.Namespace, snapshot.Name, baseKey, len(changed))
+	}
+
+	var (
+		volType  = snapshot.Spec.VolType
+		lvolName = fmt.Sprintf("%s/%s", snapshot.Spec.SpdkLvol.LvsName, snapshot.Spec.SpdkLvol.Name)
+	)
+	f, size, closeDev, err := openLogicVolume(ss.poolService, volType, snapshot.Spec.KernelLvol.DevPath, lvolName, os.O_RDONLY)
+	if err != nil {
+		return
+	}
+	defer closeDev()
+
+	manifest, err = objstore.ExportImage(ctx, store, f, objstore.ExportImageRequest{
+		Source:    fmt.Sprintf("%s/%s", snapshot.Namespace, snapshot.Name),
+		Prefix:    prefix,
+		SizeBytes: size,
+		ChunkSize: int64(exp.ChunkSizeMiB) << 20,
+		Base:      baseKey,
+		Changed:   changed,
+		Progress: func(done, total, stored int64) {
+			st.TotalChunks = total
+			st.ExportedChunks = done
+			st.StoredBytes = stored
+			if done < total && throttle.ready() {
+				ss.updateExportStatus(snapshot.Namespace, snapshot.Name, st)
+			}
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	st.ManifestKey = objstore.ManifestKey(prefix)
+	st.BaseManifestKey = baseKey
+	st.SizeBytes = manifest.SizeBytes
+	st.ChunkSize = manifest.ChunkSize
+	return
+}
+
+// updateExportStatus sets Status.Export of the latest snapshot
+func (ss *SnapshotSyncer) updateExportStatus(ns, name string, st *v1.SnapshotExportStatus) {
+	var snapCli = ss.storeCli.VolumeV1().AntstorSnapshots(ns)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		snapshot, err := snapCli.Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		snapshot.Status.Export = st.DeepCopy()
+		_, err = snapCli.UpdateStatus(context.Background(), snapshot, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		klog.Error(err)
+	}
+}
@@ -0,0 +1,192 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"lite.io/liteio/pkg/agent/pool"
+	"lite.io/liteio/pkg/agent/pool/engine"
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/generated/clientset/versioned/fake"
+	"lite.io/liteio/pkg/spdk"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakePoolService returns the fake engine and access. Other methods are not implemented.
+type fakePoolService struct {
+	pool.StoragePoolServiceIface
+	engine *fakeEngine
+	access *fakeAccess
+	spdk   spdk.SpdkServiceIface
+}
+
+func (f *fakePoolService) PoolEngine() engine.PoolEngineIface {
+	return f.engine
+}
+
+func (f *fakePoolService) Access() pool.AccessIface {
+	return f.access
+}
+
+func (f *fakePoolService) SpdkService() spdk.SpdkServiceIface {
+	return f.spdk
+}
+
+// fakeEngine records restored snapshots. Other methods are not implemented.
+type fakeEngine struct {
+	engine.PoolEngineIface
+	restored []engine.RestoreSnapshotRequest
+}
+
+func (f *fakeEngine) RestoreSnapshot(req engine.RestoreSnapshotRequest) (err error) {
+	f.restored = append(f.restored, req)
+	return
+}
+
+// fakeAccess records exposed and removed accesses
+type fakeAccess struct {
+	exposed []pool.Access
+	removed []pool.Access
+}
+
+func (f *fakeAccess) ExposeAccess(a pool.Access) (tgt spdk.Target, err error) {
+	f.exposed = append(f.exposed, a)
+	return a.OpenAccess, nil
+}
+
+func (f *fakeAccess) RemoveAccces(a pool.Access) (err error) {
+	f.removed = append(f.removed, a)
+	return
+}
+
+func TestRollbackSnapshot(t *testing.T) {
+	var (
+		vol  = newReplicatedVolume("vol-1-leg")
+		snap = &v1.AntstorSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: v1.DefaultNamespace,
+				Name:      "snap-1",
+			},
+			Spec: v1.AntstorSnapshotSpec{
+				VolType:            v1.VolumeTypeSpdkLVol,
+				SpdkLvol:           v1.SpdkLvol{Name: "snap-1"},
+				OriginVolName:      "vol-1",
+				OriginVolNamespace: v1.DefaultNamespace,
+				Rollback:           true,
+			},
+			Status: v1.AntstorSnapshotStatus{
+				Status: v1.SnapshotStatusMerging,
+				Rollback: &v1.SnapshotRollbackStatus{
+					Phase: v1.SnapshotRollbackUnstaging,
+				},
+			},
+		}
+	)
+	vol.Spec.Encryption = &v1.VolumeEncryption{SecretName: "key"}
+	vol.Spec.SpdkTarget = &v1.SpdkTarget{SubsysNQN: "nqn-1"}
+	var (
+		storeCli = fake.NewSimpleClientset(vol, snap)
+		svc      = &fakePoolService{engine: &fakeEngine{}, access: &fakeAccess{}}
+		ss       = NewSnapshotSyncer(storeCli, nil, svc)
+	)
+
+	getSnap := func() *v1.AntstorSnapshot {
+		obj, err := storeCli.VolumeV1().AntstorSnapshots(v1.DefaultNamespace).Get(context.Background(), "snap-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		return obj
+	}
+	getVol := func() *v1.AntstorVolume {
+		obj, err := storeCli.VolumeV1().AntstorVolumes(v1.DefaultNamespace).Get(context.Background(), "vol-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		return obj
+	}
+
+	// Unstaging: target, crypto bdev and RAID1 bdev are removed
+	assert.NoError(t, ss.rollbackSnapshot(getSnap()))
+	assert.Equal(t, v1.SnapshotRollbackRestoring, getSnap().Status.Rollback.Phase)
+	if assert.Len(t, svc.access.removed, 1) {
+		removed := svc.access.removed[0]
+		assert.Equal(t, "nqn-1", removed.OpenAccess.NQN)
+		assert.NotNil(t, removed.Crypto)
+		if assert.NotNil(t, removed.Raid) {
+			assert.Equal(t, GetRaidBdevName("uuid-vol-1"), removed.Raid.BdevName)
+			assert.Equal(t, []pool.RemoteBdev{{ControllerName: GetReplicaControllerName("uuid-vol-1-leg")}}, removed.Raid.Replicas)
+		}
+	}
+	assert.Nil(t, getVol().Spec.SpdkTarget)
+	assert.Empty(t, getVol().Finalizers)
+
+	// Restoring: lvol is reverted to the snapshot
+	assert.NoError(t, ss.rollbackSnapshot(getSnap()))
+	assert.Equal(t, v1.SnapshotRollbackActivating, getSnap().Status.Rollback.Phase)
+	assert.Equal(t, []engine.RestoreSnapshotRequest{{OriginName: "vol-1", SnapshotName: "snap-1"}}, svc.engine.restored)
+
+	// Activating: volume is requeued to be exposed again
+	assert.NoError(t, ss.rollbackSnapshot(getSnap()))
+	assert.Equal(t, v1.SnapshotRollbackFinished, getSnap().Status.Rollback.Phase)
+	assert.NotNil(t, getSnap().Status.Rollback.FinishTime)
+	assert.Equal(t, v1.SnapshotStatusMerged, getSnap().Status.Status)
+	assert.Equal(t, v1.VolumeStatusCreating, getVol().Status.Status)
+	// legs are rebuilt from the rolled back lvol
+	assert.Equal(t, []v1.ReplicaStatus{
+		{Name: "vol-1-leg", State: v1.ReplicaStatePending, Message: "rolled back to snapshot snap-1"},
+	}, getVol().Status.Replicas)
+
+	// finished rollback is not repeated
+	assert.NoError(t, ss.rollbackSnapshot(getSnap()))
+	assert.Len(t, svc.engine.restored, 1)
+	assert.Len(t, svc.access.removed, 1)
+}
+
+func TestAssignSequence(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		newSnap = func(name, volName string, seq int64, created bool) *v1.AntstorSnapshot {
+			snap := &v1.AntstorSnapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: v1.DefaultNamespace,
+					Name:      name,
+					Labels:    map[string]string{v1.OriginVolumeNameLabelKey: volName},
+				},
+				Spec: v1.AntstorSnapshotSpec{OriginVolName: volName, OriginVolNamespace: v1.DefaultNamespace, Sequence: seq},
+			}
+			if created {
+				snap.Finalizers = []string{v1.SnapshotFinalizer}
+			}
+			return snap
+		}
+		storeCli = fake.NewSimpleClientset(
+			newSnap("snap-1", "vol-1", 1, true),
+			newSnap("snap-2", "vol-1", 2, true),
+			newSnap("snap-other", "vol-2", 5, true),
+			newSnap("snap-3", "vol-1", 0, false),
+			newSnap("snap-4", "vol-1", 0, false),
+		)
+		ss      = &SnapshotSyncer{storeCli: storeCli}
+		snapCli = storeCli.VolumeV1().AntstorSnapshots(v1.DefaultNamespace)
+	)
+
+	snap, err := snapCli.Get(ctx, "snap-3", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, ss.assignSequence(snap))
+	snap, err = snapCli.Get(ctx, "snap-3", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), snap.Spec.Sequence)
+
+	// snap-3 is not created yet
+	snap, err = snapCli.Get(ctx, "snap-4", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Error(t, ss.assignSequence(snap))
+
+	snap, err = snapCli.Get(ctx, "snap-3", metav1.GetOptions{})
+	assert.NoError(t, err)
+	snap.Finalizers = []string{v1.SnapshotFinalizer}
+	_, err = snapCli.Update(ctx, snap, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	snap, err = snapCli.Get(ctx, "snap-4", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, ss.assignSequence(snap))
+	assert.Equal(t, int64(4), snap.Spec.Sequence)
+}
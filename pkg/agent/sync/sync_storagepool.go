@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"lite.io/liteio/pkg/agent/config"
+	"lite.io/liteio/pkg/agent/metric"
 	"lite.io/liteio/pkg/agent/pool"
 	"lite.io/liteio/pkg/agent/pool/engine"
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
@@ -23,6 +24,12 @@ import (
 	"k8s.io/klog/v2"
 )
 
+const (
+	// IO load is published if utilization changes by ioLoadUtilChangePct, or IOPS changes by ioLoadIOPSChangePct percent
+	ioLoadUtilChangePct = 10
+	ioLoadIOPSChangePct = 20
+)
+
 type PoolSyncer struct {
 	poolService pool.StoragePoolServiceIface
 	// storeCli is used to read/write StoragePool, AntstorVolumes from APIServer
@@ -30,14 +37,17 @@ type PoolSyncer struct {
 	// read node info from APIServer
 	nodeGetter kubeutil.NodeInfoGetterIface
 	cfg        config.Config
+	// loadGetter provides recent IO load of pool, it could be nil
+	loadGetter metric.PoolLoadGetterIface
 }
 
-func NewPoolSyncer(poolService pool.StoragePoolServiceIface, storeCli versioned.Interface, nodeGetter kubeutil.NodeInfoGetterIface, cfg config.Config) *PoolSyncer {
+func NewPoolSyncer(poolService pool.StoragePoolServiceIface, storeCli versioned.Interface, nodeGetter kubeutil.NodeInfoGetterIface, cfg config.Config, loadGetter metric.PoolLoadGetterIface) *PoolSyncer {
 	return &PoolSyncer{
 		poolService: poolService,
 		storeCli:    storeCli,
 		nodeGetter:  nodeGetter,
 		cfg:         cfg,
+		loadGetter:  loadGetter,
 	}
 }
 
@@ -171,6 +181,10 @@ func (ps *PoolSyncer) syncPool() (err error) {
 		pool.Status = apiPool.Status
 		// 3. assemble Spec(address, node info, lvm/spdk info ) in local
 		pool.Spec, err = ps.getPoolSpec()
+		// overcommit ratio could be set on StoragePool directly
+		if pool.Spec.ThinOvercommitPct == 0 {
+			pool.Spec.ThinOvercommitPct = apiPool.Spec.ThinOvercommitPct
+		}
 		if err != nil {
 			// if vgs with error, update pool status
 			if errors.Is(err, lvm.PvLostErr) {
@@ -217,14 +231,33 @@ func (ps *PoolSyncer) getPoolSpec() (spec v1.StoragePoolSpec, err error) {
 			Address: spec.NodeInfo.IP,
 		},
 	}
+	// additional addresses for NVMe multipath
+	for _, addr := range ps.cfg.Transport.MultipathAddresses {
+		if addr != "" && addr != spec.NodeInfo.IP {
+			spec.Addresses = append(spec.Addresses, corev1.NodeAddress{
+				Type:    corev1.NodeInternalIP,
+				Address: addr,
+			})
+		}
+	}
 
 	if poolInfo.LVM != nil {
 		spec.KernelLVM = *poolInfo.LVM
 	}
+	spec.ThinOvercommitPct = ps.cfg.Storage.Pooling.ThinOvercommitPct
 	if poolInfo.LVS != nil {
 		spec.SpdkLVStore = *poolInfo.LVS
 	}
 
+	// report transports of SPDK target for scheduling
+	if ps.poolService.SpdkWatcher().ReadStatus().Error == nil {
+		var errTrans error
+		spec.Transports, errTrans = ps.poolService.SpdkService().ListTransportTypes()
+		if errTrans != nil {
+			klog.Error(errTrans)
+		}
+	}
+
 	return
 }
 
@@ -248,13 +281,44 @@ func setPoolAttributes(pool *v1.StoragePool, spdkVer pool.SpdkStatus) {
 	pool.Status.VGFreeSize = *quant
 }
 
+// isIOLoadChanged returns true if the new IO load should be published.
+// It is published if it changes noticeably, or the published one is about to expire, so that scheduler knows it is not expired.
+func isIOLoadChanged(published, current *v1.PoolIOLoad) bool {
+	if current == nil {
+		return false
+	}
+	if published == nil || time.Since(published.UpdateTime.Time) > v1.PoolIOLoadExpiration/2 {
+		return true
+	}
+
+	var (
+		utilDiff = current.UtilPct - published.UtilPct
+		iopsLast = published.ReadIOPS + published.WriteIOPS
+		iopsDiff = current.ReadIOPS + current.WriteIOPS - iopsLast
+	)
+	if utilDiff < 0 {
+		utilDiff = -utilDiff
+	}
+	if iopsDiff < 0 {
+		iopsDiff = -iopsDiff
+	}
+	return utilDiff >= ioLoadUtilChangePct || iopsDiff*100 > iopsLast*ioLoadIOPSChangePct
+}
+
 func (ps *PoolSyncer) updatePoolStatus() (err error) {
 	pool := ps.poolService.GetStoragePool()
 	// update pool's status to truth
 	setStatusConditions(pool, ps.poolService)
+	setThinPoolConditions(pool, ps.poolService, ps.cfg.Storage.Pooling.ThinPool)
 	errVG := setStatusVgFree(pool, ps.poolService)
+	if errVG == nil && ps.poolService.Mode() == v1.PoolModeSpdkLVStore {
+		setLVStoreUsageCondition(pool, ps.cfg.Storage.Pooling.UsageWarningPct)
+	}
 
 	realStatus := pool.Status.DeepCopy()
+	if ps.loadGetter != nil {
+		realStatus.IOLoad = ps.loadGetter.IOLoad()
+	}
 
 	cli := ps.storeCli.VolumeV1().StoragePools(v1.DefaultNamespace)
 	apiPool, err := cli.Get(context.Background(), pool.Name, metav1.GetOptions{})
@@ -280,12 +344,17 @@ func (ps *PoolSyncer) updatePoolStatus() (err error) {
 	var freeByteEqual = realStatus.VGFreeSize.Equal(apiPool.Status.VGFreeSize)
 	var totalByteEqual = realStatus.Capacity[v1.ResourceDiskPoolByte].Equal(apiPool.Status.Capacity[v1.ResourceDiskPoolByte])
 
-	if !condEqual || !freeByteEqual || !totalByteEqual {
+	var loadChanged = isIOLoadChanged(apiPool.Status.IOLoad, realStatus.IOLoad)
+
+	if !condEqual || !freeByteEqual || !totalByteEqual || loadChanged {
 		// to update status
-		klog.Infof("update StoragePool condition and cap, %+v, server-side status is %+v", *realStatus, apiPool.Status)
+		klog.V(4).Infof("update StoragePool condition and cap, %+v, server-side status is %+v", *realStatus, apiPool.Status)
 		apiPool.Status.Conditions = realStatus.Conditions
 		apiPool.Status.VGFreeSize = realStatus.VGFreeSize.DeepCopy()
 		apiPool.Status.Capacity[v1.ResourceDiskPoolByte] = realStatus.Capacity[v1.ResourceDiskPoolByte]
+		if realStatus.IOLoad != nil {
+			apiPool.Status.IOLoad = realStatus.IOLoad
+		}
 		// APIServer is supposed to check resourceVersion before updating the data.
 		// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#concurrency-control-and-consistency
 		// https://stackoverflow.com/questions/52910322/kubernetes-resource-versioning
@@ -335,6 +404,69 @@ func setStatusConditions(pool *v1.StoragePool, poolSvc pool.StoragePoolServiceIf
 	}
 }
 
+// setThinPoolConditions sets data and metadata usage of thin pool to conditions.
+// Condition is Error if the usage exceeds the warning threshold.
+func setThinPoolConditions(pool *v1.StoragePool, poolSvc pool.StoragePoolServiceIface, cfg *config.LvmThinPool) {
+	thinEng, ok := poolSvc.PoolEngine().(engine.ThinPoolIface)
+	if !ok || cfg == nil {
+		return
+	}
+
+	usage, enabled, err := thinEng.ThinPoolUsage()
+	if !enabled {
+		return
+	}
+
+	var dataCond = v1.PoolCondition{Type: v1.PoolConditionThinPoolData, Status: v1.StatusOK}
+	var metaCond = v1.PoolCondition{Type: v1.PoolConditionThinPoolMetadata, Status: v1.StatusOK}
+	if err != nil {
+		klog.Error(err)
+		dataCond.Status, dataCond.Message = v1.StatusError, err.Error()
+		metaCond.Status, metaCond.Message = v1.StatusError, err.Error()
+	} else {
+		dataCond.Message = fmt.Sprintf("%.2f%%", usage.DataPercent)
+		if usage.DataPercent >= float64(cfg.DataWarningPct) {
+			klog.Errorf("data usage of thin pool is %s, exceeds %d%%", dataCond.Message, cfg.DataWarningPct)
+			dataCond.Status = v1.StatusError
+		}
+		metaCond.Message = fmt.Sprintf("%.2f%%", usage.MetadataPercent)
+		if usage.MetadataPercent >= float64(cfg.MetadataWarningPct) {
+			klog.Errorf("metadata usage of thin pool is %s, exceeds %d%%", metaCond.Message, cfg.MetadataWarningPct)
+			metaCond.Status = v1.StatusError
+		}
+	}
+
+	setPoolCondition(pool, dataCond)
+	setPoolCondition(pool, metaCond)
+}
+
+// setLVStoreUsageCondition warns before the lvstore fills up. Thin lvols allocate clusters on writing,
+// so the lvstore could be full even if the total size of lvols is smaller than it.
+func setLVStoreUsageCondition(pool *v1.StoragePool, warningPct int) {
+	var usedPct = pool.GetUsedPct()
+	var cond = v1.PoolCondition{
+		Type:    v1.PoolConditionLVStoreUsage,
+		Status:  v1.StatusOK,
+		Message: fmt.Sprintf("%.2f%%", usedPct),
+	}
+	if warningPct > 0 && usedPct >= float64(warningPct) {
+		klog.Errorf("usage of lvstore is %s, exceeds %d%%", cond.Message, warningPct)
+		cond.Status = v1.StatusError
+	}
+	setPoolCondition(pool, cond)
+}
+
+// setPoolCondition adds or updates the condition by type
+func setPoolCondition(sp *v1.StoragePool, cond v1.PoolCondition) {
+	for idx, item := range sp.Status.Conditions {
+		if item.Type == cond.Type {
+			sp.Status.Conditions[idx] = cond
+			return
+		}
+	}
+	sp.Status.Conditions = append(sp.Status.Conditions, cond)
+}
+
 func setStatusVgFree(pool *v1.StoragePool, poolSvc pool.StoragePoolServiceIface) (err error) {
 	totalByte, freeByte, err := poolSvc.PoolEngine().TotalAndFreeSize()
 	if err != nil {
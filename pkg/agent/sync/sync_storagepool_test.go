@@ -0,0 +1,44 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsIOLoadChanged(t *testing.T) {
+	var (
+		now       = metav1.Now()
+		published = &v1.PoolIOLoad{UtilPct: 40, ReadIOPS: 600, WriteIOPS: 400, UpdateTime: now}
+		newLoad   = func(util int, iops int64) *v1.PoolIOLoad {
+			return &v1.PoolIOLoad{UtilPct: util, ReadIOPS: iops, UpdateTime: now}
+		}
+	)
+
+	cases := []struct {
+		name      string
+		published *v1.PoolIOLoad
+		current   *v1.PoolIOLoad
+		expected  bool
+	}{
+		{name: "no load", published: published, current: nil, expected: false},
+		{name: "not published", published: nil, current: newLoad(40, 1000), expected: true},
+		{name: "slightly changed", published: published, current: newLoad(45, 1100), expected: false},
+		{name: "util changed", published: published, current: newLoad(30, 1000), expected: true},
+		{name: "iops changed", published: published, current: newLoad(40, 1300), expected: true},
+		{name: "iops from zero", published: newLoad(0, 0), current: newLoad(0, 1), expected: true},
+		{
+			name:      "about to expire",
+			published: &v1.PoolIOLoad{UtilPct: 40, ReadIOPS: 1000, UpdateTime: metav1.NewTime(time.Now().Add(-v1.PoolIOLoadExpiration/2 - time.Minute))},
+			current:   newLoad(40, 1000),
+			expected:  true,
+		},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, isIOLoadChanged(c.published, c.current), c.name)
+	}
+}
@@ -0,0 +1,252 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	gosync "sync"
+	"time"
+
+	"lite.io/liteio/pkg/agent/pool"
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/util/objstore"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// interval of updating progress of export and import to APIServer
+	transferProgressInterval = 10 * time.Second
+)
+
+// transferTasks tracks background tasks of exporting snapshots and importing volumes. Key of task is namespace/name.
+// SyncLoop has only one worker, so the long running copy must not block it.
+type transferTasks struct {
+	lock  gosync.Mutex
+	tasks map[string]context.CancelFunc
+}
+
+func newTransferTasks() *transferTasks {
+	return &transferTasks{
+		tasks: make(map[string]context.CancelFunc),
+	}
+}
+
+// start runs fn in background. It returns false if the task of key is already running.
+func (t *transferTasks) start(key string, fn func(ctx context.Context)) (started bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, has := t.tasks[key]; has {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.tasks[key] = cancel
+	go func() {
+		defer func() {
+			t.lock.Lock()
+			delete(t.tasks, key)
+			t.lock.Unlock()
+			cancel()
+		}()
+		fn(ctx)
+	}()
+	return true
+}
+
+func (t *transferTasks) isRunning(key string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	_, has := t.tasks[key]
+	return has
+}
+
+// cancel stops the task of key. It returns true if the task is still running, caller should wait for it to exit.
+func (t *transferTasks) cancel(key string) (running bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if cancel, has := t.tasks[key]; has {
+		klog.Infof("canceling transfer task %s", key)
+		cancel()
+		return true
+	}
+	return false
+}
+
+// progressThrottle limits the frequency of updating progress
+type progressThrottle struct {
+	last time.Time
+}
+
+// ready returns true at most once per transferProgressInterval
+func (p *progressThrottle) ready() bool {
+	if time.Since(p.last) >= transferProgressInterval {
+		p.last = time.Now()
+		return true
+	}
+	return false
+}
+
+// openLogicVolume opens the block device of LVM volume, or exports the SPDK lvol by nbd and opens the nbd device.
+// close must be called to close the device and stop nbd disk. It returns the error of flushing written data.
+func openLogicVolume(poolService pool.StoragePoolServiceIface, volType v1.VolumeType, devPath, lvolFullName string,
+	flag int) (f *os.File, size int64, close func() error, err error) {
+	var stopNbd = func() {}
+	if volType == v1.VolumeTypeSpdkLVol {
+		devPath, err = poolService.SpdkService().StartNbdDisk(lvolFullName)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+		stopNbd = func() {
+			if errStop := poolService.SpdkService().StopNbdDisk(lvolFullName); errStop != nil {
+				klog.Error(errStop)
+			}
+		}
+	}
+
+	f, err = os.OpenFile(devPath, flag, 0)
+	if err != nil {
+		klog.Error(err)
+		stopNbd()
+		return
+	}
+	// size of block device
+	size, err = f.Seek(0, io.SeekEnd)
+	if err != nil {
+		klog.Error(err)
+		f.Close()
+		stopNbd()
+		return
+	}
+
+	close = func() (errSync error) {
+		if flag != os.O_RDONLY {
+			errSync = f.Sync()
+		}
+		f.Close()
+		stopNbd()
+		return
+	}
+	return
+}
+
+// importVolume writes the image in object storage to the logic volume in background.
+// It returns needReturn=true until the import is finished. The volume stays creating if the import is failed.
+func (vs *VolumeSyncer) importVolume(volume *v1.AntstorVolume) (needReturn bool, err error) {
+	var (
+		key = fmt.Sprintf("%s/%s", volume.Namespace, volume.Name)
+		st  = volume.Status.Import
+	)
+
+	if st != nil && st.Phase == v1.TransferFinished {
+		return false, nil
+	}
+	if st != nil && st.Phase == v1.TransferFailed {
+		klog.Infof("import of volume %s is failed, %s", key, st.Message)
+		return true, nil
+	}
+	if vs.imports.isRunning(key) {
+		klog.Infof("volume %s is being imported", key)
+		return true, nil
+	}
+
+	volume.Status.Import = &v1.VolumeImportStatus{
+		Phase: v1.TransferRunning,
+	}
+	volume, err = vs.storeCli.VolumeV1().AntstorVolumes(volume.Namespace).UpdateStatus(context.Background(), volume, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	klog.Infof("start importing volume %s from %s", key, volume.Spec.ImportSource.ManifestKey)
+	vs.imports.start(key, func(ctx context.Context) {
+		st := vs.doImport(ctx, volume)
+		vs.updateImportStatus(volume.Namespace, volume.Name, st)
+	})
+	return true, nil
+}
+
+// doImport copies chunks of the image to the logic volume, and returns the final status
+func (vs *VolumeSyncer) doImport(ctx context.Context, volume *v1.AntstorVolume) (st *v1.VolumeImportStatus) {
+	var (
+		src      = volume.Spec.ImportSource
+		store    objstore.ObjectStoreIface
+		throttle progressThrottle
+		devPath  string
+		lvolName string
+		err      error
+	)
+	st = volume.Status.Import.DeepCopy()
+	defer func() {
+		var now = metav1.Now()
+		st.FinishTime = &now
+		if err != nil {
+			klog.Errorf("import volume %s/%s failed, %+v", volume.Namespace, volume.Name, err)
+			st.Phase = v1.TransferFailed
+			st.Message = err.Error()
+			return
+		}
+		st.Phase = v1.TransferFinished
+		st.Message = ""
+	}()
+
+	store, err = objstore.NewObjectStore(vs.kubeCli, src.ObjectStore)
+	if err != nil {
+		return
+	}
+
+	if volume.Spec.KernelLvol != nil {
+		devPath = volume.Spec.KernelLvol.DevPath
+	}
+	if volume.Spec.SpdkLvol != nil {
+		lvolName = fmt.Sprintf("%s/%s", volume.Spec.SpdkLvol.LvsName, volume.Spec.SpdkLvol.Name)
+	}
+	f, size, closeDev, err := openLogicVolume(vs.poolService, volume.Spec.Type, devPath, lvolName, os.O_WRONLY)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if errClose := closeDev(); err == nil {
+			err = errClose
+		}
+	}()
+
+	_, err = objstore.ImportImage(ctx, store, f, objstore.ImportImageRequest{
+		ManifestKey: src.ManifestKey,
+		SizeBytes:   size,
+		// unallocated blocks of thin volume are read as zero
+		DstZeroed: volume.Spec.IsThin,
+		Progress: func(done, total, stored int64) {
+			st.TotalChunks = total
+			st.ImportedChunks = done
+			if done < total && throttle.ready() {
+				vs.updateImportStatus(volume.Namespace, volume.Name, st)
+			}
+		},
+	})
+	return
+}
+
+// updateImportStatus sets Status.Import of the latest volume
+func (vs *VolumeSyncer) updateImportStatus(ns, name string, st *v1.VolumeImportStatus) {
+	var volCli = vs.storeCli.VolumeV1().AntstorVolumes(ns)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		volume, err := volCli.Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		volume.Status.Import = st.DeepCopy()
+		if st.Phase == v1.TransferFailed {
+			volume.Status.Message = fmt.Sprintf("import failed: %s", st.Message)
+		}
+		_, err = volCli.UpdateStatus(context.Background(), volume, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		klog.Error(err)
+	}
+}
@@ -1,8 +1,12 @@
 package sync
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -14,28 +18,42 @@ import (
 	"lite.io/liteio/pkg/spdk"
 	"lite.io/liteio/pkg/spdk/jsonrpc/client"
 	spdkrpc "lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"lite.io/liteio/pkg/util/cgroup"
 	"lite.io/liteio/pkg/util/misc"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 )
 
+const (
+	// DHChapKeyDir is the directory of DH-HMAC-CHAP key files, which is shared with SPDK
+	DHChapKeyDir = "/usr/tmp/dhchap"
+)
+
 // VolumeSyncer create queue and informer to sync volume on the node from APIServer
 type VolumeSyncer struct {
 	nodeID      string
 	poolService pool.StoragePoolServiceIface
 	// storeCli is used to read/write StoragePool, AntstorVolumes from APIServer
 	storeCli versioned.Interface
-	lister   metric.MetricTargetListerIface
+	// kubeCli is used to read Secrets of DH-HMAC-CHAP keys and encryption keys
+	kubeCli kubernetes.Interface
+	lister  metric.MetricTargetListerIface
+	// imports are running tasks of importing volumes from object storage
+	imports *transferTasks
 }
 
-func NewVolumeSyncer(storeCli versioned.Interface, poolSvc pool.StoragePoolServiceIface, lister metric.MetricTargetListerIface) *VolumeSyncer {
+func NewVolumeSyncer(storeCli versioned.Interface, kubeCli kubernetes.Interface, poolSvc pool.StoragePoolServiceIface, lister metric.MetricTargetListerIface) *VolumeSyncer {
 	return &VolumeSyncer{
 		nodeID:      poolSvc.GetStoragePool().Name,
 		poolService: poolSvc,
 		storeCli:    storeCli,
+		kubeCli:     kubeCli,
 		lister:      lister,
+		imports:     newTransferTasks(),
 	}
 }
 
@@ -90,6 +108,11 @@ func (vs *VolumeSyncer) syncOneVolume(volume *v1.AntstorVolume) (err error) {
 
 	if volume.DeletionTimestamp != nil {
 		klog.Infof("deleting resources of volume %s, RV %s", volume.Name, volume.ResourceVersion)
+		// logic volume is being written by import
+		if key := volume.Namespace + "/" + volume.Name; vs.imports.cancel(key) {
+			err = fmt.Errorf("waiting for import of volume %s to stop", key)
+			return
+		}
 		return vs.handleDeletion(volume)
 	}
 
@@ -125,6 +148,14 @@ func (vs *VolumeSyncer) syncOneVolume(volume *v1.AntstorVolume) (err error) {
 		return
 	}
 
+	// write data of the exported snapshot before exposing the volume
+	if volume.Spec.ImportSource != nil {
+		needReturn, err = vs.importVolume(volume)
+		if err != nil || needReturn {
+			return
+		}
+	}
+
 	// create openaccess
 	needReturn, err = vs.createOpenAccess(volume)
 	if err != nil || needReturn {
@@ -229,6 +260,14 @@ func (vs *VolumeSyncer) handleDeletion(volume *v1.AntstorVolume) (err error) {
 			return
 		}
 
+		var cryptoBdev *pool.CryptoBdev
+		if volume.Spec.Type == v1.VolumeTypeSpdkLVol && volume.Spec.Encryption != nil {
+			cryptoBdev = &pool.CryptoBdev{
+				BdevName: GetCryptoBdevName(volume.Spec.Uuid),
+				KeyName:  GetCryptoKeyName(volume.Spec.Uuid),
+			}
+		}
+
 		err = vs.poolService.Access().RemoveAccces(pool.Access{
 			AIO: &pool.AioVolume{
 				BdevName: volume.Spec.SpdkTarget.BdevName,
@@ -238,12 +277,23 @@ func (vs *VolumeSyncer) handleDeletion(volume *v1.AntstorVolume) (err error) {
 				TransType: volume.Spec.SpdkTarget.TransType,
 				NQN:       volume.Spec.SpdkTarget.SubsysNQN,
 			},
+			Crypto: cryptoBdev,
+			Raid:   newReplicaRaidBdev(volume),
 		})
 		if err != nil {
 			klog.Error(err)
 			return
 		}
 
+		// keys could be removed after the subsystem is deleted
+		if volume.Spec.HostAuth != nil {
+			err = vs.removeDHChapKeys(volume)
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+		}
+
 		// remove v1.SpdkTargetFinalizer
 		if misc.InSliceString(v1.SpdkTargetFinalizer, volume.Finalizers) {
 			var newFinalizers = make([]string, 0, len(volume.Finalizers))
@@ -265,6 +315,9 @@ func (vs *VolumeSyncer) handleDeletion(volume *v1.AntstorVolume) (err error) {
 	_, hasSnapName := volume.Labels[v1.VolumeSourceSnapNameLabelKey]
 	_, hasSnapNS := volume.Labels[v1.VolumeSourceSnapNamespaceLabelKey]
 	var fromSnap = hasSnapNS && hasSnapName
+	_, hasSrcName := volume.Labels[v1.VolumeSourceVolNameLabelKey]
+	_, hasSrcNS := volume.Labels[v1.VolumeSourceVolNamespaceLabelKey]
+	var fromVol = hasSrcName && hasSrcNS
 
 	if hasLogicVolFinalizer {
 		klog.Infof("removing logic volume finalizer from volume %s", volume.Name)
@@ -313,6 +366,15 @@ func (vs *VolumeSyncer) handleDeletion(volume *v1.AntstorVolume) (err error) {
 			return
 		}
 
+		// if this volume is cloned from a volume and not inflated, the temporary snapshot is kept. delete it.
+		if fromVol {
+			err = vs.poolService.PoolEngine().DeleteVolume(engine.GetCloneSnapshotName(volume.Name))
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+		}
+
 		// remove v1.KernelLVolFinalizer
 		var newFinalizers = make([]string, 0, len(volume.Finalizers))
 		var toDelFinalizers = []string{v1.KernelLVolFinalizer, v1.SpdkLvolFinalizer, v1.LogicVolumeFinalizer}
@@ -354,6 +416,10 @@ func (vs *VolumeSyncer) createVolume(volume *v1.AntstorVolume) (needReturn bool,
 		hasSnapName, hasSnapNS bool
 		fromSnap               bool
 		snapName, snapNS       string
+		hasSrcName, hasSrcNS   bool
+		fromVol                bool
+		srcName, srcNS         string
+		srcVol                 *v1.AntstorVolume
 		// fsType for LVM
 		fsType string
 		// lv layout
@@ -363,6 +429,9 @@ func (vs *VolumeSyncer) createVolume(volume *v1.AntstorVolume) (needReturn bool,
 	snapName, hasSnapName = volume.Labels[v1.VolumeSourceSnapNameLabelKey]
 	snapNS, hasSnapNS = volume.Labels[v1.VolumeSourceSnapNamespaceLabelKey]
 	fromSnap = hasSnapName && hasSnapNS
+	srcName, hasSrcName = volume.Labels[v1.VolumeSourceVolNameLabelKey]
+	srcNS, hasSrcNS = volume.Labels[v1.VolumeSourceVolNamespaceLabelKey]
+	fromVol = hasSrcName && hasSrcNS
 	if volume.Annotations[v1.SpdkConnectModeKey] == v1.SpdkConnectModeGuestKernelDirect && volume.Annotations[v1.FsTypeLabelKey] != "" {
 		fsType = volume.Annotations[v1.FsTypeLabelKey]
 	}
@@ -384,6 +453,21 @@ func (vs *VolumeSyncer) createVolume(volume *v1.AntstorVolume) (needReturn bool,
 		resp engine.CreateVolumeResponse
 	)
 
+	// source volume must be in the same pool
+	if fromVol {
+		srcVol, err = vs.storeCli.VolumeV1().AntstorVolumes(srcNS).Get(context.Background(), srcName, metav1.GetOptions{})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+		if srcVol.Spec.TargetNodeId != vs.nodeID || srcVol.Spec.Type != volume.Spec.Type {
+			err = fmt.Errorf("source volume %s is on node %s with type %s, cannot be cloned to volume %s on node %s with type %s",
+				srcName, srcVol.Spec.TargetNodeId, srcVol.Spec.Type, volume.Name, vs.nodeID, volume.Spec.Type)
+			klog.Error(err)
+			return
+		}
+	}
+
 	switch volume.Spec.Type {
 	case v1.VolumeTypeKernelLVol:
 		// Only Spdklvs volume can specify VolumeContentSource
@@ -396,12 +480,32 @@ func (vs *VolumeSyncer) createVolume(volume *v1.AntstorVolume) (needReturn bool,
 
 		// validate lv layout
 
-		// create new volume
-		req = engine.CreateVolumeRequest{
-			VolName:  volume.Name,
-			SizeByte: volume.Spec.SizeByte,
-			FsType:   fsType,
-			LvLayout: lvLayout,
+		if fromVol {
+			klog.Infof("cloning lvm volume for vol %s from %s", volume.Name, srcName)
+			resp, err = vs.poolService.PoolEngine().CloneVolume(engine.CloneVolumeRequest{
+				VolName:    volume.Name,
+				SourceName: srcVol.Spec.KernelLvol.Name,
+				SizeByte:   volume.Spec.SizeByte,
+				LvLayout:   lvLayout,
+			})
+			// data is copied in background. return error to retry later
+			if err == engine.ErrCloneInProgress {
+				klog.Infof("copying data of lvm volume %s from %s", volume.Name, srcName)
+				return
+			}
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+		} else {
+			// create new volume
+			req = engine.CreateVolumeRequest{
+				VolName:  volume.Name,
+				SizeByte: volume.Spec.SizeByte,
+				FsType:   fsType,
+				LvLayout: lvLayout,
+				Thin:     volume.Spec.IsThin,
+			}
 		}
 
 		if volume.Spec.KernelLvol == nil {
@@ -429,11 +533,52 @@ func (vs *VolumeSyncer) createVolume(volume *v1.AntstorVolume) (needReturn bool,
 				klog.Error(err, uuid)
 				return
 			}
+			// the size of clone is the same as the snapshot, expand it to the requested size
+			var clone spdk.Bdev
+			var cloneFullName = fmt.Sprintf("%s/%s", lvsName, volume.Name)
+			clone, _, err = vs.poolService.SpdkService().GetLvol(cloneFullName)
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+			if uint64(clone.BlockSize*clone.NumBlocks) < volume.Spec.SizeByte {
+				err = vs.poolService.SpdkService().ResizeLvol(spdk.ResizeLvolReq{
+					LvolFullName: cloneFullName,
+					TargetSize:   volume.Spec.SizeByte,
+				})
+				if err != nil {
+					klog.Error(err)
+					return
+				}
+			}
+			if volume.Annotations[v1.CloneInflateAnnoKey] == "true" {
+				err = vs.poolService.SpdkService().InflateLvol(spdk.InflateLvolReq{
+					LVStore:  lvsName,
+					LvolName: volume.Name,
+				})
+				if err != nil {
+					klog.Error(err)
+					return
+				}
+			}
+		} else if fromVol {
+			klog.Infof("cloning spdk lvol for vol %s from %s", volume.Name, srcName)
+			_, err = vs.poolService.PoolEngine().CloneVolume(engine.CloneVolumeRequest{
+				VolName:    volume.Name,
+				SourceName: srcVol.Spec.SpdkLvol.Name,
+				SizeByte:   volume.Spec.SizeByte,
+				Inflate:    volume.Annotations[v1.CloneInflateAnnoKey] == "true",
+			})
+			if err != nil {
+				klog.Error(err)
+				return
+			}
 		} else {
 			// create new volume
 			req = engine.CreateVolumeRequest{
 				VolName:  volume.Name,
 				SizeByte: volume.Spec.SizeByte,
+				Thin:     volume.Spec.IsThin,
 			}
 		}
 
@@ -442,7 +587,7 @@ func (vs *VolumeSyncer) createVolume(volume *v1.AntstorVolume) (needReturn bool,
 		}
 		volume.Spec.SpdkLvol.LvsName = lvsName
 		volume.Spec.SpdkLvol.Name = volume.Name
-		volume.Spec.SpdkLvol.Thin = false
+		volume.Spec.SpdkLvol.Thin = volume.Spec.IsThin
 	}
 
 	// create new logic volume
@@ -496,8 +641,8 @@ func (vs *VolumeSyncer) createOpenAccess(volume *v1.AntstorVolume) (needReturn b
 
 	switch volume.Spec.Type {
 	case v1.VolumeTypeKernelLVol:
-		// for loacl lvm volume, do not create subsystem
-		if isLocal {
+		// for loacl lvm volume, do not create subsystem, unless it could be attached by other nodes
+		if isLocal && !volume.IsMultiWriter() {
 			klog.Info("skip creating subsystem for local LVM volume")
 			return false, nil
 		}
@@ -514,11 +659,12 @@ func (vs *VolumeSyncer) createOpenAccess(volume *v1.AntstorVolume) (needReturn b
 			NSUUID:    volume.Spec.Uuid,
 			BdevName:  GetBdevNameFromUUID(volume.Spec.Uuid),
 			SerialNum: GetSNFromUUID(volume.Spec.Uuid),
-			TransType: spdkrpc.TransportTypeTCP,
+			TransType: volume.GetTransportType(),
 			Address:   nodeIP,
 			AddrFam:   string(client.AddrFamilyIPv4),
 			// NOTICE: SvcID is set after subsystem is created
 		}
+		setTargetPaths(volume.Spec.SpdkTarget, sp.Spec.Addresses)
 		aioVolume = &pool.AioVolume{
 			DevPath:  volume.Spec.KernelLvol.DevPath,
 			BdevName: volume.Spec.SpdkTarget.BdevName,
@@ -533,6 +679,14 @@ func (vs *VolumeSyncer) createOpenAccess(volume *v1.AntstorVolume) (needReturn b
 			volume.Spec.SpdkTarget = &v1.SpdkTarget{}
 		}
 		volume.Spec.SpdkTarget.BdevName = volume.Spec.SpdkLvol.FullName()
+		// replicated lvol is exposed by the RAID1 bdev over it
+		if volume.Spec.Replicas > 1 {
+			volume.Spec.SpdkTarget.BdevName = GetRaidBdevName(volume.Spec.Uuid)
+		}
+		// encrypted lvol is exposed by the crypto bdev over it
+		if volume.Spec.Encryption != nil {
+			volume.Spec.SpdkTarget.BdevName = GetCryptoBdevName(volume.Spec.Uuid)
+		}
 		volume.Spec.SpdkTarget.SerialNum = GetSNFromUUID(volume.Spec.Uuid)
 
 		// TODO: validate the nvmf_tgt has VFIOUser capability
@@ -553,8 +707,9 @@ func (vs *VolumeSyncer) createOpenAccess(volume *v1.AntstorVolume) (needReturn b
 		} else {
 			// for remote volume, SvcID(port) is set after subsystem is created
 			volume.Spec.SpdkTarget.Address = nodeIP
-			volume.Spec.SpdkTarget.TransType = spdkrpc.TransportTypeTCP
+			volume.Spec.SpdkTarget.TransType = volume.GetTransportType()
 			volume.Spec.SpdkTarget.AddrFam = string(client.AddrFamilyIPv4)
+			setTargetPaths(volume.Spec.SpdkTarget, sp.Spec.Addresses)
 		}
 
 		// for migration destination volume, NQN and NSUUID should be the same as resource target
@@ -571,35 +726,49 @@ func (vs *VolumeSyncer) createOpenAccess(volume *v1.AntstorVolume) (needReturn b
 		}
 	}
 
-	var allowHosts []string
-	var hostPool *v1.StoragePool
+	var allowHosts, revokeHosts []string
 	var resp spdk.Target
+	var dhchapKeys *pool.DHChapKeys
+	var cryptoBdev *pool.CryptoBdev
+	var raidBdev *pool.RaidBdev
 
-	if volume.Spec.HostNode != nil && volume.Spec.HostNode.ID != "" {
-		// get hostnqn from metadata
-		hostPool, err = vs.storeCli.VolumeV1().StoragePools(v1.DefaultNamespace).Get(context.Background(), volume.Spec.HostNode.ID, metav1.GetOptions{})
+	allowHosts, err = vs.getAllowHosts(volume)
+	if err != nil {
+		return
+	}
+	exposedHosts, detachedHosts := getDetachedHosts(volume)
+	revokeHosts, err = vs.getRevokeHosts(detachedHosts)
+	if err != nil {
+		return
+	}
+
+	dhchapKeys, err = vs.prepareDHChapKeys(volume)
+	if err != nil {
+		return
+	}
+
+	// LVM volume is encrypted by LUKS on the host, so only SpdkLVol needs crypto bdev
+	if volume.Spec.Type == v1.VolumeTypeSpdkLVol {
+		cryptoBdev, err = vs.prepareCryptoBdev(volume)
 		if err != nil {
-			klog.Error(err)
 			return
 		}
-		if hostNQN, has := hostPool.Annotations[v1.AnnotationHostNQN]; has {
-			allowHosts = append(allowHosts, hostNQN)
+		raidBdev, err = vs.prepareRaidBdev(volume)
+		if err != nil {
+			return
 		}
 	}
 
 	resp, err = vs.poolService.Access().ExposeAccess(pool.Access{
-		AIO:  aioVolume,
-		LVol: lvolVolume,
-		OpenAccess: spdk.Target{
-			NQN:          volume.Spec.SpdkTarget.SubsysNQN,
-			SerialNumber: volume.Spec.SpdkTarget.SerialNum,
-			NSUUID:       volume.Spec.SpdkTarget.NSUUID,
-			TransAddr:    volume.Spec.SpdkTarget.Address,
-			TransType:    volume.Spec.SpdkTarget.TransType,
-			AddrFam:      volume.Spec.SpdkTarget.AddrFam,
-			SvcID:        volume.Spec.SpdkTarget.SvcID,
-		},
+		AIO:          aioVolume,
+		LVol:         lvolVolume,
+		OpenAccess:   newSpdkTarget(volume.Spec.SpdkTarget),
 		AllowHostNQN: allowHosts,
+		// previous hosts of the volume are not allowed to connect
+		RevokeHostNQN: revokeHosts,
+		DHChap:        dhchapKeys,
+		Crypto:        cryptoBdev,
+		Raid:          raidBdev,
 	})
 
 	if err != nil {
@@ -609,9 +778,16 @@ func (vs *VolumeSyncer) createOpenAccess(volume *v1.AntstorVolume) (needReturn b
 	klog.Info("exposed spdk access ", resp)
 	// set SvcID by response
 	volume.Spec.SpdkTarget.SvcID = resp.SvcID
+	for idx := range volume.Spec.SpdkTarget.Paths {
+		volume.Spec.SpdkTarget.Paths[idx].SvcID = resp.SvcID
+	}
 
 	// create spdk tgt and add SpdkTargetFinalizer
 	volume.Finalizers = append(volume.Finalizers, v1.SpdkTargetFinalizer)
+	if volume.Annotations == nil {
+		volume.Annotations = make(map[string]string)
+	}
+	volume.Annotations[v1.ExposedHostNodeAnnoKey] = exposedHosts
 	_, err = vs.storeCli.VolumeV1().AntstorVolumes(volume.Namespace).Update(context.Background(), volume, metav1.UpdateOptions{})
 
 	return true, err
@@ -637,6 +813,22 @@ func GetSocketPathFromeUUID(uuid string) (path string) {
 	return "/usr/tmp/" + uuid
 }
 
+func GetDHChapKeyName(uuid string) (name string) {
+	return "dhchap-" + uuid
+}
+
+func GetDHChapCtrlrKeyName(uuid string) (name string) {
+	return "dhchap-ctrlr-" + uuid
+}
+
+func GetCryptoBdevName(uuid string) (name string) {
+	return "crypto-" + uuid
+}
+
+func GetCryptoKeyName(uuid string) (name string) {
+	return "crypto-key-" + uuid
+}
+
 func (vs *VolumeSyncer) applyVolume(volume *v1.AntstorVolume) (needReturn bool, err error) {
 	// apply allocated size of LV to Annotation
 	if _, has := volume.Annotations[v1.AllocatedSizeAnnoKey]; !has && volume.Status.Status == v1.VolumeStatusReady {
@@ -686,34 +878,426 @@ func (vs *VolumeSyncer) applyVolume(volume *v1.AntstorVolume) (needReturn bool,
 	// So we must apply allowHosts to volume even if the status is Ready.
 	klog.Infof("volume %s is ready, apply allowHosts to volume", volume.Name)
 	if volume.Spec.SpdkTarget != nil {
-		var allowHosts []string
-		var hostPool *v1.StoragePool
-		var tgt = spdk.Target{
-			NQN:          volume.Spec.SpdkTarget.SubsysNQN,
-			SerialNumber: volume.Spec.SpdkTarget.SerialNum,
-			NSUUID:       volume.Spec.SpdkTarget.NSUUID,
-			SvcID:        volume.Spec.SpdkTarget.SvcID,
-			TransAddr:    volume.Spec.SpdkTarget.Address,
-			TransType:    volume.Spec.SpdkTarget.TransType,
-			AddrFam:      volume.Spec.SpdkTarget.AddrFam,
-		}
-
-		if volume.Spec.HostNode != nil && volume.Spec.HostNode.ID != "" {
-			// get hostnqn from metadata
-			hostPool, err = vs.storeCli.VolumeV1().StoragePools(v1.DefaultNamespace).Get(context.Background(), volume.Spec.HostNode.ID, metav1.GetOptions{})
+		var allowHosts, revokeHosts []string
+		var dhchapKeys *pool.DHChapKeys
+		var tgt = newSpdkTarget(volume.Spec.SpdkTarget)
+
+		allowHosts, err = vs.getAllowHosts(volume)
+		if err != nil {
+			return
+		}
+		exposedHosts, detachedHosts := getDetachedHosts(volume)
+		revokeHosts, err = vs.getRevokeHosts(detachedHosts)
+		if err != nil {
+			return
+		}
+		dhchapKeys, err = vs.prepareDHChapKeys(volume)
+		if err != nil {
+			return
+		}
+		_, errExpose := vs.poolService.Access().ExposeAccess(pool.Access{
+			OpenAccess:    tgt,
+			AllowHostNQN:  allowHosts,
+			RevokeHostNQN: revokeHosts,
+			DHChap:        dhchapKeys,
+		})
+		// record the hosts, so that they are revoked when the volume is detached from them
+		if errExpose == nil && volume.Annotations[v1.ExposedHostNodeAnnoKey] != exposedHosts {
+			var updated *v1.AntstorVolume
+			if volume.Annotations == nil {
+				volume.Annotations = make(map[string]string)
+			}
+			volume.Annotations[v1.ExposedHostNodeAnnoKey] = exposedHosts
+			updated, err = vs.storeCli.VolumeV1().AntstorVolumes(volume.Namespace).Update(context.Background(), volume, metav1.UpdateOptions{})
 			if err != nil {
 				klog.Error(err)
 				return
 			}
-			if hostNQN, has := hostPool.Annotations[v1.AnnotationHostNQN]; has {
-				allowHosts = append(allowHosts, hostNQN)
-			}
+			volume.ResourceVersion = updated.ResourceVersion
 		}
-		vs.poolService.Access().ExposeAccess(pool.Access{
-			OpenAccess:   tgt,
-			AllowHostNQN: allowHosts,
+	}
+
+	// apply QoS limits every time, so that changes of limits take effect
+	if volume.Status.Status == v1.VolumeStatusReady {
+		err = vs.applyQoS(volume)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
+	// replica legs may be replaced by controller
+	if volume.Status.Status == v1.VolumeStatusReady {
+		err = syncReplicas(vs.storeCli, vs.poolService.SpdkService(), volume)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
+	return
+}
+
+// applyQoS sets rate limits of the bdev of volume. If QoS is nil, limits are removed.
+// LVM volume without SpdkTarget is limited by io.max of cgroup of the pod.
+func (vs *VolumeSyncer) applyQoS(volume *v1.AntstorVolume) (err error) {
+	var (
+		bdevName string
+		qos      v1.VolumeQoS
+	)
+	if volume.Spec.QoS != nil {
+		qos = *volume.Spec.QoS
+	}
+	switch volume.Spec.Type {
+	case v1.VolumeTypeSpdkLVol:
+		if volume.Spec.SpdkLvol != nil {
+			bdevName = volume.Spec.SpdkLvol.FullName()
+		}
+	case v1.VolumeTypeKernelLVol:
+		// aio bdev is created for remote LVM volume
+		if volume.Spec.SpdkTarget != nil {
+			bdevName = volume.Spec.SpdkTarget.BdevName
+		} else {
+			return vs.applyLocalQoS(volume, qos)
+		}
+	}
+	if bdevName == "" {
+		return
+	}
+
+	err = vs.poolService.SpdkService().SetBdevQoSLimit(spdk.BdevQoSLimitRequest{
+		BdevName: bdevName,
+		RWIOPS:   qos.RWIOPS,
+		RWMbytes: qos.RWMBytes,
+		RMbytes:  qos.RMBytes,
+		WMbytes:  qos.WMBytes,
+	})
+	// bdev is not created yet
+	if spdk.IsNotFoundDeviceError(err) {
+		klog.Infof("not found bdev %s of volume %s, skip applying QoS", bdevName, volume.Name)
+		return nil
+	}
+
+	// record the result in status
+	var errStatus = vs.updateQoSStatus(volume, err)
+	if err == nil {
+		err = errStatus
+	}
+	return
+}
+
+// applyLocalQoS sets io.max of the LV in the cgroup of the pod, which is recorded by NodePublishVolume.
+// It is skipped if the volume is not published to a pod, or no QoS is ever applied.
+func (vs *VolumeSyncer) applyLocalQoS(volume *v1.AntstorVolume, qos v1.VolumeQoS) (err error) {
+	var (
+		podUID string
+		pub    = volume.Status.CSINodePubParams
+	)
+	if volume.Spec.QoS == nil && volume.Status.QoS == nil {
+		return
+	}
+	if pub != nil {
+		podUID = pub.CSIVolumeContext[v1.CSIVolumeContextPodUIDKey]
+	}
+	if podUID == "" || volume.Spec.KernelLvol == nil {
+		klog.Infof("volume %s is not published to pod, skip applying QoS", volume.Name)
+		return
+	}
+
+	err = cgroup.SetPodIOMax(cgroup.DefaultRoot, podUID, volume.Spec.KernelLvol.DevPath,
+		cgroup.NewIOMax(qos.RWIOPS, qos.RWMBytes, qos.RMBytes, qos.WMBytes))
+
+	// record the result in status
+	var errStatus = vs.updateQoSStatus(volume, err)
+	if err == nil {
+		err = errStatus
+	}
+	return
+}
+
+// updateQoSStatus updates Status.QoS by the result of applying Spec.QoS. It does nothing if the status is not changed.
+func (vs *VolumeSyncer) updateQoSStatus(volume *v1.AntstorVolume, applyErr error) (err error) {
+	var st = &v1.QoSStatus{
+		LastResult: v1.QoSResultSuccess,
+	}
+	if volume.Status.QoS != nil {
+		st = volume.Status.QoS.DeepCopy()
+	}
+
+	if applyErr != nil {
+		st.LastResult = v1.QoSResultFailed
+		st.Message = applyErr.Error()
+	} else {
+		st.LastResult = v1.QoSResultSuccess
+		st.Message = ""
+		st.Applied = volume.Spec.QoS.DeepCopy()
+	}
+
+	if volume.Status.QoS != nil && volume.Status.QoS.LastResult == st.LastResult &&
+		volume.Status.QoS.Message == st.Message && reflect.DeepEqual(volume.Status.QoS.Applied, st.Applied) {
+		return
+	}
+	// no QoS is applied ever
+	if volume.Status.QoS == nil && st.Applied == nil && applyErr == nil {
+		return
+	}
+
+	st.LastUpdateTime = metav1.Now()
+	volume.Status.QoS = st
+	klog.Infof("update qos status of volume %s to %+v", volume.Name, *st)
+	_, err = vs.storeCli.VolumeV1().AntstorVolumes(volume.Namespace).UpdateStatus(context.Background(), volume, metav1.UpdateOptions{})
+	return
+}
+
+// setTargetPaths sets Paths of SpdkTarget for NVMe multipath, if the node has more than one address.
+// The listener of tgt.Address is optimized and others are non-optimized.
+func setTargetPaths(tgt *v1.SpdkTarget, addrs []corev1.NodeAddress) {
+	tgt.Paths = nil
+	for _, addr := range addrs {
+		if addr.Type != corev1.NodeInternalIP || addr.Address == tgt.Address {
+			continue
+		}
+		if len(tgt.Paths) == 0 {
+			tgt.Paths = append(tgt.Paths, v1.TargetPath{
+				Address:   tgt.Address,
+				SvcID:     tgt.SvcID,
+				TransType: tgt.TransType,
+				AddrFam:   tgt.AddrFam,
+				ANAState:  v1.ANAStateOptimized,
+			})
+		}
+		tgt.Paths = append(tgt.Paths, v1.TargetPath{
+			Address:   addr.Address,
+			SvcID:     tgt.SvcID,
+			TransType: tgt.TransType,
+			AddrFam:   tgt.AddrFam,
+			ANAState:  v1.ANAStateNonOptimized,
 		})
 	}
+}
+
+// newSpdkTarget converts SpdkTarget of volume to the Target of spdk service
+func newSpdkTarget(st *v1.SpdkTarget) (tgt spdk.Target) {
+	tgt = spdk.Target{
+		NQN:          st.SubsysNQN,
+		SerialNumber: st.SerialNum,
+		NSUUID:       st.NSUUID,
+		SvcID:        st.SvcID,
+		TransAddr:    st.Address,
+		TransType:    st.TransType,
+		AddrFam:      st.AddrFam,
+	}
+	for _, path := range st.Paths {
+		if path.Address == st.Address {
+			tgt.ANAState = path.ANAState
+			continue
+		}
+		tgt.Paths = append(tgt.Paths, spdk.TargetPath{
+			TransAddr: path.Address,
+			ANAState:  path.ANAState,
+		})
+	}
+	return
+}
+
+// getExposedHosts returns IDs of the HostNode and AttachedHosts of multi-writer volume, which are allowed to connect to the subsystem
+func getExposedHosts(volume *v1.AntstorVolume) (nodeIDs []string) {
+	if volume.Spec.HostNode != nil && volume.Spec.HostNode.ID != "" {
+		nodeIDs = append(nodeIDs, volume.Spec.HostNode.ID)
+	}
+	if volume.IsMultiWriter() {
+		nodeIDs = append(nodeIDs, volume.Spec.AttachedHosts...)
+	}
+	return
+}
+
+// getDetachedHosts returns hosts which the volume was exposed to, but are neither HostNode nor AttachedHosts now.
+// exposed is the hosts to record in annotation after the volume is exposed.
+func getDetachedHosts(volume *v1.AntstorVolume) (exposed string, detached []string) {
+	var hosts = getExposedHosts(volume)
+	exposed = strings.Join(hosts, ",")
+
+	val, has := volume.Annotations[v1.ExposedHostNodeAnnoKey]
+	if !has {
+		return
+	}
+	for _, item := range strings.Split(val, ",") {
+		if item != "" && !misc.InSliceString(item, hosts) {
+			detached = append(detached, item)
+		}
+	}
+	return
+}
+
+// getRevokeHosts returns hostnqn of the detached hosts. Removed nodes are skipped.
+func (vs *VolumeSyncer) getRevokeHosts(nodeIDs []string) (revokeHosts []string, err error) {
+	var hostPool *v1.StoragePool
+	for _, nodeID := range nodeIDs {
+		hostPool, err = vs.storeCli.VolumeV1().StoragePools(v1.DefaultNamespace).Get(context.Background(), nodeID, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				klog.Infof("not found StoragePool %s, skip revoking its hostnqn", nodeID)
+				err = nil
+				continue
+			}
+			klog.Error(err)
+			return
+		}
+		if hostNQN, has := hostPool.Annotations[v1.AnnotationHostNQN]; has {
+			revokeHosts = append(revokeHosts, hostNQN)
+		}
+	}
+	return
+}
+
+// getAllowHosts returns hostnqn of the HostNode and AttachedHosts of the volume.
+// hostnqn is recorded in the Annotations of StoragePool.
+func (vs *VolumeSyncer) getAllowHosts(volume *v1.AntstorVolume) (allowHosts []string, err error) {
+	var (
+		hostPool   *v1.StoragePool
+		nodeIDs    = getExposedHosts(volume)
+		hostNodeID string
+	)
+
+	if volume.Spec.HostNode != nil {
+		hostNodeID = volume.Spec.HostNode.ID
+	}
+
+	for _, nodeID := range nodeIDs {
+		// get hostnqn from metadata
+		hostPool, err = vs.storeCli.VolumeV1().StoragePools(v1.DefaultNamespace).Get(context.Background(), nodeID, metav1.GetOptions{})
+		if err != nil {
+			// node is removed, so it is not allowed to connect
+			if errors.IsNotFound(err) && nodeID != hostNodeID {
+				klog.Infof("not found StoragePool %s, skip adding its hostnqn", nodeID)
+				err = nil
+				continue
+			}
+			klog.Error(err)
+			return
+		}
+		if hostNQN, has := hostPool.Annotations[v1.AnnotationHostNQN]; has {
+			allowHosts = append(allowHosts, hostNQN)
+		}
+	}
+
+	return
+}
+
+// prepareDHChapKeys reads DH-HMAC-CHAP keys from the Secret of volume.Spec.HostAuth,
+// writes them to key files and adds the files to SPDK keyring.
+// It returns nil if HostAuth is not set.
+func (vs *VolumeSyncer) prepareDHChapKeys(volume *v1.AntstorVolume) (keys *pool.DHChapKeys, err error) {
+	if volume.Spec.HostAuth == nil {
+		return
+	}
+
+	var (
+		auth   = volume.Spec.HostAuth
+		secret *corev1.Secret
+	)
+	secret, err = vs.kubeCli.CoreV1().Secrets(auth.SecretNamespace).Get(context.Background(), auth.SecretName, metav1.GetOptions{})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	hostKey := secret.Data[v1.DHChapKeySecretKey]
+	if len(hostKey) == 0 {
+		err = fmt.Errorf("Secret %s/%s has no %s", auth.SecretNamespace, auth.SecretName, v1.DHChapKeySecretKey)
+		klog.Error(err)
+		return
+	}
+
+	keys = &pool.DHChapKeys{
+		Key: GetDHChapKeyName(volume.Spec.Uuid),
+	}
+	err = vs.addKeyFile(keys.Key, hostKey)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	if ctrlrKey := secret.Data[v1.DHChapCtrlrKeySecretKey]; len(ctrlrKey) > 0 {
+		keys.CtrlrKey = GetDHChapCtrlrKeyName(volume.Spec.Uuid)
+		err = vs.addKeyFile(keys.CtrlrKey, ctrlrKey)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
+	return
+}
+
+// addKeyFile writes the key to file and adds the file to SPDK keyring
+func (vs *VolumeSyncer) addKeyFile(name string, key []byte) (err error) {
+	var (
+		path    = filepath.Join(DHChapKeyDir, name)
+		content = bytes.TrimSpace(key)
+		old     []byte
+	)
+
+	err = os.MkdirAll(DHChapKeyDir, 0700)
+	if err != nil {
+		return
+	}
+
+	// keyring only accepts the file which is not accessible by group and others
+	old, err = os.ReadFile(path)
+	if err != nil || !bytes.Equal(old, content) {
+		err = os.WriteFile(path, content, 0600)
+		if err != nil {
+			return
+		}
+	}
+
+	return vs.poolService.SpdkService().AddFileKey(name, path)
+}
+
+// removeDHChapKeys removes keys of the volume from SPDK keyring and deletes the key files
+func (vs *VolumeSyncer) removeDHChapKeys(volume *v1.AntstorVolume) (err error) {
+	for _, name := range []string{GetDHChapKeyName(volume.Spec.Uuid), GetDHChapCtrlrKeyName(volume.Spec.Uuid)} {
+		err = vs.poolService.SpdkService().RemoveFileKey(name)
+		if err != nil {
+			return
+		}
+		err = os.Remove(filepath.Join(DHChapKeyDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return
+		}
+		err = nil
+	}
+	return
+}
+
+// prepareCryptoBdev reads the encryption key from the Secret of volume.Spec.Encryption.
+// It returns nil if Encryption is not set.
+func (vs *VolumeSyncer) prepareCryptoBdev(volume *v1.AntstorVolume) (crypto *pool.CryptoBdev, err error) {
+	if volume.Spec.Encryption == nil {
+		return
+	}
+
+	var (
+		enc    = volume.Spec.Encryption
+		secret *corev1.Secret
+	)
+	secret, err = vs.kubeCli.CoreV1().Secrets(enc.SecretNamespace).Get(context.Background(), enc.SecretName, metav1.GetOptions{})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	crypto = &pool.CryptoBdev{
+		BdevName: GetCryptoBdevName(volume.Spec.Uuid),
+		KeyName:  GetCryptoKeyName(volume.Spec.Uuid),
+	}
+	crypto.Key, crypto.Key2, err = v1.ParseEncryptionKey(secret.Data[v1.EncryptionKeySecretKey])
+	if err != nil {
+		err = fmt.Errorf("invalid Secret %s/%s, %w", enc.SecretNamespace, enc.SecretName, err)
+		klog.Error(err)
+		return nil, err
+	}
 
 	return
 }
@@ -0,0 +1,131 @@
+package sync
+
+import (
+	"testing"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/generated/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newHostPool(nodeID, hostNQN string) *v1.StoragePool {
+	return &v1.StoragePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: v1.DefaultNamespace,
+			Name:      nodeID,
+			Annotations: map[string]string{
+				v1.AnnotationHostNQN: hostNQN,
+			},
+		},
+	}
+}
+
+func TestGetAllowHosts(t *testing.T) {
+	vs := &VolumeSyncer{
+		storeCli: fake.NewSimpleClientset(newHostPool("node-1", "nqn-1"), newHostPool("node-2", "nqn-2")),
+	}
+
+	// HostNode is not set yet
+	vol := &v1.AntstorVolume{}
+	hosts, err := vs.getAllowHosts(vol)
+	assert.NoError(t, err)
+	assert.Empty(t, hosts)
+
+	// multi-writer volume without HostNode, removed node is skipped
+	vol.Spec.AccessMode = v1.VolumeAccessModeMultiNodeMultiWriter
+	vol.Spec.AttachedHosts = []string{"node-2", "node-removed"}
+	hosts, err = vs.getAllowHosts(vol)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nqn-2"}, hosts)
+
+	vol.Spec.HostNode = &v1.NodeInfo{ID: "node-1"}
+	hosts, err = vs.getAllowHosts(vol)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nqn-1", "nqn-2"}, hosts)
+
+	// StoragePool of HostNode must exist
+	vol.Spec.HostNode = &v1.NodeInfo{ID: "node-removed"}
+	_, err = vs.getAllowHosts(vol)
+	assert.Error(t, err)
+}
+
+func TestGetDetachedHosts(t *testing.T) {
+	// volume is not exposed before
+	vol := &v1.AntstorVolume{}
+	vol.Spec.HostNode = &v1.NodeInfo{ID: "node-1"}
+	exposed, detached := getDetachedHosts(vol)
+	assert.Equal(t, "node-1", exposed)
+	assert.Empty(t, detached)
+
+	// same host
+	vol.Annotations = map[string]string{v1.ExposedHostNodeAnnoKey: "node-1"}
+	_, detached = getDetachedHosts(vol)
+	assert.Empty(t, detached)
+
+	// host is changed
+	vol.Spec.HostNode.ID = "node-2"
+	exposed, detached = getDetachedHosts(vol)
+	assert.Equal(t, "node-2", exposed)
+	assert.Equal(t, []string{"node-1"}, detached)
+
+	// multi-writer volume is attached to more hosts
+	vol.Spec.AccessMode = v1.VolumeAccessModeMultiNodeMultiWriter
+	vol.Spec.AttachedHosts = []string{"node-1", "node-3"}
+	vol.Annotations[v1.ExposedHostNodeAnnoKey] = "node-1"
+	exposed, detached = getDetachedHosts(vol)
+	assert.Equal(t, "node-2,node-1,node-3", exposed)
+	assert.Empty(t, detached)
+
+	// node-3 is detached from multi-writer volume
+	vol.Annotations[v1.ExposedHostNodeAnnoKey] = exposed
+	vol.Spec.AttachedHosts = []string{"node-1"}
+	exposed, detached = getDetachedHosts(vol)
+	assert.Equal(t, "node-2,node-1", exposed)
+	assert.Equal(t, []string{"node-3"}, detached)
+}
+
+func TestGetRevokeHosts(t *testing.T) {
+	vs := &VolumeSyncer{
+		storeCli: fake.NewSimpleClientset(newHostPool("node-1", "nqn-1"), newHostPool("node-2", "nqn-2")),
+	}
+
+	// removed node is skipped
+	hosts, err := vs.getRevokeHosts([]string{"node-2", "node-removed"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nqn-2"}, hosts)
+
+	hosts, err = vs.getRevokeHosts(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, hosts)
+}
+
+func TestApplyLocalQoS(t *testing.T) {
+	vol := &v1.AntstorVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: v1.DefaultNamespace,
+			Name:      "vol-1",
+		},
+		Spec: v1.AntstorVolumeSpec{
+			Type:       v1.VolumeTypeKernelLVol,
+			KernelLvol: &v1.KernelLvol{Name: "vol-1", DevPath: "/dev/not-exist-vg/vol-1"},
+			QoS:        &v1.VolumeQoS{RWIOPS: 1000},
+		},
+	}
+	vs := &VolumeSyncer{
+		storeCli: fake.NewSimpleClientset(vol.DeepCopy()),
+	}
+
+	// not published to pod
+	assert.NoError(t, vs.applyQoS(vol))
+	assert.Nil(t, vol.Status.QoS)
+
+	// failure of setting io.max is recorded in status
+	vol.Status.CSINodePubParams = &v1.CSINodePubParams{
+		CSIVolumeContext: map[string]string{v1.CSIVolumeContextPodUIDKey: "a-b-c"},
+	}
+	assert.Error(t, vs.applyQoS(vol))
+	if assert.NotNil(t, vol.Status.QoS) {
+		assert.Equal(t, v1.QoSResultFailed, vol.Status.QoS.LastResult)
+	}
+}
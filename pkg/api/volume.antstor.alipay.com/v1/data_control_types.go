@@ -30,6 +30,19 @@ const (
 
 type RaidLevel string
 
+const (
+	// controller is allocating a replacement volume for the missing member
+	RebuildPhaseReplacing RebuildPhase = "Replacing"
+	// agent is adding the replacement PV to VG and rebuilding data of raid LV
+	RebuildPhaseRebuilding RebuildPhase = "Rebuilding"
+	// data is rebuilt and the missing PV is removed from VG, old member is waiting to be deleted
+	RebuildPhaseSynced RebuildPhase = "Synced"
+	// old member is deleted
+	RebuildPhaseFinished RebuildPhase = "Finished"
+)
+
+type RebuildPhase string
+
 type LVMControl struct {
 	// +optional
 	VG string `json:"vg"`
@@ -54,11 +67,31 @@ type EntityIdentity struct {
 	UUID      string `json:"uuid"`
 }
 
+// DataControlRebuildStatus is the progress of replacing a missing member of raid
+type DataControlRebuildStatus struct {
+	Phase RebuildPhase `json:"phase"`
+	// OldPV is the missing member
+	OldPV LVMControlPV `json:"oldPV"`
+	// NewVolId is the replacement volume in VolumeGroup
+	// +optional
+	NewVolId EntityIdentity `json:"newVolId"`
+	// SyncPercent is the sync progress of raid LV, from 0 to 100
+	// +optional
+	SyncPercent int `json:"syncPercent,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 type Raid struct {
 	Level RaidLevel `json:"level"`
 	// TODO: other raid params
 }
 
+// IsRedundant returns true if data is still available when a member is lost
+func (r Raid) IsRedundant() bool {
+	return r.Level == Raid1 || r.Level == Raid5 || r.Level == Raid6
+}
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -107,6 +140,14 @@ type AntstorDataControlStatus struct {
 
 	// +optional
 	Message string `json:"message,omitempty"`
+
+	// MissingPVs are members of raid which are lost in VG. They are reported by agent.
+	// +optional
+	MissingPVs []EntityIdentity `json:"missingPVs,omitempty"`
+
+	// Rebuild is the progress of replacing a missing member
+	// +optional
+	Rebuild *DataControlRebuildStatus `json:"rebuild,omitempty"`
 }
 
 // +genclient
@@ -2,6 +2,13 @@ package v1
 
 import (
 	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// IO load of pool is ignored if it is not updated in this duration
+	PoolIOLoadExpiration = 10 * time.Minute
 )
 
 // GetVgTotalBytes get total space of VolumeGroup in byte, including reserved space
@@ -69,6 +76,27 @@ func (sp *StoragePool) GetAvailableBytes() int64 {
 // 	return 0
 // }
 
+// GetThinOvercommitPct returns the overcommit percentage of thin volumes. ThinOvercommitPct of the pool takes precedence,
+// then defaultPct is used. Default is 100.
+func (sp *StoragePool) GetThinOvercommitPct(defaultPct int) int {
+	if sp.Spec.ThinOvercommitPct > 0 {
+		return sp.Spec.ThinOvercommitPct
+	}
+	if defaultPct > 0 {
+		return defaultPct
+	}
+	return 100
+}
+
+// GetUsedPct returns the percentage of physical space used in pool, which is not affected by overcommit of thin volumes
+func (sp *StoragePool) GetUsedPct() float64 {
+	var total = sp.GetVgTotalBytes()
+	if total <= 0 {
+		return 0
+	}
+	return float64(total-sp.GetVgFreeBytes()) * 100 / float64(total)
+}
+
 func (sp *StoragePool) IsSchedulable() bool {
 	val, has := sp.Labels[PoolSchedulingStatusLabelKey]
 	labelLocked := has && val == string(PoolSchedulingStatusLocked)
@@ -86,3 +114,26 @@ func (sp *StoragePool) Mode() (mode PoolMode) {
 	}
 	return
 }
+
+// SupportTransport returns true if SPDK target of the pool supports the transport type.
+// TCP is supported by default.
+func (sp *StoragePool) SupportTransport(transType string) bool {
+	if len(sp.Spec.Transports) == 0 {
+		return strings.EqualFold(transType, TransportTypeTCP)
+	}
+	for _, item := range sp.Spec.Transports {
+		if strings.EqualFold(item, transType) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRecentIOLoad returns IO load of the pool. It is nil if agent has not reported it in PoolIOLoadExpiration.
+func (sp *StoragePool) GetRecentIOLoad() *PoolIOLoad {
+	var load = sp.Status.IOLoad
+	if load == nil || time.Since(load.UpdateTime.Time) > PoolIOLoadExpiration {
+		return nil
+	}
+	return load
+}
@@ -44,6 +44,11 @@ const (
 	PoolConditionSpkdHealth PoolConditionType = "Spdk"
 	PoolConditionLvmHealth  PoolConditionType = "Lvm"
 	PoolConditionKubeNode   PoolConditionType = "KubeNode"
+	// usage of thin pool in KernelLVM. Message is the usage percentage, e.g. "45.20%"
+	PoolConditionThinPoolData     PoolConditionType = "ThinPoolData"
+	PoolConditionThinPoolMetadata PoolConditionType = "ThinPoolMetadata"
+	// physical usage of SpdkLVStore. Message is the usage percentage
+	PoolConditionLVStoreUsage PoolConditionType = "LVStoreUsage"
 
 	KubeNodeMsgNcOffline = "NC_OFFLINE"
 
@@ -85,6 +90,10 @@ const (
 
 	PoolLabelsNodeSnKey = "obnvmf/node-sn"
 
+	// default labels of node for rack and room, which are used by agent and scheduler
+	NodeLabelKeyRack = "lite.io/rack"
+	NodeLabelKeyRoom = "lite.io/room"
+
 	// static local storage
 	// PoolStaticLocalStoragePercentageKey = "obnvmf/static-local-storage-pct"
 	// PoolStaticLocalStorageSizeKey       = "obnvmf/static-local-storage-size"
@@ -126,6 +135,15 @@ type KernelLVM struct {
 	// +patchStrategy=merge
 	// +optional
 	ReservedLVol []KernelLVol `json:"reservedLVol,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+
+	// ThinPool is the thin pool LV in VG. It is nil if thin provisioning is not enabled
+	// +optional
+	ThinPool *KernelThinPool `json:"thinPool,omitempty"`
+}
+
+type KernelThinPool struct {
+	Name  string `json:"name,omitempty"`
+	Bytes uint64 `json:"bytes,omitempty"`
 }
 
 type SpdkLVStore struct {
@@ -168,6 +186,16 @@ type StoragePoolSpec struct {
 	// NodeInfo contains info of node
 	// +optional
 	NodeInfo NodeInfo `json:"nodeInfo,omitempty"`
+
+	// Transports are NVMe-oF transport types supported by the SPDK target, e.g. TCP, RDMA.
+	// If it is empty, only TCP is supported.
+	// +optional
+	Transports []string `json:"transports,omitempty"`
+
+	// ThinOvercommitPct is the percentage of total size of thin volumes to physical space for thin volumes.
+	// e.g. 200 means thin volumes could be allocated twice the space. Default is 100, which means no overcommit.
+	// +optional
+	ThinOvercommitPct int `json:"thinOvercommitPct,omitempty"`
 }
 
 // StoragePoolStatus defines the observed state of StoragePool
@@ -194,6 +222,38 @@ type StoragePoolStatus struct {
 
 	// +optional
 	Message string `json:"message,omitempty"`
+
+	// IOLoad is the summary of recent IO load of the pool
+	// +optional
+	IOLoad *PoolIOLoad `json:"ioLoad,omitempty"`
+}
+
+// PoolIOLoad is the summary of recent IO load of the pool, which is averaged in a rolling window.
+// Latency percentiles are not reported, because neither diskstats nor SPDK iostat provides latency distribution.
+type PoolIOLoad struct {
+	// UtilPct is the percentage of time that the disks of pool are busy, 0-100
+	UtilPct int `json:"utilPct"`
+	// ReadIOPS is read requests per second
+	// +optional
+	ReadIOPS int64 `json:"readIOPS,omitempty"`
+	// WriteIOPS is write requests per second
+	// +optional
+	WriteIOPS int64 `json:"writeIOPS,omitempty"`
+	// ReadBps is read bytes per second
+	// +optional
+	ReadBps int64 `json:"readBps,omitempty"`
+	// WriteBps is written bytes per second
+	// +optional
+	WriteBps int64 `json:"writeBps,omitempty"`
+	// AvgLatencyUs is the average latency of read and write requests in microseconds
+	// +optional
+	AvgLatencyUs int64 `json:"avgLatencyUs,omitempty"`
+	// WindowSeconds is the length of the window in which the load is averaged
+	// +optional
+	WindowSeconds int `json:"windowSeconds,omitempty"`
+	// UpdateTime is the time when the summary is updated
+	// +optional
+	UpdateTime metav1.Time `json:"updateTime,omitempty"`
 }
 
 // +genclient
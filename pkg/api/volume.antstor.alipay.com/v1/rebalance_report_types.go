@@ -0,0 +1,94 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RebalanceReportLabelKey is the name of RebalanceReport, whose run creates the VolumeMigration
+	RebalanceReportLabelKey = "obnvmf/rebalance-report"
+	// MigrationAnnoKeyDestNodeId is the node of the destination pool. If it is set, dest volume is scheduled to this pool.
+	MigrationAnnoKeyDestNodeId = "migrate.obnvmf/dest-node-id"
+)
+
+// RebalancePoolUsage is the usage of a pool when the rebalancer runs
+type RebalancePoolUsage struct {
+	NodeID string `json:"nodeId"`
+	// UsedPct is the percentage of allocated space, including volumes and reservations
+	UsedPct int `json:"usedPct"`
+	// IOUtilPct is the recent IO utilization. It is -1 if agent does not report IO load.
+	IOUtilPct int `json:"ioUtilPct"`
+}
+
+// RebalanceMove is a planned migration of a volume
+type RebalanceMove struct {
+	Volume       EntityIdentity `json:"volume"`
+	SizeByte     uint64         `json:"sizeByte"`
+	SourceNodeID string         `json:"sourceNodeId"`
+	DestNodeID   string         `json:"destNodeId"`
+	// Migration is the name of created VolumeMigration. It is empty in dry-run mode or if creating fails.
+	// +optional
+	Migration string `json:"migration,omitempty"`
+	// Message shows why creating VolumeMigration fails
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type RebalanceReportSpec struct {
+	// DryRun is true if the run only plans migrations, without creating VolumeMigrations
+	DryRun bool `json:"dryRun"`
+	// HighWatermarkPct and LowWatermarkPct are the watermarks of pool usage in the run
+	HighWatermarkPct int `json:"highWatermarkPct"`
+	LowWatermarkPct  int `json:"lowWatermarkPct"`
+	// MaxIOUtilPct is the max IO utilization of destination pools in the run
+	MaxIOUtilPct int `json:"maxIOUtilPct"`
+}
+
+type RebalanceReportStatus struct {
+	// OverfullPools are the pools whose usage exceed the high watermark
+	// +optional
+	OverfullPools []RebalancePoolUsage `json:"overfullPools,omitempty"`
+
+	// InflightMigrations is the number of unfinished VolumeMigrations before the run
+	// +optional
+	InflightMigrations int `json:"inflightMigrations,omitempty"`
+
+	// Moves are the migrations planned in the run
+	// +optional
+	Moves []RebalanceMove `json:"moves,omitempty"`
+
+	// Message shows why over-full pools are not fully rebalanced, e.g. migrations are throttled
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="dry_run",type=boolean,JSONPath=`.spec.dryRun`
+// +kubebuilder:printcolumn:name="inflight",type=integer,JSONPath=`.status.inflightMigrations`
+// +kubebuilder:printcolumn:name="message",type=string,JSONPath=`.status.message`
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// RebalanceReport is the result of a run of capacity rebalancer. It is created by controller, and old reports are pruned.
+type RebalanceReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RebalanceReportSpec `json:"spec,omitempty"`
+
+	// +optional
+	Status RebalanceReportStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// RebalanceReportList contains a list of RebalanceReport
+type RebalanceReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RebalanceReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RebalanceReport{}, &RebalanceReportList{})
+}
@@ -0,0 +1,120 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SnapshotPolicyNameLabelKey is the name of SnapshotPolicy which creates the AntstorSnapshot
+	SnapshotPolicyNameLabelKey = "obnvmf/snapshot-policy-name"
+	// SnapshotScheduleTimeAnnoKey is the scheduled time of the run which creates the AntstorSnapshot, in RFC3339
+	SnapshotScheduleTimeAnnoKey = "obnvmf/snapshot-schedule-time"
+	// SnapshotPolicyFailureRecordedAnnoKey is set to "true" once the failure of the AntstorSnapshot is counted by SnapshotPolicy
+	SnapshotPolicyFailureRecordedAnnoKey = "obnvmf/snapshot-policy-failure-recorded"
+)
+
+type SnapshotRetention struct {
+	// MaxCount is the max number of snapshots kept for each volume. The oldest snapshots are deleted first.
+	// Snapshots of a volume share its reserved snapshot space, so the default size of each snapshot is reserved space / MaxCount.
+	// Default is 1.
+	// +optional
+	MaxCount int `json:"maxCount,omitempty"`
+
+	// MaxAge is the max age of snapshots, e.g. "168h". Older snapshots are deleted. Default is no limit.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// GetMaxCount returns MaxCount, or 1 if it is not set
+func (r SnapshotRetention) GetMaxCount() int {
+	if r.MaxCount <= 0 {
+		return 1
+	}
+	return r.MaxCount
+}
+
+type SnapshotPolicySpec struct {
+	// Schedule is a cron expression of 5 fields, e.g. "0 */6 * * *", or a descriptor like "@daily"
+	Schedule string `json:"schedule"`
+
+	// VolumeSelector selects AntstorVolumes in the same namespace by labels
+	VolumeSelector *metav1.LabelSelector `json:"volumeSelector"`
+
+	// SnapshotSize is the size of each snapshot. Default is reserved snapshot space of the volume / Retention.MaxCount.
+	// +optional
+	SnapshotSize int64 `json:"snapshotSize,omitempty"`
+
+	// +optional
+	Retention SnapshotRetention `json:"retention,omitempty"`
+
+	// StartingDeadlineSeconds is the deadline of starting a scheduled run. A run which cannot start in time is missed.
+	// Default is no deadline.
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// Suspend stops subsequent runs. Retention is still enforced.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+type SnapshotPolicyStatus struct {
+	// LastScheduleTime is the scheduled time of the last run, whether it is missed or not
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is the scheduled time of the last run which creates snapshots for all selected volumes
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// NextScheduleTime is the time of the next run
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// SelectedVolumes is the number of volumes selected in the last run
+	// +optional
+	SelectedVolumes int `json:"selectedVolumes,omitempty"`
+
+	// SkippedVolumes are volumes which have no snapshot created in the last run, e.g. without reserved snapshot space
+	// +optional
+	SkippedVolumes []string `json:"skippedVolumes,omitempty"`
+
+	// MissedRuns is the number of runs which are missed since the policy is created
+	// +optional
+	MissedRuns int `json:"missedRuns,omitempty"`
+
+	// Message shows why the last run fails
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="suspend",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="last_schedule",type="date",JSONPath=".status.lastScheduleTime"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// SnapshotPolicy creates AntstorSnapshots of selected volumes on a cron schedule, and deletes expired snapshots
+type SnapshotPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SnapshotPolicySpec `json:"spec,omitempty"`
+
+	// +optional
+	Status SnapshotPolicyStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// SnapshotPolicyList contains a list of SnapshotPolicy
+type SnapshotPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SnapshotPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SnapshotPolicy{}, &SnapshotPolicyList{})
+}
@@ -26,6 +26,37 @@ const (
 // +kubebuilder:validation:Enum=creating;ready;merging;merged
 type SnapshotStatusName string
 
+// SnapshotRollbackPhase is the progress of rolling back the origin volume to the snapshot
+type SnapshotRollbackPhase string
+
+const (
+	// Pending: waiting for the origin volume to be unmounted
+	SnapshotRollbackPending SnapshotRollbackPhase = "Pending"
+	// Unstaging: removing the target of the origin volume, so that no host could access it
+	SnapshotRollbackUnstaging SnapshotRollbackPhase = "Unstaging"
+	// Restoring: merging LVM snapshot or reverting SPDK lvol
+	SnapshotRollbackRestoring SnapshotRollbackPhase = "Restoring"
+	// Activating: reactivating the LV, and requeuing the origin volume to expose it again
+	SnapshotRollbackActivating SnapshotRollbackPhase = "Activating"
+	SnapshotRollbackFinished   SnapshotRollbackPhase = "Finished"
+	SnapshotRollbackFailed     SnapshotRollbackPhase = "Failed"
+)
+
+// TransferPhase is the progress of exporting a snapshot to object storage, or importing it to a volume
+type TransferPhase string
+
+const (
+	TransferRunning  TransferPhase = "Running"
+	TransferFinished TransferPhase = "Finished"
+	TransferFailed   TransferPhase = "Failed"
+)
+
+const (
+	// keys in the data of object storage Secret
+	ObjectStoreAccessKeyIDSecretKey     = "accessKeyID"
+	ObjectStoreSecretAccessKeySecretKey = "secretAccessKey"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:object:root=true
@@ -72,11 +103,122 @@ type AntstorSnapshotSpec struct {
 
 	// +optional
 	OriginVolTargetNodeID string `json:"originVolTargetNodeId"`
+
+	// Rollback requests rolling back the origin volume to the snapshot in place.
+	// It is refused while the origin volume is still mounted.
+	// +optional
+	Rollback bool `json:"rollback,omitempty"`
+
+	// Sequence is the order of snapshots of the origin volume. It is set by agent before creating the snapshot lvol.
+	// SpdkLVol snapshots of a volume form a chain in this order.
+	// +optional
+	Sequence int64 `json:"sequence,omitempty"`
+
+	// Export requests exporting the snapshot to S3-compatible object storage after it is ready.
+	// The exported image could be imported to a new volume on any node.
+	// +optional
+	// +nullable
+	Export *SnapshotExportSpec `json:"export,omitempty"`
+
+	// Encryption is copied from the origin volume, so that volumes restored from the snapshot use the same key.
+	// +optional
+	// +nullable
+	Encryption *VolumeEncryption `json:"encryption,omitempty"`
+}
+
+// ObjectStore is a bucket of S3-compatible object storage
+type ObjectStore struct {
+	// Endpoint of the service, e.g. http://minio.example.com:9000
+	Endpoint string `json:"endpoint"`
+	// +optional
+	Region string `json:"region,omitempty"`
+	Bucket string `json:"bucket"`
+	// Secret of credentials, whose keys are accessKeyID and secretAccessKey
+	SecretName      string `json:"secretName"`
+	SecretNamespace string `json:"secretNamespace"`
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+type SnapshotExportSpec struct {
+	ObjectStore `json:",inline"`
+	// Prefix of object keys. Default is <namespace>/<name>/<uuid> of the snapshot
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// size of chunk before compression, default is 4
+	// +optional
+	ChunkSizeMiB int `json:"chunkSizeMiB,omitempty"`
+	// BaseSnapshot is name of an earlier snapshot of the same volume in the same namespace, which is exported to the same ObjectStore.
+	// If it is set, only blocks changed since BaseSnapshot are exported, and the manifest refers to the manifest of BaseSnapshot.
+	// BaseSnapshot must not be deleted until the export is finished.
+	// +optional
+	BaseSnapshot string `json:"baseSnapshot,omitempty"`
 }
 
 type AntstorSnapshotStatus struct {
 	// +optional
 	Status SnapshotStatusName `json:"status"`
+
+	// ConsumedClusters is the number of clusters allocated by the snapshot. Only for SpdkLVol
+	// +optional
+	ConsumedClusters int `json:"consumedClusters,omitempty"`
+
+	// ConsumedBytes is the space consumed by the snapshot. Only for SpdkLVol
+	// +optional
+	ConsumedBytes int64 `json:"consumedBytes,omitempty"`
+
+	// Message shows the reason why the snapshot cannot be restored or deleted
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Rollback is the progress of Spec.Rollback
+	// +optional
+	Rollback *SnapshotRollbackStatus `json:"rollback,omitempty"`
+
+	// Export is the progress of Spec.Export
+	// +optional
+	Export *SnapshotExportStatus `json:"export,omitempty"`
+}
+
+type SnapshotRollbackStatus struct {
+	// +kubebuilder:validation:Enum=Pending;Unstaging;Restoring;Activating;Finished;Failed
+	Phase SnapshotRollbackPhase `json:"phase"`
+	// Message shows the reason of refusal or failure of current phase
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// +optional
+	FinishTime *metav1.Time `json:"finishTime,omitempty"`
+}
+
+type SnapshotExportStatus struct {
+	// +kubebuilder:validation:Enum=Running;Finished;Failed
+	Phase TransferPhase `json:"phase"`
+	// +optional
+	Message string `json:"message,omitempty"`
+	// ManifestKey is the object key of manifest, which lists all chunks of the image
+	// +optional
+	ManifestKey string `json:"manifestKey,omitempty"`
+	// BaseManifestKey is the manifest of BaseSnapshot, if the export is incremental
+	// +optional
+	BaseManifestKey string `json:"baseManifestKey,omitempty"`
+	// SizeBytes is the size of exported image
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// +optional
+	ChunkSize int64 `json:"chunkSize,omitempty"`
+	// +optional
+	TotalChunks int64 `json:"totalChunks,omitempty"`
+	// +optional
+	ExportedChunks int64 `json:"exportedChunks,omitempty"`
+	// StoredBytes is the size of compressed chunks in object storage. Zero chunks are not stored.
+	// +optional
+	StoredBytes int64 `json:"storedBytes,omitempty"`
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// +optional
+	FinishTime *metav1.Time `json:"finishTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
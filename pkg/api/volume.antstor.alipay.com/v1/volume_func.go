@@ -1,8 +1,10 @@
 package v1
 
 import (
+	"encoding/hex"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"k8s.io/klog/v2"
 )
@@ -36,10 +38,28 @@ func (vol *AntstorVolume) GetTotalSize() (size uint64) {
 	return
 }
 
+// GetTransportType returns the NVMe-oF transport type of the volume. Default is TCP.
+func (vol *AntstorVolume) GetTransportType() string {
+	if val := vol.Annotations[TransportTypeAnnoKey]; val != "" {
+		return strings.ToUpper(val)
+	}
+	return TransportTypeTCP
+}
+
 func (vol *AntstorVolume) IsLocal() bool {
 	return vol.Spec.HostNode.ID == vol.Spec.TargetNodeId
 }
 
+// IsMultiWriter returns true if the volume could be attached by multiple nodes
+func (vol *AntstorVolume) IsMultiWriter() bool {
+	return vol.Spec.AccessMode == VolumeAccessModeMultiNodeMultiWriter
+}
+
+// IsReplicated returns true if data of the volume is mirrored to replica legs, or the volume is a replica leg
+func (vol *AntstorVolume) IsReplicated() bool {
+	return vol.Spec.Replicas > 1 || len(vol.Spec.ReplicaLegs) > 0 || vol.Labels[ReplicaOfLabelKey] != ""
+}
+
 func (vol *AntstorVolume) ReservationID() string {
 	if vol.Annotations != nil {
 		return vol.Annotations[ReservationIDKey]
@@ -51,3 +71,17 @@ func (vol *AntstorVolume) ReservationID() string {
 func (vol *SpdkLvol) FullName() string {
 	return fmt.Sprintf("%s/%s", vol.LvsName, vol.Name)
 }
+
+// ParseEncryptionKey validates the value of EncryptionKeySecretKey and splits it to the two keys of AES-XTS
+func ParseEncryptionKey(val []byte) (key, key2 string, err error) {
+	var str = strings.TrimSpace(string(val))
+	if len(str) != 128 {
+		err = fmt.Errorf("length of %s should be 128, but is %d", EncryptionKeySecretKey, len(str))
+		return
+	}
+	if _, err = hex.DecodeString(str); err != nil {
+		err = fmt.Errorf("%s is not in hex, %w", EncryptionKeySecretKey, err)
+		return
+	}
+	return str[:64], str[64:], nil
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// GroupSnapshotNameLabelKey is the name of AntstorVolumeGroupSnapshot which the member AntstorSnapshot belongs to
+	GroupSnapshotNameLabelKey = "obnvmf/group-snapshot-name"
+	// GroupSnapshotUuidLabelKey is the uuid of AntstorVolumeGroupSnapshot, which is the group_snapshot_id of CSI
+	GroupSnapshotUuidLabelKey = "obnvmf/group-snapshot-uuid"
+	// GroupSnapshotSourceVolumeIdAnnoKey is the CSI volume id of the DataControl
+	GroupSnapshotSourceVolumeIdAnnoKey = "obnvmf/group-snapshot-source-volume-id"
+
+	GroupSnapshotFinalizer = "antstor.alipay.com/group-snapshot"
+
+	// DefaultFreezeTimeoutSeconds is the max duration of blocking IO of the host LV
+	DefaultFreezeTimeoutSeconds = 60
+)
+
+// GroupSnapshotPhase is the progress of group snapshot.
+// Controller and the agent on host node of DataControl drive the phase in turn:
+// controller sets Freezing -> agent suspends host LV and sets Snapshotting -> controller creates member snapshots
+// and sets Thawing when all of them are ready -> agent resumes host LV and sets Ready.
+// Any failure sets Thawing with Message, and the agent resumes host LV and sets Failed.
+type GroupSnapshotPhase string
+
+const (
+	GroupSnapshotPending      GroupSnapshotPhase = ""
+	GroupSnapshotFreezing     GroupSnapshotPhase = "Freezing"
+	GroupSnapshotSnapshotting GroupSnapshotPhase = "Snapshotting"
+	GroupSnapshotThawing      GroupSnapshotPhase = "Thawing"
+	GroupSnapshotReady        GroupSnapshotPhase = "Ready"
+	GroupSnapshotFailed       GroupSnapshotPhase = "Failed"
+)
+
+type AntstorVolumeGroupSnapshotSpec struct {
+	// Uuid is generated by CSI controller, and is used as group_snapshot_id
+	// +optional
+	Uuid string `json:"uuid,omitempty"`
+
+	// DataControlName is the name of AntstorDataControl in the same namespace.
+	// All volumes in its VolumeGroups are snapshotted while IO of the host LV is frozen.
+	DataControlName string `json:"dataControlName"`
+
+	// SnapshotSize is the size of each member snapshot.
+	// Default is the value of obnvmf/snapshot-reserved-bytes annotation of the member volume.
+	// +optional
+	SnapshotSize int64 `json:"snapshotSize,omitempty"`
+
+	// FreezeTimeoutSeconds is the max duration of freezing IO. Group snapshot fails if member snapshots are not ready in time.
+	// Default is 60.
+	// +optional
+	FreezeTimeoutSeconds int `json:"freezeTimeoutSeconds,omitempty"`
+}
+
+type AntstorVolumeGroupSnapshotStatus struct {
+	// +optional
+	// +kubebuilder:validation:Enum="";Freezing;Snapshotting;Thawing;Ready;Failed
+	Phase GroupSnapshotPhase `json:"phase,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// HostNodeId is the node where the host LV of DataControl is frozen
+	// +optional
+	HostNodeId string `json:"hostNodeId,omitempty"`
+
+	// FreezeTime is the time when IO of the host LV is suspended
+	// +optional
+	FreezeTime *metav1.Time `json:"freezeTime,omitempty"`
+
+	// ReadyTime is the time when all member snapshots are ready
+	// +optional
+	ReadyTime *metav1.Time `json:"readyTime,omitempty"`
+
+	// Snapshots are member AntstorSnapshots, one for each volume of the DataControl
+	// +optional
+	Snapshots []EntityIdentity `json:"snapshots,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="dataControl",type=string,JSONPath=`.spec.dataControlName`
+// +kubebuilder:printcolumn:name="phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// AntstorVolumeGroupSnapshot is a crash-consistent snapshot of all volumes of an AntstorDataControl
+type AntstorVolumeGroupSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AntstorVolumeGroupSnapshotSpec `json:"spec,omitempty"`
+
+	// +optional
+	Status AntstorVolumeGroupSnapshotStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// AntstorVolumeGroupSnapshotList contains a list of AntstorVolumeGroupSnapshot
+type AntstorVolumeGroupSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AntstorVolumeGroupSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AntstorVolumeGroupSnapshot{}, &AntstorVolumeGroupSnapshotList{})
+}
@@ -35,6 +35,8 @@ const (
 	ExpansionOriginalSize = "obnvmf/expansion-original-size"
 	// VolumeGroupNameLabelKey is a label key of volumegroup name
 	VolumeGroupNameLabelKey = "obnvmf/vol-group-name"
+	// ReplicaOfLabelKey is a label key of replica leg volume, value is the name of the replicated volume
+	ReplicaOfLabelKey = "obnvmf/replica-of"
 )
 
 const (
@@ -55,12 +57,23 @@ const (
 	// volume label for scheduling
 	NodeLabelSelectorKey = "obnvmf/node-label-selector"
 	PoolLabelSelectorKey = "obnvmf/pool-label-selector"
+	// spread volumes of the same data-holder across topology domains. value is "rack", "room" or a node label key
+	TopologySpreadKeyAnnoKey = "obnvmf/topology-spread-key"
+	// max difference of volume count between topology domains, default is 1
+	TopologySpreadMaxSkewAnnoKey = "obnvmf/topology-spread-max-skew"
 
 	// snapshot reserved space key
 	SnapshotReservedSpaceAnnotationKey = "obnvmf/snapshot-reserved-bytes"
 	// content source info
 	VolumeSourceSnapNameLabelKey      = "obnvmf/volume-source-snap-name"
 	VolumeSourceSnapNamespaceLabelKey = "obnvmf/volume-source-snap-ns"
+	VolumeSourceVolNameLabelKey       = "obnvmf/volume-source-vol-name"
+	VolumeSourceVolNamespaceLabelKey  = "obnvmf/volume-source-vol-ns"
+	// value is "true" or "false". If true, the cloned volume is inflated and no longer depends on the source volume.
+	CloneInflateAnnoKey = "obnvmf/clone-inflate"
+	// value is "true" or "false". If true, volume restored from snapshot is imported from the image exported to object storage,
+	// so it could be created on any node.
+	ImportFromExportKey = "obnvmf/import-from-export"
 
 	// key of reservation id
 	ReservationIDKey = "obnvmf/reservation-id"
@@ -71,6 +84,62 @@ const (
 
 	// key of VFIOUSER mode(INTRA_HOST or LOCAL_COPY)
 	VfiouserModeKey = "obnvmf/volume-vfiouser-mode"
+
+	// key of NVMe-oF transport type of remote volume, value is TCP or RDMA. Default is TCP.
+	TransportTypeAnnoKey = "obnvmf/transport-type"
+	// key of pod uid in CSINodePubParams.CSIVolumeContext
+	CSIVolumeContextPodUIDKey = "csi.storage.k8s.io/pod.uid"
+	// IDs of host nodes which the subsystem of volume is last exposed to, separated by comma. It is set by agent.
+	ExposedHostNodeAnnoKey = "obnvmf/exposed-host-node"
+)
+
+const (
+	// ANA states of listeners
+	ANAStateOptimized    = "optimized"
+	ANAStateNonOptimized = "non_optimized"
+
+	// state of path which is not connected by host
+	PathStateDisconnected = "disconnected"
+	PathStateLive         = "live"
+)
+
+const (
+	// NVMe-oF transport types, same as trtype of SPDK
+	TransportTypeTCP  = "TCP"
+	TransportTypeRDMA = "RDMA"
+)
+
+const (
+	// volume is attached by only one node
+	VolumeAccessModeSingleNodeWriter VolumeAccessMode = "SingleNodeWriter"
+	// volume is attached by multiple nodes. Only block volume supports this mode
+	VolumeAccessModeMultiNodeMultiWriter VolumeAccessMode = "MultiNodeMultiWriter"
+)
+
+const (
+	// keys in the data of DH-HMAC-CHAP Secret. Values are in the format of "DHHC-1:xx:<base64>:"
+	// key of host, required
+	DHChapKeySecretKey = "dhchap-key"
+	// key of controller, optional. If it is set, bidirectional authentication is enabled.
+	DHChapCtrlrKeySecretKey = "dhchap-ctrlr-key"
+
+	// key in the data of encryption Secret. Value is 128 hex characters, which is split to the two 256-bit keys of AES-XTS.
+	// For LVM volume, the whole value is used as the LUKS passphrase.
+	EncryptionKeySecretKey = "encryption-key"
+	// annotation of encryption Secret created by CSI driver, value is the name of PV. Secrets without it are never deleted by the driver.
+	EncryptionSecretOwnerAnnoKey = "obnvmf/encryption-secret-owner"
+)
+
+const (
+	// keys of QoS limits in StorageClass parameters or PVC annotations. Names are the same as bdev_set_qos_limit of SPDK.
+	QoSRWIOPSKey   = "obnvmf/rw_ios_per_sec"
+	QoSRWMBytesKey = "obnvmf/rw_mbytes_per_sec"
+	QoSRMBytesKey  = "obnvmf/r_mbytes_per_sec"
+	QoSWMBytesKey  = "obnvmf/w_mbytes_per_sec"
+
+	// results of applying QoS
+	QoSResultSuccess = "Success"
+	QoSResultFailed  = "Failed"
 )
 
 const (
@@ -110,6 +179,12 @@ const (
 	VolumeStatusReady    VolumeStatus = "ready"
 	VolumeStatusDeleted  VolumeStatus = "deleted"
 
+	// states of replica legs
+	ReplicaStatePending    ReplicaState = "Pending"
+	ReplicaStateOnline     ReplicaState = "Online"
+	ReplicaStateRebuilding ReplicaState = "Rebuilding"
+	ReplicaStateFailed     ReplicaState = "Failed"
+
 	PendingPhase PhaseType = "Pending"
 	ReadyPhase   PhaseType = "Ready"
 
@@ -132,6 +207,9 @@ type VolumeType string
 // +kubebuilder:validation:Enum=creating;ready;deleted
 type VolumeStatus string
 
+// +kubebuilder:validation:Enum=SingleNodeWriter;MultiNodeMultiWriter
+type VolumeAccessMode string
+
 // +kubebuilder:validation:Enum=MustLocal;PreferLocal;PreferRemote;MustRemote;""
 type VolumePosition string
 
@@ -141,6 +219,8 @@ type PoolStatus string
 // +kubebuilder:validation:Enum=Pending;Ready
 type PhaseType string
 
+type ReplicaState string
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -164,6 +244,50 @@ type SpdkTarget struct {
 	NSUUID    string `json:"nsUuid"`
 	Address   string `json:"address"`
 	AddrFam   string `json:"addrFam"`
+	// Paths are all listeners of the subsystem for NVMe multipath, including the one of Address and SvcID.
+	// If it is empty, the target only has one path.
+	// +optional
+	Paths []TargetPath `json:"paths,omitempty"`
+}
+
+type TargetPath struct {
+	Address   string `json:"address"`
+	SvcID     string `json:"svcID"`
+	TransType string `json:"transType"`
+	AddrFam   string `json:"addrFam"`
+	// ANA state of the listener, optimized or non_optimized
+	// +optional
+	ANAState string `json:"anaState,omitempty"`
+}
+
+// HostAuth refers to the Secret of DH-HMAC-CHAP keys, which are used by hosts to connect the SpdkTarget
+type HostAuth struct {
+	SecretName      string `json:"secretName"`
+	SecretNamespace string `json:"secretNamespace"`
+}
+
+// VolumeEncryption refers to the Secret of the encryption key. Volumes cloned or restored from the volume share the Secret.
+// The Secret created by CSI driver is deleted with the last volume using it.
+type VolumeEncryption struct {
+	SecretName      string `json:"secretName"`
+	SecretNamespace string `json:"secretNamespace"`
+}
+
+// VolumeQoS is rate limits of the volume. 0 means unlimited.
+type VolumeQoS struct {
+	// read/write IOPS. It must be multiple of 1000 for SpdkLVol volume.
+	// For KernelLVol volume, it limits read and write IOPS separately.
+	// +optional
+	RWIOPS uint64 `json:"rwIOPS,omitempty"`
+	// read/write bandwidth in MiB/s. For KernelLVol volume, it limits read and write bandwidth separately.
+	// +optional
+	RWMBytes uint64 `json:"rwMBytes,omitempty"`
+	// read bandwidth in MiB/s
+	// +optional
+	RMBytes uint64 `json:"rMBytes,omitempty"`
+	// write bandwidth in MiB/s
+	// +optional
+	WMBytes uint64 `json:"wMBytes,omitempty"`
 }
 
 type KernelLvol struct {
@@ -230,6 +354,15 @@ type AntstorVolumeSpec struct {
 	// +nullable
 	HostNode *NodeInfo `json:"hostNode,omitempty"`
 
+	// AccessMode of volume. Default is SingleNodeWriter
+	// +optional
+	AccessMode VolumeAccessMode `json:"accessMode,omitempty"`
+
+	// AttachedHosts are IDs of nodes which have staged the volume, except HostNode.
+	// It is only used by MultiNodeMultiWriter volume.
+	// +optional
+	AttachedHosts []string `json:"attachedHosts,omitempty"`
+
 	// +optional
 	// +nullable
 	KernelLvol *KernelLvol `json:"kernelLvol,omitempty"`
@@ -241,6 +374,36 @@ type AntstorVolumeSpec struct {
 	// +optional
 	// +nullable
 	SpdkTarget *SpdkTarget `json:"spdkTarget,omitempty"`
+
+	// HostAuth enables DH-HMAC-CHAP authentication of SpdkTarget
+	// +optional
+	// +nullable
+	HostAuth *HostAuth `json:"hostAuth,omitempty"`
+
+	// QoS limits IOPS and bandwidth of the volume
+	// +optional
+	// +nullable
+	QoS *VolumeQoS `json:"qos,omitempty"`
+
+	// Encryption enables encryption at rest of the volume.
+	// SpdkLVol is encrypted by SPDK crypto bdev, KernelLVol is encrypted by LUKS on the host.
+	// +optional
+	// +nullable
+	Encryption *VolumeEncryption `json:"encryption,omitempty"`
+
+	// ImportSource is the image exported from a snapshot. Data of the image is written to the new volume before it is ready.
+	// +optional
+	// +nullable
+	ImportSource *VolumeImportSource `json:"importSource,omitempty"`
+
+	// Replicas is the number of data copies of SpdkLVol volume. Default is 1.
+	// If it is larger than 1, replica legs are created on other pools, and mirrored with the volume by a RAID1 bdev on TargetNodeId.
+	// +optional
+	Replicas int `json:"replicas,omitempty"`
+
+	// ReplicaLegs are volumes holding the other copies of data. They are set by controller.
+	// +optional
+	ReplicaLegs []EntityIdentity `json:"replicaLegs,omitempty"`
 }
 
 // AntstorVolumeStatus defines the observed state of AntstorVolume
@@ -260,6 +423,84 @@ type AntstorVolumeStatus struct {
 
 	// +optional
 	Message string `json:"msg,omitempty"`
+
+	// HostPaths are states of NVMe-oF paths on each host, reported by NodeStageVolume
+	// +patchStrategy=merge
+	// +optional
+	HostPaths []HostPathStatus `json:"hostPaths,omitempty" patchStrategy:"merge" patchMergeKey:"nodeId"`
+
+	// QoS is the result of applying Spec.QoS to the volume
+	// +optional
+	QoS *QoSStatus `json:"qos,omitempty"`
+
+	// Import is the progress of Spec.ImportSource
+	// +optional
+	Import *VolumeImportStatus `json:"import,omitempty"`
+
+	// Replicas are states of the volume and its replica legs in the RAID1 bdev
+	// +optional
+	Replicas []ReplicaStatus `json:"replicas,omitempty"`
+}
+
+type ReplicaStatus struct {
+	// Name of the volume
+	Name         string `json:"name"`
+	TargetNodeId string `json:"targetNodeId"`
+	// +kubebuilder:validation:Enum=Pending;Online;Rebuilding;Failed
+	State ReplicaState `json:"state"`
+	// RebuildPercent is the progress of rebuilding data to the leg
+	// +optional
+	RebuildPercent int `json:"rebuildPercent,omitempty"`
+	// +optional
+	Message string `json:"msg,omitempty"`
+}
+
+// VolumeImportSource refers to the manifest of the image in object storage
+type VolumeImportSource struct {
+	ObjectStore `json:",inline"`
+	ManifestKey string `json:"manifestKey"`
+}
+
+type VolumeImportStatus struct {
+	// +kubebuilder:validation:Enum=Running;Finished;Failed
+	Phase TransferPhase `json:"phase"`
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	TotalChunks int64 `json:"totalChunks,omitempty"`
+	// +optional
+	ImportedChunks int64 `json:"importedChunks,omitempty"`
+	// +optional
+	FinishTime *metav1.Time `json:"finishTime,omitempty"`
+}
+
+type QoSStatus struct {
+	// Applied is the limits which are applied to the volume successfully
+	// +optional
+	Applied *VolumeQoS `json:"applied,omitempty"`
+	// LastResult is the result of the last modification, Success or Failed
+	LastResult string `json:"lastResult"`
+	// +optional
+	Message string `json:"msg,omitempty"`
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+type HostPathStatus struct {
+	NodeID string `json:"nodeId"`
+	// Degraded is true if some paths of SpdkTarget are not live on the host
+	Degraded bool `json:"degraded"`
+	// +optional
+	Paths []PathState `json:"paths,omitempty"`
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+type PathState struct {
+	Address string `json:"address"`
+	SvcID   string `json:"svcID"`
+	// state of the path on the host, e.g. live, connecting. If the path is not connected, state is disconnected.
+	State string `json:"state"`
 }
 
 /*
@@ -23,6 +23,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -120,6 +121,16 @@ func (in *AntstorDataControlStatus) DeepCopyInto(out *AntstorDataControlStatus)
 		*out = new(CSINodePubParams)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MissingPVs != nil {
+		in, out := &in.MissingPVs, &out.MissingPVs
+		*out = make([]EntityIdentity, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rebuild != nil {
+		in, out := &in.Rebuild, &out.Rebuild
+		*out = new(DataControlRebuildStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorDataControlStatus.
@@ -137,8 +148,8 @@ func (in *AntstorSnapshot) DeepCopyInto(out *AntstorSnapshot) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorSnapshot.
@@ -196,6 +207,16 @@ func (in *AntstorSnapshotSpec) DeepCopyInto(out *AntstorSnapshotSpec) {
 	*out = *in
 	out.KernelLvol = in.KernelLvol
 	out.SpdkLvol = in.SpdkLvol
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = new(SnapshotExportSpec)
+		**out = **in
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(VolumeEncryption)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorSnapshotSpec.
@@ -211,6 +232,16 @@ func (in *AntstorSnapshotSpec) DeepCopy() *AntstorSnapshotSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AntstorSnapshotStatus) DeepCopyInto(out *AntstorSnapshotStatus) {
 	*out = *in
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(SnapshotRollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = new(SnapshotExportStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorSnapshotStatus.
@@ -309,6 +340,108 @@ func (in *AntstorVolumeGroupList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AntstorVolumeGroupSnapshot) DeepCopyInto(out *AntstorVolumeGroupSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorVolumeGroupSnapshot.
+func (in *AntstorVolumeGroupSnapshot) DeepCopy() *AntstorVolumeGroupSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(AntstorVolumeGroupSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AntstorVolumeGroupSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AntstorVolumeGroupSnapshotList) DeepCopyInto(out *AntstorVolumeGroupSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AntstorVolumeGroupSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorVolumeGroupSnapshotList.
+func (in *AntstorVolumeGroupSnapshotList) DeepCopy() *AntstorVolumeGroupSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(AntstorVolumeGroupSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AntstorVolumeGroupSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AntstorVolumeGroupSnapshotSpec) DeepCopyInto(out *AntstorVolumeGroupSnapshotSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorVolumeGroupSnapshotSpec.
+func (in *AntstorVolumeGroupSnapshotSpec) DeepCopy() *AntstorVolumeGroupSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AntstorVolumeGroupSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AntstorVolumeGroupSnapshotStatus) DeepCopyInto(out *AntstorVolumeGroupSnapshotStatus) {
+	*out = *in
+	if in.FreezeTime != nil {
+		in, out := &in.FreezeTime, &out.FreezeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ReadyTime != nil {
+		in, out := &in.ReadyTime, &out.ReadyTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]EntityIdentity, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorVolumeGroupSnapshotStatus.
+func (in *AntstorVolumeGroupSnapshotStatus) DeepCopy() *AntstorVolumeGroupSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AntstorVolumeGroupSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AntstorVolumeGroupSpec) DeepCopyInto(out *AntstorVolumeGroupSpec) {
 	*out = *in
@@ -403,6 +536,11 @@ func (in *AntstorVolumeSpec) DeepCopyInto(out *AntstorVolumeSpec) {
 		*out = new(NodeInfo)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AttachedHosts != nil {
+		in, out := &in.AttachedHosts, &out.AttachedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.KernelLvol != nil {
 		in, out := &in.KernelLvol, &out.KernelLvol
 		*out = new(KernelLvol)
@@ -416,8 +554,33 @@ func (in *AntstorVolumeSpec) DeepCopyInto(out *AntstorVolumeSpec) {
 	if in.SpdkTarget != nil {
 		in, out := &in.SpdkTarget, &out.SpdkTarget
 		*out = new(SpdkTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostAuth != nil {
+		in, out := &in.HostAuth, &out.HostAuth
+		*out = new(HostAuth)
 		**out = **in
 	}
+	if in.QoS != nil {
+		in, out := &in.QoS, &out.QoS
+		*out = new(VolumeQoS)
+		**out = **in
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(VolumeEncryption)
+		**out = **in
+	}
+	if in.ImportSource != nil {
+		in, out := &in.ImportSource, &out.ImportSource
+		*out = new(VolumeImportSource)
+		**out = **in
+	}
+	if in.ReplicaLegs != nil {
+		in, out := &in.ReplicaLegs, &out.ReplicaLegs
+		*out = make([]EntityIdentity, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorVolumeSpec.
@@ -443,6 +606,28 @@ func (in *AntstorVolumeStatus) DeepCopyInto(out *AntstorVolumeStatus) {
 		*out = new(HostAttachment)
 		**out = **in
 	}
+	if in.HostPaths != nil {
+		in, out := &in.HostPaths, &out.HostPaths
+		*out = make([]HostPathStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QoS != nil {
+		in, out := &in.QoS, &out.QoS
+		*out = new(QoSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(VolumeImportStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]ReplicaStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AntstorVolumeStatus.
@@ -492,6 +677,23 @@ func (in *CSINodePubParams) DeepCopy() *CSINodePubParams {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataControlRebuildStatus) DeepCopyInto(out *DataControlRebuildStatus) {
+	*out = *in
+	in.OldPV.DeepCopyInto(&out.OldPV)
+	out.NewVolId = in.NewVolId
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataControlRebuildStatus.
+func (in *DataControlRebuildStatus) DeepCopy() *DataControlRebuildStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataControlRebuildStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DesiredVolumeSpec) DeepCopyInto(out *DesiredVolumeSpec) {
 	*out = *in
@@ -553,6 +755,21 @@ func (in *HostAttachment) DeepCopy() *HostAttachment {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostAuth) DeepCopyInto(out *HostAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostAuth.
+func (in *HostAuth) DeepCopy() *HostAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(HostAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostConnectDestVolume) DeepCopyInto(out *HostConnectDestVolume) {
 	*out = *in
@@ -568,6 +785,27 @@ func (in *HostConnectDestVolume) DeepCopy() *HostConnectDestVolume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostPathStatus) DeepCopyInto(out *HostPathStatus) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]PathState, len(*in))
+		copy(*out, *in)
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostPathStatus.
+func (in *HostPathStatus) DeepCopy() *HostPathStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HostPathStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntRange) DeepCopyInto(out *IntRange) {
 	*out = *in
@@ -606,6 +844,11 @@ func (in *KernelLVM) DeepCopyInto(out *KernelLVM) {
 		*out = make([]KernelLVol, len(*in))
 		copy(*out, *in)
 	}
+	if in.ThinPool != nil {
+		in, out := &in.ThinPool, &out.ThinPool
+		*out = new(KernelThinPool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelLVM.
@@ -648,13 +891,30 @@ func (in *KernelLvol) DeepCopy() *KernelLvol {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelThinPool) DeepCopyInto(out *KernelThinPool) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelThinPool.
+func (in *KernelThinPool) DeepCopy() *KernelThinPool {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelThinPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LVMControl) DeepCopyInto(out *LVMControl) {
 	*out = *in
 	if in.PVs != nil {
 		in, out := &in.PVs, &out.PVs
 		*out = make([]LVMControlPV, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -672,7 +932,7 @@ func (in *LVMControl) DeepCopy() *LVMControl {
 func (in *LVMControlPV) DeepCopyInto(out *LVMControlPV) {
 	*out = *in
 	out.VolId = in.VolId
-	out.TargetInfo = in.TargetInfo
+	in.TargetInfo.DeepCopyInto(&out.TargetInfo)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LVMControlPV.
@@ -740,6 +1000,36 @@ func (in *NodeInfo) DeepCopy() *NodeInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStore) DeepCopyInto(out *ObjectStore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStore.
+func (in *ObjectStore) DeepCopy() *ObjectStore {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PathState) DeepCopyInto(out *PathState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PathState.
+func (in *PathState) DeepCopy() *PathState {
+	if in == nil {
+		return nil
+	}
+	out := new(PathState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PoolCondition) DeepCopyInto(out *PoolCondition) {
 	*out = *in
@@ -756,103 +1046,126 @@ func (in *PoolCondition) DeepCopy() *PoolCondition {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantityRange) DeepCopyInto(out *QuantityRange) {
+func (in *PoolIOLoad) DeepCopyInto(out *PoolIOLoad) {
 	*out = *in
-	out.Min = in.Min.DeepCopy()
-	out.Max = in.Max.DeepCopy()
+	in.UpdateTime.DeepCopyInto(&out.UpdateTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantityRange.
-func (in *QuantityRange) DeepCopy() *QuantityRange {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolIOLoad.
+func (in *PoolIOLoad) DeepCopy() *PoolIOLoad {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantityRange)
+	out := new(PoolIOLoad)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Raid) DeepCopyInto(out *Raid) {
+func (in *QoSStatus) DeepCopyInto(out *QoSStatus) {
 	*out = *in
+	if in.Applied != nil {
+		in, out := &in.Applied, &out.Applied
+		*out = new(VolumeQoS)
+		**out = **in
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Raid.
-func (in *Raid) DeepCopy() *Raid {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QoSStatus.
+func (in *QoSStatus) DeepCopy() *QoSStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Raid)
+	out := new(QoSStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SpdkLVStore) DeepCopyInto(out *SpdkLVStore) {
+func (in *QuantityRange) DeepCopyInto(out *QuantityRange) {
 	*out = *in
+	out.Min = in.Min.DeepCopy()
+	out.Max = in.Max.DeepCopy()
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpdkLVStore.
-func (in *SpdkLVStore) DeepCopy() *SpdkLVStore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantityRange.
+func (in *QuantityRange) DeepCopy() *QuantityRange {
 	if in == nil {
 		return nil
 	}
-	out := new(SpdkLVStore)
+	out := new(QuantityRange)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SpdkLvol) DeepCopyInto(out *SpdkLvol) {
+func (in *Raid) DeepCopyInto(out *Raid) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpdkLvol.
-func (in *SpdkLvol) DeepCopy() *SpdkLvol {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Raid.
+func (in *Raid) DeepCopy() *Raid {
 	if in == nil {
 		return nil
 	}
-	out := new(SpdkLvol)
+	out := new(Raid)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SpdkTarget) DeepCopyInto(out *SpdkTarget) {
+func (in *RebalanceMove) DeepCopyInto(out *RebalanceMove) {
 	*out = *in
+	out.Volume = in.Volume
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpdkTarget.
-func (in *SpdkTarget) DeepCopy() *SpdkTarget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceMove.
+func (in *RebalanceMove) DeepCopy() *RebalanceMove {
 	if in == nil {
 		return nil
 	}
-	out := new(SpdkTarget)
+	out := new(RebalanceMove)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoragePool) DeepCopyInto(out *StoragePool) {
+func (in *RebalancePoolUsage) DeepCopyInto(out *RebalancePoolUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePoolUsage.
+func (in *RebalancePoolUsage) DeepCopy() *RebalancePoolUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalancePoolUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalanceReport) DeepCopyInto(out *RebalanceReport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePool.
-func (in *StoragePool) DeepCopy() *StoragePool {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceReport.
+func (in *RebalanceReport) DeepCopy() *RebalanceReport {
 	if in == nil {
 		return nil
 	}
-	out := new(StoragePool)
+	out := new(RebalanceReport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *StoragePool) DeepCopyObject() runtime.Object {
+func (in *RebalanceReport) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -860,31 +1173,31 @@ func (in *StoragePool) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoragePoolList) DeepCopyInto(out *StoragePoolList) {
+func (in *RebalanceReportList) DeepCopyInto(out *RebalanceReportList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]StoragePool, len(*in))
+		*out = make([]RebalanceReport, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePoolList.
-func (in *StoragePoolList) DeepCopy() *StoragePoolList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceReportList.
+func (in *RebalanceReportList) DeepCopy() *RebalanceReportList {
 	if in == nil {
 		return nil
 	}
-	out := new(StoragePoolList)
+	out := new(RebalanceReportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *StoragePoolList) DeepCopyObject() runtime.Object {
+func (in *RebalanceReportList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -892,16 +1205,383 @@ func (in *StoragePoolList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoragePoolSpec) DeepCopyInto(out *StoragePoolSpec) {
+func (in *RebalanceReportSpec) DeepCopyInto(out *RebalanceReportSpec) {
 	*out = *in
-	in.KernelLVM.DeepCopyInto(&out.KernelLVM)
-	out.SpdkLVStore = in.SpdkLVStore
-	if in.Addresses != nil {
-		in, out := &in.Addresses, &out.Addresses
-		*out = make([]corev1.NodeAddress, len(*in))
-		copy(*out, *in)
-	}
-	in.NodeInfo.DeepCopyInto(&out.NodeInfo)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceReportSpec.
+func (in *RebalanceReportSpec) DeepCopy() *RebalanceReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalanceReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalanceReportStatus) DeepCopyInto(out *RebalanceReportStatus) {
+	*out = *in
+	if in.OverfullPools != nil {
+		in, out := &in.OverfullPools, &out.OverfullPools
+		*out = make([]RebalancePoolUsage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Moves != nil {
+		in, out := &in.Moves, &out.Moves
+		*out = make([]RebalanceMove, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceReportStatus.
+func (in *RebalanceReportStatus) DeepCopy() *RebalanceReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalanceReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaStatus) DeepCopyInto(out *ReplicaStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaStatus.
+func (in *ReplicaStatus) DeepCopy() *ReplicaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotExportSpec) DeepCopyInto(out *SnapshotExportSpec) {
+	*out = *in
+	out.ObjectStore = in.ObjectStore
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotExportSpec.
+func (in *SnapshotExportSpec) DeepCopy() *SnapshotExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotExportStatus) DeepCopyInto(out *SnapshotExportStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishTime != nil {
+		in, out := &in.FinishTime, &out.FinishTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotExportStatus.
+func (in *SnapshotExportStatus) DeepCopy() *SnapshotExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotPolicy) DeepCopyInto(out *SnapshotPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotPolicy.
+func (in *SnapshotPolicy) DeepCopy() *SnapshotPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SnapshotPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotPolicyList) DeepCopyInto(out *SnapshotPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SnapshotPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotPolicyList.
+func (in *SnapshotPolicyList) DeepCopy() *SnapshotPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SnapshotPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotPolicySpec) DeepCopyInto(out *SnapshotPolicySpec) {
+	*out = *in
+	if in.VolumeSelector != nil {
+		in, out := &in.VolumeSelector, &out.VolumeSelector
+		*out = (*in).DeepCopy()
+	}
+	in.Retention.DeepCopyInto(&out.Retention)
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotPolicySpec.
+func (in *SnapshotPolicySpec) DeepCopy() *SnapshotPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotPolicyStatus) DeepCopyInto(out *SnapshotPolicyStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SkippedVolumes != nil {
+		in, out := &in.SkippedVolumes, &out.SkippedVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotPolicyStatus.
+func (in *SnapshotPolicyStatus) DeepCopy() *SnapshotPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRetention) DeepCopyInto(out *SnapshotRetention) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRetention.
+func (in *SnapshotRetention) DeepCopy() *SnapshotRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRollbackStatus) DeepCopyInto(out *SnapshotRollbackStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishTime != nil {
+		in, out := &in.FinishTime, &out.FinishTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRollbackStatus.
+func (in *SnapshotRollbackStatus) DeepCopy() *SnapshotRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpdkLVStore) DeepCopyInto(out *SpdkLVStore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpdkLVStore.
+func (in *SpdkLVStore) DeepCopy() *SpdkLVStore {
+	if in == nil {
+		return nil
+	}
+	out := new(SpdkLVStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpdkLvol) DeepCopyInto(out *SpdkLvol) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpdkLvol.
+func (in *SpdkLvol) DeepCopy() *SpdkLvol {
+	if in == nil {
+		return nil
+	}
+	out := new(SpdkLvol)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpdkTarget) DeepCopyInto(out *SpdkTarget) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]TargetPath, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpdkTarget.
+func (in *SpdkTarget) DeepCopy() *SpdkTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(SpdkTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoragePool) DeepCopyInto(out *StoragePool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePool.
+func (in *StoragePool) DeepCopy() *StoragePool {
+	if in == nil {
+		return nil
+	}
+	out := new(StoragePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StoragePool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoragePoolList) DeepCopyInto(out *StoragePoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StoragePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePoolList.
+func (in *StoragePoolList) DeepCopy() *StoragePoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(StoragePoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StoragePoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoragePoolSpec) DeepCopyInto(out *StoragePoolSpec) {
+	*out = *in
+	in.KernelLVM.DeepCopyInto(&out.KernelLVM)
+	out.SpdkLVStore = in.SpdkLVStore
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]corev1.NodeAddress, len(*in))
+		copy(*out, *in)
+	}
+	in.NodeInfo.DeepCopyInto(&out.NodeInfo)
+	if in.Transports != nil {
+		in, out := &in.Transports, &out.Transports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePoolSpec.
@@ -930,6 +1610,11 @@ func (in *StoragePoolStatus) DeepCopyInto(out *StoragePoolStatus) {
 		*out = make([]PoolCondition, len(*in))
 		copy(*out, *in)
 	}
+	if in.IOLoad != nil {
+		in, out := &in.IOLoad, &out.IOLoad
+		*out = new(PoolIOLoad)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePoolStatus.
@@ -942,6 +1627,36 @@ func (in *StoragePoolStatus) DeepCopy() *StoragePoolStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetPath) DeepCopyInto(out *TargetPath) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetPath.
+func (in *TargetPath) DeepCopy() *TargetPath {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetPath)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeEncryption) DeepCopyInto(out *VolumeEncryption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeEncryption.
+func (in *VolumeEncryption) DeepCopy() *VolumeEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeGroupStrategy) DeepCopyInto(out *VolumeGroupStrategy) {
 	*out = *in
@@ -957,10 +1672,45 @@ func (in *VolumeGroupStrategy) DeepCopy() *VolumeGroupStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeImportSource) DeepCopyInto(out *VolumeImportSource) {
+	*out = *in
+	out.ObjectStore = in.ObjectStore
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeImportSource.
+func (in *VolumeImportSource) DeepCopy() *VolumeImportSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeImportSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeImportStatus) DeepCopyInto(out *VolumeImportStatus) {
+	*out = *in
+	if in.FinishTime != nil {
+		in, out := &in.FinishTime, &out.FinishTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeImportStatus.
+func (in *VolumeImportStatus) DeepCopy() *VolumeImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeInfo) DeepCopyInto(out *VolumeInfo) {
 	*out = *in
-	out.Spdk = in.Spdk
+	in.Spdk.DeepCopyInto(&out.Spdk)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeInfo.
@@ -994,7 +1744,7 @@ func (in *VolumeMigration) DeepCopyInto(out *VolumeMigration) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -1051,8 +1801,8 @@ func (in *VolumeMigrationList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeMigrationSpec) DeepCopyInto(out *VolumeMigrationSpec) {
 	*out = *in
-	out.SourceVolume = in.SourceVolume
-	out.DestVolume = in.DestVolume
+	in.SourceVolume.DeepCopyInto(&out.SourceVolume)
+	in.DestVolume.DeepCopyInto(&out.DestVolume)
 	out.MigrationInfo = in.MigrationInfo
 }
 
@@ -1081,13 +1831,28 @@ func (in *VolumeMigrationStatus) DeepCopy() *VolumeMigrationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeQoS) DeepCopyInto(out *VolumeQoS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeQoS.
+func (in *VolumeQoS) DeepCopy() *VolumeQoS {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeQoS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeTargetStatus) DeepCopyInto(out *VolumeTargetStatus) {
 	*out = *in
 	if in.SpdkTarget != nil {
 		in, out := &in.SpdkTarget, &out.SpdkTarget
 		*out = new(SpdkTarget)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -10,8 +10,9 @@ import (
 )
 
 type Config struct {
-	Scheduler     SchedulerConfig `json:"scheduler" yaml:"scheduler"`
-	PluginConfigs json.RawMessage `json:"pluginConfigs" yaml:"pluginConfigs"`
+	Scheduler     SchedulerConfig  `json:"scheduler" yaml:"scheduler"`
+	Rebalancer    RebalancerConfig `json:"rebalancer" yaml:"rebalancer"`
+	PluginConfigs json.RawMessage  `json:"pluginConfigs" yaml:"pluginConfigs"`
 }
 
 type SchedulerConfig struct {
@@ -21,8 +22,8 @@ type SchedulerConfig struct {
 	RemoteIgnoreAnnoSelector map[string]string `json:"remoteIgnoreAnnoSelector" yaml:"remoteIgnoreAnnoSelector"`
 	// filter names
 	Filters []string `json:"filters" yaml:"filters"`
-	// priority names
-	Priorities []string `json:"priorities" yaml:"priorities"`
+	// priority names and weights
+	Priorities []PriorityConfig `json:"priorities" yaml:"priorities"`
 	// LockSchedCfg
 	LockSchedCfg NoScheduleConfig `json:"lockSchedConfig" yaml:"lockSchedConfig"`
 	// NodeCacheSelector specify which nodes are cached to Node Informer.
@@ -32,6 +33,72 @@ type SchedulerConfig struct {
 	MinLocalStoragePct int `json:"minLocalStoragePct" yaml:"minLocalStoragePct"`
 	// NodeReservations defines the reservations on each node
 	NodeReservations []NodeReservation `json:"nodeReservations" yaml:"nodeReservations"`
+	// ThinOvercommitPct is the overcommit percentage of KernelLVM thin pools, which do not set ThinOvercommitPct in spec.
+	// Deprecated: set thinOvercommitPct in the pooling config of agent, which is reported to StoragePool.
+	ThinOvercommitPct int `json:"thinOvercommitPct" yaml:"thinOvercommitPct"`
+	// PoolHighWatermarkPct is used by HighWatermark filter. SpdkLVStore pool is not schedulable if its physical usage exceeds it.
+	PoolHighWatermarkPct int `json:"poolHighWatermarkPct" yaml:"poolHighWatermarkPct"`
+	// PoolIOUtilCeilingPct is used by IOUtilCeiling filter. Pool is not schedulable if its recent IO utilization exceeds it.
+	PoolIOUtilCeilingPct int `json:"poolIOUtilCeilingPct" yaml:"poolIOUtilCeilingPct"`
+	// HotPoolUtilPct is used by LowestIOLoad priority. Pool is regarded as hot for remote volumes if its recent IO utilization exceeds it.
+	HotPoolUtilPct int `json:"hotPoolUtilPct" yaml:"hotPoolUtilPct"`
+	// RackLabelKey and RoomLabelKey are node labels of topology domains, which are used by TopologySpread filter and priority
+	RackLabelKey string `json:"rackLabelKey" yaml:"rackLabelKey"`
+	RoomLabelKey string `json:"roomLabelKey" yaml:"roomLabelKey"`
+}
+
+// RebalancerConfig is the config of capacity rebalancer, which migrates volumes off over-full pools
+type RebalancerConfig struct {
+	// Enable starts the rebalancer in controller. Default is false.
+	Enable bool `json:"enable" yaml:"enable"`
+	// DryRun only plans migrations and records them in RebalanceReport, without creating VolumeMigrations
+	DryRun bool `json:"dryRun" yaml:"dryRun"`
+	// IntervalSec is the interval of rebalancing runs in seconds
+	IntervalSec int `json:"intervalSec" yaml:"intervalSec"`
+	// HighWatermarkPct is the usage of pool, above which volumes are migrated off the pool
+	HighWatermarkPct int `json:"highWatermarkPct" yaml:"highWatermarkPct"`
+	// LowWatermarkPct is the usage of pool, below which the pool could be a destination. Usage after migration is also checked.
+	LowWatermarkPct int `json:"lowWatermarkPct" yaml:"lowWatermarkPct"`
+	// MaxIOUtilPct is the max recent IO utilization of destination pools
+	MaxIOUtilPct int `json:"maxIOUtilPct" yaml:"maxIOUtilPct"`
+	// MaxConcurrentMigrations is the max number of unfinished VolumeMigrations in cluster
+	MaxConcurrentMigrations int `json:"maxConcurrentMigrations" yaml:"maxConcurrentMigrations"`
+	// MaxMigrationsPerNode is the max number of unfinished VolumeMigrations from or to a node
+	MaxMigrationsPerNode int `json:"maxMigrationsPerNode" yaml:"maxMigrationsPerNode"`
+	// ReportNamespace is the namespace of RebalanceReports
+	ReportNamespace string `json:"reportNamespace" yaml:"reportNamespace"`
+	// ReportHistoryLimit is the number of RebalanceReports to keep
+	ReportHistoryLimit int `json:"reportHistoryLimit" yaml:"reportHistoryLimit"`
+}
+
+// PriorityConfig is the name and weight of a priority.
+// It could be a string of priority name, whose weight is 0. A priority of weight 0 uses its raw score without normalization,
+// so that a list of names is scored the same as before weights are supported.
+type PriorityConfig struct {
+	Name string `json:"name" yaml:"name"`
+	// Weight multiplies the normalized score (0-100) of the priority. If it is 0, the raw score is used.
+	// It is 0 if the priority is a plain string of name, and defaults to 1 if the priority is an object.
+	Weight int `json:"weight" yaml:"weight"`
+}
+
+func (p *PriorityConfig) UnmarshalJSON(bs []byte) (err error) {
+	var name string
+	if err = json.Unmarshal(bs, &name); err == nil {
+		p.Name = name
+		p.Weight = 0
+		return
+	}
+
+	type plain PriorityConfig
+	var val plain
+	if err = json.Unmarshal(bs, &val); err != nil {
+		return
+	}
+	*p = PriorityConfig(val)
+	if p.Weight <= 0 {
+		p.Weight = 1
+	}
+	return
 }
 
 type NodeReservation struct {
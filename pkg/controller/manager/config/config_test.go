@@ -10,6 +10,10 @@ import (
 var (
 	cfg = `scheduler:
   maxRemoteVolumeCount: 3
+  priorities:
+  - LeastResource
+  - name: PositionAdvice
+    weight: 5
 pluginConfigs:
   test:
     aaa: bbb
@@ -21,6 +25,10 @@ func TestConfig(t *testing.T) {
 	c, err := fromYamlBytes([]byte(cfg))
 	assert.NoError(t, err)
 	assert.Equal(t, 3, c.Scheduler.MaxRemoteVolumeCount)
+	assert.Equal(t, []PriorityConfig{
+		{Name: "LeastResource", Weight: 0},
+		{Name: "PositionAdvice", Weight: 5},
+	}, c.Scheduler.Priorities)
 
 	type TestPluginConfigs struct {
 		Test  map[string]string `json:"test"`
@@ -35,3 +43,21 @@ func TestConfig(t *testing.T) {
 	assert.Equal(t, "bbb", testCfg.Test["aaa"])
 	assert.Equal(t, "ddd", testCfg.Test2["ccc"])
 }
+
+func TestRebalancerDefaults(t *testing.T) {
+	c, err := fromYamlBytes([]byte(`rebalancer:
+  enable: true
+  highWatermarkPct: 60
+  lowWatermarkPct: 80`))
+	assert.NoError(t, err)
+	SetDefaults(&c)
+
+	assert.True(t, c.Rebalancer.Enable)
+	assert.False(t, c.Rebalancer.DryRun)
+	assert.Equal(t, 60, c.Rebalancer.HighWatermarkPct)
+	// low watermark must be lower than high watermark
+	assert.Equal(t, 48, c.Rebalancer.LowWatermarkPct)
+	assert.Equal(t, 2, c.Rebalancer.MaxConcurrentMigrations)
+	assert.Equal(t, 1, c.Rebalancer.MaxMigrationsPerNode)
+	assert.Equal(t, "obnvmf", c.Rebalancer.ReportNamespace)
+}
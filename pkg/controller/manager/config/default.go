@@ -1,22 +1,93 @@
 package config
 
+import (
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"k8s.io/klog/v2"
+)
+
 func SetDefaults(cfg *Config) {
 	// set max remote volume
 	if cfg.Scheduler.MaxRemoteVolumeCount <= 0 {
 		cfg.Scheduler.MaxRemoteVolumeCount = 3
 	}
 
+	if cfg.Scheduler.PoolHighWatermarkPct <= 0 {
+		cfg.Scheduler.PoolHighWatermarkPct = 90
+	}
+
+	if cfg.Scheduler.PoolIOUtilCeilingPct <= 0 {
+		cfg.Scheduler.PoolIOUtilCeilingPct = 90
+	}
+
+	if cfg.Scheduler.HotPoolUtilPct <= 0 {
+		cfg.Scheduler.HotPoolUtilPct = 70
+	}
+
+	if cfg.Scheduler.RackLabelKey == "" {
+		cfg.Scheduler.RackLabelKey = v1.NodeLabelKeyRack
+	}
+
+	if cfg.Scheduler.RoomLabelKey == "" {
+		cfg.Scheduler.RoomLabelKey = v1.NodeLabelKeyRoom
+	}
+
+	setRebalancerDefaults(&cfg.Rebalancer)
+
 	if len(cfg.Scheduler.Filters) == 0 {
 		cfg.Scheduler.Filters = []string{
 			"Basic",
 			"Affinity",
+			"Transport",
+			"HighWatermark",
+			"TopologySpread",
 		}
 	}
 
+	// scores of priorities are normalized to 0-100.
+	// LeastResource has larger weight, so that PositionAdvice and TopologySpread are equivalent to 20% of pool usage.
 	if len(cfg.Scheduler.Priorities) == 0 {
-		cfg.Scheduler.Priorities = []string{
-			"LeastResource",
-			"PositionAdvice",
+		cfg.Scheduler.Priorities = []PriorityConfig{
+			{Name: "LeastResource", Weight: 5},
+			{Name: "PositionAdvice", Weight: 1},
+			{Name: "TopologySpread", Weight: 1},
 		}
 	}
 }
+
+func setRebalancerDefaults(cfg *RebalancerConfig) {
+	if cfg.IntervalSec <= 0 {
+		cfg.IntervalSec = 300
+	}
+
+	if cfg.HighWatermarkPct <= 0 {
+		cfg.HighWatermarkPct = 85
+	}
+
+	if cfg.LowWatermarkPct >= cfg.HighWatermarkPct {
+		klog.Warningf("rebalancer lowWatermarkPct %d is not lower than highWatermarkPct %d, use default value %d",
+			cfg.LowWatermarkPct, cfg.HighWatermarkPct, cfg.HighWatermarkPct*4/5)
+	}
+	if cfg.LowWatermarkPct <= 0 || cfg.LowWatermarkPct >= cfg.HighWatermarkPct {
+		cfg.LowWatermarkPct = cfg.HighWatermarkPct * 4 / 5
+	}
+
+	if cfg.MaxIOUtilPct <= 0 {
+		cfg.MaxIOUtilPct = 70
+	}
+
+	if cfg.MaxConcurrentMigrations <= 0 {
+		cfg.MaxConcurrentMigrations = 2
+	}
+
+	if cfg.MaxMigrationsPerNode <= 0 {
+		cfg.MaxMigrationsPerNode = 1
+	}
+
+	if cfg.ReportNamespace == "" {
+		cfg.ReportNamespace = v1.DefaultNamespace
+	}
+
+	if cfg.ReportHistoryLimit <= 0 {
+		cfg.ReportHistoryLimit = 10
+	}
+}
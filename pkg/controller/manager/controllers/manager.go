@@ -21,6 +21,7 @@ import (
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
 	"lite.io/liteio/pkg/controller/kubeutil"
 	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/rebalancer"
 	"lite.io/liteio/pkg/controller/manager/reconciler"
 	"lite.io/liteio/pkg/controller/manager/reconciler/handler"
 	"lite.io/liteio/pkg/controller/manager/reconciler/plugin"
@@ -207,7 +208,9 @@ func NewAndInitControllerManager(req NewManagerRequest) manager.Manager {
 
 		Concurrency: 1,
 		MainHandler: &reconciler.AntstorDataControlReconcileHandler{
-			Client: mgr.GetClient(),
+			Client:    mgr.GetClient(),
+			Scheduler: scheduler,
+			State:     stateObj,
 		},
 		ForType: &v1.AntstorDataControl{},
 	}
@@ -272,6 +275,26 @@ func NewAndInitControllerManager(req NewManagerRequest) manager.Manager {
 		os.Exit(1)
 	}
 
+	groupSnapshotReconciler := &reconciler.VolumeGroupSnapshotReconciler{
+		Client:        mgr.GetClient(),
+		Log:           rt.Log.WithName("controllers").WithName("GroupSnapshot"),
+		EventRecorder: mgr.GetEventRecorderFor("AntstorVolumeGroupSnapshot"),
+	}
+	if err = groupSnapshotReconciler.SetupWithManager(mgr); err != nil {
+		klog.Error(err, "unable to create GroupSnapshot controller")
+		os.Exit(1)
+	}
+
+	snapshotPolicyReconciler := &reconciler.SnapshotPolicyReconciler{
+		Client:        mgr.GetClient(),
+		Log:           rt.Log.WithName("controllers").WithName("SnapshotPolicy"),
+		EventRecorder: mgr.GetEventRecorderFor("SnapshotPolicy"),
+	}
+	if err = snapshotPolicyReconciler.SetupWithManager(mgr); err != nil {
+		klog.Error(err, "unable to create SnapshotPolicy controller")
+		os.Exit(1)
+	}
+
 	migrationReconcile := &reconciler.VolumeMigrationReconciler{
 		Client: mgr.GetClient(),
 		Log:    rt.Log.WithName("controllers").WithName("Migration"),
@@ -281,9 +304,19 @@ func NewAndInitControllerManager(req NewManagerRequest) manager.Manager {
 		os.Exit(1)
 	}
 
+	if req.ControllerConfig.Rebalancer.Enable {
+		klog.Infof("setup rebalancer, config %+v", req.ControllerConfig.Rebalancer)
+		if err = mgr.Add(rebalancer.NewRebalancer(mgr.GetClient(), stateObj, req.ControllerConfig.Rebalancer)); err != nil {
+			klog.Error(err, "unable to add rebalancer")
+			os.Exit(1)
+		}
+	}
+
 	// setup state API service
 	klog.Infof("setup state API service on %s, URI /state/storagepool", req.MetricsAddr)
 	mgr.AddMetricsExtraHandler("/state/storagepool", state.NewStateHandler(stateObj))
+	klog.Infof("setup scheduler explain API on %s, URI /scheduler/explain", req.MetricsAddr)
+	mgr.AddMetricsExtraHandler("/scheduler/explain", sched.NewExplainHandler(scheduler, stateObj))
 
 	if req.EnableWebhook {
 		klog.Info("setup webhook service for AntstorVolume")
@@ -0,0 +1,242 @@
+package rebalancer
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/state"
+)
+
+const (
+	MsgThrottled     = "max concurrent migrations reached"
+	MsgNoCandidate   = "no volume can be migrated off over-full pools"
+	MsgNoDestination = "no destination pool below low watermark"
+)
+
+// poolUsage is the usage of a pool, which is updated by planned moves
+type poolUsage struct {
+	node  *state.Node
+	total float64
+	free  float64
+	// ioUtilPct is -1 if IO load is not reported
+	ioUtilPct int
+	// migrations is the number of unfinished migrations from or to the pool
+	migrations int
+}
+
+func newPoolUsage(n *state.Node) (pu *poolUsage) {
+	pu = &poolUsage{
+		node:      n,
+		total:     n.Pool.Status.Capacity.Storage().AsApproximateFloat64(),
+		free:      n.FreeResource.Storage().AsApproximateFloat64(),
+		ioUtilPct: -1,
+	}
+	if load := n.Pool.GetRecentIOLoad(); load != nil {
+		pu.ioUtilPct = load.UtilPct
+	}
+	return
+}
+
+// usedPctAfter returns the percentage of allocated space after the size is allocated
+func (pu *poolUsage) usedPctAfter(size float64) int {
+	if pu.total <= 0 {
+		return 0
+	}
+	return int((pu.total - pu.free + size) * 100 / pu.total)
+}
+
+func (pu *poolUsage) usedPct() int {
+	return pu.usedPctAfter(0)
+}
+
+func (pu *poolUsage) toReport() v1.RebalancePoolUsage {
+	return v1.RebalancePoolUsage{
+		NodeID:    pu.node.Info.ID,
+		UsedPct:   pu.usedPct(),
+		IOUtilPct: pu.ioUtilPct,
+	}
+}
+
+// IsMigrationInflight returns true if the VolumeMigration is neither finished nor failed
+func IsMigrationInflight(m *v1.VolumeMigration) bool {
+	return m.Status.Phase != v1.MigrationPhaseFinished && m.Status.Status != v1.MigrationStatusError
+}
+
+// Plan picks volumes on pools above the high watermark, and moves them to pools which stay below the low watermark after migration.
+// Unfinished migrations count towards the cluster-wide and per-node limits, and their volumes are not picked again.
+// Volumes with snapshots are not picked, because snapshots are not migrated with them.
+func Plan(cfg config.RebalancerConfig, nodes []*state.Node, migrations []v1.VolumeMigration, snapshots []v1.AntstorSnapshot) (status v1.RebalanceReportStatus) {
+	var (
+		pools     = make(map[string]*poolUsage, len(nodes))
+		sources   []*poolUsage
+		migrating = make(map[string]bool)
+		// volumes which could not be migrated, keyed by namespace/name
+		pinned = make(map[string]bool)
+		budget int
+	)
+
+	for _, snap := range snapshots {
+		pinned[snap.Spec.OriginVolNamespace+"/"+snap.Spec.OriginVolName] = true
+	}
+
+	for _, n := range nodes {
+		pu := newPoolUsage(n)
+		if pu.total <= 0 {
+			continue
+		}
+		pools[n.Info.ID] = pu
+		if pu.usedPct() >= cfg.HighWatermarkPct {
+			sources = append(sources, pu)
+		}
+	}
+
+	for i := range migrations {
+		m := &migrations[i]
+		if !IsMigrationInflight(m) {
+			continue
+		}
+		status.InflightMigrations++
+		migrating[m.Spec.SourceVolume.Namespace+"/"+m.Spec.SourceVolume.Name] = true
+		if pu, has := pools[m.Spec.SourceVolume.TargetNodeId]; has {
+			pu.migrations++
+		}
+		destNodeID := m.Spec.DestVolume.TargetNodeId
+		if destNodeID == "" {
+			destNodeID = m.Annotations[v1.MigrationAnnoKeyDestNodeId]
+		}
+		if pu, has := pools[destNodeID]; has {
+			pu.migrations++
+		}
+	}
+
+	// the fullest pool is rebalanced first
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].usedPct() > sources[j].usedPct()
+	})
+	for _, src := range sources {
+		status.OverfullPools = append(status.OverfullPools, src.toReport())
+	}
+	if len(sources) == 0 {
+		return
+	}
+
+	budget = cfg.MaxConcurrentMigrations - status.InflightMigrations
+	for _, src := range sources {
+		candidates := candidateVolumes(src.node, migrating, pinned)
+		if len(candidates) == 0 {
+			status.Message = MsgNoCandidate
+			continue
+		}
+
+		for _, vol := range candidates {
+			if budget <= 0 || src.migrations >= cfg.MaxMigrationsPerNode || src.usedPct() < cfg.HighWatermarkPct {
+				break
+			}
+			size := float64(vol.GetTotalSize())
+			dest := pickDestination(cfg, pools, src, size)
+			if dest == nil {
+				continue
+			}
+
+			status.Moves = append(status.Moves, v1.RebalanceMove{
+				Volume: v1.EntityIdentity{
+					Namespace: vol.Namespace,
+					Name:      vol.Name,
+					UUID:      vol.Spec.Uuid,
+				},
+				SizeByte:     vol.Spec.SizeByte,
+				SourceNodeID: src.node.Info.ID,
+				DestNodeID:   dest.node.Info.ID,
+			})
+			src.free += size
+			dest.free -= size
+			src.migrations++
+			dest.migrations++
+			budget--
+		}
+
+		// explain why the pool is still over-full
+		switch {
+		case src.usedPct() < cfg.HighWatermarkPct:
+		case budget <= 0:
+			status.Message = MsgThrottled
+		case src.migrations >= cfg.MaxMigrationsPerNode:
+			status.Message = fmt.Sprintf("max migrations of node %s reached", src.node.Info.ID)
+		default:
+			status.Message = MsgNoDestination
+		}
+	}
+
+	return
+}
+
+// candidateVolumes returns volumes which could be migrated by VolumeMigration.
+// Remote volumes are picked first, so that local volumes stay with their host. Larger volumes are picked first.
+// Thin volumes are skipped, because moving them frees less space than their size.
+// Replicated volumes, members of VolumeGroup or DataControl and volumes cloned from snapshots are skipped, because VolumeMigration does not support them.
+func candidateVolumes(n *state.Node, migrating, pinned map[string]bool) (list []*v1.AntstorVolume) {
+	for _, vol := range n.Volumes {
+		if vol.Spec.Type != v1.VolumeTypeSpdkLVol || vol.Spec.PositionAdvice == v1.MustLocal || vol.Spec.IsThin {
+			continue
+		}
+		if vol.Status.Status != v1.VolumeStatusReady || vol.DeletionTimestamp != nil ||
+			vol.Spec.HostNode == nil || vol.Spec.SpdkTarget == nil {
+			continue
+		}
+		if vol.IsReplicated() || isGroupMember(vol) || vol.Labels[v1.VolumeSourceSnapNameLabelKey] != "" {
+			continue
+		}
+		if key := vol.Namespace + "/" + vol.Name; migrating[key] || pinned[key] {
+			continue
+		}
+		list = append(list, vol)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		remoteI, remoteJ := isRemote(list[i]), isRemote(list[j])
+		if remoteI != remoteJ {
+			return remoteI
+		}
+		return list[i].GetTotalSize() > list[j].GetTotalSize()
+	})
+	return
+}
+
+// isGroupMember returns true if the volume is a member of VolumeGroup, whose placement is decided by the group
+func isGroupMember(vol *v1.AntstorVolume) bool {
+	if vol.Labels[v1.VolumeGroupNameLabelKey] != "" || vol.Labels[v1.DataControlNameKey] != "" {
+		return true
+	}
+	for _, owner := range vol.OwnerReferences {
+		if owner.Kind == v1.AntstorVolumeGroupKind {
+			return true
+		}
+	}
+	return false
+}
+
+func isRemote(vol *v1.AntstorVolume) bool {
+	return vol.Spec.HostNode != nil && vol.Spec.TargetNodeId != vol.Spec.HostNode.ID
+}
+
+// pickDestination returns the least used pool, which is schedulable, not hot, under the per-node limit and stays below the low watermark after migration
+func pickDestination(cfg config.RebalancerConfig, pools map[string]*poolUsage, src *poolUsage, size float64) (dest *poolUsage) {
+	for id, pu := range pools {
+		if id == src.node.Info.ID || !pu.node.Pool.IsSchedulable() {
+			continue
+		}
+		if pu.migrations >= cfg.MaxMigrationsPerNode || pu.ioUtilPct >= cfg.MaxIOUtilPct {
+			continue
+		}
+		if pu.usedPctAfter(size) >= cfg.LowWatermarkPct {
+			continue
+		}
+		if dest == nil || pu.usedPct() < dest.usedPct() ||
+			(pu.usedPct() == dest.usedPct() && id < dest.node.Info.ID) {
+			dest = pu
+		}
+	}
+	return
+}
@@ -0,0 +1,208 @@
+package rebalancer
+
+import (
+	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPlan(t *testing.T) {
+	var cfg = config.RebalancerConfig{
+		HighWatermarkPct:        85,
+		LowWatermarkPct:         70,
+		MaxIOUtilPct:            70,
+		MaxConcurrentMigrations: 2,
+		MaxMigrationsPerNode:    1,
+	}
+
+	// node-1 is 90% used. node-2 is 10% used. node-3 is 50% used.
+	newNodes := func() []*state.Node {
+		full := newNode("node-1", 100<<30)
+		addVolume(t, full, newVolume("local", "node-1", 40<<30, v1.PreferLocal))
+		addVolume(t, full, newVolume("remote-small", "node-x", 10<<30, v1.NoPreference))
+		addVolume(t, full, newVolume("remote-large", "node-x", 20<<30, v1.NoPreference))
+		addVolume(t, full, newVolume("must-local", "node-1", 20<<30, v1.MustLocal))
+		empty := newNode("node-2", 100<<30)
+		addVolume(t, empty, newVolume("vol-2", "node-2", 10<<30, v1.NoPreference))
+		half := newNode("node-3", 100<<30)
+		addVolume(t, half, newVolume("vol-3", "node-3", 50<<30, v1.NoPreference))
+		return []*state.Node{full, empty, half}
+	}
+
+	// the largest remote volume is moved to the least used pool
+	status := Plan(cfg, newNodes(), nil, nil)
+	assert.Equal(t, []v1.RebalancePoolUsage{{NodeID: "node-1", UsedPct: 90, IOUtilPct: -1}}, status.OverfullPools)
+	if assert.Len(t, status.Moves, 1) {
+		assert.Equal(t, "remote-large", status.Moves[0].Volume.Name)
+		assert.Equal(t, "node-1", status.Moves[0].SourceNodeID)
+		assert.Equal(t, "node-2", status.Moves[0].DestNodeID)
+	}
+
+	// per-node limit allows more moves from node-1 after the first one
+	cfgMore := cfg
+	cfgMore.HighWatermarkPct = 60
+	cfgMore.MaxMigrationsPerNode = 2
+	status = Plan(cfgMore, newNodes(), nil, nil)
+	assert.Len(t, status.Moves, 2)
+	assert.Equal(t, MsgThrottled, status.Message)
+
+	// hot pool is not a destination. remote-large does not fit node-3 under low watermark, but remote-small does.
+	nodes := newNodes()
+	nodes[1].Pool.Status.IOLoad = &v1.PoolIOLoad{UtilPct: 80, UpdateTime: metav1.Now()}
+	status = Plan(cfg, nodes, nil, nil)
+	if assert.Len(t, status.Moves, 1) {
+		assert.Equal(t, "remote-small", status.Moves[0].Volume.Name)
+		assert.Equal(t, "node-3", status.Moves[0].DestNodeID)
+	}
+	assert.Empty(t, status.Message)
+
+	nodes[2].Pool.Status.IOLoad = &v1.PoolIOLoad{UtilPct: 90, UpdateTime: metav1.Now()}
+	status = Plan(cfg, nodes, nil, nil)
+	assert.Empty(t, status.Moves)
+	assert.Equal(t, MsgNoDestination, status.Message)
+
+	// expired IO load is ignored
+	nodes[1].Pool.Status.IOLoad.UpdateTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	status = Plan(cfg, nodes, nil, nil)
+	assert.Len(t, status.Moves, 1)
+
+	// inflight migrations are throttled cluster-wide
+	inflight := []v1.VolumeMigration{
+		newMigration("vol-2", "node-2", v1.MigrationPhaseSyncing, v1.MigrationStatusWorking),
+		newMigration("vol-3", "node-3", v1.MigrationPhaseCreatingVolume, v1.MigrationStatusWorking),
+		newMigration("remote-large", "node-1", v1.MigrationPhaseFinished, string(v1.MigrationPhaseFinished)),
+	}
+	status = Plan(cfg, newNodes(), inflight, nil)
+	assert.Equal(t, 2, status.InflightMigrations)
+	assert.Empty(t, status.Moves)
+	assert.Equal(t, MsgThrottled, status.Message)
+
+	// migrating volume is not picked again, and source node is throttled
+	inflight = []v1.VolumeMigration{
+		newMigration("remote-large", "node-1", v1.MigrationPhaseSyncing, v1.MigrationStatusWorking),
+	}
+	status = Plan(cfg, newNodes(), inflight, nil)
+	assert.Empty(t, status.Moves)
+	assert.Equal(t, "max migrations of node node-1 reached", status.Message)
+	cfgMore = cfg
+	cfgMore.MaxMigrationsPerNode = 2
+	status = Plan(cfgMore, newNodes(), inflight, nil)
+	if assert.Len(t, status.Moves, 1) {
+		assert.Equal(t, "remote-small", status.Moves[0].Volume.Name)
+	}
+
+	// pools below high watermark are not rebalanced
+	cfgMore = cfg
+	cfgMore.HighWatermarkPct = 95
+	status = Plan(cfgMore, newNodes(), nil, nil)
+	assert.Empty(t, status.OverfullPools)
+	assert.Empty(t, status.Moves)
+
+	// volumes not supported by VolumeMigration are not picked, the smaller one is moved instead
+	cases := []struct {
+		name      string
+		setup     func(vol *v1.AntstorVolume)
+		snapshots []v1.AntstorSnapshot
+	}{
+		{name: "replica leg", setup: func(vol *v1.AntstorVolume) {
+			vol.Labels = map[string]string{v1.ReplicaOfLabelKey: "vol-0"}
+		}},
+		{name: "replicated", setup: func(vol *v1.AntstorVolume) {
+			vol.Spec.ReplicaLegs = []v1.EntityIdentity{{Namespace: v1.DefaultNamespace, Name: "remote-large-leg"}}
+		}},
+		{name: "VolumeGroup member", setup: func(vol *v1.AntstorVolume) {
+			vol.OwnerReferences = []metav1.OwnerReference{{Kind: v1.AntstorVolumeGroupKind, Name: "vg-1"}}
+		}},
+		{name: "DataControl member", setup: func(vol *v1.AntstorVolume) {
+			vol.Labels = map[string]string{v1.VolumeGroupNameLabelKey: "vg-1", v1.DataControlNameKey: "dc-1"}
+		}},
+		{name: "cloned from snapshot", setup: func(vol *v1.AntstorVolume) {
+			vol.Labels = map[string]string{v1.VolumeSourceSnapNameLabelKey: "snap-1"}
+		}},
+		{name: "has snapshots", setup: func(vol *v1.AntstorVolume) {}, snapshots: []v1.AntstorSnapshot{
+			{Spec: v1.AntstorSnapshotSpec{OriginVolName: "remote-large", OriginVolNamespace: v1.DefaultNamespace}},
+		}},
+	}
+	for _, c := range cases {
+		nodes = newNodes()
+		for _, vol := range nodes[0].Volumes {
+			if vol.Name == "remote-large" {
+				c.setup(vol)
+			}
+		}
+		status = Plan(cfg, nodes, nil, c.snapshots)
+		if assert.Len(t, status.Moves, 1, c.name) {
+			assert.Equal(t, "remote-small", status.Moves[0].Volume.Name, c.name)
+		}
+	}
+}
+
+func newNode(nodeID string, total int64) *state.Node {
+	pool := &v1.StoragePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeID,
+		},
+		Spec: v1.StoragePoolSpec{
+			NodeInfo: v1.NodeInfo{
+				ID: nodeID,
+			},
+		},
+		Status: v1.StoragePoolStatus{
+			Status: v1.PoolStatusReady,
+			Capacity: corev1.ResourceList{
+				v1.ResourceDiskPoolByte: *resource.NewQuantity(total, resource.BinarySI),
+			},
+		},
+	}
+	return state.NewNode(pool)
+}
+
+func addVolume(t *testing.T, n *state.Node, vol *v1.AntstorVolume) {
+	vol.Spec.TargetNodeId = n.Info.ID
+	assert.NoError(t, n.AddVolume(vol))
+}
+
+func newVolume(name, hostNodeID string, size uint64, advice v1.VolumePosition) *v1.AntstorVolume {
+	return &v1.AntstorVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: v1.DefaultNamespace,
+			Name:      name,
+		},
+		Spec: v1.AntstorVolumeSpec{
+			Uuid:           "uuid-" + name,
+			Type:           v1.VolumeTypeSpdkLVol,
+			SizeByte:       size,
+			PositionAdvice: advice,
+			HostNode: &v1.NodeInfo{
+				ID: hostNodeID,
+			},
+			SpdkTarget: &v1.SpdkTarget{},
+		},
+		Status: v1.AntstorVolumeStatus{
+			Status: v1.VolumeStatusReady,
+		},
+	}
+}
+
+func newMigration(volName, srcNodeID string, phase v1.MigrationPhase, status string) v1.VolumeMigration {
+	return v1.VolumeMigration{
+		Spec: v1.VolumeMigrationSpec{
+			SourceVolume: v1.VolumeInfo{
+				Namespace:    v1.DefaultNamespace,
+				Name:         volName,
+				TargetNodeId: srcNodeID,
+			},
+		},
+		Status: v1.VolumeMigrationStatus{
+			Phase:  phase,
+			Status: status,
+		},
+	}
+}
@@ -0,0 +1,199 @@
+package rebalancer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"lite.io/liteio/pkg/util/misc"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	rebalancerMetricSubsystem = "rebalancer"
+)
+
+var (
+	// pools above high watermark in the last run
+	rebalancerOverfullPools = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: rebalancerMetricSubsystem,
+		Name:      "overfull_pools",
+		Help:      "Number of pools above high watermark in the last run of rebalancer",
+	})
+
+	// migrations handled by rebalancer. result is one of planned, created and failed
+	rebalancerMigrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: rebalancerMetricSubsystem,
+		Name:      "migrations_total",
+		Help:      "Number of migrations planned, created or failed by rebalancer",
+	}, []string{"result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(rebalancerOverfullPools, rebalancerMigrations)
+}
+
+// Rebalancer periodically migrates volumes off over-full pools by VolumeMigrations.
+// A RebalanceReport is saved for each run which finds over-full pools.
+type Rebalancer struct {
+	client client.Client
+	state  state.StateIface
+	cfg    config.RebalancerConfig
+}
+
+func NewRebalancer(cli client.Client, s state.StateIface, cfg config.RebalancerConfig) *Rebalancer {
+	return &Rebalancer{
+		client: cli,
+		state:  s,
+		cfg:    cfg,
+	}
+}
+
+// Start implements Runnable
+func (r *Rebalancer) Start(ctx context.Context) (err error) {
+	var tick = time.NewTicker(time.Duration(r.cfg.IntervalSec) * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			err = r.Rebalance(ctx)
+			if err != nil {
+				klog.Error(err)
+			}
+		case <-ctx.Done():
+			klog.Info("quit rebalancer loop")
+			return nil
+		}
+	}
+}
+
+// Rebalance plans migrations, creates them unless in dry-run mode, and saves the report
+func (r *Rebalancer) Rebalance(ctx context.Context) (err error) {
+	var (
+		migrations v1.VolumeMigrationList
+		snapshots  v1.AntstorSnapshotList
+		now        = time.Now()
+		report     *v1.RebalanceReport
+	)
+
+	err = r.client.List(ctx, &migrations)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	err = r.client.List(ctx, &snapshots)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	report = &v1.RebalanceReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.cfg.ReportNamespace,
+			Name:      "rebalance-" + strconv.FormatInt(now.Unix(), 10),
+		},
+		Spec: v1.RebalanceReportSpec{
+			DryRun:           r.cfg.DryRun,
+			HighWatermarkPct: r.cfg.HighWatermarkPct,
+			LowWatermarkPct:  r.cfg.LowWatermarkPct,
+			MaxIOUtilPct:     r.cfg.MaxIOUtilPct,
+		},
+		Status: Plan(r.cfg, r.state.GetAllNodes(), migrations.Items, snapshots.Items),
+	}
+	rebalancerOverfullPools.Set(float64(len(report.Status.OverfullPools)))
+	rebalancerMigrations.WithLabelValues("planned").Add(float64(len(report.Status.Moves)))
+	klog.Infof("rebalancer found %d over-full pools, planned %d moves, dryRun=%t, message=%q",
+		len(report.Status.OverfullPools), len(report.Status.Moves), r.cfg.DryRun, report.Status.Message)
+
+	// nothing to report if all pools are below high watermark
+	if len(report.Status.OverfullPools) == 0 {
+		return
+	}
+
+	if !r.cfg.DryRun {
+		for idx := range report.Status.Moves {
+			move := &report.Status.Moves[idx]
+			move.Migration, err = r.createMigration(ctx, report.Name, move)
+			if err != nil {
+				klog.Error(err)
+				move.Message = err.Error()
+				rebalancerMigrations.WithLabelValues("failed").Inc()
+				continue
+			}
+			rebalancerMigrations.WithLabelValues("created").Inc()
+		}
+	}
+
+	err = r.client.Create(ctx, report)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	return r.pruneReports(ctx)
+}
+
+func (r *Rebalancer) createMigration(ctx context.Context, reportName string, move *v1.RebalanceMove) (name string, err error) {
+	var migration = &v1.VolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: move.Volume.Namespace,
+			Name:      fmt.Sprintf("%s-%s", move.Volume.Name, misc.RandomStringWithCharSet(5, misc.LowerCharNumSet)),
+			Labels: map[string]string{
+				v1.RebalanceReportLabelKey: reportName,
+			},
+			Annotations: map[string]string{
+				v1.MigrationAnnoKeyDestNodeId: move.DestNodeID,
+			},
+		},
+		Spec: v1.VolumeMigrationSpec{
+			SourceVolume: v1.VolumeInfo{
+				Namespace: move.Volume.Namespace,
+				Name:      move.Volume.Name,
+			},
+			MigrationInfo: v1.MigrationInfo{
+				AutoSwitch: v1.AutoSwitch{
+					Enabled: true,
+				},
+			},
+		},
+	}
+
+	klog.Infof("rebalancer creates migration %s, volume %s from node %s to %s", migration.Name, move.Volume.Name, move.SourceNodeID, move.DestNodeID)
+	err = r.client.Create(ctx, migration)
+	if err != nil {
+		return
+	}
+	return migration.Name, nil
+}
+
+// pruneReports deletes the oldest reports exceeding ReportHistoryLimit
+func (r *Rebalancer) pruneReports(ctx context.Context) (err error) {
+	var list v1.RebalanceReportList
+	err = r.client.List(ctx, &list, client.InNamespace(r.cfg.ReportNamespace))
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].CreationTimestamp.Before(&list.Items[j].CreationTimestamp)
+	})
+	for i := 0; i < len(list.Items)-r.cfg.ReportHistoryLimit; i++ {
+		err = r.client.Delete(ctx, &list.Items[i])
+		if client.IgnoreNotFound(err) != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
+	return nil
+}
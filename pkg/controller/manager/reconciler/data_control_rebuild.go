@@ -0,0 +1,292 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/reconciler/plugin"
+	"lite.io/liteio/pkg/util/misc"
+	"github.com/go-logr/logr"
+	uuid "github.com/satori/go.uuid"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileRebuild replaces the missing members of redundant raid one by one.
+// A replacement volume is allocated in the VolumeGroup of the missing member, then agent adds it to VG and rebuilds data.
+// The old member is deleted after data is rebuilt.
+func (r *AntstorDataControlReconcileHandler) reconcileRebuild(ctx context.Context, dataControl *v1.AntstorDataControl, log logr.Logger) (result plugin.Result) {
+	if dataControl.Spec.LVM == nil || !dataControl.Spec.Raid.IsRedundant() || dataControl.Status.Status != v1.VolumeStatusReady {
+		return
+	}
+
+	var rebuild = dataControl.Status.Rebuild
+	if rebuild == nil || rebuild.Phase == v1.RebuildPhaseFinished {
+		for _, item := range dataControl.Status.MissingPVs {
+			if rebuild != nil && item.UUID == rebuild.OldPV.VolId.UUID {
+				continue
+			}
+			return r.startRebuild(ctx, dataControl, item, log)
+		}
+		return
+	}
+
+	switch rebuild.Phase {
+	case v1.RebuildPhaseReplacing:
+		return r.syncReplacement(ctx, dataControl, log)
+	case v1.RebuildPhaseRebuilding:
+		log.Info("raid is rebuilding, check in 1 min", "percent", rebuild.SyncPercent, "newVol", rebuild.NewVolId.Name)
+		return plugin.Result{Break: true, Result: ctrl.Result{RequeueAfter: time.Minute}}
+	case v1.RebuildPhaseSynced:
+		return r.cleanupOldMember(ctx, dataControl, log)
+	}
+
+	return
+}
+
+// startRebuild records the missing member and the identity of its replacement volume in status
+func (r *AntstorDataControlReconcileHandler) startRebuild(ctx context.Context, dataControl *v1.AntstorDataControl, missing v1.EntityIdentity, log logr.Logger) (result plugin.Result) {
+	var (
+		oldPV    *v1.LVMControlPV
+		volGroup *v1.AntstorVolumeGroup
+		err      error
+	)
+	for idx, item := range dataControl.Spec.LVM.PVs {
+		if item.VolId.UUID == missing.UUID {
+			oldPV = &dataControl.Spec.LVM.PVs[idx]
+			break
+		}
+	}
+	if oldPV == nil {
+		log.Info("missing PV is not a member of datacontrol, skip it", "vol", missing)
+		return
+	}
+
+	_, volGroup, err = r.findMemberVolumeGroup(ctx, dataControl, missing.UUID)
+	if err != nil {
+		log.Error(err, "finding VolumeGroup of missing member failed")
+		return plugin.Result{Error: err}
+	}
+
+	log.Info("member of raid is missing, start to rebuild", "vol", missing.Name)
+	dataControl.Status.Rebuild = &v1.DataControlRebuildStatus{
+		Phase: v1.RebuildPhaseReplacing,
+		OldPV: *oldPV.DeepCopy(),
+		NewVolId: v1.EntityIdentity{
+			Namespace: volGroup.Namespace,
+			Name:      fmt.Sprintf("%s-%s", volGroup.Name, misc.RandomStringWithCharSet(10, misc.LowerCharNumSet)),
+			UUID:      uuid.NewV4().String(),
+		},
+	}
+	err = r.Client.Status().Update(ctx, dataControl)
+	if err != nil {
+		log.Error(err, "updating DataControl status failed")
+		return plugin.Result{Error: err}
+	}
+
+	return plugin.Result{Break: true}
+}
+
+// syncReplacement schedules the replacement volume in VolumeGroup, and waits for it to be ready.
+// Then the missing PV in spec is replaced by the new volume, which is added to VG by agent.
+func (r *AntstorDataControlReconcileHandler) syncReplacement(ctx context.Context, dataControl *v1.AntstorDataControl, log logr.Logger) (result plugin.Result) {
+	var (
+		rebuild  = dataControl.Status.Rebuild
+		volGroup *v1.AntstorVolumeGroup
+		vol      v1.AntstorVolume
+		err      error
+	)
+
+	// 1. schedule the replacement volume in VolumeGroup
+	_, volGroup, err = r.findMemberVolumeGroup(ctx, dataControl, rebuild.NewVolId.UUID)
+	if errors.IsNotFound(err) {
+		return r.scheduleReplacement(ctx, dataControl, log)
+	}
+	if err != nil {
+		log.Error(err, "finding VolumeGroup of replacement volume failed")
+		return plugin.Result{Error: err}
+	}
+
+	// 2. wait for the replacement volume to be ready
+	err = r.Client.Get(ctx, client.ObjectKey{
+		Namespace: rebuild.NewVolId.Namespace,
+		Name:      rebuild.NewVolId.Name,
+	}, &vol)
+	if err != nil {
+		log.Info("replacement volume is not created yet, retry in 20 sec", "vol", rebuild.NewVolId.Name, "volGroup", volGroup.Name, "err", err)
+		return plugin.Result{Break: true, Result: ctrl.Result{RequeueAfter: 20 * time.Second}}
+	}
+
+	if vol.Spec.HostNode == nil || vol.Spec.HostNode.ID != dataControl.Spec.HostNode.ID {
+		hostNode := dataControl.Spec.HostNode
+		vol.Spec.HostNode = &hostNode
+		err = r.Client.Update(ctx, &vol)
+		if err != nil {
+			log.Error(err, "updating HostNode of replacement volume failed")
+			return plugin.Result{Error: err}
+		}
+	}
+
+	if vol.Status.Status != v1.VolumeStatusReady || vol.Spec.SpdkTarget == nil {
+		log.Info("replacement volume is not ready, retry in 20 sec", "vol", vol.Name, "status", vol.Status.Status)
+		return plugin.Result{Break: true, Result: ctrl.Result{RequeueAfter: 20 * time.Second}}
+	}
+
+	// 3. replace the missing PV, agent connects the new target and repairs raid LV
+	var replaced bool
+	for idx, item := range dataControl.Spec.LVM.PVs {
+		if item.VolId.UUID == rebuild.NewVolId.UUID {
+			replaced = true
+			break
+		}
+		if item.VolId.UUID == rebuild.OldPV.VolId.UUID {
+			dataControl.Spec.LVM.PVs[idx] = v1.LVMControlPV{
+				VolId:      rebuild.NewVolId,
+				TargetInfo: *vol.Spec.SpdkTarget.DeepCopy(),
+			}
+		}
+	}
+	if !replaced {
+		err = r.Client.Update(ctx, dataControl)
+		if err != nil {
+			log.Error(err, "replacing PV of DataControl failed")
+			return plugin.Result{Error: err}
+		}
+	}
+
+	dataControl.Status.Rebuild.Phase = v1.RebuildPhaseRebuilding
+	err = r.Client.Status().Update(ctx, dataControl)
+	if err != nil {
+		log.Error(err, "updating DataControl status failed")
+		return plugin.Result{Error: err}
+	}
+
+	return plugin.Result{Break: true}
+}
+
+// scheduleReplacement replaces the missing member in its VolumeGroup with an unscheduled volume, and schedules it by ScheduleVolumeGroup.
+// The status of VolumeGroup is reset to creating, so that the new volume is created by VolumeGroup reconciler.
+func (r *AntstorDataControlReconcileHandler) scheduleReplacement(ctx context.Context, dataControl *v1.AntstorDataControl, log logr.Logger) (result plugin.Result) {
+	var (
+		rebuild   = dataControl.Status.Rebuild
+		idx       int
+		volGroup  *v1.AntstorVolumeGroup
+		oldMember v1.VolumeMeta
+		newMember *v1.VolumeMeta
+		err       error
+	)
+
+	idx, volGroup, err = r.findMemberVolumeGroup(ctx, dataControl, rebuild.OldPV.VolId.UUID)
+	if err != nil {
+		log.Error(err, "finding VolumeGroup of missing member failed")
+		return plugin.Result{Error: err}
+	}
+
+	// the pool of missing member is offline, so it is filtered out by scheduler
+	oldMember = volGroup.Spec.Volumes[idx]
+	volGroup.Spec.Volumes[idx] = v1.VolumeMeta{
+		VolId: rebuild.NewVolId,
+	}
+	err = r.Scheduler.ScheduleVolumeGroup(r.State.GetAllNodes(), volGroup)
+	if err == nil {
+		newMember = findVolumeMeta(volGroup.Spec.Volumes, rebuild.NewVolId.UUID)
+		if newMember == nil || newMember.Size < oldMember.Size {
+			err = fmt.Errorf("no pool is available for replacement volume of size %d", oldMember.Size)
+		}
+	}
+	if err != nil {
+		log.Error(err, "scheduling replacement volume failed, retry in 1 min")
+		dataControl.Status.Rebuild.Message = err.Error()
+		if updateErr := r.Client.Status().Update(ctx, dataControl); updateErr != nil {
+			log.Error(updateErr, "updating DataControl status failed")
+		}
+		return plugin.Result{Break: true, Result: ctrl.Result{RequeueAfter: time.Minute}}
+	}
+
+	log.Info("scheduled replacement volume", "vol", rebuild.NewVolId.Name, "node", newMember.TargetNodeName, "size", newMember.Size)
+	err = r.Client.Update(ctx, volGroup)
+	if err != nil {
+		log.Error(err, "updating VolumeGroup failed")
+		return plugin.Result{Error: err}
+	}
+
+	volGroup.Status.Status = v1.VolumeStatusCreating
+	err = r.Client.Status().Update(ctx, volGroup)
+	if err != nil {
+		log.Error(err, "updating VolumeGroup status failed")
+		return plugin.Result{Error: err}
+	}
+
+	return plugin.Result{Break: true, Result: ctrl.Result{RequeueAfter: 20 * time.Second}}
+}
+
+// cleanupOldMember deletes the missing member after data is rebuilt on the replacement volume
+func (r *AntstorDataControlReconcileHandler) cleanupOldMember(ctx context.Context, dataControl *v1.AntstorDataControl, log logr.Logger) (result plugin.Result) {
+	var (
+		oldVolId = dataControl.Status.Rebuild.OldPV.VolId
+		vol      v1.AntstorVolume
+		err      error
+	)
+
+	err = r.Client.Get(ctx, client.ObjectKey{
+		Namespace: oldVolId.Namespace,
+		Name:      oldVolId.Name,
+	}, &vol)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "fetching old member failed")
+		return plugin.Result{Error: err}
+	}
+	if err == nil && vol.DeletionTimestamp == nil {
+		log.Info("deleting old member of raid", "vol", oldVolId.Name)
+		err = r.Client.Delete(ctx, &vol)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "deleting old member failed")
+			return plugin.Result{Error: err}
+		}
+	}
+
+	dataControl.Status.Rebuild.Phase = v1.RebuildPhaseFinished
+	dataControl.Status.Rebuild.Message = ""
+	err = r.Client.Status().Update(ctx, dataControl)
+	if err != nil {
+		log.Error(err, "updating DataControl status failed")
+		return plugin.Result{Error: err}
+	}
+
+	return plugin.Result{Break: true}
+}
+
+// findMemberVolumeGroup returns the VolumeGroup of datacontrol which has the volume, and index of the volume in it.
+// A NotFound error is returned if no VolumeGroup has the volume.
+func (r *AntstorDataControlReconcileHandler) findMemberVolumeGroup(ctx context.Context, dataControl *v1.AntstorDataControl, volUUID string) (idx int, volGroup *v1.AntstorVolumeGroup, err error) {
+	for _, item := range dataControl.Spec.VolumeGroups {
+		volGroup = &v1.AntstorVolumeGroup{}
+		err = r.Client.Get(ctx, client.ObjectKey{
+			Namespace: item.Namespace,
+			Name:      item.Name,
+		}, volGroup)
+		if err != nil {
+			return
+		}
+		for idx = range volGroup.Spec.Volumes {
+			if volGroup.Spec.Volumes[idx].VolId.UUID == volUUID {
+				return
+			}
+		}
+	}
+
+	err = errors.NewNotFound(v1.Resource("antstorvolume"), volUUID)
+	return -1, nil, err
+}
+
+func findVolumeMeta(list []v1.VolumeMeta, volUUID string) *v1.VolumeMeta {
+	for i := range list {
+		if list[i].VolId.UUID == volUUID {
+			return &list[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	sched "lite.io/liteio/pkg/controller/manager/scheduler"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeGroupScheduler schedules unscheduled volumes of VolumeGroup to the node
+type fakeGroupScheduler struct {
+	node string
+	err  error
+}
+
+func (s *fakeGroupScheduler) ScheduleVolume(allNodes []*state.Node, vol *v1.AntstorVolume) (node v1.NodeInfo, err error) {
+	err = fmt.Errorf("not implemented")
+	return
+}
+
+func (s *fakeGroupScheduler) ScheduleVolumeGroup(allNodes []*state.Node, volGroup *v1.AntstorVolumeGroup) (err error) {
+	if s.err != nil {
+		return s.err
+	}
+	for idx := range volGroup.Spec.Volumes {
+		if volGroup.Spec.Volumes[idx].TargetNodeName == "" {
+			volGroup.Spec.Volumes[idx].TargetNodeName = s.node
+			volGroup.Spec.Volumes[idx].Size = 1 << 30
+		}
+	}
+	return
+}
+
+func (s *fakeGroupScheduler) ExplainVolume(allNodes []*state.Node, vol *v1.AntstorVolume) (result sched.ExplainResult) {
+	return
+}
+
+func (s *fakeGroupScheduler) ExplainVolumeGroup(allNodes []*state.Node, volGroup *v1.AntstorVolumeGroup) (result sched.ExplainResult) {
+	return
+}
+
+func newRebuildMember(name string) v1.EntityIdentity {
+	return v1.EntityIdentity{Namespace: v1.DefaultNamespace, Name: name, UUID: "uuid-" + name}
+}
+
+func TestReconcileRebuild(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		member1  = newRebuildMember("vg-1-member-1")
+		member2  = newRebuildMember("vg-1-member-2")
+		volGroup = &v1.AntstorVolumeGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "vg-1"},
+			Spec: v1.AntstorVolumeGroupSpec{
+				Volumes: []v1.VolumeMeta{
+					{VolId: member1, TargetNodeName: "node-1", Size: 1 << 30},
+					{VolId: member2, TargetNodeName: "node-2", Size: 1 << 30},
+				},
+			},
+			Status: v1.AntstorVolumeGroupStatus{Status: v1.VolumeStatusReady},
+		}
+		oldVol = &v1.AntstorVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  v1.DefaultNamespace,
+				Name:       member1.Name,
+				Finalizers: []string{v1.InStateFinalizer},
+			},
+		}
+		dataControl = &v1.AntstorDataControl{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "dc-1"},
+			Spec: v1.AntstorDataControlSpec{
+				EngineType: v1.PoolModeKernelLVM,
+				Raid:       v1.Raid{Level: v1.Raid1},
+				HostNode:   v1.NodeInfo{ID: "node-host"},
+				LVM: &v1.LVMControl{
+					VG:   "vg-dc-1",
+					LVol: "lv-dc-1",
+					PVs: []v1.LVMControlPV{
+						{DevPath: "/dev/nvme0n1", VolId: member1, TargetInfo: v1.SpdkTarget{SerialNum: "sn-1"}},
+						{DevPath: "/dev/nvme1n1", VolId: member2, TargetInfo: v1.SpdkTarget{SerialNum: "sn-2"}},
+					},
+				},
+				VolumeGroups: []v1.EntityIdentity{{Namespace: v1.DefaultNamespace, Name: "vg-1"}},
+			},
+			Status: v1.AntstorDataControlStatus{
+				Status:     v1.VolumeStatusReady,
+				MissingPVs: []v1.EntityIdentity{member1},
+			},
+		}
+		sched = &fakeGroupScheduler{node: "node-3", err: fmt.Errorf("no pool is available")}
+	)
+	var r = &AntstorDataControlReconcileHandler{
+		Client:    newFakeClient(t, volGroup, oldVol, dataControl),
+		State:     state.NewState(),
+		Scheduler: sched,
+	}
+	getDataControl := func() *v1.AntstorDataControl {
+		var obj v1.AntstorDataControl
+		assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(dataControl), &obj))
+		return &obj
+	}
+	getVolGroup := func() *v1.AntstorVolumeGroup {
+		var obj v1.AntstorVolumeGroup
+		assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(volGroup), &obj))
+		return &obj
+	}
+
+	// datacontrol is not ready
+	notReady := dataControl.DeepCopy()
+	notReady.Status.Status = v1.VolumeStatusCreating
+	result := r.reconcileRebuild(ctx, notReady, logr.Discard())
+	assert.False(t, result.NeedBreak())
+
+	// missing member is recorded, and the replacement volume is allocated in its VolumeGroup
+	result = r.reconcileRebuild(ctx, getDataControl(), logr.Discard())
+	assert.True(t, result.NeedBreak())
+	assert.NoError(t, result.Error)
+	rebuild := getDataControl().Status.Rebuild
+	if !assert.NotNil(t, rebuild) {
+		return
+	}
+	assert.Equal(t, v1.RebuildPhaseReplacing, rebuild.Phase)
+	assert.Equal(t, member1, rebuild.OldPV.VolId)
+	assert.Equal(t, "/dev/nvme0n1", rebuild.OldPV.DevPath)
+	assert.Equal(t, v1.DefaultNamespace, rebuild.NewVolId.Namespace)
+	assert.Contains(t, rebuild.NewVolId.Name, "vg-1-")
+	var newVolId = rebuild.NewVolId
+
+	// no pool for replacement volume, VolumeGroup is not changed
+	result = r.reconcileRebuild(ctx, getDataControl(), logr.Discard())
+	assert.Equal(t, time.Minute, result.Result.RequeueAfter)
+	assert.Equal(t, "no pool is available", getDataControl().Status.Rebuild.Message)
+	assert.Equal(t, member1, getVolGroup().Spec.Volumes[0].VolId)
+
+	// replacement volume is scheduled, and created by VolumeGroup reconciler
+	sched.err = nil
+	result = r.reconcileRebuild(ctx, getDataControl(), logr.Discard())
+	assert.Equal(t, 20*time.Second, result.Result.RequeueAfter)
+	vg := getVolGroup()
+	assert.Equal(t, v1.VolumeMeta{VolId: newVolId, TargetNodeName: "node-3", Size: 1 << 30}, vg.Spec.Volumes[0])
+	assert.Equal(t, member2, vg.Spec.Volumes[1].VolId)
+	assert.Equal(t, v1.VolumeStatusCreating, vg.Status.Status)
+
+	// replacement volume is not created yet
+	result = r.reconcileRebuild(ctx, getDataControl(), logr.Discard())
+	assert.Equal(t, 20*time.Second, result.Result.RequeueAfter)
+	assert.Equal(t, v1.RebuildPhaseReplacing, getDataControl().Status.Rebuild.Phase)
+
+	// replacement volume is connected by host node of datacontrol
+	newVol := &v1.AntstorVolume{
+		ObjectMeta: metav1.ObjectMeta{Namespace: newVolId.Namespace, Name: newVolId.Name},
+		Spec:       v1.AntstorVolumeSpec{Uuid: newVolId.UUID, TargetNodeId: "node-3"},
+		Status:     v1.AntstorVolumeStatus{Status: v1.VolumeStatusCreating},
+	}
+	assert.NoError(t, r.Create(ctx, newVol))
+	result = r.reconcileRebuild(ctx, getDataControl(), logr.Discard())
+	assert.Equal(t, 20*time.Second, result.Result.RequeueAfter)
+	assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(newVol), newVol))
+	if assert.NotNil(t, newVol.Spec.HostNode) {
+		assert.Equal(t, "node-host", newVol.Spec.HostNode.ID)
+	}
+
+	// replacement volume is ready, the missing PV is replaced in spec
+	newVol.Spec.SpdkTarget = &v1.SpdkTarget{SubsysNQN: "nqn-new", SerialNum: "sn-new"}
+	newVol.Status.Status = v1.VolumeStatusReady
+	assert.NoError(t, r.Update(ctx, newVol))
+	result = r.reconcileRebuild(ctx, getDataControl(), logr.Discard())
+	assert.True(t, result.NeedBreak())
+	assert.NoError(t, result.Error)
+	dc := getDataControl()
+	assert.Equal(t, v1.RebuildPhaseRebuilding, dc.Status.Rebuild.Phase)
+	assert.Equal(t, v1.LVMControlPV{VolId: newVolId, TargetInfo: *newVol.Spec.SpdkTarget}, dc.Spec.LVM.PVs[0])
+	assert.Equal(t, member2, dc.Spec.LVM.PVs[1].VolId)
+
+	// agent is rebuilding data
+	result = r.reconcileRebuild(ctx, dc, logr.Discard())
+	assert.Equal(t, time.Minute, result.Result.RequeueAfter)
+
+	// data is rebuilt, old member is deleted
+	dc.Status.Rebuild.Phase = v1.RebuildPhaseSynced
+	dc.Status.MissingPVs = nil
+	assert.NoError(t, r.Status().Update(ctx, dc))
+	result = r.reconcileRebuild(ctx, getDataControl(), logr.Discard())
+	assert.True(t, result.NeedBreak())
+	assert.NoError(t, result.Error)
+	assert.Equal(t, v1.RebuildPhaseFinished, getDataControl().Status.Rebuild.Phase)
+	assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(oldVol), oldVol))
+	assert.NotNil(t, oldVol.DeletionTimestamp)
+
+	// finished rebuild of the old member is not started again
+	dc = getDataControl()
+	dc.Status.MissingPVs = []v1.EntityIdentity{member1}
+	assert.NoError(t, r.Status().Update(ctx, dc))
+	result = r.reconcileRebuild(ctx, getDataControl(), logr.Discard())
+	assert.False(t, result.NeedBreak())
+	assert.Equal(t, v1.RebuildPhaseFinished, getDataControl().Status.Rebuild.Phase)
+}
+
+func TestFindMemberVolumeGroup(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		member   = newRebuildMember("vg-1-member-1")
+		volGroup = &v1.AntstorVolumeGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "vg-1"},
+			Spec: v1.AntstorVolumeGroupSpec{
+				Volumes: []v1.VolumeMeta{{VolId: newRebuildMember("vg-1-member-0")}, {VolId: member}},
+			},
+		}
+		dataControl = &v1.AntstorDataControl{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "dc-1"},
+			Spec: v1.AntstorDataControlSpec{
+				VolumeGroups: []v1.EntityIdentity{{Namespace: v1.DefaultNamespace, Name: "vg-1"}},
+			},
+		}
+	)
+	var r = &AntstorDataControlReconcileHandler{
+		Client: newFakeClient(t, volGroup),
+	}
+
+	idx, vg, err := r.findMemberVolumeGroup(ctx, dataControl, member.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, idx)
+	assert.Equal(t, "vg-1", vg.Name)
+
+	// volume is not in any VolumeGroup
+	_, _, err = r.findMemberVolumeGroup(ctx, dataControl, "uuid-unknown")
+	assert.True(t, errors.IsNotFound(err))
+
+	// VolumeGroup is not fetched
+	dataControl.Spec.VolumeGroups = append([]v1.EntityIdentity{{Namespace: v1.DefaultNamespace, Name: "vg-bad"}}, dataControl.Spec.VolumeGroups...)
+	_, _, err = r.findMemberVolumeGroup(ctx, dataControl, member.UUID)
+	assert.Error(t, err)
+}
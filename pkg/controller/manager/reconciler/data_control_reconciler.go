@@ -10,11 +10,16 @@ import (
 
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
 	"lite.io/liteio/pkg/controller/manager/reconciler/plugin"
+	sched "lite.io/liteio/pkg/controller/manager/scheduler"
+	"lite.io/liteio/pkg/controller/manager/state"
 	"lite.io/liteio/pkg/util/misc"
 )
 
 type AntstorDataControlReconcileHandler struct {
 	client.Client
+
+	State     state.StateIface
+	Scheduler sched.SchedulerIface
 }
 
 func (r *AntstorDataControlReconcileHandler) ResourceName() string {
@@ -52,6 +57,12 @@ func (r *AntstorDataControlReconcileHandler) HandleReconcile(pCtx *plugin.Contex
 		return result
 	}
 
+	// replace missing members of raid
+	result = r.reconcileRebuild(ctx, dataControl, log)
+	if result.NeedBreak() {
+		return result
+	}
+
 	// sync volume group status
 
 	// wait all volumes are ready
@@ -0,0 +1,340 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/util"
+	"lite.io/liteio/pkg/util/misc"
+	"github.com/go-logr/logr"
+	uuid "github.com/satori/go.uuid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	GroupSnapshotFailure = "GroupSnapshotFailure"
+
+	// interval of checking member snapshots while IO is frozen
+	groupSnapshotPollInterval = 2 * time.Second
+)
+
+// VolumeGroupSnapshotReconciler creates member snapshots of AntstorVolumeGroupSnapshot while the agent on host node freezes IO.
+type VolumeGroupSnapshotReconciler struct {
+	client.Client
+	Log logr.Logger
+	// EventRecorder
+	EventRecorder record.EventRecorder
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VolumeGroupSnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1,
+		}).
+		For(&v1.AntstorVolumeGroupSnapshot{}).
+		Owns(&v1.AntstorSnapshot{}).
+		Complete(r)
+}
+
+func (r *VolumeGroupSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	var (
+		log = r.Log.WithValues("GroupSnapshot", req.NamespacedName)
+		obj v1.AntstorVolumeGroupSnapshot
+	)
+
+	if err = r.Get(ctx, req.NamespacedName, &obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	log.Info("start handling GroupSnapshot", "phase", obj.Status.Phase)
+
+	if obj.DeletionTimestamp != nil {
+		return r.handleDeletion(ctx, log, &obj)
+	}
+
+	switch obj.Status.Phase {
+	case v1.GroupSnapshotPending:
+		return r.startFreezing(ctx, log, &obj)
+	case v1.GroupSnapshotSnapshotting:
+		return r.syncMemberSnapshots(ctx, log, &obj)
+	case v1.GroupSnapshotFailed:
+		// partial member snapshots are useless
+		err = r.deleteMemberSnapshots(ctx, &obj)
+		return
+	}
+
+	// Freezing and Thawing are handled by agent
+	return
+}
+
+// startFreezing validates DataControl and requests agent on host node to freeze IO
+func (r *VolumeGroupSnapshotReconciler) startFreezing(ctx context.Context, log logr.Logger, obj *v1.AntstorVolumeGroupSnapshot) (result ctrl.Result, err error) {
+	var dc v1.AntstorDataControl
+	err = r.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Spec.DataControlName}, &dc)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, r.setFailed(ctx, obj, fmt.Sprintf("DataControl %s not found", obj.Spec.DataControlName))
+		}
+		log.Error(err, "get DataControl failed")
+		return
+	}
+
+	if dc.Spec.EngineType != v1.PoolModeKernelLVM || dc.Spec.LVM == nil || dc.Spec.LVM.LVol == "" {
+		return ctrl.Result{}, r.setFailed(ctx, obj, "only DataControl of LVM engine supports group snapshot")
+	}
+	if dc.Status.Status != v1.VolumeStatusReady {
+		log.Info("DataControl is not ready, retry in 20 sec", "status", dc.Status.Status)
+		return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
+	}
+
+	members, msg, err := r.getMemberVolumes(ctx, &dc)
+	if err != nil {
+		log.Error(err, "get member volumes failed")
+		return
+	}
+	if msg == "" {
+		for _, vol := range members {
+			if _, msg = groupMemberSnapshotSize(obj, vol); msg != "" {
+				break
+			}
+		}
+	}
+	if msg != "" {
+		return ctrl.Result{}, r.setFailed(ctx, obj, msg)
+	}
+
+	// agent on host node watches the group snapshot by label
+	if obj.Labels[v1.TargetNodeIdLabelKey] != dc.Spec.TargetNodeId || !misc.InSliceString(v1.GroupSnapshotFinalizer, obj.Finalizers) {
+		if obj.Labels == nil {
+			obj.Labels = make(map[string]string)
+		}
+		obj.Labels[v1.TargetNodeIdLabelKey] = dc.Spec.TargetNodeId
+		obj.Labels[v1.DataControlNameKey] = dc.Name
+		if obj.Spec.Uuid == "" {
+			obj.Spec.Uuid = uuid.NewV4().String()
+		}
+		obj.Labels[v1.GroupSnapshotUuidLabelKey] = obj.Spec.Uuid
+		controllerutil.AddFinalizer(obj, v1.GroupSnapshotFinalizer)
+		err = r.Update(ctx, obj)
+		return
+	}
+
+	log.Info("request freezing IO of DataControl", "hostNode", dc.Spec.TargetNodeId, "members", len(members))
+	obj.Status.Phase = v1.GroupSnapshotFreezing
+	obj.Status.HostNodeId = dc.Spec.TargetNodeId
+	err = r.Status().Update(ctx, obj)
+	return
+}
+
+// syncMemberSnapshots creates a snapshot for each member volume, and requests thawing when all of them are ready or any fails
+func (r *VolumeGroupSnapshotReconciler) syncMemberSnapshots(ctx context.Context, log logr.Logger, obj *v1.AntstorVolumeGroupSnapshot) (result ctrl.Result, err error) {
+	var (
+		dc      v1.AntstorDataControl
+		members []v1.AntstorVolume
+		msg     string
+		allDone = true
+		timeout = time.Duration(obj.Spec.FreezeTimeoutSeconds) * time.Second
+	)
+	if timeout <= 0 {
+		timeout = v1.DefaultFreezeTimeoutSeconds * time.Second
+	}
+
+	if obj.Status.FreezeTime != nil && time.Since(obj.Status.FreezeTime.Time) > timeout {
+		return ctrl.Result{}, r.setThawing(ctx, obj, fmt.Sprintf("member snapshots are not ready in %s", timeout))
+	}
+
+	err = r.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Spec.DataControlName}, &dc)
+	if err == nil {
+		members, msg, err = r.getMemberVolumes(ctx, &dc)
+	}
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, r.setThawing(ctx, obj, err.Error())
+		}
+		log.Error(err, "get member volumes failed")
+		return
+	}
+	if msg != "" {
+		return ctrl.Result{}, r.setThawing(ctx, obj, msg)
+	}
+
+	var snapshots = make([]v1.EntityIdentity, 0, len(members))
+	for idx, vol := range members {
+		var snap v1.AntstorSnapshot
+		snap, err = r.ensureMemberSnapshot(ctx, obj, idx, vol)
+		if err != nil {
+			log.Error(err, "create member snapshot failed", "volume", vol.Name)
+			return
+		}
+		snapshots = append(snapshots, v1.EntityIdentity{Namespace: snap.Namespace, Name: snap.Name, UUID: snap.Spec.Uuid})
+
+		switch snap.Status.Status {
+		case v1.SnapshotStatusReady:
+		case v1.SnapshotStatusError:
+			return ctrl.Result{}, r.setThawing(ctx, obj, fmt.Sprintf("snapshot %s of volume %s failed, %s", snap.Name, vol.Name, snap.Status.Message))
+		default:
+			allDone = false
+		}
+	}
+
+	obj.Status.Snapshots = snapshots
+	if allDone {
+		log.Info("all member snapshots are ready, request thawing")
+		var now = metav1.Now()
+		obj.Status.ReadyTime = &now
+		return ctrl.Result{}, r.setThawing(ctx, obj, "")
+	}
+
+	err = r.Status().Update(ctx, obj)
+	return ctrl.Result{RequeueAfter: groupSnapshotPollInterval}, err
+}
+
+// ensureMemberSnapshot creates the snapshot of the idx-th member volume if it does not exist
+func (r *VolumeGroupSnapshotReconciler) ensureMemberSnapshot(ctx context.Context, obj *v1.AntstorVolumeGroupSnapshot, idx int, vol v1.AntstorVolume) (snap v1.AntstorSnapshot, err error) {
+	var key = types.NamespacedName{Namespace: obj.Namespace, Name: fmt.Sprintf("%s-%d", obj.Name, idx)}
+	err = r.Get(ctx, key, &snap)
+	if err == nil || !errors.IsNotFound(err) {
+		return
+	}
+
+	size, _ := groupMemberSnapshotSize(obj, vol)
+	snap = v1.AntstorSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels: map[string]string{
+				v1.OriginVolumeNameLabelKey:      vol.Name,
+				v1.OriginVolumeNamespaceLabelKey: vol.Namespace,
+				v1.GroupSnapshotNameLabelKey:     obj.Name,
+			},
+		},
+		Spec: v1.AntstorSnapshotSpec{
+			Uuid:               uuid.NewV4().String(),
+			VolType:            vol.Spec.Type,
+			Size:               size,
+			OriginVolName:      vol.Name,
+			OriginVolNamespace: vol.Namespace,
+		},
+	}
+	snap.Labels[v1.SnapUuidLabelKey] = snap.Spec.Uuid
+	// member snapshots are garbage collected with the group snapshot
+	err = controllerutil.SetControllerReference(obj, &snap, r.Scheme())
+	if err != nil {
+		return
+	}
+
+	err = r.Create(ctx, &snap)
+	return
+}
+
+// getMemberVolumes returns all volumes of VolumeGroups of DataControl.
+// msg is not empty if the DataControl could not be snapshotted.
+func (r *VolumeGroupSnapshotReconciler) getMemberVolumes(ctx context.Context, dc *v1.AntstorDataControl) (vols []v1.AntstorVolume, msg string, err error) {
+	for _, item := range dc.Spec.VolumeGroups {
+		var volGroup v1.AntstorVolumeGroup
+		err = r.Get(ctx, types.NamespacedName{Namespace: item.Namespace, Name: item.Name}, &volGroup)
+		if err != nil {
+			return
+		}
+
+		for _, meta := range volGroup.Spec.Volumes {
+			var vol v1.AntstorVolume
+			err = r.Get(ctx, types.NamespacedName{Namespace: meta.VolId.Namespace, Name: meta.VolId.Name}, &vol)
+			if err != nil {
+				return
+			}
+			if vol.Status.Status != v1.VolumeStatusReady {
+				msg = fmt.Sprintf("member volume %s is not ready, status %s", vol.Name, vol.Status.Status)
+				return
+			}
+			vols = append(vols, vol)
+		}
+	}
+
+	if len(vols) == 0 {
+		msg = "DataControl has no member volume"
+	}
+	return
+}
+
+// groupMemberSnapshotSize returns the snapshot size of member volume. msg is not empty if the size is invalid.
+func groupMemberSnapshotSize(obj *v1.AntstorVolumeGroupSnapshot, vol v1.AntstorVolume) (size int64, msg string) {
+	reserved, err := strconv.Atoi(vol.Annotations[v1.SnapshotReservedSpaceAnnotationKey])
+	if err != nil {
+		return 0, fmt.Sprintf("member volume %s has no valid %s annotation", vol.Name, v1.SnapshotReservedSpaceAnnotationKey)
+	}
+
+	size = obj.Spec.SnapshotSize
+	if size == 0 {
+		size = int64(reserved)
+	}
+	size = size / util.FourMiB * util.FourMiB
+	if size < util.FourMiB || size > int64(reserved) {
+		return 0, fmt.Sprintf("invalid snapshot size %d of member volume %s, reserved size %d", size, vol.Name, reserved)
+	}
+	return
+}
+
+// handleDeletion waits for IO to be thawed before removing finalizer. Member snapshots are deleted by garbage collector.
+func (r *VolumeGroupSnapshotReconciler) handleDeletion(ctx context.Context, log logr.Logger, obj *v1.AntstorVolumeGroupSnapshot) (result ctrl.Result, err error) {
+	switch obj.Status.Phase {
+	case v1.GroupSnapshotFreezing, v1.GroupSnapshotSnapshotting:
+		log.Info("group snapshot is deleted while IO is frozen, request thawing")
+		return ctrl.Result{}, r.setThawing(ctx, obj, "group snapshot is deleted")
+	case v1.GroupSnapshotThawing:
+		log.Info("wait for agent to thaw IO")
+		return ctrl.Result{RequeueAfter: groupSnapshotPollInterval}, nil
+	}
+
+	if controllerutil.ContainsFinalizer(obj, v1.GroupSnapshotFinalizer) {
+		controllerutil.RemoveFinalizer(obj, v1.GroupSnapshotFinalizer)
+		err = r.Update(ctx, obj)
+	}
+	return
+}
+
+func (r *VolumeGroupSnapshotReconciler) deleteMemberSnapshots(ctx context.Context, obj *v1.AntstorVolumeGroupSnapshot) (err error) {
+	var list v1.AntstorSnapshotList
+	err = r.List(ctx, &list, client.InNamespace(obj.Namespace), client.MatchingLabels{v1.GroupSnapshotNameLabelKey: obj.Name})
+	if err != nil {
+		return
+	}
+	for idx := range list.Items {
+		if list.Items[idx].DeletionTimestamp == nil {
+			err = r.Delete(ctx, &list.Items[idx])
+			if err != nil && !errors.IsNotFound(err) {
+				return
+			}
+		}
+	}
+	return nil
+}
+
+// setFailed fails the group snapshot before IO is frozen
+func (r *VolumeGroupSnapshotReconciler) setFailed(ctx context.Context, obj *v1.AntstorVolumeGroupSnapshot, msg string) error {
+	r.EventRecorder.Event(obj, corev1.EventTypeWarning, GroupSnapshotFailure, msg)
+	obj.Status.Phase = v1.GroupSnapshotFailed
+	obj.Status.Message = msg
+	return r.Status().Update(ctx, obj)
+}
+
+// setThawing requests agent to thaw IO. Non-empty msg means the group snapshot is failed.
+func (r *VolumeGroupSnapshotReconciler) setThawing(ctx context.Context, obj *v1.AntstorVolumeGroupSnapshot, msg string) error {
+	if msg != "" {
+		r.EventRecorder.Event(obj, corev1.EventTypeWarning, GroupSnapshotFailure, msg)
+	}
+	obj.Status.Phase = v1.GroupSnapshotThawing
+	obj.Status.Message = msg
+	return r.Status().Update(ctx, obj)
+}
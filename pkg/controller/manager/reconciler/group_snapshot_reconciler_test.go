@@ -0,0 +1,216 @@
+package reconciler
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newGroupSnapshotTestObjects() []client.Object {
+	var objs = []client.Object{
+		&v1.AntstorDataControl{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "dc-1"},
+			Spec: v1.AntstorDataControlSpec{
+				EngineType:   v1.PoolModeKernelLVM,
+				TargetNodeId: "node-1",
+				LVM:          &v1.LVMControl{VG: "vg-1", LVol: "lv-1"},
+				VolumeGroups: []v1.EntityIdentity{{Namespace: v1.DefaultNamespace, Name: "vg-1"}},
+			},
+			Status: v1.AntstorDataControlStatus{Status: v1.VolumeStatusReady},
+		},
+		&v1.AntstorVolumeGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "vg-1"},
+			Spec: v1.AntstorVolumeGroupSpec{
+				Volumes: []v1.VolumeMeta{
+					{VolId: v1.EntityIdentity{Namespace: v1.DefaultNamespace, Name: "vol-1"}},
+					{VolId: v1.EntityIdentity{Namespace: v1.DefaultNamespace, Name: "vol-2"}},
+				},
+			},
+		},
+	}
+	for _, name := range []string{"vol-1", "vol-2"} {
+		objs = append(objs, &v1.AntstorVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: v1.DefaultNamespace,
+				Name:      name,
+				Annotations: map[string]string{
+					v1.SnapshotReservedSpaceAnnotationKey: strconv.Itoa(8 << 20),
+				},
+			},
+			Spec:   v1.AntstorVolumeSpec{Type: v1.VolumeTypeKernelLVol},
+			Status: v1.AntstorVolumeStatus{Status: v1.VolumeStatusReady},
+		})
+	}
+	return objs
+}
+
+func newTestGroupSnapshot(name string) *v1.AntstorVolumeGroupSnapshot {
+	return &v1.AntstorVolumeGroupSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: name},
+		Spec: v1.AntstorVolumeGroupSnapshotSpec{
+			DataControlName: "dc-1",
+		},
+	}
+}
+
+// newFakeClient returns a fake client of controller-runtime with the scheme of antstor and kubernetes objects
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	var scheme = runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func newGroupSnapshotReconciler(t *testing.T, objs ...client.Object) *VolumeGroupSnapshotReconciler {
+	return &VolumeGroupSnapshotReconciler{
+		Client:        newFakeClient(t, objs...),
+		Log:           logr.Discard(),
+		EventRecorder: record.NewFakeRecorder(10),
+	}
+}
+
+func TestGroupSnapshotPhases(t *testing.T) {
+	var (
+		ctx = context.Background()
+		r   = newGroupSnapshotReconciler(t, append(newGroupSnapshotTestObjects(), newTestGroupSnapshot("gs-1"))...)
+		key = types.NamespacedName{Namespace: v1.DefaultNamespace, Name: "gs-1"}
+		gs  v1.AntstorVolumeGroupSnapshot
+	)
+	reconcileGs := func() ctrl.Result {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		assert.NoError(t, err)
+		assert.NoError(t, r.Get(ctx, key, &gs))
+		return result
+	}
+
+	// Pending: label and finalizer are added, then agent on host node is requested to freeze IO
+	reconcileGs()
+	assert.Equal(t, v1.GroupSnapshotPending, gs.Status.Phase)
+	assert.Equal(t, "node-1", gs.Labels[v1.TargetNodeIdLabelKey])
+	assert.NotEmpty(t, gs.Spec.Uuid)
+	assert.Contains(t, gs.Finalizers, v1.GroupSnapshotFinalizer)
+	reconcileGs()
+	assert.Equal(t, v1.GroupSnapshotFreezing, gs.Status.Phase)
+	assert.Equal(t, "node-1", gs.Status.HostNodeId)
+
+	// Freezing is handled by agent
+	reconcileGs()
+	assert.Equal(t, v1.GroupSnapshotFreezing, gs.Status.Phase)
+
+	// Snapshotting: member snapshots are created
+	var now = metav1.Now()
+	gs.Status.Phase = v1.GroupSnapshotSnapshotting
+	gs.Status.FreezeTime = &now
+	assert.NoError(t, r.Status().Update(ctx, &gs))
+	result := reconcileGs()
+	assert.Equal(t, groupSnapshotPollInterval, result.RequeueAfter)
+	assert.Equal(t, v1.GroupSnapshotSnapshotting, gs.Status.Phase)
+	var snaps v1.AntstorSnapshotList
+	assert.NoError(t, r.List(ctx, &snaps, client.MatchingLabels{v1.GroupSnapshotNameLabelKey: "gs-1"}))
+	if assert.Len(t, snaps.Items, 2) && assert.Len(t, gs.Status.Snapshots, 2) {
+		for idx, snap := range snaps.Items {
+			assert.Equal(t, int64(8<<20), snap.Spec.Size)
+			assert.Equal(t, "gs-1", snap.OwnerReferences[0].Name)
+			assert.Equal(t, snap.Spec.Uuid, gs.Status.Snapshots[idx].UUID)
+		}
+	}
+
+	// Thawing is requested when all member snapshots are ready
+	for idx := range snaps.Items {
+		snaps.Items[idx].Status.Status = v1.SnapshotStatusReady
+		assert.NoError(t, r.Status().Update(ctx, &snaps.Items[idx]))
+	}
+	reconcileGs()
+	assert.Equal(t, v1.GroupSnapshotThawing, gs.Status.Phase)
+	assert.Empty(t, gs.Status.Message)
+	assert.NotNil(t, gs.Status.ReadyTime)
+
+	// Failed: member snapshots are deleted
+	gs.Status.Phase = v1.GroupSnapshotFailed
+	assert.NoError(t, r.Status().Update(ctx, &gs))
+	reconcileGs()
+	assert.NoError(t, r.List(ctx, &snaps, client.MatchingLabels{v1.GroupSnapshotNameLabelKey: "gs-1"}))
+	assert.Empty(t, snaps.Items)
+}
+
+func TestGroupSnapshotFailures(t *testing.T) {
+	var (
+		ctx = context.Background()
+		r   = newGroupSnapshotReconciler(t, append(newGroupSnapshotTestObjects(),
+			newTestGroupSnapshot("gs-timeout"), newTestGroupSnapshot("gs-error"), newTestGroupSnapshot("gs-deleted"))...)
+		gs v1.AntstorVolumeGroupSnapshot
+	)
+	reconcileGs := func(name string) {
+		key := types.NamespacedName{Namespace: v1.DefaultNamespace, Name: name}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		assert.NoError(t, err)
+		assert.NoError(t, r.Get(ctx, key, &gs))
+	}
+	startSnapshotting := func(name string, freezeTime time.Time) {
+		reconcileGs(name)
+		reconcileGs(name)
+		assert.Equal(t, v1.GroupSnapshotFreezing, gs.Status.Phase)
+		var frozen = metav1.NewTime(freezeTime)
+		gs.Status.Phase = v1.GroupSnapshotSnapshotting
+		gs.Status.FreezeTime = &frozen
+		assert.NoError(t, r.Status().Update(ctx, &gs))
+	}
+
+	// member snapshots are not ready before timeout
+	startSnapshotting("gs-timeout", time.Now().Add(-2*v1.DefaultFreezeTimeoutSeconds*time.Second))
+	reconcileGs("gs-timeout")
+	assert.Equal(t, v1.GroupSnapshotThawing, gs.Status.Phase)
+	assert.Contains(t, gs.Status.Message, "not ready in")
+
+	// member snapshot is failed
+	startSnapshotting("gs-error", time.Now())
+	reconcileGs("gs-error")
+	var snap v1.AntstorSnapshot
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: v1.DefaultNamespace, Name: "gs-error-1"}, &snap))
+	snap.Status.Status = v1.SnapshotStatusError
+	snap.Status.Message = "no space"
+	assert.NoError(t, r.Status().Update(ctx, &snap))
+	reconcileGs("gs-error")
+	assert.Equal(t, v1.GroupSnapshotThawing, gs.Status.Phase)
+	assert.Contains(t, gs.Status.Message, "no space")
+
+	// deleted while IO is frozen: thawing is requested, and finalizer is kept until agent thaws IO
+	startSnapshotting("gs-deleted", time.Now())
+	assert.NoError(t, r.Delete(ctx, &gs))
+	reconcileGs("gs-deleted")
+	assert.Equal(t, v1.GroupSnapshotThawing, gs.Status.Phase)
+	assert.Contains(t, gs.Finalizers, v1.GroupSnapshotFinalizer)
+	reconcileGs("gs-deleted")
+	assert.Contains(t, gs.Finalizers, v1.GroupSnapshotFinalizer)
+	gs.Status.Phase = v1.GroupSnapshotFailed
+	assert.NoError(t, r.Status().Update(ctx, &gs))
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: v1.DefaultNamespace, Name: "gs-deleted"}})
+	assert.NoError(t, err)
+	// finalizer is removed, and the object is gone
+	err = r.Get(ctx, types.NamespacedName{Namespace: v1.DefaultNamespace, Name: "gs-deleted"}, &gs)
+	assert.True(t, errors.IsNotFound(err))
+
+	// DataControl of SPDK engine is not supported
+	var dc v1.AntstorDataControl
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: v1.DefaultNamespace, Name: "dc-1"}, &dc))
+	dc.Spec.EngineType = v1.PoolModeSpdkLVStore
+	assert.NoError(t, r.Update(ctx, &dc))
+	assert.NoError(t, r.Create(ctx, newTestGroupSnapshot("gs-spdk")))
+	reconcileGs("gs-spdk")
+	assert.Equal(t, v1.GroupSnapshotFailed, gs.Status.Phase)
+	assert.Contains(t, gs.Status.Message, "LVM engine")
+}
@@ -96,6 +96,14 @@ func (r *VolumeMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, nil
 	}
 
+	// data of replicated volume is mirrored by RAID1 bdev over the lvol, which is not migrated
+	if srcVol.IsReplicated() {
+		migration.Status.Message = "invalid source volume: replicated volume is not supported"
+		migration.Status.Status = v1.MigrationStatusError
+		log.Info("update migration status", "err", r.Status().Update(ctx, &migration))
+		return ctrl.Result{}, nil
+	}
+
 	if srcVol.Status.Status != v1.VolumeStatusReady {
 		migration.Status.Message = fmt.Sprintf("invalid source volume: status not ready %+v", srcVol.Status)
 		log.Info("update migration status", "err", r.Status().Update(ctx, &migration))
@@ -189,6 +197,12 @@ func (r *VolumeMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		destVolume.Labels[v1.MigrationLabelKeyMigrationName] = migration.Name
 		// dest volume cannot reside on the same node of src volume, b/c dest subsystem should have same NQN, NSUUID of src subsystem.
 		destVolume.Annotations[v1.PoolLabelSelectorKey] = fmt.Sprintf("%s!=%s", v1.PoolLabelsNodeSnKey, srcVol.Spec.TargetNodeId)
+		// selected-tgt-node of src volume is the source node, which must not be inherited
+		delete(destVolume.Annotations, v1.SelectedTgtNodeKey)
+		// dest pool is chosen by rebalancer. Scheduler still checks the pool by filters.
+		if destNodeId := migration.Annotations[v1.MigrationAnnoKeyDestNodeId]; destNodeId != "" {
+			destVolume.Annotations[v1.PoolLabelSelectorKey] += fmt.Sprintf(",%s=%s", v1.PoolLabelsNodeSnKey, destNodeId)
+		}
 
 		log.Info("creating dest volume", "name", destVolume.Name)
 		err = r.Create(ctx, &destVolume)
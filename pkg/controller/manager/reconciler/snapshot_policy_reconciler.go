@@ -0,0 +1,345 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/util"
+	"lite.io/liteio/pkg/util/cron"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	uuid "github.com/satori/go.uuid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	SnapshotPolicyInvalid      = "SnapshotPolicyInvalid"
+	SnapshotPolicyMissedRun    = "SnapshotPolicyMissedRun"
+	SnapshotPolicyFailedRun    = "SnapshotPolicyFailedRun"
+	SnapshotPolicySkipVolume   = "SnapshotPolicySkipVolume"
+	SnapshotPolicyPruneFailure = "SnapshotPolicyPruneFailure"
+
+	snapshotPolicyMetricSubsystem = "snapshot_policy"
+
+	// max number of scheduled times to check since the last run, in case the controller is down for a long time
+	maxScheduleIterations = 100000
+)
+
+var (
+	// runs of SnapshotPolicy. result is one of success, failed and missed
+	snapshotPolicyRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: snapshotPolicyMetricSubsystem,
+		Name:      "runs_total",
+		Help:      "Number of scheduled runs of SnapshotPolicy",
+	}, []string{"namespace", "policy", "result"})
+
+	// snapshots handled by SnapshotPolicy. result is one of created, skipped, failed and pruned
+	snapshotPolicySnapshots = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: snapshotPolicyMetricSubsystem,
+		Name:      "snapshots_total",
+		Help:      "Number of snapshots created, skipped, failed or pruned by SnapshotPolicy",
+	}, []string{"namespace", "policy", "result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(snapshotPolicyRuns, snapshotPolicySnapshots)
+}
+
+// SnapshotPolicyReconciler creates AntstorSnapshots of selected volumes on schedule and enforces retention.
+type SnapshotPolicyReconciler struct {
+	client.Client
+	Log logr.Logger
+	// EventRecorder
+	EventRecorder record.EventRecorder
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SnapshotPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1,
+		}).
+		For(&v1.SnapshotPolicy{}).
+		Complete(r)
+}
+
+func (r *SnapshotPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	var (
+		log = r.Log.WithValues("SnapshotPolicy", req.NamespacedName)
+		obj v1.SnapshotPolicy
+		now = time.Now()
+	)
+
+	if err = r.Get(ctx, req.NamespacedName, &obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// snapshots are kept after the policy is deleted
+	if obj.DeletionTimestamp != nil {
+		return
+	}
+
+	sched, err := cron.Parse(obj.Spec.Schedule)
+	if err == nil && obj.Spec.VolumeSelector == nil {
+		err = fmt.Errorf("volumeSelector is empty")
+	}
+	if err != nil {
+		log.Error(err, "invalid SnapshotPolicy")
+		r.EventRecorder.Event(&obj, corev1.EventTypeWarning, SnapshotPolicyInvalid, err.Error())
+		obj.Status.Message = err.Error()
+		obj.Status.NextScheduleTime = nil
+		return ctrl.Result{}, r.Status().Update(ctx, &obj)
+	}
+
+	// enforce retention before creating new snapshots, so that the reserved space is released
+	r.pruneSnapshots(ctx, log, &obj, now)
+
+	// find the latest scheduled time which is due
+	var (
+		last      = obj.CreationTimestamp.Time
+		scheduled time.Time
+		missed    int
+	)
+	if obj.Status.LastScheduleTime != nil {
+		last = obj.Status.LastScheduleTime.Time
+	}
+	for i, t := 0, sched.Next(last); i < maxScheduleIterations && !t.IsZero() && !t.After(now); i, t = i+1, sched.Next(t) {
+		if !scheduled.IsZero() {
+			missed++
+		}
+		scheduled = t
+	}
+
+	if !scheduled.IsZero() {
+		obj.Status.LastScheduleTime = &metav1.Time{Time: scheduled}
+
+		var deadline time.Duration
+		if obj.Spec.StartingDeadlineSeconds != nil {
+			deadline = time.Duration(*obj.Spec.StartingDeadlineSeconds) * time.Second
+		}
+		if !obj.Spec.Suspend && deadline > 0 && now.Sub(scheduled) > deadline {
+			missed++
+		}
+
+		switch {
+		case obj.Spec.Suspend:
+			log.Info("policy is suspended, skip the run", "scheduleTime", scheduled)
+			missed = 0
+		case deadline > 0 && now.Sub(scheduled) > deadline:
+			log.Info("scheduled run is too late to start", "scheduleTime", scheduled, "deadline", deadline)
+		default:
+			r.runPolicy(ctx, log, &obj, scheduled)
+		}
+
+		if missed > 0 {
+			obj.Status.MissedRuns += missed
+			snapshotPolicyRuns.WithLabelValues(obj.Namespace, obj.Name, "missed").Add(float64(missed))
+			r.EventRecorder.Event(&obj, corev1.EventTypeWarning, SnapshotPolicyMissedRun,
+				fmt.Sprintf("missed %d runs, last schedule time %s", missed, scheduled.Format(time.RFC3339)))
+		}
+	}
+
+	if next := sched.Next(now); !next.IsZero() {
+		obj.Status.NextScheduleTime = &metav1.Time{Time: next}
+		result.RequeueAfter = next.Sub(now)
+	}
+
+	err = r.Status().Update(ctx, &obj)
+	return
+}
+
+// runPolicy creates a snapshot for each selected volume
+func (r *SnapshotPolicyReconciler) runPolicy(ctx context.Context, log logr.Logger, obj *v1.SnapshotPolicy, scheduled time.Time) {
+	var (
+		volList v1.AntstorVolumeList
+		skipped []string
+		failed  int
+	)
+
+	selector, err := metav1.LabelSelectorAsSelector(obj.Spec.VolumeSelector)
+	if err == nil {
+		err = r.List(ctx, &volList, client.InNamespace(obj.Namespace), client.MatchingLabelsSelector{Selector: selector})
+	}
+	if err != nil {
+		log.Error(err, "list volumes failed")
+		obj.Status.Message = err.Error()
+		snapshotPolicyRuns.WithLabelValues(obj.Namespace, obj.Name, "failed").Inc()
+		r.EventRecorder.Event(obj, corev1.EventTypeWarning, SnapshotPolicyFailedRun, err.Error())
+		return
+	}
+
+	log.Info("run SnapshotPolicy", "scheduleTime", scheduled, "volumes", len(volList.Items))
+	for _, vol := range volList.Items {
+		size, msg := policySnapshotSize(obj, &vol)
+		if msg == "" && (vol.DeletionTimestamp != nil || vol.Status.Status != v1.VolumeStatusReady) {
+			msg = fmt.Sprintf("volume %s is not ready", vol.Name)
+		}
+		if msg != "" {
+			skipped = append(skipped, vol.Name)
+			snapshotPolicySnapshots.WithLabelValues(obj.Namespace, obj.Name, "skipped").Inc()
+			r.EventRecorder.Event(obj, corev1.EventTypeWarning, SnapshotPolicySkipVolume, msg)
+			continue
+		}
+
+		if err = r.createPolicySnapshot(ctx, obj, &vol, size, scheduled); err != nil {
+			log.Error(err, "create snapshot failed", "volume", vol.Name)
+			failed++
+			snapshotPolicySnapshots.WithLabelValues(obj.Namespace, obj.Name, "failed").Inc()
+			r.EventRecorder.Event(obj, corev1.EventTypeWarning, SnapshotPolicyFailedRun, fmt.Sprintf("create snapshot of volume %s failed, %s", vol.Name, err.Error()))
+			continue
+		}
+		snapshotPolicySnapshots.WithLabelValues(obj.Namespace, obj.Name, "created").Inc()
+	}
+
+	obj.Status.SelectedVolumes = len(volList.Items)
+	obj.Status.SkippedVolumes = skipped
+	if failed > 0 {
+		obj.Status.Message = fmt.Sprintf("failed to create snapshots of %d volumes", failed)
+		snapshotPolicyRuns.WithLabelValues(obj.Namespace, obj.Name, "failed").Inc()
+		return
+	}
+
+	obj.Status.Message = ""
+	obj.Status.LastSuccessfulTime = &metav1.Time{Time: scheduled}
+	snapshotPolicyRuns.WithLabelValues(obj.Namespace, obj.Name, "success").Inc()
+}
+
+// createPolicySnapshot creates the snapshot of the volume for the scheduled run. Name of the snapshot is unique for each run.
+func (r *SnapshotPolicyReconciler) createPolicySnapshot(ctx context.Context, obj *v1.SnapshotPolicy, vol *v1.AntstorVolume, size int64, scheduled time.Time) (err error) {
+	var snapUuid = uuid.NewV4().String()
+	var snap = v1.AntstorSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: vol.Namespace,
+			Name:      fmt.Sprintf("%s-%s-%s", vol.Name, obj.Name, scheduled.UTC().Format("20060102-1504")),
+			Labels: map[string]string{
+				v1.OriginVolumeNameLabelKey:      vol.Name,
+				v1.OriginVolumeNamespaceLabelKey: vol.Namespace,
+				v1.SnapshotPolicyNameLabelKey:    obj.Name,
+				v1.SnapUuidLabelKey:              snapUuid,
+			},
+			Annotations: map[string]string{
+				v1.SnapshotScheduleTimeAnnoKey: scheduled.Format(time.RFC3339),
+			},
+		},
+		Spec: v1.AntstorSnapshotSpec{
+			Uuid:               snapUuid,
+			VolType:            vol.Spec.Type,
+			Size:               size,
+			OriginVolName:      vol.Name,
+			OriginVolNamespace: vol.Namespace,
+		},
+	}
+
+	err = r.Create(ctx, &snap)
+	if errors.IsAlreadyExists(err) {
+		return nil
+	}
+	return
+}
+
+// pruneSnapshots deletes failed snapshots, and snapshots exceeding MaxCount or MaxAge of each volume
+func (r *SnapshotPolicyReconciler) pruneSnapshots(ctx context.Context, log logr.Logger, obj *v1.SnapshotPolicy, now time.Time) {
+	var (
+		snapList v1.AntstorSnapshotList
+		byVolume = make(map[string][]*v1.AntstorSnapshot)
+		maxCount = obj.Spec.Retention.GetMaxCount()
+	)
+
+	err := r.List(ctx, &snapList, client.InNamespace(obj.Namespace), client.MatchingLabels{v1.SnapshotPolicyNameLabelKey: obj.Name})
+	if err != nil {
+		log.Error(err, "list snapshots failed")
+		return
+	}
+	for i := range snapList.Items {
+		snap := &snapList.Items[i]
+		if snap.DeletionTimestamp != nil || snap.Status.Status == v1.SnapshotStatusMerged {
+			continue
+		}
+		byVolume[snap.Spec.OriginVolName] = append(byVolume[snap.Spec.OriginVolName], snap)
+	}
+
+	for _, snaps := range byVolume {
+		// newest first
+		sort.Slice(snaps, func(i, j int) bool {
+			return snaps[j].CreationTimestamp.Before(&snaps[i].CreationTimestamp)
+		})
+
+		var kept int
+		for _, snap := range snaps {
+			var reason string
+			switch {
+			case snap.Status.Status == v1.SnapshotStatusError:
+				reason = "snapshot failed"
+				if err = r.recordSnapshotFailure(ctx, obj, snap); err != nil {
+					log.Error(err, "record snapshot failure failed", "name", snap.Name)
+					continue
+				}
+			case obj.Spec.Retention.MaxAge != nil && now.Sub(snap.CreationTimestamp.Time) > obj.Spec.Retention.MaxAge.Duration:
+				reason = "exceeding max age"
+			case kept >= maxCount:
+				reason = "exceeding max count"
+			default:
+				kept++
+				continue
+			}
+
+			log.Info("delete snapshot", "name", snap.Name, "reason", reason)
+			if err = r.Delete(ctx, snap); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "delete snapshot failed", "name", snap.Name)
+				r.EventRecorder.Event(obj, corev1.EventTypeWarning, SnapshotPolicyPruneFailure, fmt.Sprintf("delete snapshot %s failed, %s", snap.Name, err.Error()))
+				continue
+			}
+			snapshotPolicySnapshots.WithLabelValues(obj.Namespace, obj.Name, "pruned").Inc()
+		}
+	}
+}
+
+// recordSnapshotFailure counts the failed snapshot and emits an event, only once for each snapshot.
+// The snapshot is annotated before counting, so that a failed deletion does not count it again.
+func (r *SnapshotPolicyReconciler) recordSnapshotFailure(ctx context.Context, obj *v1.SnapshotPolicy, snap *v1.AntstorSnapshot) (err error) {
+	if snap.Annotations[v1.SnapshotPolicyFailureRecordedAnnoKey] == "true" {
+		return
+	}
+
+	if snap.Annotations == nil {
+		snap.Annotations = make(map[string]string)
+	}
+	snap.Annotations[v1.SnapshotPolicyFailureRecordedAnnoKey] = "true"
+	if err = r.Update(ctx, snap); err != nil {
+		return
+	}
+
+	snapshotPolicySnapshots.WithLabelValues(obj.Namespace, obj.Name, "failed").Inc()
+	r.EventRecorder.Event(obj, corev1.EventTypeWarning, SnapshotPolicyFailedRun,
+		fmt.Sprintf("snapshot %s of volume %s failed, %s", snap.Name, snap.Spec.OriginVolName, snap.Status.Message))
+	return
+}
+
+// policySnapshotSize returns size of the snapshot. A message is returned if the volume cannot be snapshotted.
+func policySnapshotSize(obj *v1.SnapshotPolicy, vol *v1.AntstorVolume) (size int64, msg string) {
+	reserved, err := strconv.ParseInt(vol.Annotations[v1.SnapshotReservedSpaceAnnotationKey], 10, 64)
+	if err != nil || reserved <= 0 {
+		return 0, fmt.Sprintf("volume %s has no reserved snapshot space in annotation %s", vol.Name, v1.SnapshotReservedSpaceAnnotationKey)
+	}
+
+	size = obj.Spec.SnapshotSize
+	if size == 0 {
+		size = reserved / int64(obj.Spec.Retention.GetMaxCount())
+	}
+	size = size / util.FourMiB * util.FourMiB
+
+	if size < util.FourMiB || size > reserved {
+		return 0, fmt.Sprintf("snapshot size %d is invalid for volume %s, reserved snapshot space is %d", size, vol.Name, reserved)
+	}
+	return
+}
@@ -0,0 +1,162 @@
+package reconciler
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newSnapshotPolicyReconciler(t *testing.T, objs ...client.Object) (*SnapshotPolicyReconciler, *record.FakeRecorder) {
+	var recorder = record.NewFakeRecorder(100)
+	return &SnapshotPolicyReconciler{
+		Client:        newFakeClient(t, objs...),
+		Log:           logr.Discard(),
+		EventRecorder: recorder,
+	}, recorder
+}
+
+func newTestSnapshotPolicy(name string, retention v1.SnapshotRetention) *v1.SnapshotPolicy {
+	return &v1.SnapshotPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: name},
+		Spec: v1.SnapshotPolicySpec{
+			Schedule:       "0 * * * *",
+			VolumeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Retention:      retention,
+		},
+	}
+}
+
+func newPolicySnapshot(policy, name, volName string, created time.Time, status v1.SnapshotStatusName) *v1.AntstorSnapshot {
+	snap := newTestSnapshot(name, volName, created, status)
+	snap.Labels[v1.SnapshotPolicyNameLabelKey] = policy
+	// finalizer is removed by agent, so the deleted snapshot is kept in the fake client
+	snap.Finalizers = []string{v1.SnapshotFinalizer}
+	return snap
+}
+
+func TestSnapshotPolicyRunPolicy(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		scheduled = time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+		policy    = newTestSnapshotPolicy("policy-1", v1.SnapshotRetention{MaxCount: 4})
+		newVol    = func(name string, labels map[string]string, reserved int64, status v1.VolumeStatus) *v1.AntstorVolume {
+			vol := &v1.AntstorVolume{
+				ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: name, Labels: labels},
+				Spec:       v1.AntstorVolumeSpec{Type: v1.VolumeTypeKernelLVol},
+				Status:     v1.AntstorVolumeStatus{Status: status},
+			}
+			if reserved > 0 {
+				vol.Annotations = map[string]string{v1.SnapshotReservedSpaceAnnotationKey: strconv.FormatInt(reserved, 10)}
+			}
+			return vol
+		}
+		db = map[string]string{"app": "db"}
+		r  *SnapshotPolicyReconciler
+	)
+	r, _ = newSnapshotPolicyReconciler(t, policy,
+		newVol("vol-ready", db, 64<<20, v1.VolumeStatusReady),
+		newVol("vol-no-reserved", db, 0, v1.VolumeStatusReady),
+		newVol("vol-creating", db, 64<<20, v1.VolumeStatusCreating),
+		newVol("vol-other", nil, 64<<20, v1.VolumeStatusReady))
+
+	// snapshot is created only for the selected volume which is ready and has reserved space
+	r.runPolicy(ctx, logr.Discard(), policy, scheduled)
+	var snaps v1.AntstorSnapshotList
+	assert.NoError(t, r.List(ctx, &snaps, client.MatchingLabels{v1.SnapshotPolicyNameLabelKey: "policy-1"}))
+	if assert.Len(t, snaps.Items, 1) {
+		snap := snaps.Items[0]
+		assert.Equal(t, "vol-ready-policy-1-20261017-1000", snap.Name)
+		assert.Equal(t, int64(16<<20), snap.Spec.Size)
+		assert.Equal(t, "vol-ready", snap.Spec.OriginVolName)
+		assert.Equal(t, "vol-ready", snap.Labels[v1.OriginVolumeNameLabelKey])
+		assert.Equal(t, snap.Spec.Uuid, snap.Labels[v1.SnapUuidLabelKey])
+		assert.Equal(t, scheduled.Format(time.RFC3339), snap.Annotations[v1.SnapshotScheduleTimeAnnoKey])
+	}
+	assert.Equal(t, 3, policy.Status.SelectedVolumes)
+	assert.ElementsMatch(t, []string{"vol-no-reserved", "vol-creating"}, policy.Status.SkippedVolumes)
+	assert.Empty(t, policy.Status.Message)
+	if assert.NotNil(t, policy.Status.LastSuccessfulTime) {
+		assert.True(t, scheduled.Equal(policy.Status.LastSuccessfulTime.Time))
+	}
+
+	// the same run is idempotent
+	r.runPolicy(ctx, logr.Discard(), policy, scheduled)
+	assert.NoError(t, r.List(ctx, &snaps, client.MatchingLabels{v1.SnapshotPolicyNameLabelKey: "policy-1"}))
+	assert.Len(t, snaps.Items, 1)
+
+	// invalid snapshot size
+	policy.Spec.SnapshotSize = 128 << 20
+	r.runPolicy(ctx, logr.Discard(), policy, scheduled.Add(time.Hour))
+	assert.NoError(t, r.List(ctx, &snaps, client.MatchingLabels{v1.SnapshotPolicyNameLabelKey: "policy-1"}))
+	assert.Len(t, snaps.Items, 1)
+	assert.Len(t, policy.Status.SkippedVolumes, 3)
+}
+
+func TestSnapshotPolicyPruneSnapshots(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		now    = time.Now()
+		policy = newTestSnapshotPolicy("policy-prune", v1.SnapshotRetention{
+			MaxCount: 2,
+			MaxAge:   &metav1.Duration{Duration: 24 * time.Hour},
+		})
+		recorded = newPolicySnapshot("policy-prune", "vol-1-recorded", "vol-1", now.Add(-10*time.Minute), v1.SnapshotStatusError)
+		other    = newPolicySnapshot("policy-prune", "vol-1-other", "vol-1", now.Add(-time.Hour), v1.SnapshotStatusReady)
+		failed   = testutil.ToFloat64(snapshotPolicySnapshots.WithLabelValues(v1.DefaultNamespace, "policy-prune", "failed"))
+		r        *SnapshotPolicyReconciler
+		recorder *record.FakeRecorder
+	)
+	recorded.Annotations = map[string]string{v1.SnapshotPolicyFailureRecordedAnnoKey: "true"}
+	delete(other.Labels, v1.SnapshotPolicyNameLabelKey)
+	r, recorder = newSnapshotPolicyReconciler(t, policy, recorded, other,
+		newPolicySnapshot("policy-prune", "vol-1-1h", "vol-1", now.Add(-time.Hour), v1.SnapshotStatusReady),
+		newPolicySnapshot("policy-prune", "vol-1-2h", "vol-1", now.Add(-2*time.Hour), v1.SnapshotStatusReady),
+		newPolicySnapshot("policy-prune", "vol-1-3h", "vol-1", now.Add(-3*time.Hour), v1.SnapshotStatusReady),
+		newPolicySnapshot("policy-prune", "vol-1-failed", "vol-1", now.Add(-30*time.Minute), v1.SnapshotStatusError),
+		newPolicySnapshot("policy-prune", "vol-1-merged", "vol-1", now.Add(-48*time.Hour), v1.SnapshotStatusMerged),
+		newPolicySnapshot("policy-prune", "vol-2-1h", "vol-2", now.Add(-time.Hour), v1.SnapshotStatusReady),
+		newPolicySnapshot("policy-prune", "vol-2-48h", "vol-2", now.Add(-48*time.Hour), v1.SnapshotStatusReady))
+
+	isDeleted := func(name string) bool {
+		var snap v1.AntstorSnapshot
+		assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: v1.DefaultNamespace, Name: name}, &snap))
+		return snap.DeletionTimestamp != nil
+	}
+
+	// failed snapshots, the oldest one exceeding max count and the one exceeding max age are deleted
+	r.pruneSnapshots(ctx, logr.Discard(), policy, now)
+	for name, deleted := range map[string]bool{
+		"vol-1-recorded": true,
+		"vol-1-failed":   true,
+		"vol-1-1h":       false,
+		"vol-1-2h":       false,
+		"vol-1-3h":       true,
+		"vol-1-merged":   false,
+		"vol-1-other":    false,
+		"vol-2-1h":       false,
+		"vol-2-48h":      true,
+	} {
+		assert.Equal(t, deleted, isDeleted(name), name)
+	}
+
+	// failure is recorded only once, and the recorded one is not counted again
+	var snap v1.AntstorSnapshot
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: v1.DefaultNamespace, Name: "vol-1-failed"}, &snap))
+	assert.Equal(t, "true", snap.Annotations[v1.SnapshotPolicyFailureRecordedAnnoKey])
+	assert.Equal(t, failed+1, testutil.ToFloat64(snapshotPolicySnapshots.WithLabelValues(v1.DefaultNamespace, "policy-prune", "failed")))
+	assert.Len(t, recorder.Events, 1)
+
+	r.pruneSnapshots(ctx, logr.Discard(), policy, now)
+	assert.Equal(t, failed+1, testutil.ToFloat64(snapshotPolicySnapshots.WithLabelValues(v1.DefaultNamespace, "policy-prune", "failed")))
+	assert.Len(t, recorder.Events, 1)
+}
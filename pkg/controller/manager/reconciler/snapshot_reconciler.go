@@ -9,8 +9,10 @@ import (
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
 	"lite.io/liteio/pkg/generated/clientset/versioned"
 	"lite.io/liteio/pkg/util"
+	"lite.io/liteio/pkg/util/misc"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -22,9 +24,10 @@ import (
 )
 
 const (
-	SnapshotCreateFailure = "SnapshotCreateFailure"
-	SnapshotMergeFailure  = "SnapshotMergeFailure"
-	SnapshotDeleteFailure = "SnapshotDeleteFailure"
+	SnapshotCreateFailure   = "SnapshotCreateFailure"
+	SnapshotMergeFailure    = "SnapshotMergeFailure"
+	SnapshotDeleteFailure   = "SnapshotDeleteFailure"
+	SnapshotRollbackFailure = "SnapshotRollbackFailure"
 )
 
 type SnapshotReconciler struct {
@@ -102,6 +105,12 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			err = r.Update(context.Background(), &obj)
 			return ctrl.Result{}, err
 		}
+
+		// agent refuses to delete the snapshot, e.g. SpdkLVol snapshot has more than one clone
+		if obj.Status.Message != "" {
+			r.EventRecorder.Event(&obj, corev1.EventTypeWarning, SnapshotDeleteFailure, obj.Status.Message)
+			return ctrl.Result{}, nil
+		}
 	}
 
 	if obj.Status.Status == v1.SnapshotStatusMerged {
@@ -132,7 +141,8 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
-	// 2. The origin volume should only have one snapshot
+	// 2. Snapshots of the origin volume share the reserved snapshot space of it
+	var otherSnapSize int64
 	snapList, err := r.AntstorClientset.VolumeV1().AntstorSnapshots(obj.Namespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("%s=%s", v1.OriginVolumeNameLabelKey, obj.Spec.OriginVolName),
 	})
@@ -146,9 +156,7 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			continue
 		}
 		if item.Status.Status != v1.SnapshotStatusMerged {
-			log.Info("origin volume can only have one snapshot", "originVolName", obj.Spec.OriginVolName)
-			r.EventRecorder.Event(&obj, corev1.EventTypeWarning, SnapshotCreateFailure, "origin volume can only have one snapshot")
-			return ctrl.Result{Requeue: true, RequeueAfter: 3 * time.Minute}, nil
+			otherSnapSize += item.Spec.Size
 		}
 	}
 
@@ -186,8 +194,9 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			return ctrl.Result{Requeue: true, RequeueAfter: 3 * time.Minute}, nil
 		}
 
-		if obj.Spec.Size > int64(snapReservedBytes) {
-			r.EventRecorder.Event(&obj, corev1.EventTypeWarning, SnapshotCreateFailure, fmt.Sprintf("snap size too large: %d, reserved size %d", obj.Spec.Size, snapReservedBytes))
+		// other snapshots, e.g. the base snapshot of incremental export, share the reserved space
+		if obj.Spec.Size+otherSnapSize > int64(snapReservedBytes) {
+			r.EventRecorder.Event(&obj, corev1.EventTypeWarning, SnapshotCreateFailure, fmt.Sprintf("snap size too large: %d, size of other snapshots %d, reserved size %d", obj.Spec.Size, otherSnapSize, snapReservedBytes))
 			return ctrl.Result{Requeue: true, RequeueAfter: 3 * time.Minute}, nil
 		}
 	}
@@ -196,6 +205,7 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	if obj.Spec.OriginVolTargetNodeID == "" {
 		log.Info("update origin vol node id", "nodeID", originVol.Spec.TargetNodeId)
 		obj.Spec.OriginVolTargetNodeID = originVol.Spec.TargetNodeId
+		obj.Spec.Encryption = originVol.Spec.Encryption.DeepCopy()
 		obj.Labels[v1.TargetNodeIdLabelKey] = originVol.Spec.TargetNodeId
 		err = r.Update(context.Background(), &obj)
 		return ctrl.Result{}, err
@@ -209,6 +219,17 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	// start rolling back origin volume to the snapshot
+	if obj.Spec.Rollback && obj.Status.Status == v1.SnapshotStatusReady {
+		return r.startRollback(ctx, &obj, &originVol)
+	}
+
+	// SpdkLVol snapshot is merged by replacing the origin lvol, so the target of origin volume is removed first, like rolling back
+	if val, has := obj.Labels[v1.MergeStartTimestampLabelKey]; has && val != "" && obj.Spec.VolType == v1.VolumeTypeSpdkLVol &&
+		obj.Status.Status == v1.SnapshotStatusReady {
+		return r.startRollback(ctx, &obj, &originVol)
+	}
+
 	// update status to merging
 	if val, has := obj.Labels[v1.MergeStartTimestampLabelKey]; has && val != "" && obj.Status.Status != v1.SnapshotStatusMerging {
 		log.Info("update status to merging")
@@ -217,5 +238,167 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	// agent failed to merge the snapshot, e.g. SpdkLVol snapshot has clones other than the origin volume
+	if obj.Status.Status == v1.SnapshotStatusMerging && obj.Status.Message != "" {
+		r.EventRecorder.Event(&obj, corev1.EventTypeWarning, SnapshotMergeFailure, obj.Status.Message)
+	}
+
+	// agent failed to roll back the volume
+	if rb := obj.Status.Rollback; rb != nil && rb.Phase != v1.SnapshotRollbackPending && rb.Message != "" {
+		r.EventRecorder.Event(&obj, corev1.EventTypeWarning, SnapshotRollbackFailure, rb.Message)
+	}
+
 	return ctrl.Result{}, nil
 }
+
+// startRollback checks the origin volume is not mounted, and sets the snapshot to merging.
+// The rollback is done by agent on the node of the snapshot.
+func (r *SnapshotReconciler) startRollback(ctx context.Context, obj *v1.AntstorSnapshot, originVol *v1.AntstorVolume) (ctrl.Result, error) {
+	var log = r.Log.WithValues("snapshot", obj.Name, "originVol", originVol.Name)
+
+	// SpdkLVol snapshots of a volume form a chain, and only the latest one is the parent of the origin lvol
+	if obj.Spec.VolType == v1.VolumeTypeSpdkLVol {
+		newer, err := r.getNewerSnapshots(ctx, obj)
+		if err != nil {
+			log.Error(err, "list snapshots of origin volume failed")
+			return ctrl.Result{}, err
+		}
+		if len(newer) > 0 {
+			log.Info("refuse to roll back volume", "newerSnapshots", newer)
+			return r.refuseRollback(ctx, obj, fmt.Sprintf("snapshots %v of origin volume %s are newer, which should be deleted first", newer, originVol.Name))
+		}
+	}
+
+	mountedBy, err := r.getVolumeConsumers(ctx, originVol)
+	if err != nil {
+		log.Error(err, "check if origin volume is mounted failed")
+		return ctrl.Result{}, err
+	}
+
+	if len(mountedBy) > 0 {
+		log.Info("refuse to roll back volume", "mountedBy", mountedBy)
+		return r.refuseRollback(ctx, obj, fmt.Sprintf("origin volume %s is still mounted by %v", originVol.Name, mountedBy))
+	}
+
+	log.Info("start rolling back volume")
+	var now = metav1.Now()
+	obj.Status.Status = v1.SnapshotStatusMerging
+	obj.Status.Rollback = &v1.SnapshotRollbackStatus{
+		Phase:     v1.SnapshotRollbackUnstaging,
+		StartTime: &now,
+	}
+	err = r.Status().Update(ctx, obj)
+	return ctrl.Result{}, err
+}
+
+// refuseRollback keeps the rollback pending with the reason, and checks again later
+func (r *SnapshotReconciler) refuseRollback(ctx context.Context, obj *v1.AntstorSnapshot, msg string) (result ctrl.Result, err error) {
+	r.EventRecorder.Event(obj, corev1.EventTypeWarning, SnapshotRollbackFailure, msg)
+	if obj.Status.Rollback == nil || obj.Status.Rollback.Message != msg {
+		obj.Status.Rollback = &v1.SnapshotRollbackStatus{
+			Phase:   v1.SnapshotRollbackPending,
+			Message: msg,
+		}
+		err = r.Status().Update(ctx, obj)
+	}
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+}
+
+// getNewerSnapshots returns names of unmerged snapshots of the same origin volume, which are created after the snapshot.
+// The order is decided by Spec.Sequence, because CreationTimestamp is in seconds.
+func (r *SnapshotReconciler) getNewerSnapshots(ctx context.Context, obj *v1.AntstorSnapshot) (names []string, err error) {
+	var list v1.AntstorSnapshotList
+	err = r.List(ctx, &list, client.InNamespace(obj.Namespace), client.MatchingLabels{v1.OriginVolumeNameLabelKey: obj.Spec.OriginVolName})
+	if err != nil {
+		return
+	}
+
+	for _, item := range list.Items {
+		if item.Name == obj.Name || item.Status.Status == v1.SnapshotStatusMerged {
+			continue
+		}
+		if isNewerSnapshot(&item, obj) {
+			names = append(names, item.Name)
+		}
+	}
+	return
+}
+
+// isNewerSnapshot returns true if snapshot a is created after snapshot b
+func isNewerSnapshot(a, b *v1.AntstorSnapshot) bool {
+	switch {
+	case a.Spec.Sequence > 0 && b.Spec.Sequence > 0:
+		return a.Spec.Sequence > b.Spec.Sequence
+	case a.Spec.Sequence == 0 && b.Spec.Sequence == 0:
+		// snapshots created by previous version have no sequence
+		return a.CreationTimestamp.After(b.CreationTimestamp.Time)
+	case a.Spec.Sequence == 0:
+		// snapshot a is not assigned a sequence yet, and will be created on top of the chain
+		return a.Status.Status == "" || a.Status.Status == v1.SnapshotStatusCreating
+	default:
+		// snapshot b is created by previous version, or not assigned a sequence yet
+		return !isNewerSnapshot(b, a)
+	}
+}
+
+// getVolumeConsumers returns the hosts, VolumeAttachments and running pods which are using the volume
+func (r *SnapshotReconciler) getVolumeConsumers(ctx context.Context, vol *v1.AntstorVolume) (consumers []string, err error) {
+	var hosts = misc.NewEmptySet()
+	for _, host := range vol.Spec.AttachedHosts {
+		hosts.Add(host)
+		consumers = append(consumers, "host/"+host)
+	}
+	// hosts which staged the volume, reported by NodeStageVolume and removed by NodeUnstageVolume
+	for _, item := range vol.Status.HostPaths {
+		if !hosts.Contains(item.NodeID) {
+			hosts.Add(item.NodeID)
+			consumers = append(consumers, "host/"+item.NodeID)
+		}
+	}
+
+	pvName := vol.Labels[v1.VolumePVNameLabelKey]
+	if pvName == "" {
+		return
+	}
+
+	var vaList storagev1.VolumeAttachmentList
+	err = r.List(ctx, &vaList)
+	if err != nil {
+		return
+	}
+	for _, va := range vaList.Items {
+		if va.Spec.Source.PersistentVolumeName != nil && *va.Spec.Source.PersistentVolumeName == pvName && va.Status.Attached {
+			consumers = append(consumers, "volumeattachment/"+va.Name)
+		}
+	}
+
+	var pv corev1.PersistentVolume
+	err = r.Get(ctx, client.ObjectKey{Name: pvName}, &pv)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = nil
+		}
+		return
+	}
+	if pv.Spec.ClaimRef == nil {
+		return
+	}
+
+	var pods corev1.PodList
+	err = r.List(ctx, &pods, client.InNamespace(pv.Spec.ClaimRef.Namespace))
+	if err != nil {
+		return
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, item := range pod.Spec.Volumes {
+			if item.PersistentVolumeClaim != nil && item.PersistentVolumeClaim.ClaimName == pv.Spec.ClaimRef.Name {
+				consumers = append(consumers, "pod/"+pod.Namespace+"/"+pod.Name)
+				break
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,149 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func newTestSnapshot(name, volName string, created time.Time, status v1.SnapshotStatusName) *v1.AntstorSnapshot {
+	return &v1.AntstorSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         v1.DefaultNamespace,
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{v1.OriginVolumeNameLabelKey: volName},
+		},
+		Spec:   v1.AntstorSnapshotSpec{OriginVolName: volName, OriginVolNamespace: v1.DefaultNamespace},
+		Status: v1.AntstorSnapshotStatus{Status: status},
+	}
+}
+
+func TestStartRollbackWithNewerSnapshots(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		now     = time.Now()
+		vol     = &v1.AntstorVolume{ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "vol-1"}}
+		newSnap = func(name string, seq int64, created time.Time, volType v1.VolumeType, status v1.SnapshotStatusName) *v1.AntstorSnapshot {
+			snap := newTestSnapshot(name, "vol-1", created, status)
+			snap.Spec.Sequence = seq
+			snap.Spec.VolType = volType
+			snap.Spec.Rollback = true
+			return snap
+		}
+	)
+	var r = &SnapshotReconciler{
+		Client: newFakeClient(t, vol,
+			newSnap("snap-old", 1, now.Add(-2*time.Hour), v1.VolumeTypeSpdkLVol, v1.SnapshotStatusReady),
+			newSnap("snap-new", 2, now.Add(-time.Hour), v1.VolumeTypeSpdkLVol, v1.SnapshotStatusReady),
+			newSnap("snap-merged", 3, now.Add(-time.Minute), v1.VolumeTypeSpdkLVol, v1.SnapshotStatusMerged),
+			newSnap("snap-lvm", 0, now.Add(-3*time.Hour), v1.VolumeTypeKernelLVol, v1.SnapshotStatusReady),
+		),
+		Log:           logr.Discard(),
+		EventRecorder: record.NewFakeRecorder(10),
+	}
+	rollback := func(name string) (snap v1.AntstorSnapshot, result ctrl.Result) {
+		var key = types.NamespacedName{Namespace: v1.DefaultNamespace, Name: name}
+		assert.NoError(t, r.Get(ctx, key, &snap))
+		result, err := r.startRollback(ctx, &snap, vol)
+		assert.NoError(t, err)
+		assert.NoError(t, r.Get(ctx, key, &snap))
+		return
+	}
+
+	// SpdkLVol snapshot is not the latest one, merged snapshot is ignored
+	snap, result := rollback("snap-old")
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+	assert.Equal(t, v1.SnapshotStatusReady, snap.Status.Status)
+	if assert.NotNil(t, snap.Status.Rollback) {
+		assert.Equal(t, v1.SnapshotRollbackPending, snap.Status.Rollback.Phase)
+		assert.Contains(t, snap.Status.Rollback.Message, "[snap-new]")
+	}
+
+	// the latest SpdkLVol snapshot
+	snap, _ = rollback("snap-new")
+	assert.Equal(t, v1.SnapshotStatusMerging, snap.Status.Status)
+	assert.Equal(t, v1.SnapshotRollbackUnstaging, snap.Status.Rollback.Phase)
+
+	// KernelLVol snapshots are merged by LVM, regardless of newer snapshots
+	snap, _ = rollback("snap-lvm")
+	assert.Equal(t, v1.SnapshotStatusMerging, snap.Status.Status)
+}
+
+func TestIsNewerSnapshot(t *testing.T) {
+	var (
+		now     = time.Now()
+		newSnap = func(seq int64, created time.Time, status v1.SnapshotStatusName) *v1.AntstorSnapshot {
+			snap := newTestSnapshot("snap", "vol-1", created, status)
+			snap.Spec.Sequence = seq
+			return snap
+		}
+	)
+
+	// created in the same second, ordered by sequence
+	assert.True(t, isNewerSnapshot(newSnap(2, now, v1.SnapshotStatusReady), newSnap(1, now, v1.SnapshotStatusReady)))
+	assert.False(t, isNewerSnapshot(newSnap(1, now, v1.SnapshotStatusReady), newSnap(2, now, v1.SnapshotStatusReady)))
+	// sequence takes precedence over CreationTimestamp
+	assert.True(t, isNewerSnapshot(newSnap(2, now.Add(-time.Hour), v1.SnapshotStatusReady), newSnap(1, now, v1.SnapshotStatusReady)))
+
+	// snapshots of previous version are ordered by CreationTimestamp
+	assert.True(t, isNewerSnapshot(newSnap(0, now, v1.SnapshotStatusReady), newSnap(0, now.Add(-time.Hour), v1.SnapshotStatusReady)))
+	assert.True(t, isNewerSnapshot(newSnap(1, now.Add(-time.Hour), v1.SnapshotStatusReady), newSnap(0, now, v1.SnapshotStatusReady)))
+	assert.False(t, isNewerSnapshot(newSnap(0, now, v1.SnapshotStatusReady), newSnap(1, now.Add(-time.Hour), v1.SnapshotStatusReady)))
+
+	// snapshot without sequence is being created on top of the chain
+	assert.True(t, isNewerSnapshot(newSnap(0, now, v1.SnapshotStatusCreating), newSnap(1, now, v1.SnapshotStatusReady)))
+	assert.False(t, isNewerSnapshot(newSnap(1, now, v1.SnapshotStatusReady), newSnap(0, now, "")))
+}
+
+func TestGetVolumeConsumers(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		pvName = "pv-1"
+		vol    = &v1.AntstorVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: v1.DefaultNamespace,
+				Name:      "vol-1",
+				Labels:    map[string]string{v1.VolumePVNameLabelKey: pvName},
+			},
+			Spec: v1.AntstorVolumeSpec{AttachedHosts: []string{"node-1"}},
+			Status: v1.AntstorVolumeStatus{
+				HostPaths: []v1.HostPathStatus{{NodeID: "node-1"}, {NodeID: "node-2"}},
+			},
+		}
+		newVA = func(name, pv string, attached bool) *storagev1.VolumeAttachment {
+			return &storagev1.VolumeAttachment{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: storagev1.VolumeAttachmentSpec{
+					Attacher: "antstor.csi.alipay.com",
+					NodeName: "node-3",
+					Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pv},
+				},
+				Status: storagev1.VolumeAttachmentStatus{Attached: attached},
+			}
+		}
+	)
+
+	var r = &SnapshotReconciler{
+		Client: newFakeClient(t,
+			newVA("va-attached", pvName, true),
+			newVA("va-detached", pvName, false),
+			newVA("va-other", "pv-2", true),
+		),
+		Log:           logr.Discard(),
+		EventRecorder: record.NewFakeRecorder(10),
+	}
+
+	consumers, err := r.getVolumeConsumers(ctx, vol)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"host/node-1", "host/node-2", "volumeattachment/va-attached"}, consumers)
+}
@@ -87,6 +87,12 @@ func (r *AntstorVolumeReconcileHandler) HandleReconcile(pCtx *plugin.Context) (r
 		return
 	}
 
+	// create and replace replica legs
+	result = r.reconcileReplicas(ctx, volume, log)
+	if result.NeedBreak() {
+		return
+	}
+
 	return
 }
 
@@ -238,6 +244,29 @@ func (r *AntstorVolumeReconcileHandler) validateAndMutate(ctx context.Context, v
 		}
 	}
 
+	// replicated volume is mirrored by SPDK RAID1 bdev
+	if volume.Spec.Replicas > 1 && volume.Spec.Type != v1.VolumeTypeSpdkLVol {
+		if volume.Spec.Type == v1.VolumeTypeFlexible && volume.Spec.TargetNodeId == "" {
+			volume.Spec.Type = v1.VolumeTypeSpdkLVol
+			err = r.Client.Patch(ctx, volume, patch)
+			if err != nil {
+				log.Error(err, "set type of replicated volume failed")
+			}
+			return plugin.Result{
+				Break: true,
+				Error: err,
+			}
+		}
+		if volume.Status.Message == "" {
+			volume.Status.Message = fmt.Sprintf("replicas is not supported by volume type %s", volume.Spec.Type)
+			err = r.Client.Status().Update(ctx, volume)
+		}
+		return plugin.Result{
+			Break: true,
+			Error: err,
+		}
+	}
+
 	// bind volume to state
 	if volume.Spec.TargetNodeId != "" {
 		var updated bool
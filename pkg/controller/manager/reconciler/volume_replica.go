@@ -0,0 +1,212 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/reconciler/plugin"
+	"lite.io/liteio/pkg/util/misc"
+	"github.com/go-logr/logr"
+	uuid "github.com/satori/go.uuid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileReplicas creates replica legs of the volume on other pools, and replaces the failed legs.
+// Data is mirrored to the legs by the RAID1 bdev on TargetNodeId, which rebuilds data to the new legs.
+func (r *AntstorVolumeReconcileHandler) reconcileReplicas(ctx context.Context, volume *v1.AntstorVolume, log logr.Logger) (result plugin.Result) {
+	if volume.Spec.Replicas <= 1 || volume.Spec.TargetNodeId == "" || volume.Labels[v1.ReplicaOfLabelKey] != "" {
+		return
+	}
+
+	// list legs from APIServer, in case that newly created legs are not in cache
+	legList, err := r.AntstoreCli.VolumeV1().AntstorVolumes(volume.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", v1.ReplicaOfLabelKey, volume.Name),
+	})
+	if err != nil {
+		log.Error(err, "list replica legs failed")
+		return plugin.Result{Error: err}
+	}
+
+	var (
+		patch  = client.MergeFrom(volume.DeepCopy())
+		legs   = make(map[string]*v1.AntstorVolume, len(legList.Items))
+		oldLeg *v1.EntityIdentity
+		newLeg *v1.AntstorVolume
+		// each copy of data is on a different pool
+		usedNodes = []string{volume.Spec.TargetNodeId}
+	)
+	for i := range legList.Items {
+		leg := &legList.Items[i]
+		legs[leg.Name] = leg
+		if leg.Spec.TargetNodeId != "" && !misc.InSliceString(leg.Spec.TargetNodeId, usedNodes) {
+			usedNodes = append(usedNodes, leg.Spec.TargetNodeId)
+		}
+	}
+
+	// legs are created one by one, so that the next leg is not scheduled to the same pool
+	for _, item := range volume.Spec.ReplicaLegs {
+		if leg := legs[item.Name]; leg != nil && leg.Spec.TargetNodeId == "" {
+			log.Info("replica leg is not scheduled yet, requeue after 10s", "leg", item.Name)
+			return plugin.Result{Result: reconcile.Result{RequeueAfter: 10 * time.Second}}
+		}
+	}
+
+	// replace the failed leg
+	for idx, item := range volume.Spec.ReplicaLegs {
+		reason := r.getLegFailure(volume, legs[item.Name])
+		if reason == "" {
+			continue
+		}
+		newLeg, err = r.createReplicaLeg(ctx, volume, usedNodes)
+		if err != nil {
+			log.Error(err, "create replica leg failed")
+			return plugin.Result{Error: err}
+		}
+		log.Info("replace failed replica leg", "leg", item.Name, "reason", reason, "newLeg", newLeg.Name)
+		oldLeg = item.DeepCopy()
+		volume.Spec.ReplicaLegs[idx] = v1.EntityIdentity{
+			Namespace: newLeg.Namespace,
+			Name:      newLeg.Name,
+			UUID:      newLeg.Spec.Uuid,
+		}
+		break
+	}
+
+	// create the missing leg
+	if newLeg == nil && len(volume.Spec.ReplicaLegs) < volume.Spec.Replicas-1 {
+		newLeg, err = r.createReplicaLeg(ctx, volume, usedNodes)
+		if err != nil {
+			log.Error(err, "create replica leg failed")
+			return plugin.Result{Error: err}
+		}
+		log.Info("created replica leg", "leg", newLeg.Name)
+		volume.Spec.ReplicaLegs = append(volume.Spec.ReplicaLegs, v1.EntityIdentity{
+			Namespace: newLeg.Namespace,
+			Name:      newLeg.Name,
+			UUID:      newLeg.Spec.Uuid,
+		})
+	}
+
+	if newLeg == nil {
+		// check legs periodically until all of them are online
+		for _, item := range volume.Status.Replicas {
+			if item.State != v1.ReplicaStateOnline {
+				return plugin.Result{Result: reconcile.Result{RequeueAfter: time.Minute}}
+			}
+		}
+		return
+	}
+
+	err = r.Client.Patch(ctx, volume, patch)
+	if err != nil {
+		log.Error(err, "patch replica legs of volume failed")
+		return plugin.Result{Error: err}
+	}
+
+	// the agent removes the replaced leg from RAID1 bdev after volume is updated
+	if oldLeg != nil {
+		err = r.AntstoreCli.VolumeV1().AntstorVolumes(oldLeg.Namespace).Delete(ctx, oldLeg.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "delete replaced replica leg failed", "leg", oldLeg.Name)
+			return plugin.Result{Error: err}
+		}
+	}
+
+	return plugin.Result{Break: true}
+}
+
+// getLegFailure returns the reason why the leg should be replaced. It is empty if the leg is healthy.
+func (r *AntstorVolumeReconcileHandler) getLegFailure(volume *v1.AntstorVolume, leg *v1.AntstorVolume) (reason string) {
+	if leg == nil {
+		return "leg volume is not found"
+	}
+	if leg.DeletionTimestamp != nil {
+		return "leg volume is being deleted"
+	}
+
+	for _, item := range volume.Status.Replicas {
+		if item.Name == leg.Name && item.State == v1.ReplicaStateFailed {
+			return fmt.Sprintf("leg is failed in RAID1 bdev, %s", item.Message)
+		}
+	}
+
+	if leg.Spec.TargetNodeId != "" {
+		sp, err := r.State.GetStoragePoolByNodeID(leg.Spec.TargetNodeId)
+		if err == nil && (sp.Status.Status == v1.PoolStatusOffline || sp.Status.Status == v1.PoolStatusUnknown) {
+			return fmt.Sprintf("StoragePool %s status is %s", sp.Name, sp.Status.Status)
+		}
+	}
+
+	return
+}
+
+// createReplicaLeg creates a leg volume which is exposed to TargetNodeId of the volume. The leg is not scheduled to usedNodes.
+func (r *AntstorVolumeReconcileHandler) createReplicaLeg(ctx context.Context, volume *v1.AntstorVolume, usedNodes []string) (leg *v1.AntstorVolume, err error) {
+	tgtPool, err := r.State.GetStoragePoolByNodeID(volume.Spec.TargetNodeId)
+	if err != nil {
+		return
+	}
+
+	var (
+		legUuid = uuid.NewV4().String()
+		annos   = make(map[string]string)
+	)
+	if val, has := volume.Annotations[v1.TransportTypeAnnoKey]; has {
+		annos[v1.TransportTypeAnnoKey] = val
+	}
+
+	leg = &v1.AntstorVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: volume.Namespace,
+			Name:      fmt.Sprintf("%s-leg-%s", volume.Name, legUuid[:8]),
+			Labels: map[string]string{
+				v1.ReplicaOfLabelKey: volume.Name,
+				v1.UuidLabelKey:      legUuid,
+			},
+			Annotations: annos,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1.GroupVersion.String(),
+					Kind:       v1.AntstorVolumeKind,
+					Name:       volume.Name,
+					UID:        volume.UID,
+				},
+			},
+		},
+		Spec: v1.AntstorVolumeSpec{
+			Uuid:     legUuid,
+			Type:     v1.VolumeTypeSpdkLVol,
+			SizeByte: volume.Spec.SizeByte,
+			IsThin:   volume.Spec.IsThin,
+			PoolAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{
+									Key:      v1.PoolLabelsNodeSnKey,
+									Operator: corev1.NodeSelectorOpNotIn,
+									Values:   append([]string{}, usedNodes...),
+								},
+							},
+						},
+					},
+				},
+			},
+			// leg is connected by the RAID1 bdev on TargetNodeId of the volume
+			HostNode: tgtPool.Spec.NodeInfo.DeepCopy(),
+		},
+		Status: v1.AntstorVolumeStatus{
+			Status: v1.VolumeStatusCreating,
+		},
+	}
+
+	err = r.Client.Create(ctx, leg)
+	return
+}
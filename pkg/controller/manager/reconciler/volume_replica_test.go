@@ -0,0 +1,222 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"lite.io/liteio/pkg/generated/clientset/versioned/fake"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newReplicaTestState(pools map[string]v1.PoolStatus) state.StateIface {
+	var st = state.NewState()
+	for nodeID, status := range pools {
+		st.SetStoragePool(&v1.StoragePool{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: nodeID},
+			Spec: v1.StoragePoolSpec{
+				NodeInfo: v1.NodeInfo{ID: nodeID, IP: "ip-" + nodeID},
+			},
+			Status: v1.StoragePoolStatus{Status: status},
+		})
+	}
+	return st
+}
+
+func newReplicatedVolume(name string) *v1.AntstorVolume {
+	return &v1.AntstorVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   v1.DefaultNamespace,
+			Name:        name,
+			UID:         types.UID("uid-" + name),
+			Annotations: map[string]string{v1.TransportTypeAnnoKey: "rdma"},
+		},
+		Spec: v1.AntstorVolumeSpec{
+			Uuid:         "uuid-" + name,
+			Type:         v1.VolumeTypeSpdkLVol,
+			SizeByte:     1 << 30,
+			IsThin:       true,
+			Replicas:     2,
+			TargetNodeId: "node-1",
+		},
+		Status: v1.AntstorVolumeStatus{Status: v1.VolumeStatusReady},
+	}
+}
+
+func TestGetLegFailure(t *testing.T) {
+	var (
+		r = &AntstorVolumeReconcileHandler{
+			State: newReplicaTestState(map[string]v1.PoolStatus{
+				"node-1": v1.PoolStatusReady,
+				"node-2": v1.PoolStatusReady,
+				"node-3": v1.PoolStatusOffline,
+			}),
+		}
+		vol = newReplicatedVolume("vol-1")
+		leg = &v1.AntstorVolume{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "vol-1-leg"},
+			Spec:       v1.AntstorVolumeSpec{TargetNodeId: "node-2"},
+		}
+		now = metav1.Now()
+	)
+
+	assert.Equal(t, "leg volume is not found", r.getLegFailure(vol, nil))
+	assert.Empty(t, r.getLegFailure(vol, leg))
+
+	// leg is online, rebuilding or pending
+	for _, state := range []v1.ReplicaState{v1.ReplicaStateOnline, v1.ReplicaStateRebuilding, v1.ReplicaStatePending} {
+		vol.Status.Replicas = []v1.ReplicaStatus{{Name: "vol-1-leg", State: state}}
+		assert.Empty(t, r.getLegFailure(vol, leg))
+	}
+
+	vol.Status.Replicas = []v1.ReplicaStatus{{Name: "vol-1-leg", State: v1.ReplicaStateFailed, Message: "io error"}}
+	assert.Contains(t, r.getLegFailure(vol, leg), "io error")
+	vol.Status.Replicas = nil
+
+	// pool of leg is offline
+	leg.Spec.TargetNodeId = "node-3"
+	assert.Contains(t, r.getLegFailure(vol, leg), "offline")
+
+	// pool of leg is not in state
+	leg.Spec.TargetNodeId = "node-removed"
+	assert.Empty(t, r.getLegFailure(vol, leg))
+
+	leg.DeletionTimestamp = &now
+	assert.Equal(t, "leg volume is being deleted", r.getLegFailure(vol, leg))
+}
+
+func TestCreateReplicaLeg(t *testing.T) {
+	var (
+		ctx = context.Background()
+		vol = newReplicatedVolume("vol-1")
+	)
+	var r = &AntstorVolumeReconcileHandler{
+		Client: newFakeClient(t),
+		State:  newReplicaTestState(map[string]v1.PoolStatus{"node-1": v1.PoolStatusReady}),
+	}
+
+	leg, err := r.createReplicaLeg(ctx, vol, []string{"node-1", "node-2"})
+	assert.NoError(t, err)
+	assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(leg), leg))
+	assert.Equal(t, "vol-1", leg.Labels[v1.ReplicaOfLabelKey])
+	assert.Equal(t, leg.Spec.Uuid, leg.Labels[v1.UuidLabelKey])
+	assert.Equal(t, "rdma", leg.Annotations[v1.TransportTypeAnnoKey])
+	assert.Equal(t, vol.UID, leg.OwnerReferences[0].UID)
+	assert.Equal(t, v1.VolumeTypeSpdkLVol, leg.Spec.Type)
+	assert.Equal(t, vol.Spec.SizeByte, leg.Spec.SizeByte)
+	assert.True(t, leg.Spec.IsThin)
+	assert.Equal(t, 0, leg.Spec.Replicas)
+	assert.Empty(t, leg.Spec.TargetNodeId)
+	// leg is connected by the node of RAID1 bdev, and is not scheduled to used nodes
+	if assert.NotNil(t, leg.Spec.HostNode) {
+		assert.Equal(t, "node-1", leg.Spec.HostNode.ID)
+		assert.Equal(t, "ip-node-1", leg.Spec.HostNode.IP)
+	}
+	req := leg.Spec.PoolAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0]
+	assert.Equal(t, v1.PoolLabelsNodeSnKey, req.Key)
+	assert.Equal(t, []string{"node-1", "node-2"}, req.Values)
+	assert.Equal(t, v1.VolumeStatusCreating, leg.Status.Status)
+
+	// pool of TargetNodeId is not found
+	vol.Spec.TargetNodeId = "node-removed"
+	_, err = r.createReplicaLeg(ctx, vol, []string{"node-removed"})
+	assert.Error(t, err)
+}
+
+func TestReconcileReplicas(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		vol    = newReplicatedVolume("vol-1")
+		legCli = fake.NewSimpleClientset()
+	)
+	var r = &AntstorVolumeReconcileHandler{
+		Client: newFakeClient(t, vol),
+		State: newReplicaTestState(map[string]v1.PoolStatus{
+			"node-1": v1.PoolStatusReady,
+			"node-2": v1.PoolStatusReady,
+		}),
+		AntstoreCli: legCli,
+	}
+	getVol := func() *v1.AntstorVolume {
+		var obj v1.AntstorVolume
+		assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(vol), &obj))
+		return &obj
+	}
+	// legs are created by controller-runtime client, and listed by clientset from APIServer
+	syncLeg := func(name, targetNodeId string) {
+		var leg v1.AntstorVolume
+		assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: v1.DefaultNamespace, Name: name}, &leg))
+		leg.ResourceVersion = ""
+		leg.Spec.TargetNodeId = targetNodeId
+		_, err := legCli.VolumeV1().AntstorVolumes(v1.DefaultNamespace).Create(ctx, &leg, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	// volume is not replicated, or is a leg
+	result := r.reconcileReplicas(ctx, &v1.AntstorVolume{}, logr.Discard())
+	assert.False(t, result.NeedBreak())
+	leg := newReplicatedVolume("vol-leg")
+	leg.Labels = map[string]string{v1.ReplicaOfLabelKey: "vol-0"}
+	result = r.reconcileReplicas(ctx, leg, logr.Discard())
+	assert.False(t, result.NeedBreak())
+
+	// missing leg is created
+	result = r.reconcileReplicas(ctx, getVol(), logr.Discard())
+	assert.True(t, result.NeedBreak())
+	assert.NoError(t, result.Error)
+	legs := getVol().Spec.ReplicaLegs
+	if !assert.Len(t, legs, 1) {
+		return
+	}
+	var firstLeg = legs[0]
+
+	// leg is not scheduled yet
+	syncLeg(firstLeg.Name, "")
+	result = r.reconcileReplicas(ctx, getVol(), logr.Discard())
+	assert.Equal(t, 10*time.Second, result.Result.RequeueAfter)
+
+	// leg is scheduled, and checked until it is online
+	assert.NoError(t, legCli.VolumeV1().AntstorVolumes(v1.DefaultNamespace).Delete(ctx, firstLeg.Name, metav1.DeleteOptions{}))
+	syncLeg(firstLeg.Name, "node-2")
+	var obj = getVol()
+	obj.Status.Replicas = []v1.ReplicaStatus{
+		{Name: "vol-1", State: v1.ReplicaStateOnline},
+		{Name: firstLeg.Name, State: v1.ReplicaStateRebuilding},
+	}
+	assert.NoError(t, r.Status().Update(ctx, obj))
+	result = r.reconcileReplicas(ctx, getVol(), logr.Discard())
+	assert.False(t, result.NeedBreak())
+	assert.Equal(t, time.Minute, result.Result.RequeueAfter)
+
+	obj = getVol()
+	obj.Status.Replicas[1].State = v1.ReplicaStateOnline
+	assert.NoError(t, r.Status().Update(ctx, obj))
+	result = r.reconcileReplicas(ctx, getVol(), logr.Discard())
+	assert.False(t, result.NeedBreak())
+	assert.Zero(t, result.Result.RequeueAfter)
+
+	// failed leg is replaced by a new leg, which is not on the pools of volume and the failed leg
+	obj = getVol()
+	obj.Status.Replicas[1].State = v1.ReplicaStateFailed
+	assert.NoError(t, r.Status().Update(ctx, obj))
+	result = r.reconcileReplicas(ctx, getVol(), logr.Discard())
+	assert.True(t, result.NeedBreak())
+	assert.NoError(t, result.Error)
+	legs = getVol().Spec.ReplicaLegs
+	if assert.Len(t, legs, 1) {
+		assert.NotEqual(t, firstLeg.Name, legs[0].Name)
+		var newLeg v1.AntstorVolume
+		assert.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: v1.DefaultNamespace, Name: legs[0].Name}, &newLeg))
+		req := newLeg.Spec.PoolAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0]
+		assert.Equal(t, []string{"node-1", "node-2"}, req.Values)
+	}
+	_, err := legCli.VolumeV1().AntstorVolumes(v1.DefaultNamespace).Get(ctx, firstLeg.Name, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
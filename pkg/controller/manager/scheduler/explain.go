@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"sort"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/scheduler/filter"
+	"lite.io/liteio/pkg/controller/manager/scheduler/priority"
+	"lite.io/liteio/pkg/controller/manager/state"
+)
+
+// ExplainResult is the result of scheduling dry-run
+type ExplainResult struct {
+	// Filters are the results of filters on every StoragePool
+	Filters []filter.FilterResult `json:"filters"`
+	// Priorities are the score breakdown of qualified StoragePools, high -> low
+	Priorities []priority.PriorityExplain `json:"priorities,omitempty"`
+	// Selected is the node ID which the volume would be scheduled to
+	Selected string `json:"selected,omitempty"`
+	// Volumes are the members of VolumeGroup after scheduling
+	Volumes []v1.VolumeMeta `json:"volumes,omitempty"`
+	// Error of scheduling
+	Error string `json:"error,omitempty"`
+}
+
+// ExplainVolume dry-runs scheduling of the volume. Nodes are read under the lock of scheduler, so that they are not changed by scheduling.
+func (s *scheduler) ExplainVolume(allNodes []*state.Node, vol *v1.AntstorVolume) (result ExplainResult) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return explainVolume(s.cfg, allNodes, vol)
+}
+
+// ExplainVolumeGroup dry-runs scheduling of the VolumeGroup under the lock of scheduler
+func (s *scheduler) ExplainVolumeGroup(allNodes []*state.Node, volGroup *v1.AntstorVolumeGroup) (result ExplainResult) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return explainVolumeGroup(s.cfg, allNodes, volGroup)
+}
+
+// explainVolume runs filters and priorities for the volume without changing it or reserving any resource
+func explainVolume(cfg config.Config, allNodes []*state.Node, vol *v1.AntstorVolume) (result ExplainResult) {
+	var (
+		chain     *filter.FilterChain
+		qualified []*state.Node
+		err       error
+	)
+
+	vol = vol.DeepCopy()
+	chain = filter.NewFilterChain(cfg.Scheduler).
+		Input(allNodes, vol).
+		LoadFilterFromConfig()
+	result.Filters = chain.Explain()
+
+	if nodeName, has := vol.Annotations[v1.SelectedTgtNodeKey]; has {
+		result.Selected = nodeName
+		return
+	}
+
+	qualified, err = chain.MatchAll()
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+
+	result.Priorities = priority.NewPriorityCalculator(cfg.Scheduler).
+		WithContextValue(priority.CtxAllNodesKey, allNodes).
+		Input(qualified, vol).
+		LoadPriorityFromConfig().
+		Explain()
+	if len(result.Priorities) > 0 {
+		result.Selected = result.Priorities[0].NodeID
+	}
+
+	return
+}
+
+// explainVolumeGroup runs filters and schedules members of a copy of the VolumeGroup without reserving any resource
+func explainVolumeGroup(cfg config.Config, allNodes []*state.Node, volGroup *v1.AntstorVolumeGroup) (result ExplainResult) {
+	var (
+		chain     *filter.FilterChain
+		qualified []*state.Node
+		err       error
+	)
+
+	volGroup = volGroup.DeepCopy()
+	chain = newVolGroupFilterChain(cfg.Scheduler, allNodes, volGroup)
+	result.Filters = chain.Explain()
+
+	qualified, err = chain.MatchAll()
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+
+	sort.Sort(sort.Reverse(SortByStorage(qualified)))
+	err = schedVolGroup(cfg, allNodes, qualified, volGroup)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Volumes = volGroup.Spec.Volumes
+
+	return
+}
@@ -28,13 +28,25 @@ func BasicFilterFunc(ctx *FilterContext, n *state.Node, vol *v1.AntstorVolume) b
 	}
 
 	// consider Pool FreeSpace
-	var freeRes = n.GetFreeResourceNonLock()
-	var freeDisk = freeRes[v1.ResourceDiskPoolByte]
-	// comparing quantity. freeDisk cannot be convert to int64 by AsInt64()
-	if freeDisk.CmpInt64(int64(vol.Spec.SizeByte)) < 0 {
-		klog.Infof("[SchedFail] vol=%s Pool %s freeBytes is %s, has %d volumes on it. volSize=%d,", vol.Name, n.Pool.Name, freeDisk.String(), len(n.Volumes), vol.Spec.SizeByte)
-		err.AddReason(ReasonPoolFreeSize)
-		return false
+	if vol.Spec.IsThin && n.Pool.Mode() == v1.PoolModeKernelLVM {
+		// thin volume consumes virtual space of thin pool
+		if !thinPoolFilter(ctx, n, vol) {
+			return false
+		}
+	} else {
+		var freeRes = n.GetFreeResourceNonLock()
+		var freeDisk = freeRes[v1.ResourceDiskPoolByte]
+		var sizeByte = int64(vol.Spec.SizeByte)
+		// thin lvol in lvstore could be overcommitted
+		if vol.Spec.IsThin {
+			sizeByte = sizeByte * 100 / int64(n.Pool.GetThinOvercommitPct(0))
+		}
+		// comparing quantity. freeDisk cannot be convert to int64 by AsInt64()
+		if freeDisk.CmpInt64(sizeByte) < 0 {
+			klog.Infof("[SchedFail] vol=%s Pool %s freeBytes is %s, has %d volumes on it. volSize=%d,", vol.Name, n.Pool.Name, freeDisk.String(), len(n.Volumes), vol.Spec.SizeByte)
+			err.AddReason(ReasonPoolFreeSize)
+			return false
+		}
 	}
 
 	// if Pool type is SPDK Lvol and spdk condition is bad, then reject the Volume
@@ -107,6 +119,34 @@ func BasicFilterFunc(ctx *FilterContext, n *state.Node, vol *v1.AntstorVolume) b
 	return true
 }
 
+func thinPoolFilter(ctx *FilterContext, n *state.Node, vol *v1.AntstorVolume) bool {
+	if n.Pool.Spec.KernelLVM.ThinPool == nil {
+		klog.Infof("[SchedFail] vol=%s Pool %s has no thin pool", vol.Name, n.Pool.Name)
+		ctx.Error.AddReason(ReasonThinPoolNotFound)
+		return false
+	}
+
+	// reject thin volume if usage of thin pool is high
+	for _, item := range n.Pool.Status.Conditions {
+		if item.Type == v1.PoolConditionThinPoolData || item.Type == v1.PoolConditionThinPoolMetadata {
+			if item.Status != v1.StatusOK {
+				klog.Infof("[SchedFail] vol=%s Pool %s, condition %s is %s, usage %s", vol.Name, n.Pool.Name, item.Type, item.Status, item.Message)
+				ctx.Error.AddReason(ReasonThinPoolUsage)
+				return false
+			}
+		}
+	}
+
+	var thinFree = n.GetThinFreeBytes(ctx.Config.ThinOvercommitPct)
+	if thinFree < int64(vol.Spec.SizeByte) {
+		klog.Infof("[SchedFail] vol=%s Pool %s thin free bytes is %d, volSize=%d, overcommit %d%%", vol.Name, n.Pool.Name, thinFree, vol.Spec.SizeByte, n.Pool.GetThinOvercommitPct(ctx.Config.ThinOvercommitPct))
+		ctx.Error.AddReason(ReasonThinPoolFreeSize)
+		return false
+	}
+
+	return true
+}
+
 func matchReservationFilter(ctx *FilterContext, n *state.Node, vol *v1.AntstorVolume) (pass, hasError bool) {
 	if resvId, has := vol.Annotations[v1.ReservationIDKey]; has {
 		free := n.FreeResource.Storage()
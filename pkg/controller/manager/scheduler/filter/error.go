@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,6 +19,13 @@ const (
 	ReasonPoolUnschedulable = "PoolUnschedulable"
 	ReasonReservationSize   = "ReservationTooSmall"
 	ReasonReserveNotMatch   = "ReservationNotMatch"
+	ReasonTransportNotMatch = "TransportNotMatch"
+	ReasonThinPoolNotFound  = "ThinPoolNotFound"
+	ReasonThinPoolFreeSize  = "ThinPoolFreeSize"
+	ReasonThinPoolUsage     = "ThinPoolUsageHigh"
+	ReasonPoolHighWatermark = "PoolHighWatermark"
+	ReasonTopologySpread    = "TopologySpread"
+	ReasonPoolIOUtilHigh    = "PoolIOUtilHigh"
 
 	NoStoragePoolAvailable = "NoStoragePoolAvailable"
 	//
@@ -49,6 +57,17 @@ func (e *MergedError) Error() string {
 	return s.String()
 }
 
+// Reasons returns the sorted reasons
+func (e *MergedError) Reasons() (reasons []string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for reason := range e.reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	return
+}
+
 func (e *MergedError) AddReason(reason string) {
 	e.lock.Lock()
 	defer e.lock.Unlock()
@@ -2,6 +2,7 @@ package filter
 
 import (
 	"context"
+	"fmt"
 
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
 	"lite.io/liteio/pkg/controller/manager/config"
@@ -13,6 +14,8 @@ type FilterContext struct {
 	Ctx    context.Context
 	Config config.SchedulerConfig
 	Error  *MergedError
+	// AllNodes are the input nodes of filter chain
+	AllNodes []*state.Node
 }
 
 type PredicateFunc func(*FilterContext, *state.Node, *v1.AntstorVolume) bool
@@ -21,7 +24,20 @@ type FilterChain struct {
 	nodes   []*state.Node
 	vol     *v1.AntstorVolume
 	filters []PredicateFunc
-	ctx     *FilterContext
+	// names of filters, empty for anonymous filter
+	names []string
+	ctx   *FilterContext
+}
+
+// FilterResult is the result of filters on a node
+type FilterResult struct {
+	NodeID string `json:"nodeId"`
+	// Passed is true if the node passes all filters
+	Passed bool `json:"passed"`
+	// Filter is the name of the filter which rejects the node
+	Filter string `json:"filter,omitempty"`
+	// Reasons why the node is rejected
+	Reasons []string `json:"reasons,omitempty"`
 }
 
 func NewFilterChain(cfg config.SchedulerConfig) *FilterChain {
@@ -45,6 +61,7 @@ func (fc *FilterChain) WithContextValue(key, val interface{}) *FilterChain {
 func (fc *FilterChain) Input(nodes []*state.Node, vol *v1.AntstorVolume) *FilterChain {
 	fc.nodes = nodes
 	fc.vol = vol
+	fc.ctx.AllNodes = nodes
 	return fc
 }
 
@@ -56,7 +73,7 @@ func (fc *FilterChain) LoadFilterFromConfig() *FilterChain {
 			continue
 		} else {
 			klog.Info("use filter ", name)
-			fc.Filter(f)
+			fc.NamedFilter(name, f)
 		}
 	}
 
@@ -64,7 +81,12 @@ func (fc *FilterChain) LoadFilterFromConfig() *FilterChain {
 }
 
 func (fc *FilterChain) Filter(f PredicateFunc) *FilterChain {
+	return fc.NamedFilter("", f)
+}
+
+func (fc *FilterChain) NamedFilter(name string, f PredicateFunc) *FilterChain {
 	fc.filters = append(fc.filters, f)
+	fc.names = append(fc.names, name)
 	return fc
 }
 
@@ -88,3 +110,33 @@ func (fc *FilterChain) passAllFilters(filters []PredicateFunc, node *state.Node,
 	}
 	return true
 }
+
+// Explain runs filters on every node, and returns which filter rejects the node and why.
+// It does not change the error of the chain.
+func (fc *FilterChain) Explain() (results []FilterResult) {
+	var globalErr = fc.ctx.Error
+	defer func() {
+		fc.ctx.Error = globalErr
+	}()
+
+	for _, node := range fc.nodes {
+		var result = FilterResult{
+			NodeID: node.Info.ID,
+			Passed: true,
+		}
+		for idx, filterFunc := range fc.filters {
+			fc.ctx.Error = NewMergedError()
+			if !filterFunc(fc.ctx, node, fc.vol) {
+				result.Passed = false
+				result.Filter = fc.names[idx]
+				if result.Filter == "" {
+					result.Filter = fmt.Sprintf("anonymous-%d", idx)
+				}
+				result.Reasons = fc.ctx.Error.Reasons()
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return
+}
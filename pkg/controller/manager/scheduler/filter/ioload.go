@@ -0,0 +1,25 @@
+package filter
+
+import (
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"k8s.io/klog/v2"
+)
+
+// IOUtilCeilingFilterFunc filters out the pools whose recent IO utilization exceeds the ceiling.
+// Pools without recent IO load are not filtered out. MustLocal volumes are not checked, as they have no other choice.
+func IOUtilCeilingFilterFunc(ctx *FilterContext, n *state.Node, vol *v1.AntstorVolume) bool {
+	var ceiling = ctx.Config.PoolIOUtilCeilingPct
+	if ceiling <= 0 || vol.Spec.PositionAdvice == v1.MustLocal {
+		return true
+	}
+
+	var load = n.Pool.GetRecentIOLoad()
+	if load != nil && load.UtilPct >= ceiling {
+		klog.Infof("[SchedFail] vol=%s Pool %s IO util %d%%, exceeds ceiling %d%%", vol.Name, n.Pool.Name, load.UtilPct, ceiling)
+		ctx.Error.AddReason(ReasonPoolIOUtilHigh)
+		return false
+	}
+
+	return true
+}
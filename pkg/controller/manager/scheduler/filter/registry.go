@@ -15,6 +15,10 @@ func init() {
 	RegisterFilter("Basic", BasicFilterFunc)
 	RegisterFilter("Affinity", AffinityFilterFunc)
 	RegisterFilter("MinLocalStorage", MinLocalStorageFilterFunc)
+	RegisterFilter("Transport", TransportFilterFunc)
+	RegisterFilter("HighWatermark", HighWatermarkFilterFunc)
+	RegisterFilter("TopologySpread", TopologySpreadFilterFunc)
+	RegisterFilter("IOUtilCeiling", IOUtilCeilingFilterFunc)
 }
 
 func RegisterFilter(name string, filter PredicateFunc) {
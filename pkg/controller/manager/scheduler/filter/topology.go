@@ -0,0 +1,124 @@
+package filter
+
+import (
+	"strconv"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"k8s.io/klog/v2"
+)
+
+const (
+	TopologyRack = "rack"
+	TopologyRoom = "room"
+)
+
+// TopologySpread is the constraint of spreading volumes across topology domains, e.g. racks or rooms
+type TopologySpread struct {
+	// LabelKey is the node label whose value is the topology domain
+	LabelKey string
+	// MaxSkew is the max difference of volume count between domains
+	MaxSkew int
+}
+
+// GetTopologySpread parses the spreading constraint in annotations. ok is false if there is no constraint.
+func GetTopologySpread(cfg config.SchedulerConfig, annos map[string]string) (spread TopologySpread, ok bool) {
+	switch key := annos[v1.TopologySpreadKeyAnnoKey]; key {
+	case "":
+		return
+	case TopologyRack:
+		spread.LabelKey = cfg.RackLabelKey
+	case TopologyRoom:
+		spread.LabelKey = cfg.RoomLabelKey
+	default:
+		spread.LabelKey = key
+	}
+
+	spread.MaxSkew = 1
+	if val, has := annos[v1.TopologySpreadMaxSkewAnnoKey]; has {
+		skew, err := strconv.Atoi(val)
+		if err != nil || skew <= 0 {
+			klog.Errorf("invalid value of TopologySpreadMaxSkewAnnoKey, %s, use 1", val)
+		} else {
+			spread.MaxSkew = skew
+		}
+	}
+
+	return spread, spread.LabelKey != ""
+}
+
+// Domain returns the topology domain of the node. It is empty if the node has no such label.
+func (ts TopologySpread) Domain(n *state.Node) string {
+	if n == nil || n.Info == nil {
+		return ""
+	}
+	return n.Info.Labels[ts.LabelKey]
+}
+
+// CountVolumes returns count of volumes matched by fn in each domain.
+// Domains of schedulable pools are counted even if they have no matched volumes.
+func (ts TopologySpread) CountVolumes(nodes []*state.Node, fn func(vol *v1.AntstorVolume) bool) (counts map[string]int) {
+	counts = make(map[string]int)
+	for _, n := range nodes {
+		domain := ts.Domain(n)
+		if domain == "" {
+			continue
+		}
+		if _, has := counts[domain]; !has && n.Pool.IsSchedulable() {
+			counts[domain] = 0
+		}
+		for _, vol := range n.Volumes {
+			if fn(vol) {
+				counts[domain]++
+			}
+		}
+	}
+	return
+}
+
+// Fits returns true if one more volume in the domain does not exceed MaxSkew
+func (ts TopologySpread) Fits(counts map[string]int, domain string) bool {
+	var minCnt = -1
+	for _, cnt := range counts {
+		if minCnt < 0 || cnt < minCnt {
+			minCnt = cnt
+		}
+	}
+	if minCnt < 0 {
+		minCnt = 0
+	}
+	return counts[domain]+1-minCnt <= ts.MaxSkew
+}
+
+// SameDataHolder returns a function matching the other volumes which have the same data-holder as vol
+func SameDataHolder(vol *v1.AntstorVolume) func(*v1.AntstorVolume) bool {
+	var holder = vol.Labels[v1.VolumeDataHolderKey]
+	return func(item *v1.AntstorVolume) bool {
+		return holder != "" && item.Labels[v1.VolumeDataHolderKey] == holder && item.Name != vol.Name
+	}
+}
+
+// TopologySpreadFilterFunc filters out the pools, on whose topology domain volume count of the same data-holder would exceed the max skew.
+func TopologySpreadFilterFunc(ctx *FilterContext, n *state.Node, vol *v1.AntstorVolume) bool {
+	spread, ok := GetTopologySpread(ctx.Config, vol.Annotations)
+	if !ok || vol.Labels[v1.VolumeDataHolderKey] == "" {
+		return true
+	}
+
+	var domain = spread.Domain(n)
+	if domain == "" {
+		klog.Infof("[SchedFail] vol=%s Pool %s has no topology label %s", vol.Name, n.Pool.Name, spread.LabelKey)
+		ctx.Error.AddReason(ReasonTopologySpread)
+		return false
+	}
+
+	var counts = spread.CountVolumes(ctx.AllNodes, SameDataHolder(vol))
+	if !spread.Fits(counts, domain) {
+		klog.Infof("[SchedFail] vol=%s Pool %s, %s=%s has %d volumes of the same data-holder, max skew %d, counts %v", vol.Name, n.Pool.Name, spread.LabelKey, domain, counts[domain], spread.MaxSkew, counts)
+		ctx.Error.AddReason(ReasonTopologySpread)
+		return false
+	}
+
+	return true
+}
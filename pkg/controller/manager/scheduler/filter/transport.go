@@ -0,0 +1,25 @@
+package filter
+
+import (
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"k8s.io/klog/v2"
+)
+
+// TransportFilterFunc filters out the pools whose SPDK target does not support the transport type of the volume
+func TransportFilterFunc(ctx *FilterContext, n *state.Node, vol *v1.AntstorVolume) bool {
+	var transType = vol.GetTransportType()
+	// local LVM volume is not accessed by SPDK target
+	var isLocalVol = n.Pool.Spec.NodeInfo.ID == vol.Spec.HostNode.ID
+	if isLocalVol && n.Pool.Mode() == v1.PoolModeKernelLVM {
+		return true
+	}
+
+	if !n.Pool.SupportTransport(transType) {
+		klog.Infof("[SchedFail] vol=%s Pool %s does not support transport %s, supported transports %v", vol.Name, n.Pool.Name, transType, n.Pool.Spec.Transports)
+		ctx.Error.AddReason(ReasonTransportNotMatch)
+		return false
+	}
+
+	return true
+}
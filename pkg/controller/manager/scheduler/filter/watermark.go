@@ -0,0 +1,32 @@
+package filter
+
+import (
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"k8s.io/klog/v2"
+)
+
+// HighWatermarkFilterFunc filters out the SpdkLVStore pools whose physical usage exceeds the high watermark.
+// Thin lvols allocate clusters on writing, so the logical free space of an overcommitted pool is not reliable.
+func HighWatermarkFilterFunc(ctx *FilterContext, n *state.Node, vol *v1.AntstorVolume) bool {
+	var highWatermark = ctx.Config.PoolHighWatermarkPct
+	if n.Pool.Mode() != v1.PoolModeSpdkLVStore || highWatermark <= 0 {
+		return true
+	}
+
+	var usedPct = n.Pool.GetUsedPct()
+	if usedPct >= float64(highWatermark) {
+		klog.Infof("[SchedFail] vol=%s Pool %s used %.2f%%, exceeds high watermark %d%%", vol.Name, n.Pool.Name, usedPct, highWatermark)
+		ctx.Error.AddReason(ReasonPoolHighWatermark)
+		return false
+	}
+
+	// thick lvol allocates all clusters on creation
+	if !vol.Spec.IsThin && n.Pool.GetVgFreeBytes() < int64(vol.Spec.SizeByte) {
+		klog.Infof("[SchedFail] vol=%s Pool %s physical free bytes is %d, volSize=%d", vol.Name, n.Pool.Name, n.Pool.GetVgFreeBytes(), vol.Spec.SizeByte)
+		ctx.Error.AddReason(ReasonPoolFreeSize)
+		return false
+	}
+
+	return true
+}
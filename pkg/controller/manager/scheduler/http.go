@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/state"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func NewExplainHandler(sched SchedulerIface, s state.StateIface) *ExplainHandler {
+	return &ExplainHandler{sched: sched, state: s}
+}
+
+// ExplainHandler dry-runs scheduling of the AntstorVolume or AntstorVolumeGroup in request body.
+// Kind of the object decides which one is scheduled, default is AntstorVolume.
+type ExplainHandler struct {
+	sched SchedulerIface
+	state state.StateIface
+}
+
+func (h *ExplainHandler) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	var (
+		typeMeta metav1.TypeMeta
+		result   ExplainResult
+	)
+
+	if req.Method != http.MethodPost {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		writer.Write([]byte("only POST is allowed"))
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(err.Error()))
+		return
+	}
+
+	if err = json.Unmarshal(body, &typeMeta); err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(err.Error()))
+		return
+	}
+
+	switch typeMeta.Kind {
+	case "AntstorVolumeGroup":
+		var volGroup v1.AntstorVolumeGroup
+		if err = json.Unmarshal(body, &volGroup); err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(err.Error()))
+			return
+		}
+		result = h.sched.ExplainVolumeGroup(h.state.GetAllNodes(), &volGroup)
+	default:
+		var vol v1.AntstorVolume
+		if err = json.Unmarshal(body, &vol); err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(err.Error()))
+			return
+		}
+		result = h.sched.ExplainVolume(h.state.GetAllNodes(), &vol)
+	}
+
+	bs, err := json.Marshal(result)
+	if err != nil {
+		writer.Write([]byte(err.Error()))
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Write(bs)
+}
@@ -0,0 +1,30 @@
+package priority
+
+import (
+	"context"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/state"
+)
+
+// PriorityByFewestVolumes is a PriorityFunc. Nodes with fewer volumes are more prefered.
+// Score is relative to the max volume count of all nodes.
+func PriorityByFewestVolumes(ctx context.Context, n *state.Node, vol *v1.AntstorVolume) int {
+	var (
+		allNodes, _ = ctx.Value(CtxAllNodesKey).([]*state.Node)
+		cnt         = len(n.Volumes)
+		maxCnt      = cnt
+	)
+
+	for _, item := range allNodes {
+		if len(item.Volumes) > maxCnt {
+			maxCnt = len(item.Volumes)
+		}
+	}
+	if maxCnt == 0 {
+		return MaxScore
+	}
+
+	// score = (max - cnt) / max * 100
+	return (maxCnt - cnt) * MaxScore / maxCnt
+}
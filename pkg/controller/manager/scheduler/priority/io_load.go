@@ -0,0 +1,44 @@
+package priority
+
+import (
+	"context"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/state"
+)
+
+// PriorityByLowestIOLoad is a PriorityFunc, which steers remote volumes away from hot pools. Nodes with lower recent IO utilization are more prefered.
+// Pools whose IO utilization exceeds HotPoolUtilPct get zero score. Nodes without IO load or with an expired one get the medium score.
+// Local placement is not penalized, as it adds no remote IO to the pool.
+func PriorityByLowestIOLoad(ctx context.Context, n *state.Node, vol *v1.AntstorVolume) int {
+	var (
+		cfg, _ = ctx.Value(CtxConfigKey).(config.SchedulerConfig)
+		load   = n.Pool.GetRecentIOLoad()
+	)
+
+	if vol.Spec.HostNode != nil && vol.Spec.HostNode.ID == n.Info.ID {
+		return MaxScore
+	}
+	if load == nil {
+		return MaxScore / 2
+	}
+
+	var util = clampPct(load.UtilPct)
+	if cfg.HotPoolUtilPct > 0 && util >= cfg.HotPoolUtilPct {
+		return 0
+	}
+
+	// score = (100 - util) / 100 * 100
+	return (100 - util) * MaxScore / 100
+}
+
+func clampPct(pct int) int {
+	if pct < 0 {
+		return 0
+	}
+	if pct > 100 {
+		return 100
+	}
+	return pct
+}
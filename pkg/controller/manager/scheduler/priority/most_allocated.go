@@ -0,0 +1,31 @@
+package priority
+
+import (
+	"context"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"k8s.io/klog/v2"
+)
+
+// PriorityByMostAllocated is a PriorityFunc for bin-packing. Nodes with more allocated space are more prefered.
+// Different from LeastResource, which uses VG free size reported by agent, the allocated space is calculated in controller memory,
+// including volumes and reservations which are not created yet.
+func PriorityByMostAllocated(ctx context.Context, n *state.Node, vol *v1.AntstorVolume) int {
+	var (
+		total = n.Pool.Status.Capacity.Storage().AsApproximateFloat64()
+		free  = n.FreeResource.Storage().AsApproximateFloat64()
+	)
+	if total <= 0 {
+		klog.Errorf("found StoragePool %s total space is invalid", n.Pool.Name)
+		return 0
+	}
+
+	// score = (allocated / total) * 100
+	score := int((total - free) / total * MaxScore)
+	if score < 0 {
+		score = 0
+	}
+
+	return score
+}
@@ -2,6 +2,7 @@ package priority
 
 import (
 	"context"
+	"fmt"
 	"sort"
 
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
@@ -10,6 +11,13 @@ import (
 	"k8s.io/klog/v2"
 )
 
+const (
+	// context key of SchedulerConfig
+	CtxConfigKey = "schedulerConfig"
+	// context key of all nodes before filtering
+	CtxAllNodesKey = "allNodes"
+)
+
 type PriorityResult struct {
 	NodeID string
 	Score  int
@@ -27,19 +35,34 @@ func (p PriorityResultList) Swap(i, j int) {
 	p[i], p[j] = p[j], p[i]
 }
 
+// PriorityExplain is the score breakdown of a node
+type PriorityExplain struct {
+	NodeID string `json:"nodeId"`
+	Score  int    `json:"score"`
+	// Scores of each priority, keyed by priority name
+	Scores map[string]int `json:"scores"`
+}
+
 type PriorityFunc func(context.Context, *state.Node, *v1.AntstorVolume) int
 
+type weightedPriority struct {
+	name     string
+	fn       PriorityFunc
+	weight   int
+	maxScore int
+}
+
 type PriorityCalculator struct {
 	nodes []*state.Node
 	vol   *v1.AntstorVolume
-	funcs []PriorityFunc
+	funcs []weightedPriority
 	ctx   context.Context
 	cfg   config.SchedulerConfig
 }
 
 func NewPriorityCalculator(cfg config.SchedulerConfig) *PriorityCalculator {
 	return &PriorityCalculator{
-		ctx: context.Background(),
+		ctx: context.WithValue(context.Background(), CtxConfigKey, cfg),
 		cfg: cfg,
 	}
 }
@@ -51,22 +74,43 @@ func (pc *PriorityCalculator) Input(nodes []*state.Node, vol *v1.AntstorVolume)
 }
 
 func (pc *PriorityCalculator) LoadPriorityFromConfig() *PriorityCalculator {
-	for _, name := range pc.cfg.Priorities {
-		fn, err := GetPriorityByName(name)
+	for _, item := range pc.cfg.Priorities {
+		fn, err := GetPriorityByName(item.Name)
 		if err != nil {
 			klog.Error(err)
 			continue
 		} else {
-			klog.Info("use priority ", name)
-			pc.AddPriorityFunc(fn)
+			klog.Info("use priority ", item.Name, " weight ", item.Weight)
+			pc.AddWeightedPriorityFunc(item.Name, fn, item.Weight, GetMaxScoreByName(item.Name))
 		}
 	}
 
 	return pc
 }
 
+// AddPriorityFunc adds a priority whose score is 0-MaxScore, and weight is 1
 func (pc *PriorityCalculator) AddPriorityFunc(f PriorityFunc) *PriorityCalculator {
-	pc.funcs = append(pc.funcs, f)
+	return pc.AddWeightedPriorityFunc("", f, 1, MaxScore)
+}
+
+// AddWeightedPriorityFunc adds a priority whose score is 0-maxScore. The score is normalized to 0-MaxScore then multiplied by weight.
+// If weight is 0, the raw score is used.
+func (pc *PriorityCalculator) AddWeightedPriorityFunc(name string, f PriorityFunc, weight, maxScore int) *PriorityCalculator {
+	if weight < 0 {
+		weight = 0
+	}
+	if maxScore <= 0 {
+		maxScore = MaxScore
+	}
+	if name == "" {
+		name = fmt.Sprintf("anonymous-%d", len(pc.funcs))
+	}
+	pc.funcs = append(pc.funcs, weightedPriority{
+		name:     name,
+		fn:       f,
+		weight:   weight,
+		maxScore: maxScore,
+	})
 	return pc
 }
 
@@ -87,13 +131,11 @@ func (pc *PriorityCalculator) GetFirstByScore() (*state.Node, int) {
 
 	resultList := make([]PriorityResult, 0, len(pc.nodes))
 	for _, node := range pc.nodes {
-		var result = PriorityResult{
+		score, _ := pc.score(node)
+		resultList = append(resultList, PriorityResult{
 			NodeID: node.Pool.Spec.NodeInfo.ID,
-		}
-		for _, pfunc := range pc.funcs {
-			result.Score += pfunc(pc.ctx, node, pc.vol)
-		}
-		resultList = append(resultList, result)
+			Score:  score,
+		})
 	}
 
 	sort.Sort(sort.Reverse(PriorityResultList(resultList)))
@@ -108,3 +150,62 @@ func (pc *PriorityCalculator) GetFirstByScore() (*state.Node, int) {
 
 	return nil, 0
 }
+
+// Explain returns weighted score of each priority on every node, sorted by total score, high -> low
+func (pc *PriorityCalculator) Explain() (results []PriorityExplain) {
+	if pc.ctx == nil {
+		pc.ctx = context.Background()
+	}
+
+	for _, node := range pc.nodes {
+		score, scores := pc.score(node)
+		results = append(results, PriorityExplain{
+			NodeID: node.Pool.Spec.NodeInfo.ID,
+			Score:  score,
+			Scores: scores,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return
+}
+
+// score returns sum of weighted scores of the node, and weighted score of each priority
+func (pc *PriorityCalculator) score(node *state.Node) (total int, scores map[string]int) {
+	scores = make(map[string]int, len(pc.funcs))
+	for _, item := range pc.funcs {
+		var score = item.fn(pc.ctx, node, pc.vol)
+		if item.weight > 0 {
+			score = NormalizeScore(score, item.maxScore) * item.weight
+		} else {
+			score = clampScore(score, item.maxScore)
+		}
+		scores[item.name] += score
+		total += score
+	}
+	return
+}
+
+// clampScore limits raw score to 0-maxScore
+func clampScore(score, maxScore int) int {
+	if score < 0 {
+		return 0
+	}
+	if score > maxScore {
+		return maxScore
+	}
+	return score
+}
+
+// NormalizeScore scales score of 0-maxScore to 0-MaxScore
+func NormalizeScore(score, maxScore int) int {
+	if score <= 0 || maxScore <= 0 {
+		return 0
+	}
+	if score >= maxScore {
+		return MaxScore
+	}
+	return score * MaxScore / maxScore
+}
@@ -1,9 +1,19 @@
 package priority
 
 import (
+	"context"
 	"sort"
 	"strconv"
 	"testing"
+	"time"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/state"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestPriorityList(t *testing.T) {
@@ -17,3 +27,209 @@ func TestPriorityList(t *testing.T) {
 
 	t.Logf("%+v", list)
 }
+
+func TestNormalizeScore(t *testing.T) {
+	cases := []struct {
+		score    int
+		maxScore int
+		expect   int
+	}{
+		{score: 0, maxScore: 20, expect: 0},
+		{score: 10, maxScore: 20, expect: 50},
+		{score: 20, maxScore: 20, expect: 100},
+		{score: 30, maxScore: 20, expect: 100},
+		{score: -1, maxScore: 20, expect: 0},
+		{score: 10, maxScore: 0, expect: 0},
+		{score: 42, maxScore: 100, expect: 42},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expect, NormalizeScore(c.score, c.maxScore), "%+v", c)
+	}
+}
+
+func TestPriorityByMostAllocated(t *testing.T) {
+	cases := []struct {
+		name     string
+		total    int64
+		volSizes []uint64
+		expected int
+	}{
+		{name: "empty", total: 100 << 30, expected: 0},
+		{name: "half", total: 100 << 30, volSizes: []uint64{20 << 30, 30 << 30}, expected: 50},
+		{name: "full", total: 100 << 30, volSizes: []uint64{100 << 30}, expected: 100},
+		{name: "invalid total", total: 0, expected: 0},
+	}
+
+	for _, c := range cases {
+		node := newNode("node-1", c.total)
+		for idx, size := range c.volSizes {
+			assert.NoError(t, node.AddVolume(newVolume("vol-"+strconv.Itoa(idx), size)))
+		}
+		score := PriorityByMostAllocated(context.Background(), node, newVolume("vol", 1<<30))
+		assert.Equal(t, c.expected, score, c.name)
+	}
+}
+
+func TestPriorityByFewestVolumes(t *testing.T) {
+	cases := []struct {
+		name     string
+		volCnts  []int
+		nodeIdx  int
+		expected int
+	}{
+		{name: "all empty", volCnts: []int{0, 0}, nodeIdx: 0, expected: 100},
+		{name: "fewest", volCnts: []int{0, 4}, nodeIdx: 0, expected: 100},
+		{name: "most", volCnts: []int{0, 4}, nodeIdx: 1, expected: 0},
+		{name: "middle", volCnts: []int{1, 4, 2}, nodeIdx: 2, expected: 50},
+	}
+
+	for _, c := range cases {
+		var allNodes []*state.Node
+		for i, cnt := range c.volCnts {
+			node := newNode("node-"+strconv.Itoa(i), 100<<30)
+			for j := 0; j < cnt; j++ {
+				assert.NoError(t, node.AddVolume(newVolume("vol-"+strconv.Itoa(j), 1<<30)))
+			}
+			allNodes = append(allNodes, node)
+		}
+		ctx := context.WithValue(context.Background(), CtxAllNodesKey, allNodes)
+		score := PriorityByFewestVolumes(ctx, allNodes[c.nodeIdx], newVolume("vol", 1<<30))
+		assert.Equal(t, c.expected, score, c.name)
+	}
+}
+
+func TestPriorityByLowestIOLoad(t *testing.T) {
+	cfg := config.SchedulerConfig{HotPoolUtilPct: 70}
+	cases := []struct {
+		name     string
+		nodeID   string
+		load     *v1.PoolIOLoad
+		expected int
+	}{
+		{name: "local", nodeID: "node-1", load: &v1.PoolIOLoad{UtilPct: 95, UpdateTime: metav1.Now()}, expected: 100},
+		{name: "no load", nodeID: "node-2", load: nil, expected: 50},
+		{name: "idle", nodeID: "node-2", load: &v1.PoolIOLoad{UtilPct: 0, UpdateTime: metav1.Now()}, expected: 100},
+		{name: "busy", nodeID: "node-2", load: &v1.PoolIOLoad{UtilPct: 60, UpdateTime: metav1.Now()}, expected: 40},
+		{name: "hot", nodeID: "node-2", load: &v1.PoolIOLoad{UtilPct: 70, UpdateTime: metav1.Now()}, expected: 0},
+		{name: "overflow", nodeID: "node-2", load: &v1.PoolIOLoad{UtilPct: 120, UpdateTime: metav1.Now()}, expected: 0},
+		{name: "expired", nodeID: "node-2", load: &v1.PoolIOLoad{UtilPct: 80, UpdateTime: metav1.NewTime(time.Now().Add(-time.Hour))}, expected: 50},
+	}
+
+	for _, c := range cases {
+		node := newNode(c.nodeID, 100<<30)
+		node.Pool.Status.IOLoad = c.load
+		ctx := context.WithValue(context.Background(), CtxConfigKey, cfg)
+		score := PriorityByLowestIOLoad(ctx, node, newVolume("vol", 1<<30))
+		assert.Equal(t, c.expected, score, c.name)
+	}
+
+	// pool is not regarded as hot without HotPoolUtilPct
+	node := newNode("node-2", 100<<30)
+	node.Pool.Status.IOLoad = &v1.PoolIOLoad{UtilPct: 80, UpdateTime: metav1.Now()}
+	assert.Equal(t, 20, PriorityByLowestIOLoad(context.Background(), node, newVolume("vol", 1<<30)))
+}
+
+func TestWeightedPriority(t *testing.T) {
+	// node-1 is local and empty, node-2 is remote and half allocated
+	local := newNode("node-1", 100<<30)
+	remote := newNode("node-2", 100<<30)
+	assert.NoError(t, remote.AddVolume(newVolume("vol-1", 50<<30)))
+
+	vol := newVolume("vol", 1<<30)
+	vol.Spec.PositionAdvice = v1.PreferLocal
+
+	cases := []struct {
+		name       string
+		priorities []config.PriorityConfig
+		expected   string
+		score      int
+	}{
+		{
+			name: "position advice dominates",
+			priorities: []config.PriorityConfig{
+				{Name: "MostAllocated", Weight: 1},
+				{Name: "PositionAdvice", Weight: 1},
+			},
+			expected: "node-1",
+			score:    100,
+		},
+		{
+			name: "raw scores without weight",
+			priorities: []config.PriorityConfig{
+				{Name: "MostAllocated"},
+				{Name: "PositionAdvice"},
+			},
+			expected: "node-2",
+			score:    50,
+		},
+		{
+			name: "most allocated dominates",
+			priorities: []config.PriorityConfig{
+				{Name: "MostAllocated", Weight: 3},
+				{Name: "PositionAdvice", Weight: 1},
+			},
+			expected: "node-2",
+			score:    150,
+		},
+		{
+			name: "unknown priority is ignored",
+			priorities: []config.PriorityConfig{
+				{Name: "NotExisting", Weight: 10},
+				{Name: "FewestVolumes", Weight: 2},
+			},
+			expected: "node-1",
+			score:    200,
+		},
+	}
+
+	for _, c := range cases {
+		pc := NewPriorityCalculator(config.SchedulerConfig{Priorities: c.priorities}).
+			WithContextValue(CtxAllNodesKey, []*state.Node{local, remote}).
+			Input([]*state.Node{local, remote}, vol).
+			LoadPriorityFromConfig()
+
+		node, score := pc.GetFirstByScore()
+		assert.Equal(t, c.expected, node.Info.ID, c.name)
+		assert.Equal(t, c.score, score, c.name)
+
+		results := pc.Explain()
+		assert.Len(t, results, 2, c.name)
+		assert.Equal(t, c.expected, results[0].NodeID, c.name)
+		assert.Equal(t, c.score, results[0].Score, c.name)
+	}
+}
+
+func newNode(nodeID string, total int64) *state.Node {
+	pool := &v1.StoragePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeID,
+		},
+		Spec: v1.StoragePoolSpec{
+			NodeInfo: v1.NodeInfo{
+				ID: nodeID,
+			},
+		},
+		Status: v1.StoragePoolStatus{
+			Capacity: corev1.ResourceList{
+				v1.ResourceDiskPoolByte: *resource.NewQuantity(total, resource.BinarySI),
+			},
+		},
+	}
+	return state.NewNode(pool)
+}
+
+func newVolume(name string, size uint64) *v1.AntstorVolume {
+	return &v1.AntstorVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: v1.AntstorVolumeSpec{
+			Uuid:     "uuid-" + name,
+			SizeByte: size,
+			HostNode: &v1.NodeInfo{
+				ID: "node-1",
+			},
+		},
+	}
+}
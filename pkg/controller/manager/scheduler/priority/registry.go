@@ -5,22 +5,40 @@ import (
 	"sync"
 )
 
+const (
+	// MaxScore is the max normalized score of a priority
+	MaxScore = 100
+)
+
 var (
 	regLock sync.Mutex
 
 	prioritiesMap = make(map[string]PriorityFunc, 0)
+	// max raw score of priorities, which is used to normalize score to 0-MaxScore
+	maxScoresMap = make(map[string]int, 0)
 )
 
 func init() {
 	RegisterPriorityFunc("LeastResource", PriorityByLeastResource)
-	RegisterPriorityFunc("PositionAdvice", PriorityByPositionAdivce)
+	RegisterPriorityFuncWithMaxScore("PositionAdvice", PriorityByPositionAdivce, 20)
+	RegisterPriorityFuncWithMaxScore("TopologySpread", PriorityByTopologySpread, 20)
+	RegisterPriorityFunc("MostAllocated", PriorityByMostAllocated)
+	RegisterPriorityFunc("FewestVolumes", PriorityByFewestVolumes)
+	RegisterPriorityFunc("LowestIOLoad", PriorityByLowestIOLoad)
 }
 
+// RegisterPriorityFunc registers a priority whose score is 0-MaxScore
 func RegisterPriorityFunc(name string, filter PriorityFunc) {
+	RegisterPriorityFuncWithMaxScore(name, filter, MaxScore)
+}
+
+// RegisterPriorityFuncWithMaxScore registers a priority whose score is 0-maxScore
+func RegisterPriorityFuncWithMaxScore(name string, filter PriorityFunc, maxScore int) {
 	regLock.Lock()
 	defer regLock.Unlock()
 
 	prioritiesMap[name] = filter
+	maxScoresMap[name] = maxScore
 }
 
 func GetPriorityByName(name string) (filter PriorityFunc, err error) {
@@ -32,3 +50,14 @@ func GetPriorityByName(name string) (filter PriorityFunc, err error) {
 	}
 	return nil, fmt.Errorf("not found priority by name %s", name)
 }
+
+// GetMaxScoreByName returns the max raw score of the priority. It is MaxScore if the priority is not found.
+func GetMaxScoreByName(name string) (maxScore int) {
+	regLock.Lock()
+	defer regLock.Unlock()
+
+	if val, has := maxScoresMap[name]; has && val > 0 {
+		return val
+	}
+	return MaxScore
+}
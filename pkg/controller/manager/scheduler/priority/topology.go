@@ -0,0 +1,47 @@
+package priority
+
+import (
+	"context"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/scheduler/filter"
+	"lite.io/liteio/pkg/controller/manager/state"
+)
+
+// PriorityByTopologySpread is a PriorityFunc. Nodes in the topology domain with fewer volumes of the same data-holder are more prefered.
+func PriorityByTopologySpread(ctx context.Context, n *state.Node, vol *v1.AntstorVolume) int {
+	var (
+		cfg, _      = ctx.Value(CtxConfigKey).(config.SchedulerConfig)
+		allNodes, _ = ctx.Value(CtxAllNodesKey).([]*state.Node)
+	)
+
+	spread, ok := filter.GetTopologySpread(cfg, vol.Annotations)
+	if !ok || vol.Labels[v1.VolumeDataHolderKey] == "" {
+		return 0
+	}
+
+	var domain = spread.Domain(n)
+	if domain == "" {
+		return 0
+	}
+
+	var (
+		counts         = spread.CountVolumes(allNodes, filter.SameDataHolder(vol))
+		minCnt, maxCnt = counts[domain], counts[domain]
+	)
+	for _, cnt := range counts {
+		if cnt < minCnt {
+			minCnt = cnt
+		}
+		if cnt > maxCnt {
+			maxCnt = cnt
+		}
+	}
+	if maxCnt == minCnt {
+		return 0
+	}
+
+	// score = (max - cnt) / (max - min) * 20
+	return (maxCnt - counts[domain]) * 20 / (maxCnt - minCnt)
+}
@@ -14,6 +14,8 @@ import (
 type SchedulerIface interface {
 	ScheduleVolume(allNodes []*state.Node, vol *v1.AntstorVolume) (node v1.NodeInfo, err error)
 	ScheduleVolumeGroup(allNodes []*state.Node, volGroup *v1.AntstorVolumeGroup) (err error)
+	ExplainVolume(allNodes []*state.Node, vol *v1.AntstorVolume) (result ExplainResult)
+	ExplainVolumeGroup(allNodes []*state.Node, volGroup *v1.AntstorVolumeGroup) (result ExplainResult)
 }
 
 type scheduler struct {
@@ -62,13 +64,13 @@ func (s *scheduler) ScheduleVolume(allNodes []*state.Node, vol *v1.AntstorVolume
 	return
 }
 
-func (s *scheduler) sched(nodes []*state.Node, vol *v1.AntstorVolume) (node *state.Node, err error) {
-	nodes, err = predicate(nodes, vol, s.cfg.Scheduler)
+func (s *scheduler) sched(allNodes []*state.Node, vol *v1.AntstorVolume) (node *state.Node, err error) {
+	nodes, err := predicate(allNodes, vol, s.cfg.Scheduler)
 	if err != nil {
 		return
 	}
 
-	node = byPriority(nodes, vol, s.cfg.Scheduler)
+	node = byPriority(allNodes, nodes, vol, s.cfg.Scheduler)
 
 	return
 }
@@ -82,12 +84,13 @@ func predicate(nodes []*state.Node, vol *v1.AntstorVolume, cfg config.SchedulerC
 	return
 }
 
-func byPriority(nodes []*state.Node, vol *v1.AntstorVolume, cfg config.SchedulerConfig) (node *state.Node) {
+func byPriority(allNodes, nodes []*state.Node, vol *v1.AntstorVolume, cfg config.SchedulerConfig) (node *state.Node) {
 	if len(nodes) == 0 || vol == nil {
 		return
 	}
 
 	node, _ = priority.NewPriorityCalculator(cfg).
+		WithContextValue(priority.CtxAllNodesKey, allNodes).
 		Input(nodes, vol).
 		// AddPriorityFunc(priority.PriorityByPositionAdivce).
 		// AddPriorityFunc(priority.PriorityByLeastResource).
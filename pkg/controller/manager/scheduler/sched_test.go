@@ -5,6 +5,7 @@ import (
 
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
 	"lite.io/liteio/pkg/controller/manager/config"
+	"lite.io/liteio/pkg/controller/manager/scheduler/filter"
 	"lite.io/liteio/pkg/controller/manager/state"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -18,7 +19,7 @@ func TestSched(t *testing.T) {
 		config.Config{
 			Scheduler: config.SchedulerConfig{
 				Filters:    []string{"Basic", "Affinity"},
-				Priorities: []string{"LeastResource", "PositionAdvice"},
+				Priorities: []config.PriorityConfig{{Name: "LeastResource"}, {Name: "PositionAdvice"}},
 			},
 		})
 
@@ -193,7 +194,7 @@ func TestSchedVolGroup(t *testing.T) {
 				MaxRemoteVolumeCount:     3,
 				RemoteIgnoreAnnoSelector: nil,
 				Filters:                  []string{"Basic", "Affinity"},
-				Priorities:               []string{"LeastResource", "PositionAdvice"},
+				Priorities:               []config.PriorityConfig{{Name: "LeastResource"}, {Name: "PositionAdvice"}},
 			},
 		})
 
@@ -255,6 +256,273 @@ func TestSchedVolGroup(t *testing.T) {
 
 }
 
+func TestSchedPriorityWeight(t *testing.T) {
+	var tenGiB uint64 = 10 << 30
+	memState := state.NewState()
+	// node-1 is 50% used, node-2 is 80% used
+	for id, used := range map[string]uint64{"node-1": tenGiB / 2, "node-2": tenGiB / 10 * 8} {
+		pool := newStoragePool(id, tenGiB)
+		pool.Status.VGFreeSize = *resource.NewQuantity(int64(tenGiB-used), resource.BinarySI)
+		memState.SetStoragePool(pool)
+	}
+
+	vol := newVolume("vol-1", tenGiB/10)
+	vol.Spec.PositionAdvice = v1.PreferLocal
+
+	for _, item := range []struct {
+		priorities []config.PriorityConfig
+		expected   string
+	}{
+		// raw scores: node-1 50+20, node-2 80
+		{priorities: []config.PriorityConfig{{Name: "LeastResource"}, {Name: "PositionAdvice"}}, expected: "node-2"},
+		// normalized scores: node-1 50+100, node-2 80
+		{priorities: []config.PriorityConfig{{Name: "LeastResource", Weight: 1}, {Name: "PositionAdvice", Weight: 1}}, expected: "node-1"},
+		// normalized scores: node-1 250+100, node-2 400
+		{priorities: []config.PriorityConfig{{Name: "LeastResource", Weight: 5}, {Name: "PositionAdvice", Weight: 1}}, expected: "node-2"},
+	} {
+		sched := NewScheduler(
+			config.Config{
+				Scheduler: config.SchedulerConfig{
+					MaxRemoteVolumeCount: 3,
+					Filters:              []string{"Basic", "Affinity"},
+					Priorities:           item.priorities,
+				},
+			})
+		targetNode, err := sched.ScheduleVolume(memState.GetAllNodes(), vol)
+		assert.NoError(t, err)
+		assert.Equal(t, item.expected, targetNode.ID, item.priorities)
+	}
+}
+
+func TestSchedTransport(t *testing.T) {
+	var tenGiB uint64 = 10 << 30
+	memState := state.NewState()
+	sched := NewScheduler(
+		config.Config{
+			Scheduler: config.SchedulerConfig{
+				MaxRemoteVolumeCount: 3,
+				Filters:              []string{"Basic", "Affinity", "Transport"},
+				Priorities: []config.PriorityConfig{
+					{Name: "LeastResource", Weight: 5},
+					{Name: "PositionAdvice", Weight: 1},
+				},
+			},
+		})
+
+	rdmaPool := newStoragePool("node-3", tenGiB)
+	rdmaPool.Spec.Transports = []string{"RDMA", "TCP"}
+	memState.SetStoragePool(newStoragePool("node-2", tenGiB))
+	memState.SetStoragePool(rdmaPool)
+
+	// volume of RDMA is only scheduled to the pool supporting RDMA
+	vol := newVolume("vol-rdma", tenGiB/10)
+	vol.Annotations = map[string]string{
+		v1.TransportTypeAnnoKey: "rdma",
+	}
+	targetNode, err := sched.ScheduleVolume(memState.GetAllNodes(), vol)
+	assert.NoError(t, err)
+	assert.Equal(t, "node-3", targetNode.ID)
+
+	// no pool supports RDMA
+	memState.RemoveStoragePool("node-3")
+	_, err = sched.ScheduleVolume(memState.GetAllNodes(), vol)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "TransportNotMatch")
+
+	// volume of TCP could be scheduled to pool without transports
+	vol = newVolume("vol-tcp", tenGiB/10)
+	targetNode, err = sched.ScheduleVolume(memState.GetAllNodes(), vol)
+	assert.NoError(t, err)
+	assert.Equal(t, "node-2", targetNode.ID)
+}
+
+func TestSchedTopologySpread(t *testing.T) {
+	var tenGiB uint64 = 10 << 30
+	memState := state.NewState()
+	sched := NewScheduler(
+		config.Config{
+			Scheduler: config.SchedulerConfig{
+				MaxRemoteVolumeCount: 3,
+				Filters:              []string{"Basic", "Affinity", "TopologySpread"},
+				Priorities: []config.PriorityConfig{
+					{Name: "LeastResource", Weight: 5},
+					{Name: "PositionAdvice", Weight: 1},
+					{Name: "TopologySpread", Weight: 1},
+				},
+				RackLabelKey: "rack",
+			},
+		})
+
+	for id, rack := range map[string]string{"node-1": "rack-a", "node-2": "rack-a", "node-3": "rack-b", "node-4": ""} {
+		pool := newStoragePool(id, tenGiB)
+		if rack != "" {
+			pool.Spec.NodeInfo.Labels["rack"] = rack
+		}
+		memState.SetStoragePool(pool)
+	}
+	memState.BindAntstorVolume("node-1", newVolume("vol-1", tenGiB/10))
+
+	// rack-a has one volume of the same data-holder, so vol-2 is scheduled to rack-b
+	vol := newVolume("vol-2", tenGiB/10)
+	vol.Annotations = map[string]string{
+		v1.TopologySpreadKeyAnnoKey: "rack",
+	}
+	targetNode, err := sched.ScheduleVolume(memState.GetAllNodes(), vol)
+	assert.NoError(t, err)
+	assert.Equal(t, "node-3", targetNode.ID)
+	memState.BindAntstorVolume(targetNode.ID, vol)
+
+	// rack-a and rack-b both have one volume, max skew 1 allows both of them, but not node-4 without rack label
+	vol = newVolume("vol-3", tenGiB/10)
+	vol.Annotations = map[string]string{
+		v1.TopologySpreadKeyAnnoKey: "rack",
+	}
+	targetNode, err = sched.ScheduleVolume(memState.GetAllNodes(), vol)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "node-4", targetNode.ID)
+	memState.BindAntstorVolume(targetNode.ID, vol)
+
+	// volumes of the other data-holder are not constrained by rack
+	vol = newVolume("vol-4", tenGiB/10)
+	vol.Labels[v1.VolumeDataHolderKey] = "ob.otherCluster.zone"
+	_, err = sched.ScheduleVolume(memState.GetAllNodes(), vol)
+	assert.NoError(t, err)
+
+	// members of VolumeGroup are spread across racks
+	volGroup := &v1.AntstorVolumeGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "obnvmf",
+			Name:      "volgroup-spread",
+			Annotations: map[string]string{
+				v1.TopologySpreadKeyAnnoKey: "rack",
+			},
+		},
+		Spec: v1.AntstorVolumeGroupSpec{
+			TotalSize: 2 * int64(tenGiB/10),
+			Uuid:      "uuid-vg-spread",
+			Stragety: v1.VolumeGroupStrategy{
+				AllowEmptyNode: true,
+			},
+			DesiredVolumeSpec: v1.DesiredVolumeSpec{
+				CountRange: v1.IntRange{
+					Min: 1,
+					Max: 4,
+				},
+				SizeRange: v1.QuantityRange{
+					Min: resource.MustParse("1Gi"),
+					Max: resource.MustParse("1Gi"),
+				},
+				SizeSymmetry: v1.Asymmetric,
+			},
+		},
+	}
+	err = sched.ScheduleVolumeGroup(memState.GetAllNodes(), volGroup)
+	assert.NoError(t, err)
+	assert.Len(t, volGroup.Spec.Volumes, 2)
+	var racks = make(map[string]bool)
+	for _, item := range volGroup.Spec.Volumes {
+		pool, err := memState.GetStoragePoolByNodeID(item.TargetNodeName)
+		assert.NoError(t, err)
+		racks[pool.Spec.NodeInfo.Labels["rack"]] = true
+	}
+	assert.Equal(t, map[string]bool{"rack-a": true, "rack-b": true}, racks)
+}
+
+func TestSchedIOUtilCeiling(t *testing.T) {
+	var tenGiB uint64 = 10 << 30
+	memState := state.NewState()
+	sched := NewScheduler(
+		config.Config{
+			Scheduler: config.SchedulerConfig{
+				MaxRemoteVolumeCount: 3,
+				PoolIOUtilCeilingPct: 90,
+				HotPoolUtilPct:       70,
+				Filters:              []string{"Basic", "Affinity", "IOUtilCeiling"},
+				Priorities: []config.PriorityConfig{
+					{Name: "LowestIOLoad", Weight: 1},
+				},
+			},
+		})
+
+	var loads = map[string]int{"node-2": 95, "node-3": 75, "node-4": 20}
+	for nodeID, util := range loads {
+		pool := newStoragePool(nodeID, tenGiB)
+		pool.Status.IOLoad = &v1.PoolIOLoad{
+			UtilPct:    util,
+			UpdateTime: metav1.Now(),
+		}
+		memState.SetStoragePool(pool)
+	}
+
+	// remote volume is scheduled to the idle pool
+	vol := newVolume("vol-1", tenGiB/10)
+	targetNode, err := sched.ScheduleVolume(memState.GetAllNodes(), vol)
+	assert.NoError(t, err)
+	assert.Equal(t, "node-4", targetNode.ID)
+
+	// pool exceeding ceiling is filtered out
+	memState.RemoveStoragePool("node-3")
+	memState.RemoveStoragePool("node-4")
+	_, err = sched.ScheduleVolume(memState.GetAllNodes(), vol)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), filter.ReasonPoolIOUtilHigh)
+}
+
+func TestExplainVolume(t *testing.T) {
+	var tenGiB uint64 = 10 << 30
+	memState := state.NewState()
+	cfg := config.Config{
+		Scheduler: config.SchedulerConfig{
+			MaxRemoteVolumeCount: 3,
+			Filters:              []string{"Basic", "Affinity", "Transport"},
+			Priorities: []config.PriorityConfig{
+				{Name: "LeastResource", Weight: 5},
+				{Name: "PositionAdvice", Weight: 1},
+			},
+		},
+	}
+
+	rdmaPool := newStoragePool("node-3", tenGiB)
+	rdmaPool.Spec.Transports = []string{"RDMA", "TCP"}
+	memState.SetStoragePool(newStoragePool("node-2", tenGiB))
+	memState.SetStoragePool(rdmaPool)
+
+	vol := newVolume("vol-rdma", tenGiB/10)
+	vol.Annotations = map[string]string{
+		v1.TransportTypeAnnoKey: "rdma",
+	}
+	result := NewScheduler(cfg).ExplainVolume(memState.GetAllNodes(), vol)
+	t.Logf("%+v", result)
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "node-3", result.Selected)
+	assert.Empty(t, vol.Spec.TargetNodeId)
+	assert.Len(t, result.Filters, 2)
+	for _, item := range result.Filters {
+		if item.NodeID == "node-2" {
+			assert.False(t, item.Passed)
+			assert.Equal(t, "Transport", item.Filter)
+			assert.Equal(t, []string{filter.ReasonTransportNotMatch}, item.Reasons)
+		} else {
+			assert.True(t, item.Passed)
+		}
+	}
+	assert.Len(t, result.Priorities, 1)
+	assert.Contains(t, result.Priorities[0].Scores, "LeastResource")
+	assert.Contains(t, result.Priorities[0].Scores, "PositionAdvice")
+
+	// no pool is available
+	memState.RemoveStoragePool("node-3")
+	result = NewScheduler(cfg).ExplainVolume(memState.GetAllNodes(), vol)
+	assert.Contains(t, result.Error, filter.ReasonTransportNotMatch)
+	assert.Empty(t, result.Selected)
+	assert.Empty(t, result.Priorities)
+
+	// nothing is reserved
+	node, err := memState.GetNodeByNodeID("node-2")
+	assert.NoError(t, err)
+	assert.Empty(t, node.Volumes)
+}
+
 func newStoragePool(nodeID string, size uint64) (pool *v1.StoragePool) {
 	pool = &v1.StoragePool{
 		ObjectMeta: metav1.ObjectMeta{
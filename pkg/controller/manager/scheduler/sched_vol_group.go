@@ -51,7 +51,7 @@ func (s *scheduler) ScheduleVolumeGroup(allNodes []*state.Node, volGroup *v1.Ant
 	// node usage < empty threashold, set score to 0, last of the list
 	sort.Sort(sort.Reverse(SortByStorage(qualified)))
 
-	err = schedVolGroup(s.cfg, qualified, volGroup)
+	err = schedVolGroup(s.cfg, allNodes, qualified, volGroup)
 	if err != nil {
 		klog.Error(err)
 		return
@@ -62,7 +62,7 @@ func (s *scheduler) ScheduleVolumeGroup(allNodes []*state.Node, volGroup *v1.Ant
 	return
 }
 
-func schedVolGroup(cfg config.Config, nodes []*state.Node, volGroup *v1.AntstorVolumeGroup) (err error) {
+func schedVolGroup(cfg config.Config, allNodes, nodes []*state.Node, volGroup *v1.AntstorVolumeGroup) (err error) {
 	var (
 		maxVolCnt            = volGroup.Spec.DesiredVolumeSpec.CountRange.Max
 		maxSize              = volGroup.Spec.DesiredVolumeSpec.SizeRange.Max
@@ -73,6 +73,12 @@ func schedVolGroup(cfg config.Config, nodes []*state.Node, volGroup *v1.AntstorV
 		unschedIndexes []int
 	)
 
+	// topology domain -> count of members
+	var (
+		spread, needSpread = filter.GetTopologySpread(cfg.Scheduler, volGroup.Annotations)
+		domainCounts       = make(map[string]int)
+	)
+
 	// pick a correct size. if result is 0, it means picking failed.
 	var pickSizeFn = func(node *state.Node, volSize int64) (result int64) {
 		var free = *node.FreeResource.Storage()
@@ -101,6 +107,31 @@ func schedVolGroup(cfg config.Config, nodes []*state.Node, volGroup *v1.AntstorV
 		return cnt < maxVolCnt && leftSize > 0
 	}
 
+	// members are spread across topology domains of qualified nodes
+	spreadFitsFn := func(node *state.Node) bool {
+		if !needSpread {
+			return true
+		}
+		domain := spread.Domain(node)
+		return domain != "" && spread.Fits(domainCounts, domain)
+	}
+	if needSpread {
+		for _, item := range nodes {
+			if domain := spread.Domain(item); domain != "" {
+				domainCounts[domain] = 0
+			}
+		}
+		for _, vol := range volGroup.Spec.Volumes {
+			if vol.Size > 0 && vol.TargetNodeName != "" {
+				for _, item := range allNodes {
+					if domain := spread.Domain(item); item.Info.ID == vol.TargetNodeName && domain != "" {
+						domainCounts[domain]++
+					}
+				}
+			}
+		}
+	}
+
 	// record unsched volumes
 	for idx, vol := range volGroup.Spec.Volumes {
 		if vol.Size > 0 && vol.TargetNodeName != "" {
@@ -117,7 +148,7 @@ func schedVolGroup(cfg config.Config, nodes []*state.Node, volGroup *v1.AntstorV
 		if needSchedNextFn(cnt, leftSize) {
 			var result int64
 			for _, item := range nodes {
-				if !tgtNodeSet.Contains(item.Info.ID) {
+				if !tgtNodeSet.Contains(item.Info.ID) && spreadFitsFn(item) {
 					result = pickSizeFn(item, leftSize)
 					// calculate allocatable bytes by min local line
 					result = getAllocatableRemoteVolumeSize(item, result, float64(cfg.Scheduler.MinLocalStoragePct))
@@ -128,6 +159,7 @@ func schedVolGroup(cfg config.Config, nodes []*state.Node, volGroup *v1.AntstorV
 						volGroup.Spec.Volumes[idx].Size = result
 						volGroup.Spec.Volumes[idx].TargetNodeName = item.Info.ID
 						tgtNodeSet.Add(item.Info.ID)
+						domainCounts[spread.Domain(item)]++
 						cnt += 1
 						leftSize -= result
 						// TODO: check if VolId is empty?
@@ -151,7 +183,7 @@ func schedVolGroup(cfg config.Config, nodes []*state.Node, volGroup *v1.AntstorV
 		if needSchedNextFn(cnt, leftSize) {
 			var result int64
 			for _, item := range nodes {
-				if !tgtNodeSet.Contains(item.Info.ID) {
+				if !tgtNodeSet.Contains(item.Info.ID) && spreadFitsFn(item) {
 					result = pickSizeFn(item, leftSize)
 					// calculate allocatable bytes by min local line
 					result = getAllocatableRemoteVolumeSize(item, result, float64(cfg.Scheduler.MinLocalStoragePct))
@@ -160,6 +192,7 @@ func schedVolGroup(cfg config.Config, nodes []*state.Node, volGroup *v1.AntstorV
 					// success
 					if result > 0 {
 						tgtNodeSet.Add(item.Info.ID)
+						domainCounts[spread.Domain(item)]++
 						cnt += 1
 						leftSize -= result
 
@@ -201,6 +234,13 @@ func schedVolGroup(cfg config.Config, nodes []*state.Node, volGroup *v1.AntstorV
 }
 
 func (s *scheduler) filterNodes(allNodes []*state.Node, volGroup *v1.AntstorVolumeGroup) (qualified []*state.Node, err error) {
+	// filter out unqualified nodes
+	qualified, err = newVolGroupFilterChain(s.cfg.Scheduler, allNodes, volGroup).MatchAll()
+	return
+}
+
+// newVolGroupFilterChain returns the filter chain for members of VolumeGroup
+func newVolGroupFilterChain(cfg config.SchedulerConfig, allNodes []*state.Node, volGroup *v1.AntstorVolumeGroup) *filter.FilterChain {
 	var (
 		minSize = volGroup.Spec.DesiredVolumeSpec.SizeRange.Min
 		// Here we build a fake AntstorVolume, which has minSize size and emtpy HostNode.
@@ -217,9 +257,8 @@ func (s *scheduler) filterNodes(allNodes []*state.Node, volGroup *v1.AntstorVolu
 		}
 	)
 
-	// filter out unqualified nodes
-	qualified, err = filter.NewFilterChain(s.cfg.Scheduler).
-		Filter(func(ctx *filter.FilterContext, node *state.Node, vol *v1.AntstorVolume) bool {
+	return filter.NewFilterChain(cfg).
+		NamedFilter("EmptyNode", func(ctx *filter.FilterContext, node *state.Node, vol *v1.AntstorVolume) bool {
 			// filter empty node
 			if !volGroup.Spec.Stragety.AllowEmptyNode {
 				if len(node.Volumes) == 0 {
@@ -231,14 +270,7 @@ func (s *scheduler) filterNodes(allNodes []*state.Node, volGroup *v1.AntstorVolu
 			return true
 		}).
 		Input(allNodes, vol).
-		LoadFilterFromConfig().
-		MatchAll()
-
-	if len(qualified) == 0 {
-		return
-	}
-
-	return
+		LoadFilterFromConfig()
 }
 
 type SortByStorage []*state.Node
@@ -175,6 +175,23 @@ func (n *Node) GetReservedVolBytes() (size uint64) {
 	return
 }
 
+// GetThinFreeBytes returns free virtual bytes of LVM thin pool. Virtual size of thin pool is its size multiplied by overcommitPct/100.
+// ThinOvercommitPct of the pool takes precedence over defaultOvercommitPct.
+func (n *Node) GetThinFreeBytes(defaultOvercommitPct int) (free int64) {
+	var thinPool = n.Pool.Spec.KernelLVM.ThinPool
+	if thinPool == nil {
+		return 0
+	}
+
+	free = int64(thinPool.Bytes) * int64(n.Pool.GetThinOvercommitPct(defaultOvercommitPct)) / 100
+	for _, vol := range n.Volumes {
+		if vol.Spec.IsThin {
+			free -= int64(vol.GetTotalSize())
+		}
+	}
+	return
+}
+
 // GetFreeResourceNonLock return free resource without lock
 func (n *Node) GetFreeResourceNonLock() (free corev1.ResourceList) {
 	free = make(corev1.ResourceList)
@@ -195,10 +212,29 @@ func (n *Node) GetFreeResourceNonLock() (free corev1.ResourceList) {
 		}
 	}
 
+	// thin pool occupies space of VG, and thin volumes consume space of thin pool instead of VG
+	var thinPool = n.Pool.Spec.KernelLVM.ThinPool
+	if thinPool != nil {
+		if _, has := free[v1.ResourceDiskPoolByte]; has {
+			toMunisBytes += int64(thinPool.Bytes)
+		}
+	}
+
+	// thin lvol in lvstore is counted by its logical size divided by the overcommit ratio
+	var isLVS = n.Pool.Mode() == v1.PoolModeSpdkLVStore
+	var overcommitPct = uint64(n.Pool.GetThinOvercommitPct(0))
+
 	for _, vol := range n.Volumes {
 		// minus (volume size + snap reserved size)
 		sizeByte := vol.GetTotalSize()
 		resvID := getVolumeReservationID(vol)
+		if thinPool != nil && vol.Spec.IsThin {
+			volResvIDs.Add(resvID)
+			continue
+		}
+		if isLVS && vol.Spec.IsThin {
+			sizeByte = sizeByte * 100 / overcommitPct
+		}
 		if _, has := free[v1.ResourceDiskPoolByte]; has {
 			volResvIDs.Add(resvID)
 			toMunisBytes += int64(sizeByte)
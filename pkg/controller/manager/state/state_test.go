@@ -1,6 +1,7 @@
 package state
 
 import (
+	"fmt"
 	"testing"
 
 	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
@@ -147,3 +148,102 @@ func TestReservation(t *testing.T) {
 	t.Log(node.FreeResource.Storage().String())
 
 }
+
+func TestThinPool(t *testing.T) {
+	pool := v1.StoragePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: v1.DefaultNamespace,
+			Name:      "node1",
+		},
+		Spec: v1.StoragePoolSpec{
+			NodeInfo: v1.NodeInfo{
+				ID: "node1",
+			},
+			KernelLVM: v1.KernelLVM{
+				Bytes: 10737418240, // 10Gi
+				ThinPool: &v1.KernelThinPool{
+					Name:  "thinpool",
+					Bytes: 4294967296, // 4Gi
+				},
+			},
+		},
+		Status: v1.StoragePoolStatus{
+			Capacity: corev1.ResourceList{
+				v1.ResourceDiskPoolByte: resource.MustParse("10Gi"),
+			},
+		},
+	}
+	node := NewNode(&pool)
+
+	node.AddVolume(&v1.AntstorVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: v1.DefaultNamespace,
+			Name:      "thin-vol",
+		},
+		Spec: v1.AntstorVolumeSpec{
+			Uuid:     "uuid-1",
+			SizeByte: 6442450944, // 6Gi
+			IsThin:   true,
+			HostNode: &v1.NodeInfo{ID: "node1"},
+		},
+	})
+
+	// thin volume does not consume space of VG
+	free := node.GetFreeResourceNonLock()
+	assert.Equal(t, "6Gi", free.Storage().String())
+
+	// 4Gi * 200% - 6Gi
+	assert.Equal(t, int64(2147483648), node.GetThinFreeBytes(200))
+	assert.Equal(t, int64(-2147483648), node.GetThinFreeBytes(0))
+	// ThinOvercommitPct of pool takes precedence over the default
+	pool.Spec.ThinOvercommitPct = 200
+	assert.Equal(t, int64(2147483648), node.GetThinFreeBytes(0))
+	pool.Spec.ThinOvercommitPct = 100
+	assert.Equal(t, int64(-2147483648), node.GetThinFreeBytes(200))
+}
+
+func TestSpdkThinOvercommit(t *testing.T) {
+	pool := v1.StoragePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: v1.DefaultNamespace,
+			Name:      "node1",
+		},
+		Spec: v1.StoragePoolSpec{
+			NodeInfo: v1.NodeInfo{
+				ID: "node1",
+			},
+			SpdkLVStore: v1.SpdkLVStore{
+				Name:  "lvs",
+				Bytes: 10737418240, // 10Gi
+			},
+			ThinOvercommitPct: 400,
+		},
+		Status: v1.StoragePoolStatus{
+			Capacity: corev1.ResourceList{
+				v1.ResourceDiskPoolByte: resource.MustParse("10Gi"),
+			},
+			VGFreeSize: resource.MustParse("8Gi"),
+		},
+	}
+	node := NewNode(&pool)
+
+	for i, thin := range []bool{true, false} {
+		node.AddVolume(&v1.AntstorVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: v1.DefaultNamespace,
+				Name:      fmt.Sprintf("vol-%d", i),
+			},
+			Spec: v1.AntstorVolumeSpec{
+				Uuid:     fmt.Sprintf("uuid-%d", i),
+				SizeByte: 4294967296, // 4Gi
+				IsThin:   thin,
+				HostNode: &v1.NodeInfo{ID: "node1"},
+			},
+		})
+	}
+
+	// 10Gi - 4Gi / 400% - 4Gi
+	free := node.GetFreeResourceNonLock()
+	assert.Equal(t, "5Gi", free.Storage().String())
+	assert.Equal(t, float64(20), pool.GetUsedPct())
+}
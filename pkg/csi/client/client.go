@@ -125,7 +125,105 @@ func (cm *KubeAPIClient) DeleteSnapshot(snapID string) (err error) {
 	return
 }
 
+func (cm *KubeAPIClient) ListSnapshots(opt ListSnapshotsOption) (snaps []Snapshot, nextToken string, err error) {
+	var (
+		labelSelector string
+		list          *v1.AntstorSnapshotList
+	)
+	if opt.OriginVolName != "" {
+		labelSelector = fmt.Sprintf("%s=%s,%s=%s", v1.OriginVolumeNameLabelKey, opt.OriginVolName, v1.OriginVolumeNamespaceLabelKey, opt.OriginVolNamespace)
+	}
+
+	list, err = cm.cli.VolumeV1().AntstorSnapshots(defaultNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+		Limit:         opt.Limit,
+		Continue:      opt.StartToken,
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	snaps = list.Items
+	nextToken = list.Continue
+	return
+}
+
 func (cm *KubeAPIClient) GetStoragePoolByName(ns, name string) (sp *StoragePool, err error) {
 	sp, err = cm.cli.VolumeV1().StoragePools(ns).Get(context.Background(), name, metav1.GetOptions{})
 	return
 }
+
+func (cm *KubeAPIClient) CreateGroupSnapshot(gs GroupSnapshot) (groupSnapID string, err error) {
+	if gs.Name == "" || gs.Spec.DataControlName == "" {
+		err = fmt.Errorf("invalid request %+v", gs)
+		klog.Error(err)
+		return
+	}
+
+	if gs.Spec.Uuid == "" {
+		gs.Spec.Uuid = uuid.NewV4().String()
+	}
+	if gs.Labels == nil {
+		gs.Labels = make(map[string]string)
+	}
+	gs.Labels[v1.GroupSnapshotUuidLabelKey] = gs.Spec.Uuid
+
+	klog.Infof("Creating group snapshot %s of DataControl %s", gs.Name, gs.Spec.DataControlName)
+	created, err := cm.cli.VolumeV1().AntstorVolumeGroupSnapshots(defaultNamespace).Create(context.Background(), &gs, metav1.CreateOptions{})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	groupSnapID = created.Spec.Uuid
+	return
+}
+
+func (cm *KubeAPIClient) GetGroupSnapshotByID(id string) (gs *GroupSnapshot, err error) {
+	labelSelector := fmt.Sprintf("%s=%s", v1.GroupSnapshotUuidLabelKey, id)
+	list, err := cm.cli.VolumeV1().AntstorVolumeGroupSnapshots(defaultNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	if len(list.Items) == 0 {
+		err = ErrorNotFoundResource
+		return
+	}
+
+	gs = &list.Items[0]
+	return
+}
+
+func (cm *KubeAPIClient) GetGroupSnapshotByName(ns, name string) (gs *GroupSnapshot, err error) {
+	gs, err = cm.cli.VolumeV1().AntstorVolumeGroupSnapshots(ns).Get(context.Background(), name, metav1.GetOptions{})
+	return
+}
+
+// DeleteGroupSnapshot deletes the group snapshot. Member snapshots are garbage collected by owner references.
+func (cm *KubeAPIClient) DeleteGroupSnapshot(groupSnapID string) (err error) {
+	if groupSnapID == "" {
+		err = fmt.Errorf("invalid empty groupSnapID")
+		return
+	}
+
+	gs, err := cm.GetGroupSnapshotByID(groupSnapID)
+	if err != nil {
+		if err == ErrorNotFoundResource {
+			klog.Infof("group snapshot %s may be already deleted", groupSnapID)
+			return nil
+		}
+		klog.Error(err)
+		return
+	}
+
+	err = cm.cli.VolumeV1().AntstorVolumeGroupSnapshots(defaultNamespace).Delete(context.Background(), gs.Name, metav1.DeleteOptions{})
+	if err != nil {
+		klog.Error(err)
+	}
+	return
+}
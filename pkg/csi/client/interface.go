@@ -10,6 +10,7 @@ import (
 type Volume = v1.AntstorVolume
 type StoragePool = v1.StoragePool
 type Snapshot = v1.AntstorSnapshot
+type GroupSnapshot = v1.AntstorVolumeGroupSnapshot
 
 type PV struct {
 	Namespace string
@@ -32,6 +33,20 @@ type PVCreateOption struct {
 	VolumeType v1.VolumeType
 	// volume posision
 	PositionAdvice string
+	// volume access mode
+	AccessMode v1.VolumeAccessMode
+	// Secret of DH-HMAC-CHAP keys
+	HostAuth *v1.HostAuth
+	// rate limits of volume
+	QoS *v1.VolumeQoS
+	// Secret of encryption key
+	Encryption *v1.VolumeEncryption
+	// image exported from a snapshot
+	ImportSource *v1.VolumeImportSource
+	// thin provisioned volume
+	IsThin bool
+	// number of data copies
+	Replicas int
 
 	PvType string
 	// for data control
@@ -45,6 +60,14 @@ type PVCreateOption struct {
 	AllowEmptyNode bool
 }
 
+type ListSnapshotsOption struct {
+	OriginVolName      string
+	OriginVolNamespace string
+	// page size and continue token
+	Limit      int64
+	StartToken string
+}
+
 type PvBaseIface interface {
 	// GetPvByNameAndType(name, typ string) (pv PV, err error)
 	GetPvByID(id string) (pv PV, err error)
@@ -54,6 +77,11 @@ type PvBaseIface interface {
 	DeletePV(id string) (err error)
 
 	ResizePV(id string, size int64) (err error)
+
+	// ListVolumes lists AntstorVolumes created by CSI. limit and startToken are the page size and the continue token.
+	ListVolumes(limit int64, startToken string) (vols []Volume, nextToken string, err error)
+
+	GetVolumeByName(ns, name string) (vol *Volume, err error)
 }
 
 type PvAdvancedIface interface {
@@ -61,6 +89,15 @@ type PvAdvancedIface interface {
 	// UpdatePvHostNode(volID, hostNodeID string) (err error)
 
 	SetNodePublishParameters(req SetNodePublishParamRequest) (err error)
+
+	// SetPvAttachedHost adds the node to or removes the node from AttachedHosts of a MultiNodeMultiWriter volume
+	SetPvAttachedHost(id, nodeID string, attached bool) (err error)
+
+	// SetPvHostPathStatus sets the NVMe-oF path status of the node to the volume status. If st is nil, the status of the node is removed.
+	SetPvHostPathStatus(id, nodeID string, st *v1.HostPathStatus) (err error)
+
+	// SetPvQoS updates QoS limits of the volume. The agent applies the limits to the volume later.
+	SetPvQoS(id string, qos *v1.VolumeQoS) (err error)
 }
 
 type PvIface interface {
@@ -73,6 +110,15 @@ type SnapshotIface interface {
 	GetSnapshotByName(ns, name string) (snapshot *Snapshot, err error)
 	CreateSnapshot(snap Snapshot) (snapID string, err error)
 	DeleteSnapshot(snapID string) (err error)
+	// ListSnapshots lists AntstorSnapshots. If OriginVolName is set, only snapshots of this volume are returned.
+	ListSnapshots(opt ListSnapshotsOption) (snaps []Snapshot, nextToken string, err error)
+}
+
+type GroupSnapshotIface interface {
+	GetGroupSnapshotByID(groupSnapID string) (gs *GroupSnapshot, err error)
+	GetGroupSnapshotByName(ns, name string) (gs *GroupSnapshot, err error)
+	CreateGroupSnapshot(gs GroupSnapshot) (groupSnapID string, err error)
+	DeleteGroupSnapshot(groupSnapID string) (err error)
 }
 
 type StoragePoolIface interface {
@@ -82,6 +128,7 @@ type StoragePoolIface interface {
 type AntstorClientIface interface {
 	PvIface
 	SnapshotIface
+	GroupSnapshotIface
 	StoragePoolIface
 }
 
@@ -161,6 +208,19 @@ func (p *PV) IsLocal() bool {
 	return false
 }
 
+// IsLocalTo returns true if the volume is on the node. For volume could be attached by multiple nodes,
+// it depends on which node stages the volume. Otherwise, it is the same as IsLocal.
+func (p *PV) IsLocalTo(nodeID string) bool {
+	if p.Type == PvTypeVolume && p.Volume.IsMultiWriter() {
+		return p.Volume.Spec.TargetNodeId == nodeID
+	}
+	return p.IsLocal()
+}
+
+func (p *PV) IsMultiWriter() bool {
+	return p.Type == PvTypeVolume && p.Volume.IsMultiWriter()
+}
+
 func (p *PV) IsLVM() bool {
 	switch p.Type {
 	case PvTypeVolume:
@@ -185,6 +245,30 @@ func (p *PV) GetDevPath() string {
 	return ""
 }
 
+// GetHostAuth returns the Secret reference of DH-HMAC-CHAP keys. It is nil if authentication is disabled.
+func (p *PV) GetHostAuth() *v1.HostAuth {
+	if p.Type == PvTypeVolume && p.Volume != nil {
+		return p.Volume.Spec.HostAuth
+	}
+	return nil
+}
+
+// GetQoS returns rate limits of the volume. It is nil if there is no limit.
+func (p *PV) GetQoS() *v1.VolumeQoS {
+	if p.Type == PvTypeVolume && p.Volume != nil {
+		return p.Volume.Spec.QoS
+	}
+	return nil
+}
+
+// GetEncryption returns the Secret reference of encryption key. It is nil if the volume is not encrypted.
+func (p *PV) GetEncryption() *v1.VolumeEncryption {
+	if p.Type == PvTypeVolume && p.Volume != nil {
+		return p.Volume.Spec.Encryption
+	}
+	return nil
+}
+
 func (p *PV) GetSpdkTarget() *v1.SpdkTarget {
 	switch p.Type {
 	case PvTypeVolume:
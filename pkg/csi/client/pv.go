@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -84,6 +85,13 @@ func (cm *KubeAPIClient) CreatePV(opt PVCreateOption) (volId string, err error)
 				SizeByte:       uint64(opt.Size),
 				HostNode:       &opt.HostNode,
 				PositionAdvice: v1.VolumePosition(opt.PositionAdvice),
+				AccessMode:     opt.AccessMode,
+				HostAuth:       opt.HostAuth,
+				QoS:            opt.QoS,
+				Encryption:     opt.Encryption,
+				ImportSource:   opt.ImportSource,
+				IsThin:         opt.IsThin,
+				Replicas:       opt.Replicas,
 			},
 			Status: v1.AntstorVolumeStatus{
 				Status: v1.VolumeStatusCreating,
@@ -369,3 +377,126 @@ func (cm *KubeAPIClient) SetNodePublishParameters(req SetNodePublishParamRequest
 
 	return
 }
+
+func (cm *KubeAPIClient) SetPvQoS(id string, qos *v1.VolumeQoS) (err error) {
+	var pv PV
+	pv, err = cm.GetPvByID(id)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if pv.Type != PvTypeVolume {
+		return fmt.Errorf("not supported pv type %s", pv.Type)
+	}
+
+	var vol = pv.Volume
+	if reflect.DeepEqual(vol.Spec.QoS, qos) {
+		return nil
+	}
+	vol.Spec.QoS = qos
+
+	klog.Infof("update QoS of volume %s to %+v", vol.Name, qos)
+	_, err = cm.cli.VolumeV1().AntstorVolumes(vol.Namespace).Update(context.Background(), vol, metav1.UpdateOptions{})
+	return
+}
+
+func (cm *KubeAPIClient) SetPvHostPathStatus(id, nodeID string, st *v1.HostPathStatus) (err error) {
+	var pv PV
+	pv, err = cm.GetPvByID(id)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if pv.Type != PvTypeVolume {
+		return fmt.Errorf("not supported pv type %s", pv.Type)
+	}
+
+	var (
+		vol      = pv.Volume
+		found    bool
+		newPaths = make([]v1.HostPathStatus, 0, len(vol.Status.HostPaths)+1)
+	)
+	for _, item := range vol.Status.HostPaths {
+		if item.NodeID == nodeID {
+			found = true
+			continue
+		}
+		newPaths = append(newPaths, item)
+	}
+	// nothing changed
+	if !found && st == nil {
+		return nil
+	}
+	if st != nil {
+		st.NodeID = nodeID
+		newPaths = append(newPaths, *st)
+	}
+	vol.Status.HostPaths = newPaths
+
+	_, err = cm.cli.VolumeV1().AntstorVolumes(vol.Namespace).UpdateStatus(context.Background(), vol, metav1.UpdateOptions{})
+	return
+}
+
+func (cm *KubeAPIClient) ListVolumes(limit int64, startToken string) (vols []Volume, nextToken string, err error) {
+	var list *v1.AntstorVolumeList
+	// only list volumes which are created by CSI
+	list, err = cm.cli.VolumeV1().AntstorVolumes(defaultNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: v1.VolumePVNameLabelKey,
+		Limit:         limit,
+		Continue:      startToken,
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	vols = list.Items
+	nextToken = list.Continue
+	return
+}
+
+func (cm *KubeAPIClient) GetVolumeByName(ns, name string) (vol *Volume, err error) {
+	vol, err = cm.cli.VolumeV1().AntstorVolumes(ns).Get(context.Background(), name, metav1.GetOptions{})
+	return
+}
+
+func (cm *KubeAPIClient) SetPvAttachedHost(id, nodeID string, attached bool) (err error) {
+	var pv PV
+	pv, err = cm.GetPvByID(id)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if !pv.IsMultiWriter() {
+		return fmt.Errorf("volume %s is not MultiNodeMultiWriter", id)
+	}
+
+	var (
+		vol      = pv.Volume
+		hosts    = make([]string, 0, len(vol.Spec.AttachedHosts)+1)
+		isHost   = vol.Spec.HostNode != nil && vol.Spec.HostNode.ID == nodeID
+		hasNode  = misc.InSliceString(nodeID, vol.Spec.AttachedHosts)
+		toAttach = attached && !isHost
+	)
+	// nothing changed
+	if toAttach == hasNode {
+		return nil
+	}
+
+	for _, item := range vol.Spec.AttachedHosts {
+		if item != nodeID {
+			hosts = append(hosts, item)
+		}
+	}
+	if toAttach {
+		hosts = append(hosts, nodeID)
+	}
+	vol.Spec.AttachedHosts = hosts
+
+	klog.Infof("update AttachedHosts of volume %s to %v", vol.Name, hosts)
+	_, err = cm.cli.VolumeV1().AntstorVolumes(vol.Namespace).Update(context.Background(), vol, metav1.UpdateOptions{})
+	return
+}
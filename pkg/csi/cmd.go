@@ -16,6 +16,7 @@ import (
 	"lite.io/liteio/pkg/util"
 	"lite.io/liteio/pkg/util/mount"
 	"lite.io/liteio/pkg/version"
+	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -37,6 +38,8 @@ type RpcServerOption struct {
 	// init nvmf kernel module
 	InitNvmfKernelModule bool
 	IsController         bool
+	// serve SnapshotMetadata, which requires the external-snapshot-metadata sidecar
+	EnableSnapshotMetadata bool
 }
 
 func NewCSICommand() *cobra.Command {
@@ -77,6 +80,7 @@ func newRpcServerCmd() *cobra.Command {
 	// for controller
 	cmd.Flags().BoolVar(&opt.InitNvmfKernelModule, "initKernelMod", true, "load nvmf kernel mod at starting process")
 	cmd.Flags().BoolVar(&opt.IsController, "isController", false, "Run as CSI controller")
+	cmd.Flags().BoolVar(&opt.EnableSnapshotMetadata, "enableSnapshotMetadata", false, "serve SnapshotMetadata. The external-snapshot-metadata sidecar and SnapshotMetadataService must be deployed")
 
 	return cmd
 }
@@ -112,6 +116,11 @@ func (opt *RpcServerOption) run() (err error) {
 		}
 	}
 
+	var pluginCap = append([]*csi.PluginCapability{}, driver.DefaultPluginCapability...)
+	if opt.EnableSnapshotMetadata {
+		pluginCap = append(pluginCap, driver.SnapshotMetadataPluginCapability)
+	}
+
 	drv := driver.NewCSIDriver(driver.NewCSIDriverOption{
 		Name:          opt.DriverName,
 		NodeID:        opt.NodeID,
@@ -120,7 +129,7 @@ func (opt *RpcServerOption) run() (err error) {
 		VolumeCap:     driver.DefaultVolumeAccessModeType,
 		ControllerCap: driver.DefaultControllerServiceCapability,
 		NodeCap:       driver.DefaultNodeServiceCapability,
-		PluginCap:     driver.DefaultPluginCapability,
+		PluginCap:     pluginCap,
 	})
 
 	cloudMgr, err := client.NewKubeAPIClient(cfg)
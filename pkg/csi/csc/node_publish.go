@@ -74,7 +74,7 @@ USAGE
 			// Set the volume ID for the current request.
 			req.VolumeId = args[i]
 
-			fmt.Println("PublishVolume request", req)
+			fmt.Println("PublishVolume request", &req)
 			csiNode, err := newCSINodeClient()
 			if err != nil {
 				exit(err)
@@ -56,7 +56,7 @@ USAGE
 			// Set the volume ID for the current request.
 			req.VolumeId = args[i]
 
-			fmt.Println("StageVolume request", req)
+			fmt.Println("StageVolume request", &req)
 			csiNode, err := newCSINodeClient()
 			if err != nil {
 				exit(err)
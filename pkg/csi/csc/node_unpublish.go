@@ -48,7 +48,7 @@ USAGE
 			// Set the volume ID for the current request.
 			req.VolumeId = args[i]
 
-			fmt.Println("nodeUnpublishVolume request", req)
+			fmt.Println("nodeUnpublishVolume request", &req)
 
 			csiNode, err := newCSINodeClient()
 			if err != nil {
@@ -45,7 +45,7 @@ USAGE
 			// Set the volume ID for the current request.
 			req.VolumeId = args[i]
 
-			fmt.Println("nodeUnstageVolume request", req)
+			fmt.Println("nodeUnstageVolume request", &req)
 			csiNode, err := newCSINodeClient()
 			if err != nil {
 				exit(err)
@@ -7,12 +7,22 @@ import (
 var (
 	DefaultVolumeAccessModeType = []csi.VolumeCapability_AccessMode_Mode{
 		csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+		// only for volume in Block mode
+		csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
 	}
 
 	DefaultControllerServiceCapability = []csi.ControllerServiceCapability_RPC_Type{
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
 		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+		csi.ControllerServiceCapability_RPC_GET_VOLUME,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES_PUBLISHED_NODES,
+		csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
+		csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
+		csi.ControllerServiceCapability_RPC_CLONE_VOLUME,
+		// QoS limits could be modified by VolumeAttributesClass
+		csi.ControllerServiceCapability_RPC_MODIFY_VOLUME,
 		/*
 			not support ControllerPublish/ControllerUnpublish, so the external-attacher will use trivialHandler
 			to directly mark VolumeAttachment to attached status.
@@ -20,10 +30,14 @@ var (
 
 			csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
 			csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
-			csi.ControllerServiceCapability_RPC_CLONE_VOLUME,
 		*/
 	}
 
+	// group snapshot of VolumeGroup PV
+	DefaultGroupControllerServiceCapability = []csi.GroupControllerServiceCapability_RPC_Type{
+		csi.GroupControllerServiceCapability_RPC_CREATE_DELETE_GET_VOLUME_GROUP_SNAPSHOT,
+	}
+
 	DefaultNodeServiceCapability = []csi.NodeServiceCapability_RPC_Type{
 		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
 		csi.NodeServiceCapability_RPC_EXPAND_VOLUME,
@@ -51,6 +65,14 @@ var (
 				},
 			},
 		},
+		// VolumeGroupSnapshot is served by GroupController
+		{
+			Type: &csi.PluginCapability_Service_{
+				Service: &csi.PluginCapability_Service{
+					Type: csi.PluginCapability_Service_GROUP_CONTROLLER_SERVICE,
+				},
+			},
+		},
 		// LVM local PV supports online expansion. CSI ControllerExpandVolume and NodeExpandVolume must be implemented
 		// 如果 node-attached volume 不支持在线扩容，那么需要声明这个 OFFLINE, 同时必须实现 ControllerExpandVolume 和 NodeExpandVolume
 		{
@@ -61,6 +83,17 @@ var (
 			},
 		},
 	}
+
+	// SnapshotMetadata is served by ControllerServer, for snapshots exported to object storage.
+	// It is not in DefaultPluginCapability, because it requires the external-snapshot-metadata sidecar,
+	// the SnapshotMetadataService CR and the TLS certificate of the sidecar to be deployed.
+	SnapshotMetadataPluginCapability = &csi.PluginCapability{
+		Type: &csi.PluginCapability_Service_{
+			Service: &csi.PluginCapability_Service{
+				Type: csi.PluginCapability_Service_SNAPSHOT_METADATA_SERVICE,
+			},
+		},
+	}
 )
 
 // NewVolumeCapabilityAccessMode creates CSI volume access mode object.
@@ -89,3 +122,14 @@ func NewNodeServiceCapability(cap csi.NodeServiceCapability_RPC_Type) *csi.NodeS
 		},
 	}
 }
+
+// NewGroupControllerServiceCapability creates CSI group controller capability object.
+func NewGroupControllerServiceCapability(cap csi.GroupControllerServiceCapability_RPC_Type) *csi.GroupControllerServiceCapability {
+	return &csi.GroupControllerServiceCapability{
+		Type: &csi.GroupControllerServiceCapability_Rpc{
+			Rpc: &csi.GroupControllerServiceCapability_RPC{
+				Type: cap,
+			},
+		},
+	}
+}
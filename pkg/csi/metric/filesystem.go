@@ -65,7 +65,7 @@ func init() {
 	Registry.MustRegister(initiatorFreeInodesGaugeVec)
 }
 
-func SetFilesystemMetrics(nodeID, pvcName, pvcNS string, bytes, inodes csi.VolumeUsage) {
+func SetFilesystemMetrics(nodeID, pvcName, pvcNS string, bytes, inodes *csi.VolumeUsage) {
 	initiatorTotalSizeBytesGaugeVec.WithLabelValues(nodeID, pvcName, pvcNS)
 	initiatorUsedSizeBytesGaugeVec.WithLabelValues(nodeID, pvcName, pvcNS)
 	initiatorAvailSizeBytesGaugeVec.WithLabelValues(nodeID, pvcName, pvcNS)
@@ -1,6 +1,8 @@
 package rpcserver
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,6 +13,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -88,9 +91,35 @@ const (
 	// fsTypeLabelKey = "obnvmf/fs-type"
 
 	volContextKeySkipUpdatePublishParam = "skip-save-context"
+
+	// StorageClass parameters of the Secret which contains DH-HMAC-CHAP keys. Namespace of PVC is used by default.
+	// The same Secret should be set to csi.storage.k8s.io/node-stage-secret-name and namespace,
+	// so that the host could read the keys in NodeStageVolume.
+	dhchapSecretNameKey      = "obnvmf/dhchap-secret-name"
+	dhchapSecretNamespaceKey = "obnvmf/dhchap-secret-namespace"
+
+	// StorageClass parameters of the per-volume Secret of encryption key. Setting the name enables encryption.
+	// The name supports ${pv.name}, ${pvc.name} and ${pvc.namespace}, e.g. "${pv.name}-key". Namespace of PVC is used by default.
+	// If the Secret does not exist, it is created with a random key, and deleted with the last volume or snapshot using it.
+	// Volumes cloned or restored from an encrypted source use the Secret of the source instead.
+	encryptionSecretNameKey      = "obnvmf/encryption-secret-name"
+	encryptionSecretNamespaceKey = "obnvmf/encryption-secret-namespace"
+
+	// thinProvisionKey is StorageClass parameter. If the value is "true", the volume is thin provisioned
+	thinProvisionKey = "obnvmf/thin-provision"
+
+	// replicasKey is StorageClass parameter of the number of data copies. It is only supported by SpdkLVol volume.
+	replicasKey = "obnvmf/replicas"
+
+	// page size of listing volumes and snapshots
+	listPageSize = 500
 )
 
 type ControllerServer struct {
+	csi.UnimplementedControllerServer
+	csi.UnimplementedGroupControllerServer
+	csi.UnimplementedSnapshotMetadataServer
+
 	driver  *driver.CSIDriver
 	cli     client.AntstorClientIface
 	locks   *misc.ResourceLocks
@@ -164,6 +193,12 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 	volLabels[pvcNamespaceKeyForLabel] = pvcNs
 	volAnnotations[v1.FsTypeLabelKey] = fsType
 
+	opt.AccessMode, err = getVolumeAccessMode(req.GetVolumeCapabilities())
+	if err != nil {
+		klog.Error(err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	opt.RaidLevel = req.Parameters[raidLevelKey]
 	opt.EngineType = req.Parameters[engineTypeKey]
 	opt.SizeSymmetry = req.Parameters[volGroupSymmetryKey]
@@ -172,12 +207,86 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 	if val, has := req.Parameters[volGroupMaxVolumesKey]; has {
 		opt.MaxVolumes, _ = strconv.Atoi(val)
 	}
+	// NVMe-oF transport of remote volume
+	if val := req.Parameters[v1.TransportTypeAnnoKey]; val != "" {
+		transType := strings.ToUpper(val)
+		if transType != v1.TransportTypeTCP && transType != v1.TransportTypeRDMA {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid transport type %s, should be tcp or rdma", val))
+		}
+		volAnnotations[v1.TransportTypeAnnoKey] = transType
+	}
+	// spread volumes of the same data-holder across racks or rooms, which could be overridden by PVC annotations
+	if val := req.Parameters[v1.TopologySpreadKeyAnnoKey]; val != "" {
+		volAnnotations[v1.TopologySpreadKeyAnnoKey] = val
+	}
+	if val, has := req.Parameters[v1.TopologySpreadMaxSkewAnnoKey]; has {
+		if skew, err := strconv.Atoi(val); err != nil || skew <= 0 {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s: %s", v1.TopologySpreadMaxSkewAnnoKey, val))
+		}
+		volAnnotations[v1.TopologySpreadMaxSkewAnnoKey] = val
+	}
+
+	if name := req.Parameters[dhchapSecretNameKey]; name != "" {
+		opt.HostAuth = &v1.HostAuth{
+			SecretName:      name,
+			SecretNamespace: req.Parameters[dhchapSecretNamespaceKey],
+		}
+		if opt.HostAuth.SecretNamespace == "" {
+			opt.HostAuth.SecretNamespace = pvcNs
+		}
+	}
+
+	if name := req.Parameters[encryptionSecretNameKey]; name != "" {
+		if opt.PvType == client.PvTypeVolumeGroup {
+			return nil, status.Error(codes.InvalidArgument, "encryption is not supported by VolumeGroup")
+		}
+		var replacer = strings.NewReplacer("${pv.name}", req.Name, "${pvc.name}", pvcName, "${pvc.namespace}", pvcNs)
+		opt.Encryption = &v1.VolumeEncryption{
+			SecretName:      replacer.Replace(name),
+			SecretNamespace: replacer.Replace(req.Parameters[encryptionSecretNamespaceKey]),
+		}
+		if opt.Encryption.SecretNamespace == "" {
+			opt.Encryption.SecretNamespace = pvcNs
+		}
+	}
+
+	if req.Parameters[thinProvisionKey] == "true" {
+		if opt.PvType == client.PvTypeVolumeGroup {
+			return nil, status.Error(codes.InvalidArgument, "thin provisioning is not supported by VolumeGroup")
+		}
+		opt.IsThin = true
+	}
+
+	if val, has := req.Parameters[replicasKey]; has {
+		opt.Replicas, err = strconv.Atoi(val)
+		if err != nil || opt.Replicas < 1 {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s: %s", replicasKey, val))
+		}
+		if opt.Replicas > 1 && (opt.PvType == client.PvTypeVolumeGroup || opt.VolumeType == v1.VolumeTypeKernelLVol) {
+			return nil, status.Error(codes.InvalidArgument, "replicas is only supported by SpdkLVol volume")
+		}
+	}
+
+	// QoS limits in StorageClass parameters, which could be overridden by PVC annotations
+	var qos v1.VolumeQoS
+	err = parseVolumeQoS(req.Parameters, &qos)
+	if err != nil {
+		klog.Error(err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// defualt is true
 	opt.AllowEmptyNode = true
 	if val, has := req.Parameters[volGroupAllowEmptyKey]; has {
 		opt.AllowEmptyNode = val == "true"
 	}
 
+	// encryption of the snapshot or volume, which the volume is created from
+	var (
+		fromSource bool
+		srcEnc     *v1.VolumeEncryption
+	)
+
 	// get volume content source info
 	if req.VolumeContentSource.GetSnapshot() != nil {
 		id := req.VolumeContentSource.GetSnapshot().SnapshotId
@@ -186,14 +295,86 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 			klog.Error(err)
 			return nil, status.Error(codes.Internal, err.Error())
 		}
-		if snap.Status.Status != v1.SnapshotStatusReady {
-			err = fmt.Errorf("snapshot has not been ready yet, status %s", snap.Status.Status)
+		// import the exported image to a new volume, so that the volume is not limited to the node of snapshot
+		if req.Parameters[v1.ImportFromExportKey] == "true" {
+			if opt.Encryption != nil || snap.Spec.Encryption != nil {
+				return nil, status.Error(codes.InvalidArgument, "encrypted volume cannot be imported from snapshot")
+			}
+			opt.ImportSource, err = getImportSource(snap, opt.Size)
+			if err != nil {
+				klog.Error(err)
+				return nil, status.Error(codes.FailedPrecondition, err.Error())
+			}
+		} else {
+			if snap.Status.Status != v1.SnapshotStatusReady {
+				err = fmt.Errorf("snapshot has not been ready yet, status %s", snap.Status.Status)
+				klog.Error(err)
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			fromSource = true
+			srcEnc = snap.Spec.Encryption
+			volLabels[v1.VolumeSourceSnapNameLabelKey] = snap.Name
+			volLabels[v1.VolumeSourceSnapNamespaceLabelKey] = snap.Namespace
+			volAnnotations[v1.PoolLabelSelectorKey] = fmt.Sprintf("%s=%s", v1.PoolLabelsNodeSnKey, snap.Spec.OriginVolTargetNodeID)
+			// volume restored from SpdkLVol snapshot could be inflated, so that the snapshot could be deleted
+			if val, has := req.Parameters[v1.CloneInflateAnnoKey]; has {
+				volAnnotations[v1.CloneInflateAnnoKey] = val
+			}
+		}
+	}
+
+	// clone volume from a source volume
+	var srcVol *v1.AntstorVolume
+	if req.VolumeContentSource.GetVolume() != nil {
+		id := req.VolumeContentSource.GetVolume().VolumeId
+		pv, err := cs.cli.GetPvByID(id)
+		if err != nil {
+			var errCode codes.Code
+			if err == client.ErrorNotFoundResource {
+				errCode = codes.NotFound
+			} else {
+				errCode = codes.Internal
+			}
+			klog.Error(err)
+			return nil, status.Error(errCode, err.Error())
+		}
+		if pv.Type != client.PvTypeVolume || pv.Volume == nil {
+			return nil, status.Error(codes.InvalidArgument, "only support cloning Volume")
+		}
+		srcVol = pv.Volume
+		fromSource = true
+		srcEnc = srcVol.Spec.Encryption
+		if srcVol.Status.Status != v1.VolumeStatusReady {
+			err = fmt.Errorf("source volume has not been ready yet, status %s", srcVol.Status.Status)
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if uint64(opt.Size) < srcVol.Spec.SizeByte {
+			err = fmt.Errorf("request size %d is smaller than source volume size %d", opt.Size, srcVol.Spec.SizeByte)
+			klog.Error(err)
+			return nil, status.Error(codes.OutOfRange, err.Error())
+		}
+		volLabels[v1.VolumeSourceVolNameLabelKey] = srcVol.Name
+		volLabels[v1.VolumeSourceVolNamespaceLabelKey] = srcVol.Namespace
+		// cloned volume must be in the same pool as the source volume
+		volAnnotations[v1.PoolLabelSelectorKey] = fmt.Sprintf("%s=%s", v1.PoolLabelsNodeSnKey, srcVol.Spec.TargetNodeId)
+		if val, has := req.Parameters[v1.CloneInflateAnnoKey]; has {
+			volAnnotations[v1.CloneInflateAnnoKey] = val
+		}
+	}
+
+	// data of the source is encrypted by its key, so the volume shares the Secret of the source
+	if fromSource {
+		if srcEnc == nil && opt.Encryption != nil {
+			return nil, status.Error(codes.InvalidArgument, "volume created from unencrypted source cannot be encrypted")
+		}
+		opt.Encryption = srcEnc.DeepCopy()
+	} else if opt.Encryption != nil {
+		err = cs.ensureEncryptionSecret(req.Name, opt.Encryption)
+		if err != nil {
 			klog.Error(err)
 			return nil, status.Error(codes.Internal, err.Error())
 		}
-		volLabels[v1.VolumeSourceSnapNameLabelKey] = snap.Name
-		volLabels[v1.VolumeSourceSnapNamespaceLabelKey] = snap.Namespace
-		volAnnotations[v1.PoolLabelSelectorKey] = fmt.Sprintf("%s=%s", v1.PoolLabelsNodeSnKey, snap.Spec.OriginVolTargetNodeID)
 	}
 
 	if pvcNs != "" && pvcName != "" {
@@ -221,6 +402,13 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 			opt.VolumeType = v1.VolumeType(typ)
 		}
 
+		// QoS limits
+		err = parseVolumeQoS(pvc.Annotations, &qos)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
 		klog.Infof("PVC ResourceVersion %s, Annotations %+v", pvc.ResourceVersion, pvc.Annotations)
 
 		// copy PVC Annotations whose key starting with "obnvmf/" to volume's annotations
@@ -231,6 +419,29 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 		}
 	}
 
+	// QoS limits of VolumeAttributesClass
+	err = validateMutableParameters(req.MutableParameters)
+	if err == nil {
+		err = parseVolumeQoS(req.MutableParameters, &qos)
+	}
+	if err != nil {
+		klog.Error(err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if qos != (v1.VolumeQoS{}) {
+		opt.QoS = &qos
+	}
+
+	// cloned volume must have the same type as the source volume
+	if srcVol != nil {
+		opt.VolumeType = srcVol.Spec.Type
+	}
+	err = validateVolumeQoS(opt.VolumeType, opt.QoS)
+	if err != nil {
+		klog.Error(err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// set HostNode info
 	if nodeName != "" {
 		// TODO: config
@@ -289,17 +500,65 @@ func (cs *ControllerServer) DeleteVolume(ctx context.Context, req *csi.DeleteVol
 		return nil, status.Error(codes.InvalidArgument, "DeleteVolumeRequest is invalid")
 	}
 
+	// destroy the encryption key before deleting the volume, so the data could never be decrypted
+	pv, err := cs.cli.GetPvByID(req.VolumeId)
+	if err != nil && err != client.ErrorNotFoundResource {
+		klog.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if enc := pv.GetEncryption(); err == nil && enc != nil {
+		err = cs.deleteEncryptionSecret(enc, pv.Volume.Name, "")
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	// DeleteVolume is idempotent in node-disk-controller RPC
-	err := cs.cli.DeletePV(req.VolumeId)
+	err = cs.cli.DeletePV(req.VolumeId)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	return &csi.DeleteVolumeResponse{}, nil
 }
 
-func (cs *ControllerServer) ControllerGetVolume(context.Context, *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
+// ControllerGetVolume gets the current status of a volume, including its published node and condition.
+// volume id is REQUIRED in csi.ControllerGetVolumeRequest
+func (cs *ControllerServer) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
+	klog.Infof("ControllerGetVolume req=%s", req.String())
 
-	return nil, status.Error(codes.Unimplemented, "")
+	if req.VolumeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "ControllerGetVolumeRequest.VolumeId is empty")
+	}
+
+	pv, err := cs.cli.GetPvByID(req.VolumeId)
+	if err != nil {
+		var errCode codes.Code
+		if err == client.ErrorNotFoundResource {
+			errCode = codes.NotFound
+		} else {
+			errCode = codes.Internal
+		}
+		klog.Error(err)
+		return nil, status.Error(errCode, err.Error())
+	}
+	if pv.Type != client.PvTypeVolume || pv.Volume == nil {
+		return nil, status.Error(codes.InvalidArgument, "only support getting Volume")
+	}
+
+	vol := pv.Volume
+	resp := &csi.ControllerGetVolumeResponse{
+		Volume: &csi.Volume{
+			VolumeId:      vol.Spec.Uuid,
+			CapacityBytes: int64(vol.Spec.SizeByte),
+		},
+		Status: &csi.ControllerGetVolumeResponse_VolumeStatus{
+			PublishedNodeIds: getPublishedNodeIds(vol),
+			VolumeCondition:  cs.getVolumeCondition(vol, make(map[string]*client.StoragePool)),
+		},
+	}
+
+	return resp, nil
 }
 
 // ControllerPublishVolume attaches the volume to the node
@@ -353,6 +612,12 @@ func (cs *ControllerServer) ValidateVolumeCapabilities(ctx context.Context, req
 		klog.Infof("Volume capability %s", item.String())
 	}
 
+	if _, err = getVolumeAccessMode(req.GetVolumeCapabilities()); err != nil {
+		return &csi.ValidateVolumeCapabilitiesResponse{
+			Message: err.Error(),
+		}, nil
+	}
+
 	resp := &csi.ValidateVolumeCapabilitiesResponse{
 		Confirmed: &csi.ValidateVolumeCapabilitiesResponse_Confirmed{
 			VolumeCapabilities: req.GetVolumeCapabilities(),
@@ -389,6 +654,11 @@ func (cs *ControllerServer) ControllerExpandVolume(ctx context.Context, req *csi
 		return nil, status.Error(errCode, err.Error())
 	}
 
+	// RAID1 bdev and replica legs are not resized
+	if pv.Type == client.PvTypeVolume && pv.Volume.IsReplicated() {
+		return nil, status.Error(codes.FailedPrecondition, "expansion is not supported by replicated volume")
+	}
+
 	volSize := pv.GetSize()
 	if capRange.RequiredBytes <= volSize {
 		err = fmt.Errorf("target size is %d, current size is %d, only expasion is allowed", capRange.RequiredBytes, volSize)
@@ -424,9 +694,203 @@ func (cs *ControllerServer) ControllerExpandVolume(ctx context.Context, req *csi
 	return resp, nil
 }
 
+// ControllerModifyVolume updates QoS limits of the volume by parameters of VolumeAttributesClass.
+// Limits which are not in mutable_parameters remain unchanged. The agent applies the limits to the volume online.
+func (cs *ControllerServer) ControllerModifyVolume(ctx context.Context, req *csi.ControllerModifyVolumeRequest) (*csi.ControllerModifyVolumeResponse, error) {
+	klog.Infof("ControllerModifyVolume req=%s", req.String())
+
+	if req.VolumeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "ControllerModifyVolume.VolumeId is empty")
+	}
+
+	err := validateMutableParameters(req.MutableParameters)
+	if err != nil {
+		klog.Error(err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	pv, err := cs.cli.GetPvByID(req.VolumeId)
+	if err != nil {
+		var errCode codes.Code
+		if err == client.ErrorNotFoundResource {
+			errCode = codes.NotFound
+		} else {
+			errCode = codes.Internal
+		}
+		klog.Error(err)
+		return nil, status.Error(errCode, err.Error())
+	}
+	if pv.Type != client.PvTypeVolume {
+		return nil, status.Error(codes.InvalidArgument, "only support modifying Volume")
+	}
+
+	var qos v1.VolumeQoS
+	if cur := pv.GetQoS(); cur != nil {
+		qos = *cur
+	}
+	err = parseVolumeQoS(req.MutableParameters, &qos)
+	if err != nil {
+		klog.Error(err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var newQoS *v1.VolumeQoS
+	if qos != (v1.VolumeQoS{}) {
+		newQoS = &qos
+	}
+	err = validateVolumeQoS(pv.Volume.Spec.Type, newQoS)
+	if err != nil {
+		klog.Error(err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	err = cs.cli.SetPvQoS(req.VolumeId, newQoS)
+	if err != nil {
+		klog.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &csi.ControllerModifyVolumeResponse{}, nil
+}
+
+// ListVolumes returns all volumes created by CSI.
+// max_entries and starting_token are used for pagination. starting_token is the continue token of the list request to apiserver.
 func (cs *ControllerServer) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
 	klog.Infof("ListVolumes req=%s", req.String())
-	return nil, status.Error(codes.Unimplemented, "")
+
+	if req.MaxEntries < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ListVolumesRequest.MaxEntries is negative")
+	}
+
+	vols, nextToken, err := cs.cli.ListVolumes(int64(req.MaxEntries), req.StartingToken)
+	if err != nil {
+		var errCode codes.Code
+		// continue token is expired or invalid
+		if errors.IsResourceExpired(err) || errors.IsGone(err) || errors.IsBadRequest(err) {
+			errCode = codes.Aborted
+		} else {
+			errCode = codes.Internal
+		}
+		klog.Error(err)
+		return nil, status.Error(errCode, err.Error())
+	}
+
+	var (
+		resp = &csi.ListVolumesResponse{
+			NextToken: nextToken,
+		}
+		// cache StoragePools, in case of getting the same pool many times
+		pools = make(map[string]*client.StoragePool)
+	)
+	for i := range vols {
+		vol := &vols[i]
+		resp.Entries = append(resp.Entries, &csi.ListVolumesResponse_Entry{
+			Volume: &csi.Volume{
+				VolumeId:      vol.Spec.Uuid,
+				CapacityBytes: int64(vol.Spec.SizeByte),
+			},
+			Status: &csi.ListVolumesResponse_VolumeStatus{
+				PublishedNodeIds: getPublishedNodeIds(vol),
+				VolumeCondition:  cs.getVolumeCondition(vol, pools),
+			},
+		})
+	}
+
+	return resp, nil
+}
+
+// getVolumeCondition checks the status of volume and the conditions of the StoragePool where the volume is located.
+// pools is a cache of StoragePools, key is the name of StoragePool.
+func (cs *ControllerServer) getVolumeCondition(vol *client.Volume, pools map[string]*client.StoragePool) *csi.VolumeCondition {
+	if vol.Status.Status != v1.VolumeStatusReady {
+		return &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  fmt.Sprintf("volume status is %s, msg: %s", vol.Status.Status, vol.Status.Message),
+		}
+	}
+
+	tgtNodeId := vol.Spec.TargetNodeId
+	if tgtNodeId == "" {
+		return &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  "volume is not scheduled to any StoragePool",
+		}
+	}
+
+	sp, has := pools[tgtNodeId]
+	if !has {
+		var err error
+		sp, err = cs.cli.GetStoragePoolByName(vol.Namespace, tgtNodeId)
+		if err != nil {
+			klog.Error(err)
+			if !errors.IsNotFound(err) {
+				// do not cache the error, so the next volume will retry
+				return &csi.VolumeCondition{
+					Abnormal: true,
+					Message:  fmt.Sprintf("failed to get StoragePool %s: %s", tgtNodeId, err.Error()),
+				}
+			}
+			sp = nil
+		}
+		pools[tgtNodeId] = sp
+	}
+
+	if sp == nil {
+		return &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  fmt.Sprintf("StoragePool %s is not found", tgtNodeId),
+		}
+	}
+
+	if sp.Status.Status == v1.PoolStatusOffline || sp.Status.Status == v1.PoolStatusUnknown {
+		return &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  fmt.Sprintf("StoragePool %s status is %s, msg: %s", tgtNodeId, sp.Status.Status, sp.Status.Message),
+		}
+	}
+
+	var errMsgs []string
+	for _, cond := range sp.Status.Conditions {
+		if cond.Status == v1.StatusError {
+			errMsgs = append(errMsgs, fmt.Sprintf("%s: %s", cond.Type, cond.Message))
+		}
+	}
+	if len(errMsgs) > 0 {
+		return &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  fmt.Sprintf("StoragePool %s has error conditions: %s", tgtNodeId, strings.Join(errMsgs, "; ")),
+		}
+	}
+
+	return &csi.VolumeCondition{
+		Abnormal: false,
+		Message:  "volume is ready",
+	}
+}
+
+// getVolumeAccessMode returns MultiNodeMultiWriter if any capability requires MULTI_NODE_MULTI_WRITER.
+// MULTI_NODE_MULTI_WRITER is only supported by volume in Block mode.
+func getVolumeAccessMode(caps []*csi.VolumeCapability) (mode v1.VolumeAccessMode, err error) {
+	mode = v1.VolumeAccessModeSingleNodeWriter
+	for _, item := range caps {
+		if item.GetAccessMode().GetMode() == csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER {
+			if item.GetBlock() == nil {
+				err = fmt.Errorf("access mode MULTI_NODE_MULTI_WRITER is only supported by volume in Block mode")
+				return
+			}
+			mode = v1.VolumeAccessModeMultiNodeMultiWriter
+		}
+	}
+	return
+}
+
+// getPublishedNodeIds returns the nodes where the volume is published
+func getPublishedNodeIds(vol *client.Volume) (nodeIds []string) {
+	if vol.Spec.HostNode != nil && vol.Spec.HostNode.ID != "" {
+		nodeIds = append(nodeIds, vol.Spec.HostNode.ID)
+	}
+	// MultiNodeMultiWriter volume is published on all attached hosts
+	nodeIds = append(nodeIds, vol.Spec.AttachedHosts...)
+	return
 }
 
 func (cs *ControllerServer) GetCapacity(ctx context.Context, req *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
@@ -513,6 +977,8 @@ func (cs *ControllerServer) CreateSnapshot(ctx context.Context, req *csi.CreateS
 			Size:               int64(vol.Spec.SizeByte),
 			OriginVolName:      vol.Name,
 			OriginVolNamespace: vol.Namespace,
+			// set encryption at once, so that the key Secret is not deleted with the volume before the snapshot is bound
+			Encryption: vol.Spec.Encryption.DeepCopy(),
 		},
 		Status: v1.AntstorSnapshotStatus{
 			Status: v1.SnapshotStatusCreating,
@@ -551,16 +1017,120 @@ func (cs *ControllerServer) DeleteSnapshot(ctx context.Context, req *csi.DeleteS
 		return nil, status.Error(codes.InvalidArgument, "DeleteSnapshotRequest is invalid")
 	}
 
-	err := cs.cli.DeleteSnapshot(req.SnapshotId)
+	// the snapshot may be the last one using the encryption key
+	snap, err := cs.cli.GetSnapshotByID(req.SnapshotId)
+	if err != nil && err != client.ErrorNotFoundResource {
+		klog.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err == nil && snap.Spec.Encryption != nil {
+		err = cs.deleteEncryptionSecret(snap.Spec.Encryption, "", snap.Name)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	err = cs.cli.DeleteSnapshot(req.SnapshotId)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	return &csi.DeleteSnapshotResponse{}, nil
 }
 
+// ListSnapshots returns snapshots filtered by snapshot id or source volume id.
+// If no snapshot matches the filters, an empty response is returned.
+// max_entries and starting_token are used for pagination. starting_token is the continue token of the list request to apiserver.
 func (cs *ControllerServer) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
 	klog.Infof("ListSnapshots Req=%s", req.String())
-	return nil, status.Error(codes.Unimplemented, "")
+
+	if req.MaxEntries < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ListSnapshotsRequest.MaxEntries is negative")
+	}
+
+	var (
+		resp  = &csi.ListSnapshotsResponse{}
+		snaps []client.Snapshot
+		// cache uuid of origin volumes, key is namespace/name of volume
+		volIds = make(map[string]string)
+	)
+
+	if req.SnapshotId != "" {
+		snap, err := cs.cli.GetSnapshotByID(req.SnapshotId)
+		if err == client.ErrorNotFoundResource {
+			return resp, nil
+		}
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		snaps = append(snaps, *snap)
+	} else {
+		var opt = client.ListSnapshotsOption{
+			Limit:      int64(req.MaxEntries),
+			StartToken: req.StartingToken,
+		}
+		if req.SourceVolumeId != "" {
+			pv, err := cs.cli.GetPvByID(req.SourceVolumeId)
+			if err == client.ErrorNotFoundResource {
+				return resp, nil
+			}
+			if err != nil {
+				klog.Error(err)
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			opt.OriginVolName = pv.Name
+			opt.OriginVolNamespace = pv.Namespace
+			volIds[pv.Namespace+"/"+pv.Name] = pv.UUID
+		}
+
+		var err error
+		snaps, resp.NextToken, err = cs.cli.ListSnapshots(opt)
+		if err != nil {
+			var errCode codes.Code
+			// continue token is expired or invalid
+			if errors.IsResourceExpired(err) || errors.IsGone(err) || errors.IsBadRequest(err) {
+				errCode = codes.Aborted
+			} else {
+				errCode = codes.Internal
+			}
+			klog.Error(err)
+			return nil, status.Error(errCode, err.Error())
+		}
+	}
+
+	for _, snap := range snaps {
+		volKey := snap.Spec.OriginVolNamespace + "/" + snap.Spec.OriginVolName
+		volId, has := volIds[volKey]
+		if !has {
+			vol, err := cs.cli.GetVolumeByName(snap.Spec.OriginVolNamespace, snap.Spec.OriginVolName)
+			if err != nil && !errors.IsNotFound(err) {
+				klog.Error(err)
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			if err == nil {
+				volId = vol.Spec.Uuid
+			}
+			volIds[volKey] = volId
+		}
+
+		// filter by both snapshot id and source volume id
+		if req.SnapshotId != "" && req.SourceVolumeId != "" && req.SourceVolumeId != volId {
+			continue
+		}
+
+		resp.Entries = append(resp.Entries, &csi.ListSnapshotsResponse_Entry{
+			Snapshot: &csi.Snapshot{
+				SizeBytes:      snap.Spec.Size,
+				SnapshotId:     snap.Spec.Uuid,
+				SourceVolumeId: volId,
+				CreationTime:   timestamppb.New(snap.CreationTimestamp.Time),
+				ReadyToUse:     snap.Status.Status == v1.SnapshotStatusReady,
+			},
+		})
+	}
+
+	return resp, nil
 }
 
 func (cs *ControllerServer) ControllerGetCapabilities(ctx context.Context,
@@ -569,3 +1139,208 @@ func (cs *ControllerServer) ControllerGetCapabilities(ctx context.Context,
 		Capabilities: cs.driver.GetControllerCapability(),
 	}, nil
 }
+
+// validateMutableParameters checks that all parameters of VolumeAttributesClass are supported
+func validateMutableParameters(params map[string]string) (err error) {
+	for key := range params {
+		switch key {
+		case v1.QoSRWIOPSKey, v1.QoSRWMBytesKey, v1.QoSRMBytesKey, v1.QoSWMBytesKey:
+		default:
+			return fmt.Errorf("not supported mutable parameter %s", key)
+		}
+	}
+	return
+}
+
+// parseVolumeQoS parses QoS limits in kv, which is StorageClass parameters or PVC annotations.
+// Limits which are not in kv remain unchanged.
+func parseVolumeQoS(kv map[string]string, qos *v1.VolumeQoS) (err error) {
+	var limits = []struct {
+		key string
+		val *uint64
+	}{
+		{key: v1.QoSRWIOPSKey, val: &qos.RWIOPS},
+		{key: v1.QoSRWMBytesKey, val: &qos.RWMBytes},
+		{key: v1.QoSRMBytesKey, val: &qos.RMBytes},
+		{key: v1.QoSWMBytesKey, val: &qos.WMBytes},
+	}
+
+	for _, item := range limits {
+		if val, has := kv[item.key]; has {
+			*item.val, err = strconv.ParseUint(val, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %s, %w", item.key, val, err)
+			}
+		}
+	}
+	return
+}
+
+// validateVolumeQoS checks QoS limits are supported by the volume type.
+// Other types, e.g. Flexible which may be created as SpdkLVol, are checked as SpdkLVol.
+func validateVolumeQoS(volType v1.VolumeType, qos *v1.VolumeQoS) (err error) {
+	if qos == nil || volType == v1.VolumeTypeKernelLVol {
+		return
+	}
+	// SPDK requires IOPS limit to be multiple of 1000
+	if qos.RWIOPS%1000 != 0 {
+		return fmt.Errorf("invalid %s: %d, should be multiple of 1000 for %s volume", v1.QoSRWIOPSKey, qos.RWIOPS, v1.VolumeTypeSpdkLVol)
+	}
+	return
+}
+
+// ensureEncryptionSecret creates the Secret with a random key if it does not exist.
+// If the Secret exists, it must contain a valid key.
+func (cs *ControllerServer) ensureEncryptionSecret(pvName string, enc *v1.VolumeEncryption) (err error) {
+	var secret *corev1.Secret
+	secret, err = cs.kubeCli.CoreV1().Secrets(enc.SecretNamespace).Get(context.Background(), enc.SecretName, metav1.GetOptions{})
+	if err == nil {
+		_, _, err = v1.ParseEncryptionKey(secret.Data[v1.EncryptionKeySecretKey])
+		if err != nil {
+			err = fmt.Errorf("invalid Secret %s/%s, %w", enc.SecretNamespace, enc.SecretName, err)
+		}
+		return
+	}
+	if !errors.IsNotFound(err) {
+		return
+	}
+
+	var key = make([]byte, 64)
+	if _, err = rand.Read(key); err != nil {
+		return
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      enc.SecretName,
+			Namespace: enc.SecretNamespace,
+			Labels: map[string]string{
+				v1.VolumePVNameLabelKey: pvName,
+			},
+			Annotations: map[string]string{
+				v1.EncryptionSecretOwnerAnnoKey: pvName,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			v1.EncryptionKeySecretKey: []byte(hex.EncodeToString(key)),
+		},
+	}
+	klog.Infof("creating encryption key Secret %s/%s for volume %s", enc.SecretNamespace, enc.SecretName, pvName)
+	_, err = cs.kubeCli.CoreV1().Secrets(enc.SecretNamespace).Create(context.Background(), secret, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		err = nil
+	}
+	return
+}
+
+// deleteEncryptionSecret deletes the Secret if it is created by the driver and not used by other volumes or snapshots.
+// excludeVol and excludeSnap are names of the volume or snapshot being deleted.
+func (cs *ControllerServer) deleteEncryptionSecret(enc *v1.VolumeEncryption, excludeVol, excludeSnap string) (err error) {
+	var (
+		secret *corev1.Secret
+		inUse  bool
+	)
+	secret, err = cs.kubeCli.CoreV1().Secrets(enc.SecretNamespace).Get(context.Background(), enc.SecretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	// Secret is provided by user
+	if _, has := secret.Annotations[v1.EncryptionSecretOwnerAnnoKey]; !has {
+		klog.Infof("encryption key Secret %s/%s is not created by driver, skip deleting it", enc.SecretNamespace, enc.SecretName)
+		return
+	}
+
+	inUse, err = cs.isEncryptionSecretInUse(enc, excludeVol, excludeSnap)
+	if err != nil || inUse {
+		return
+	}
+
+	err = cs.kubeCli.CoreV1().Secrets(enc.SecretNamespace).Delete(context.Background(), enc.SecretName, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		err = nil
+	}
+	if err == nil {
+		klog.Infof("deleted encryption key Secret %s/%s", enc.SecretNamespace, enc.SecretName)
+	}
+	return
+}
+
+// isEncryptionSecretInUse returns true if volumes or snapshots other than the excluded ones refer to the Secret
+func (cs *ControllerServer) isEncryptionSecretInUse(enc *v1.VolumeEncryption, excludeVol, excludeSnap string) (inUse bool, err error) {
+	var (
+		vols  []client.Volume
+		snaps []client.Snapshot
+		token string
+		// volumes using the key, including the excluded one
+		encVols = misc.NewEmptySet()
+	)
+
+	for {
+		vols, token, err = cs.cli.ListVolumes(listPageSize, token)
+		if err != nil {
+			return
+		}
+		for _, vol := range vols {
+			if !isSameEncryption(vol.Spec.Encryption, enc) {
+				continue
+			}
+			if vol.Name != excludeVol {
+				klog.Infof("encryption key Secret %s/%s is used by volume %s", enc.SecretNamespace, enc.SecretName, vol.Name)
+				return true, nil
+			}
+			encVols.Add(vol.Name)
+		}
+		if token == "" {
+			break
+		}
+	}
+
+	for {
+		snaps, token, err = cs.cli.ListSnapshots(client.ListSnapshotsOption{Limit: listPageSize, StartToken: token})
+		if err != nil {
+			return
+		}
+		for _, snap := range snaps {
+			if snap.Name == excludeSnap {
+				continue
+			}
+			// snapshot which is not bound to node yet may have no encryption, if it is not created by CreateSnapshot
+			var pending = snap.Spec.Encryption == nil && snap.Spec.OriginVolTargetNodeID == "" && encVols.Contains(snap.Spec.OriginVolName)
+			if pending || isSameEncryption(snap.Spec.Encryption, enc) {
+				klog.Infof("encryption key Secret %s/%s is used by snapshot %s", enc.SecretNamespace, enc.SecretName, snap.Name)
+				return true, nil
+			}
+		}
+		if token == "" {
+			break
+		}
+	}
+
+	return
+}
+
+func isSameEncryption(a, b *v1.VolumeEncryption) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// getImportSource returns the image exported from the snapshot, which must be finished and not bigger than the requested size
+func getImportSource(snap *v1.AntstorSnapshot, size int64) (src *v1.VolumeImportSource, err error) {
+	var exp = snap.Status.Export
+	if snap.Spec.Export == nil || exp == nil || exp.Phase != v1.TransferFinished {
+		err = fmt.Errorf("snapshot %s/%s has not been exported to object storage yet", snap.Namespace, snap.Name)
+		return
+	}
+	if exp.SizeBytes > size {
+		err = fmt.Errorf("request size %d is smaller than exported snapshot size %d", size, exp.SizeBytes)
+		return
+	}
+
+	src = &v1.VolumeImportSource{
+		ObjectStore: snap.Spec.Export.ObjectStore,
+		ManifestKey: exp.ManifestKey,
+	}
+	return
+}
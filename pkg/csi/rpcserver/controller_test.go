@@ -0,0 +1,220 @@
+package rpcserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/csi/client"
+	"lite.io/liteio/pkg/csi/driver"
+)
+
+// fakeAntstorClient keeps PVs, volumes, snapshots and group snapshots in memory. Other methods are not implemented.
+// snapshots and groupSnaps are indexed by uuid, volumes are indexed by name.
+type fakeAntstorClient struct {
+	client.AntstorClientIface
+	pvs        map[string]client.PV
+	volumes    map[string]*client.Volume
+	snapshots  map[string]*client.Snapshot
+	groupSnaps map[string]*client.GroupSnapshot
+}
+
+func newFakeAntstorClient() *fakeAntstorClient {
+	return &fakeAntstorClient{
+		pvs:        make(map[string]client.PV),
+		volumes:    make(map[string]*client.Volume),
+		snapshots:  make(map[string]*client.Snapshot),
+		groupSnaps: make(map[string]*client.GroupSnapshot),
+	}
+}
+
+func (f *fakeAntstorClient) GetPvByID(id string) (pv client.PV, err error) {
+	if pv, has := f.pvs[id]; has {
+		return pv, nil
+	}
+	return pv, client.ErrorNotFoundResource
+}
+
+func (f *fakeAntstorClient) ListVolumes(limit int64, startToken string) (vols []client.Volume, nextToken string, err error) {
+	for _, vol := range f.volumes {
+		vols = append(vols, *vol.DeepCopy())
+	}
+	return
+}
+
+func (f *fakeAntstorClient) GetVolumeByName(ns, name string) (vol *client.Volume, err error) {
+	if vol, has := f.volumes[name]; has {
+		return vol.DeepCopy(), nil
+	}
+	return nil, errors.NewNotFound(v1.Resource("antstorvolumes"), name)
+}
+
+func (f *fakeAntstorClient) GetSnapshotByID(snapID string) (snapshot *client.Snapshot, err error) {
+	if snap, has := f.snapshots[snapID]; has {
+		return snap.DeepCopy(), nil
+	}
+	return nil, client.ErrorNotFoundResource
+}
+
+func (f *fakeAntstorClient) GetSnapshotByName(ns, name string) (snapshot *client.Snapshot, err error) {
+	for _, snap := range f.snapshots {
+		if snap.Namespace == ns && snap.Name == name {
+			return snap.DeepCopy(), nil
+		}
+	}
+	return nil, errors.NewNotFound(v1.Resource("antstorsnapshots"), name)
+}
+
+func (f *fakeAntstorClient) CreateSnapshot(snap client.Snapshot) (snapID string, err error) {
+	snap.Spec.Uuid = fmt.Sprintf("snap-%d", len(f.snapshots))
+	snap.Labels[v1.SnapUuidLabelKey] = snap.Spec.Uuid
+	f.snapshots[snap.Spec.Uuid] = &snap
+	return snap.Spec.Uuid, nil
+}
+
+func (f *fakeAntstorClient) DeleteSnapshot(snapID string) (err error) {
+	delete(f.snapshots, snapID)
+	return
+}
+
+func (f *fakeAntstorClient) ListSnapshots(opt client.ListSnapshotsOption) (snaps []client.Snapshot, nextToken string, err error) {
+	for _, snap := range f.snapshots {
+		snaps = append(snaps, *snap.DeepCopy())
+	}
+	return
+}
+
+func (f *fakeAntstorClient) GetGroupSnapshotByID(groupSnapID string) (gs *client.GroupSnapshot, err error) {
+	if gs, has := f.groupSnaps[groupSnapID]; has {
+		return gs.DeepCopy(), nil
+	}
+	return nil, client.ErrorNotFoundResource
+}
+
+func (f *fakeAntstorClient) GetGroupSnapshotByName(ns, name string) (gs *client.GroupSnapshot, err error) {
+	for _, item := range f.groupSnaps {
+		if item.Namespace == ns && item.Name == name {
+			return item.DeepCopy(), nil
+		}
+	}
+	return nil, errors.NewNotFound(v1.Resource("antstorvolumegroupsnapshots"), name)
+}
+
+func (f *fakeAntstorClient) CreateGroupSnapshot(gs client.GroupSnapshot) (groupSnapID string, err error) {
+	gs.Spec.Uuid = fmt.Sprintf("group-snap-%d", len(f.groupSnaps))
+	f.groupSnaps[gs.Spec.Uuid] = &gs
+	return gs.Spec.Uuid, nil
+}
+
+func TestDeleteEncryptionSecret(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		newSecret = func(name string, createdByDriver bool) *corev1.Secret {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+			if createdByDriver {
+				secret.Annotations = map[string]string{v1.EncryptionSecretOwnerAnnoKey: "pv-" + name}
+			}
+			return secret
+		}
+		newEnc = func(name string) *v1.VolumeEncryption {
+			return &v1.VolumeEncryption{SecretName: name, SecretNamespace: "default"}
+		}
+		kubeCli   = kubefake.NewSimpleClientset(newSecret("key-user", false), newSecret("key-1", true), newSecret("key-2", true))
+		cli       = newFakeAntstorClient()
+		cs        = NewControllerServer(driver.NewCSIDriver(driver.NewCSIDriverOption{Name: "test", NodeID: "node-1"}), cli, kubeCli)
+		hasSecret = func(name string) bool {
+			_, err := kubeCli.CoreV1().Secrets("default").Get(ctx, name, metav1.GetOptions{})
+			return err == nil
+		}
+	)
+	// key-1 is shared by the volume and the snapshot of it
+	cli.volumes["vol-1"] = &client.Volume{
+		ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "vol-1"},
+		Spec:       v1.AntstorVolumeSpec{Uuid: "uuid-vol-1", Encryption: newEnc("key-1")},
+	}
+	cli.snapshots["uuid-snap-1"] = &client.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "snap-1"},
+		Spec:       v1.AntstorSnapshotSpec{OriginVolName: "vol-1", Encryption: newEnc("key-1")},
+	}
+
+	// Secret provided by user is never deleted
+	assert.NoError(t, cs.deleteEncryptionSecret(newEnc("key-user"), "vol-user", ""))
+	assert.True(t, hasSecret("key-user"))
+
+	// unused Secret created by driver is deleted, and deleting it again is ok
+	assert.NoError(t, cs.deleteEncryptionSecret(newEnc("key-2"), "vol-2", ""))
+	assert.False(t, hasSecret("key-2"))
+	assert.NoError(t, cs.deleteEncryptionSecret(newEnc("key-2"), "vol-2", ""))
+
+	// Secret is used by other volumes or snapshots
+	assert.NoError(t, cs.deleteEncryptionSecret(newEnc("key-1"), "vol-clone", ""))
+	assert.True(t, hasSecret("key-1"))
+	assert.NoError(t, cs.deleteEncryptionSecret(newEnc("key-1"), "vol-1", ""))
+	assert.True(t, hasSecret("key-1"))
+
+	// Secret is deleted with the last snapshot using it
+	delete(cli.volumes, "vol-1")
+	_, err := cs.DeleteSnapshot(ctx, &csi.DeleteSnapshotRequest{SnapshotId: "uuid-snap-1"})
+	assert.NoError(t, err)
+	assert.False(t, hasSecret("key-1"))
+	assert.Empty(t, cli.snapshots)
+}
+
+func TestEncryptionSecretUsedBySnapshot(t *testing.T) {
+	var (
+		ctx = context.Background()
+		enc = &v1.VolumeEncryption{SecretName: "key-1", SecretNamespace: "default"}
+		cli = newFakeAntstorClient()
+		cs  = NewControllerServer(driver.NewCSIDriver(driver.NewCSIDriverOption{Name: "test", NodeID: "node-1"}), cli, kubefake.NewSimpleClientset())
+		vol = &client.Volume{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "vol-1"},
+			Spec:       v1.AntstorVolumeSpec{Uuid: "uuid-vol-1", SizeByte: 1 << 30, Encryption: enc},
+			Status:     v1.AntstorVolumeStatus{Status: v1.VolumeStatusReady},
+		}
+	)
+	cli.volumes[vol.Name] = vol
+	cli.pvs[vol.Spec.Uuid] = client.PV{Type: client.PvTypeVolume, Volume: vol}
+
+	// encryption is copied to the snapshot at once
+	resp, err := cs.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{SourceVolumeId: "uuid-vol-1", Name: "snap-1"})
+	assert.NoError(t, err)
+	if assert.Contains(t, cli.snapshots, resp.Snapshot.SnapshotId) {
+		assert.Equal(t, enc, cli.snapshots[resp.Snapshot.SnapshotId].Spec.Encryption)
+	}
+	inUse, err := cs.isEncryptionSecretInUse(enc, "vol-1", "")
+	assert.NoError(t, err)
+	assert.True(t, inUse)
+
+	// snapshot of the volume is not bound to node yet, and has no encryption
+	cli.snapshots[resp.Snapshot.SnapshotId].Spec.Encryption = nil
+	inUse, err = cs.isEncryptionSecretInUse(enc, "vol-1", "")
+	assert.NoError(t, err)
+	assert.True(t, inUse)
+
+	// snapshot bound to node without encryption does not use the key
+	cli.snapshots[resp.Snapshot.SnapshotId].Spec.OriginVolTargetNodeID = "node-1"
+	inUse, err = cs.isEncryptionSecretInUse(enc, "vol-1", "")
+	assert.NoError(t, err)
+	assert.False(t, inUse)
+}
+
+func TestValidateVolumeQoS(t *testing.T) {
+	var qos = &v1.VolumeQoS{RWIOPS: 1500}
+
+	// KernelLVol volume is limited by cgroup, which accepts any IOPS
+	assert.NoError(t, validateVolumeQoS(v1.VolumeTypeKernelLVol, qos))
+	assert.Error(t, validateVolumeQoS(v1.VolumeTypeSpdkLVol, qos))
+	assert.Error(t, validateVolumeQoS(v1.VolumeTypeFlexible, qos))
+
+	qos.RWIOPS = 2000
+	assert.NoError(t, validateVolumeQoS(v1.VolumeTypeSpdkLVol, qos))
+	assert.NoError(t, validateVolumeQoS(v1.VolumeTypeSpdkLVol, nil))
+}
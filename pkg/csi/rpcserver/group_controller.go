@@ -0,0 +1,187 @@
+package rpcserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/csi/client"
+	"lite.io/liteio/pkg/csi/driver"
+)
+
+var _ csi.GroupControllerServer = &ControllerServer{}
+
+func (cs *ControllerServer) GroupControllerGetCapabilities(ctx context.Context, req *csi.GroupControllerGetCapabilitiesRequest) (*csi.GroupControllerGetCapabilitiesResponse, error) {
+	var caps []*csi.GroupControllerServiceCapability
+	for _, item := range driver.DefaultGroupControllerServiceCapability {
+		caps = append(caps, driver.NewGroupControllerServiceCapability(item))
+	}
+	return &csi.GroupControllerGetCapabilitiesResponse{
+		Capabilities: caps,
+	}, nil
+}
+
+// CreateVolumeGroupSnapshot creates a crash-consistent snapshot of all volumes of a VolumeGroup PV.
+// SourceVolumeIds must contain exactly one PV of VolumeGroup type. IO of the host LV is frozen while member snapshots are created.
+// This operation MUST be idempotent.
+func (cs *ControllerServer) CreateVolumeGroupSnapshot(ctx context.Context, req *csi.CreateVolumeGroupSnapshotRequest) (*csi.CreateVolumeGroupSnapshotResponse, error) {
+	klog.Infof("CreateVolumeGroupSnapshot Req=%s", req.String())
+
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "CreateVolumeGroupSnapshotRequest.Name is nil")
+	}
+	if len(req.GetSourceVolumeIds()) != 1 || !strings.HasPrefix(req.SourceVolumeIds[0], client.DataControlUuidPrefix) {
+		return nil, status.Error(codes.InvalidArgument, "only support one source volume of VolumeGroup type")
+	}
+
+	var volID = req.SourceVolumeIds[0]
+	pv, err := cs.cli.GetPvByID(volID)
+	if err != nil {
+		klog.Error(err)
+		if err == client.ErrorNotFoundResource {
+			return nil, status.Errorf(codes.NotFound, "volume %s not found", volID)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if pv.Type != client.PvTypeVolumeGroup || pv.DataContrl == nil {
+		return nil, status.Error(codes.InvalidArgument, "only support VolumeGroup snapshot")
+	}
+	if !pv.IsLVM() {
+		return nil, status.Error(codes.InvalidArgument, "only support VolumeGroup of LVM engine")
+	}
+
+	gs, err := cs.cli.GetGroupSnapshotByName(v1.DefaultNamespace, req.GetName())
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	// found group snapshot
+	if err == nil && gs != nil {
+		if gs.Spec.DataControlName != pv.Name {
+			return nil, status.Errorf(codes.AlreadyExists, "group snapshot %s exists with different source volume", gs.Name)
+		}
+	} else {
+		if pv.DataContrl.Status.Status != v1.VolumeStatusReady {
+			return nil, status.Errorf(codes.FailedPrecondition, "group snapshot should be created after volume is ready, volume status %s", pv.DataContrl.Status.Status)
+		}
+
+		var newGs = client.GroupSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: v1.DefaultNamespace,
+				Name:      req.GetName(),
+				Labels:    make(map[string]string),
+				Annotations: map[string]string{
+					v1.GroupSnapshotSourceVolumeIdAnnoKey: pv.UUID,
+				},
+			},
+			Spec: v1.AntstorVolumeGroupSnapshotSpec{
+				DataControlName: pv.Name,
+			},
+		}
+		var groupSnapID string
+		groupSnapID, err = cs.cli.CreateGroupSnapshot(newGs)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		newGs.Spec.Uuid = groupSnapID
+		newGs.CreationTimestamp = metav1.Now()
+		gs = &newGs
+	}
+
+	if gs.Status.Phase == v1.GroupSnapshotFailed {
+		return nil, status.Errorf(codes.Internal, "group snapshot %s failed, %s", gs.Name, gs.Status.Message)
+	}
+
+	groupSnap, err := cs.toCSIGroupSnapshot(gs)
+	if err != nil {
+		return nil, err
+	}
+	return &csi.CreateVolumeGroupSnapshotResponse{
+		GroupSnapshot: groupSnap,
+	}, nil
+}
+
+// DeleteVolumeGroupSnapshot deletes the group snapshot and all of its member snapshots.
+// This operation MUST be idempotent.
+func (cs *ControllerServer) DeleteVolumeGroupSnapshot(ctx context.Context, req *csi.DeleteVolumeGroupSnapshotRequest) (*csi.DeleteVolumeGroupSnapshotResponse, error) {
+	klog.Infof("DeleteVolumeGroupSnapshot Req=%s", req.String())
+	if req.GroupSnapshotId == "" {
+		return nil, status.Error(codes.InvalidArgument, "DeleteVolumeGroupSnapshotRequest is invalid")
+	}
+
+	err := cs.cli.DeleteGroupSnapshot(req.GroupSnapshotId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &csi.DeleteVolumeGroupSnapshotResponse{}, nil
+}
+
+func (cs *ControllerServer) GetVolumeGroupSnapshot(ctx context.Context, req *csi.GetVolumeGroupSnapshotRequest) (*csi.GetVolumeGroupSnapshotResponse, error) {
+	klog.Infof("GetVolumeGroupSnapshot Req=%s", req.String())
+	if req.GroupSnapshotId == "" {
+		return nil, status.Error(codes.InvalidArgument, "GetVolumeGroupSnapshotRequest is invalid")
+	}
+
+	gs, err := cs.cli.GetGroupSnapshotByID(req.GroupSnapshotId)
+	if err != nil {
+		klog.Error(err)
+		if err == client.ErrorNotFoundResource {
+			return nil, status.Errorf(codes.NotFound, "group snapshot %s not found", req.GroupSnapshotId)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	groupSnap, err := cs.toCSIGroupSnapshot(gs)
+	if err != nil {
+		return nil, err
+	}
+	return &csi.GetVolumeGroupSnapshotResponse{
+		GroupSnapshot: groupSnap,
+	}, nil
+}
+
+// toCSIGroupSnapshot converts group snapshot to CSI message. Member snapshots are listed once they are created.
+// SourceVolumeId of each member snapshot is the id of its member volume.
+func (cs *ControllerServer) toCSIGroupSnapshot(gs *client.GroupSnapshot) (groupSnap *csi.VolumeGroupSnapshot, err error) {
+	var ready = gs.Status.Phase == v1.GroupSnapshotReady
+	groupSnap = &csi.VolumeGroupSnapshot{
+		GroupSnapshotId: gs.Spec.Uuid,
+		CreationTime:    timestamppb.New(gs.CreationTimestamp.Time),
+		ReadyToUse:      ready,
+	}
+
+	for _, item := range gs.Status.Snapshots {
+		var (
+			snap *client.Snapshot
+			vol  *client.Volume
+		)
+		snap, err = cs.cli.GetSnapshotByName(item.Namespace, item.Name)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		vol, err = cs.cli.GetVolumeByName(snap.Spec.OriginVolNamespace, snap.Spec.OriginVolName)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		groupSnap.Snapshots = append(groupSnap.Snapshots, &csi.Snapshot{
+			SizeBytes:       snap.Spec.Size,
+			SnapshotId:      snap.Spec.Uuid,
+			SourceVolumeId:  vol.Spec.Uuid,
+			CreationTime:    timestamppb.New(snap.CreationTimestamp.Time),
+			ReadyToUse:      ready && snap.Status.Status == v1.SnapshotStatusReady,
+			GroupSnapshotId: gs.Spec.Uuid,
+		})
+	}
+	return
+}
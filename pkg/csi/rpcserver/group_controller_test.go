@@ -0,0 +1,114 @@
+package rpcserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/csi/client"
+	"lite.io/liteio/pkg/csi/driver"
+)
+
+func TestVolumeGroupSnapshot(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		dcID = client.DataControlUuidPrefix + "dc-1"
+		cli  = newFakeAntstorClient()
+		cs   = NewControllerServer(driver.NewCSIDriver(driver.NewCSIDriverOption{Name: "test", NodeID: "node-1"}), cli, nil)
+		dc   = &v1.AntstorDataControl{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "dc-1"},
+			Spec: v1.AntstorDataControlSpec{
+				UUID:       dcID,
+				EngineType: v1.PoolModeKernelLVM,
+			},
+			Status: v1.AntstorDataControlStatus{
+				Status: v1.VolumeStatusCreating,
+			},
+		}
+	)
+	cli.pvs[dcID] = client.PV{Namespace: dc.Namespace, Name: dc.Name, UUID: dcID, Type: client.PvTypeVolumeGroup, DataContrl: dc}
+	for _, name := range []string{"vol-1", "vol-2"} {
+		cli.volumes[name] = &client.Volume{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: name},
+			Spec:       v1.AntstorVolumeSpec{Uuid: "uuid-" + name},
+		}
+	}
+
+	// invalid source volumes
+	_, err := cs.CreateVolumeGroupSnapshot(ctx, &csi.CreateVolumeGroupSnapshotRequest{Name: "gs-1", SourceVolumeIds: []string{"uuid-vol-1"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = cs.CreateVolumeGroupSnapshot(ctx, &csi.CreateVolumeGroupSnapshotRequest{Name: "gs-1", SourceVolumeIds: []string{client.DataControlUuidPrefix + "x"}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// DataControl is not ready
+	var req = &csi.CreateVolumeGroupSnapshotRequest{Name: "gs-1", SourceVolumeIds: []string{dcID}}
+	_, err = cs.CreateVolumeGroupSnapshot(ctx, req)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// group snapshot is created, and no member snapshot yet
+	dc.Status.Status = v1.VolumeStatusReady
+	resp, err := cs.CreateVolumeGroupSnapshot(ctx, req)
+	assert.NoError(t, err)
+	var groupSnapID = resp.GroupSnapshot.GroupSnapshotId
+	assert.NotEmpty(t, groupSnapID)
+	assert.False(t, resp.GroupSnapshot.ReadyToUse)
+	assert.Empty(t, resp.GroupSnapshot.Snapshots)
+	if assert.Len(t, cli.groupSnaps, 1) {
+		assert.Equal(t, "dc-1", cli.groupSnaps[groupSnapID].Spec.DataControlName)
+	}
+
+	// idempotent, and member snapshots report their own source volumes
+	gs := cli.groupSnaps[groupSnapID]
+	for idx, name := range []string{"vol-1", "vol-2"} {
+		snap := &client.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: fmt.Sprintf("gs-1-%d", idx)},
+			Spec: v1.AntstorSnapshotSpec{
+				Uuid:               "snap-uuid-" + name,
+				Size:               4 << 20,
+				OriginVolName:      name,
+				OriginVolNamespace: v1.DefaultNamespace,
+			},
+			Status: v1.AntstorSnapshotStatus{Status: v1.SnapshotStatusReady},
+		}
+		cli.snapshots[snap.Spec.Uuid] = snap
+		gs.Status.Snapshots = append(gs.Status.Snapshots, v1.EntityIdentity{Namespace: snap.Namespace, Name: snap.Name, UUID: snap.Spec.Uuid})
+	}
+	gs.Status.Phase = v1.GroupSnapshotReady
+	resp, err = cs.CreateVolumeGroupSnapshot(ctx, req)
+	assert.NoError(t, err)
+	assert.Len(t, cli.groupSnaps, 1)
+	assert.True(t, resp.GroupSnapshot.ReadyToUse)
+	if assert.Len(t, resp.GroupSnapshot.Snapshots, 2) {
+		for idx, name := range []string{"vol-1", "vol-2"} {
+			snap := resp.GroupSnapshot.Snapshots[idx]
+			assert.Equal(t, "snap-uuid-"+name, snap.SnapshotId)
+			assert.Equal(t, "uuid-"+name, snap.SourceVolumeId)
+			assert.Equal(t, groupSnapID, snap.GroupSnapshotId)
+			assert.True(t, snap.ReadyToUse)
+		}
+	}
+
+	getResp, err := cs.GetVolumeGroupSnapshot(ctx, &csi.GetVolumeGroupSnapshotRequest{GroupSnapshotId: groupSnapID})
+	assert.NoError(t, err)
+	if assert.Len(t, getResp.GroupSnapshot.Snapshots, 2) {
+		assert.Equal(t, "uuid-vol-2", getResp.GroupSnapshot.Snapshots[1].SourceVolumeId)
+	}
+
+	// same name with different source volume
+	var dc2ID = client.DataControlUuidPrefix + "dc-2"
+	cli.pvs[dc2ID] = client.PV{Namespace: dc.Namespace, Name: "dc-2", UUID: dc2ID, Type: client.PvTypeVolumeGroup, DataContrl: dc}
+	_, err = cs.CreateVolumeGroupSnapshot(ctx, &csi.CreateVolumeGroupSnapshotRequest{Name: "gs-1", SourceVolumeIds: []string{dc2ID}})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// failed group snapshot
+	gs.Status.Phase = v1.GroupSnapshotFailed
+	_, err = cs.CreateVolumeGroupSnapshot(ctx, req)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
@@ -9,6 +9,8 @@ import (
 )
 
 type IdentityServer struct {
+	csi.UnimplementedIdentityServer
+
 	driver *driver.CSIDriver
 }
 
@@ -1,6 +1,7 @@
 package rpcserver
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"strconv"
@@ -12,6 +13,9 @@ import (
 	"golang.org/x/sys/unix"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	"k8s.io/mount-utils"
 	"k8s.io/utils/exec"
@@ -21,7 +25,9 @@ import (
 	"lite.io/liteio/pkg/csi/driver"
 	spdkclient "lite.io/liteio/pkg/spdk/jsonrpc/client"
 	"lite.io/liteio/pkg/spdk/jsonrpc/nvme"
+	"lite.io/liteio/pkg/util/cgroup"
 	"lite.io/liteio/pkg/util/kata"
+	"lite.io/liteio/pkg/util/luks"
 	"lite.io/liteio/pkg/util/misc"
 	mkfs "lite.io/liteio/pkg/util/mount"
 )
@@ -31,21 +37,26 @@ const (
 )
 
 type NodeServer struct {
+	csi.UnimplementedNodeServer
+
 	driver  *driver.CSIDriver
 	mounter *mount.SafeFormatAndMount
 	locks   *misc.ResourceLocks
 	cli     client.AntstorClientIface
+	// kubeCli is used to read Secrets of encryption keys
+	kubeCli kubernetes.Interface
 }
 
 var _ csi.NodeServer = &NodeServer{}
 
 // NewNodeServer creates a node server
-func NewNodeServer(driver *driver.CSIDriver, mnt *mount.SafeFormatAndMount, cli client.AntstorClientIface) *NodeServer {
+func NewNodeServer(driver *driver.CSIDriver, mnt *mount.SafeFormatAndMount, cli client.AntstorClientIface, kubeCli kubernetes.Interface) *NodeServer {
 	return &NodeServer{
 		driver:  driver,
 		cli:     cli,
 		mounter: mnt,
 		locks:   misc.NewResourceLocks(),
+		kubeCli: kubeCli,
 	}
 }
 
@@ -99,6 +110,20 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 
 	klog.Infof("volume is ready, id=%s", req.VolumeId)
 
+	// MultiNodeMultiWriter volume is attached by multiple nodes. Record this node in the volume,
+	// so the target node will allow this node to connect.
+	var nodeID = ns.driver.GetInstanceId()
+	if pv.IsMultiWriter() {
+		if !isBlockMode {
+			return nil, status.Error(codes.InvalidArgument, "MultiNodeMultiWriter volume only supports Block mode")
+		}
+		err = ns.cli.SetPvAttachedHost(req.VolumeId, nodeID, true)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	// 判断是否 远程盘+ guest kernel 直连SPDK模式
 	/*
 		if !usingLocalDisk && vol.Labels[spdkConnectModeKey] == spdkConnectModeGuestKernelDirect {
@@ -120,7 +145,7 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 
 	var (
 		// 判断是否是本地磁盘, targetNode 是否等于 hostNode
-		isLocalDisk  = pv.IsLocal()
+		isLocalDisk  = pv.IsLocalTo(nodeID)
 		isLVM        = pv.IsLVM()
 		targetNodeId = pv.GetTargetNodeId()
 		anno         = pv.GetAnnotations()
@@ -138,6 +163,9 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 	// 判断是否是kata rund
 	// kata 的 rawfile 方案，不能在宿主机上挂载 dm 到 targetPath
 	if anno[containerTypeKey] == containerTypeForKata {
+		if isLVM && pv.GetEncryption() != nil {
+			return nil, status.Error(codes.InvalidArgument, "encrypted LVM volume is not supported by kata rund")
+		}
 		// 判断是否 远程盘+ kata guest kernel 直连SPDK模式
 		// 由于是远程盘，所以在创建LV时，就已经格式化了
 		if !isLocalDisk && anno[spdkConnectModeKey] == spdkConnectModeGuestKernelDirect {
@@ -181,11 +209,33 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 	// 3. if the volume is local and type is SpdkLVol, do the same as remote volume.
 	devicePath = pv.GetDevPath()
 	if !isLocalDisk || (!isLVM && pv.GetSpdkTarget() != nil) {
-		devicePath, err = connectSpdkTarget(pv.GetSpdkTarget())
+		var connOpts nvme.ConnectTargetOpts
+		// the target only allows the hostnqn recorded in StoragePool
+		connOpts.HostNQN, err = ns.getHostNQN(nodeID)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		// keys of DH-HMAC-CHAP are passed by node-stage-secret
+		if auth := pv.GetHostAuth(); auth != nil {
+			connOpts.DHChapSecret = req.Secrets[v1.DHChapKeySecretKey]
+			connOpts.DHChapCtrlSecret = req.Secrets[v1.DHChapCtrlrKeySecretKey]
+			if connOpts.DHChapSecret == "" {
+				err = fmt.Errorf("volume requires DH-HMAC-CHAP key %s of Secret %s/%s, which should be set as node-stage-secret in StorageClass",
+					v1.DHChapKeySecretKey, auth.SecretNamespace, auth.SecretName)
+				klog.Error(err)
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+		}
+
+		devicePath, err = connectSpdkTarget(pv.GetSpdkTarget(), connOpts)
 		if err != nil {
 			klog.Error(err)
 			return nil, status.Error(codes.Internal, "cannot connect target to provide devicePath")
 		}
+		if len(pv.GetSpdkTarget().Paths) > 0 && pv.Type == client.PvTypeVolume {
+			ns.reportHostPathStatus(req.VolumeId, nodeID, pv.GetSpdkTarget())
+		}
 	}
 
 	if devicePath == "" {
@@ -193,6 +243,21 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 		return nil, status.Error(codes.Internal, "cannot find block device to format and mount")
 	}
 
+	// LVM volume is encrypted by LUKS on the host. SpdkLVol is already encrypted by the crypto bdev of target.
+	if enc := pv.GetEncryption(); enc != nil && isLVM {
+		var key []byte
+		key, err = ns.getEncryptionKey(enc)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		devicePath, err = luks.FormatAndOpen(devicePath, getLuksName(req.VolumeId), key)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	// do partition, mount to targetPath
 	// do mount
 	klog.Infof("Mounting volume %s from dev %s to %s, isBlockMode=%t", req.VolumeId, devicePath, targetPath, isBlockMode)
@@ -218,9 +283,10 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 		// do format and mount
 		var _, hasSnapName = labels[v1.VolumeSourceSnapNameLabelKey]
 		var _, hasSnapNS = labels[v1.VolumeSourceSnapNamespaceLabelKey]
-		var isClonedVol = hasSnapName && hasSnapNS
+		var isImportedVol = pv.Volume != nil && pv.Volume.Spec.ImportSource != nil
+		var isClonedVol = hasSnapName && hasSnapNS || isImportedVol
 		var mountOpts = make([]string, 0, 1)
-		// if the volume is cloned, use nouuid option
+		// if the volume is cloned or imported, use nouuid option
 		// cloned volume has identical UUID with original volume. XFS needs UUID to be unique.
 		// ref: https://access.redhat.com/solutions/5494781
 		if isClonedVol {
@@ -318,14 +384,30 @@ func (ns *NodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstag
 		}
 	}
 
+	var (
+		nodeID      = ns.driver.GetInstanceId()
+		isLVM       = pv.IsLVM()
+		isLocalDisk = pv.IsLocalTo(nodeID)
+		tgt         = pv.GetSpdkTarget()
+	)
+
+	// close LUKS device before disconnecting it
+	if pv.GetEncryption() != nil && isLVM {
+		err = luks.Close(getLuksName(volumeId))
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	klog.Info("sleep 5s before disconnecting nvme")
 	time.Sleep(5 * time.Second)
 
 	// 2. Disconnect from target subsystem by NQN
 	// prepare nvme client
-	var isLVM = pv.IsLVM()
-	if !isLVM {
-		var nqn = pv.GetSpdkTarget().SubsysNQN
+	// the same condition as connecting target in NodeStageVolume
+	if tgt != nil && (!isLocalDisk || !isLVM) {
+		var nqn = tgt.SubsysNQN
 		klog.Infof("volume %s is disconnecting remote nvme %s", volumeId, nqn)
 		nvmeCli := nvme.NewClientWithCmdPath(nvmeClientFilePath)
 		// disconnect must be idempotent; 如果nqn不存在， exit-code 还是0
@@ -337,6 +419,23 @@ func (ns *NodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstag
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 	}
+
+	// remove path status of this node
+	if tgt != nil && len(tgt.Paths) > 0 && pv.Type == client.PvTypeVolume {
+		err = ns.cli.SetPvHostPathStatus(volumeId, nodeID, nil)
+		if err != nil {
+			klog.Error(err)
+		}
+	}
+
+	// revoke access of this node to the MultiNodeMultiWriter volume
+	if pv.IsMultiWriter() {
+		err = ns.cli.SetPvAttachedHost(volumeId, nodeID, false)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
 	return &csi.NodeUnstageVolumeResponse{}, nil
 }
 
@@ -402,10 +501,10 @@ func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 	}
 
 	var (
-		fsType    = pv.GetFsType()
-		isKataPod = isForRund(pv.GetAnnotations())
-		// isLocalDisk = pv.IsLocal()
-		isLVM = pv.IsLVM()
+		fsType      = pv.GetFsType()
+		isKataPod   = isForRund(pv.GetAnnotations())
+		isLocalDisk = pv.IsLocalTo(nodeID)
+		isLVM       = pv.IsLVM()
 	)
 
 	options := []string{"bind"}
@@ -448,13 +547,18 @@ func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 	} else {
 		// 获取 device path
 		var devPath string = pv.GetDevPath()
-		if !isLVM {
+		if !isLVM || !isLocalDisk {
 			devPath, err = getDevicePath(pv.GetSpdkTarget())
 			if err != nil {
 				return nil, status.Error(codes.Internal, err.Error())
 			}
 		}
 
+		// LUKS device is opened in NodeStageVolume
+		if isLVM && pv.GetEncryption() != nil {
+			devPath = luks.MapperPath(getLuksName(req.VolumeId))
+		}
+
 		if devPath == "" {
 			errStr := fmt.Sprintf("cannot find devPath of volume %s, id=%s", pv.Name, req.VolumeId)
 			return nil, status.Error(codes.Internal, errStr)
@@ -484,9 +588,31 @@ func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		// }
 	}
 
+	// local LVM volume is not limited by SPDK, so limit the IO of pod by cgroup
+	if qos := pv.GetQoS(); qos != nil && isLVM && isLocalDisk {
+		err = setPodIOMax(req.VolumeContext[podUuidKey], pv.GetDevPath(), qos)
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	return &csi.NodePublishVolumeResponse{}, nil
 }
 
+// setPodIOMax sets io.max of the device in the cgroup v2 of the pod.
+// It is skipped if the host does not use cgroup v2 or the pod uid is unknown.
+func setPodIOMax(podUID, devPath string, qos *v1.VolumeQoS) (err error) {
+	if podUID == "" || !cgroup.IsV2(cgroup.DefaultRoot) {
+		klog.Warningf("skip setting io.max of %s, pod uid %q, cgroup v2 %t", devPath, podUID, cgroup.IsV2(cgroup.DefaultRoot))
+		return nil
+	}
+
+	var limits = cgroup.NewIOMax(qos.RWIOPS, qos.RWMBytes, qos.RMBytes, qos.WMBytes)
+	klog.Infof("set io.max of %s for pod %s: %+v", devPath, podUID, limits)
+	return cgroup.SetPodIOMax(cgroup.DefaultRoot, podUID, devPath, limits)
+}
+
 // NodeUnpublishVolume MUST be idempotent
 // csi.NodeUnpublishVolumeRequest:	volume id	+ Required
 //
@@ -586,6 +712,20 @@ func (ns *NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 		return nil, status.Error(codes.Internal, errStr)
 	}
 
+	// grow the LUKS device to the size of LV before resizing the filesystem
+	if enc := pv.GetEncryption(); enc != nil && isLVM {
+		var key []byte
+		key, err = ns.getEncryptionKey(enc)
+		if err == nil {
+			err = luks.Resize(getLuksName(req.VolumeId), key)
+		}
+		if err != nil {
+			klog.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		devicePath = luks.MapperPath(getLuksName(req.VolumeId))
+	}
+
 	fsResizer := mount.NewResizeFs(exec.New())
 	ok, err := fsResizer.Resize(devicePath, volMountPath)
 	if err != nil {
@@ -675,7 +815,24 @@ func getDevicePath(tgt *v1.SpdkTarget) (devicePath string, err error) {
 	return
 }
 
-func connectSpdkTarget(tgt *v1.SpdkTarget) (devicePath string, err error) {
+// getHostNQN returns the hostnqn in the annotations of StoragePool. If StoragePool is not found, it returns empty string.
+func (ns *NodeServer) getHostNQN(nodeID string) (hostNQN string, err error) {
+	var sp *client.StoragePool
+	sp, err = ns.cli.GetStoragePoolByName(v1.DefaultNamespace, nodeID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.Infof("not found StoragePool %s, use default hostnqn", nodeID)
+			return "", nil
+		}
+		return
+	}
+	hostNQN = sp.Annotations[v1.AnnotationHostNQN]
+	return
+}
+
+// connectSpdkTarget connects the target and returns the device path. HostNQN and DH-HMAC-CHAP secrets are set in opts.
+// If the target has multiple paths, all of them are connected. It succeeds if the device is found, even if some paths fail to connect.
+func connectSpdkTarget(tgt *v1.SpdkTarget, opts nvme.ConnectTargetOpts) (devicePath string, err error) {
 	// remote disk
 	nvmeCli := nvme.NewClientWithCmdPath(nvmeClientFilePath)
 	// check if already connected
@@ -700,38 +857,68 @@ func connectSpdkTarget(tgt *v1.SpdkTarget) (devicePath string, err error) {
 		}
 	}
 
-	// if devicePath is not found, do connect
-	if devicePath == "" {
-		var transType string
-		var opts = nvme.ConnectTargetOpts{
-			ReconnectDelaySec: 2,
-			CtrlLossTMO:       10,
+	// if devicePath is not found or the target has multiple paths, connect the paths which are not connected
+	if devicePath == "" || len(tgt.Paths) > 0 {
+		var (
+			connErr   error
+			connected int
+			states    []v1.PathState
+		)
+		opts.ReconnectDelaySec = 2
+		opts.CtrlLossTMO = 10
+
+		if devicePath != "" {
+			states, err = getHostPathStates(nvmeCli, tgt)
+			if err != nil {
+				klog.Error(err)
+				return "", err
+			}
 		}
 
-		switch tgt.TransType {
-		case spdkclient.TransportTypeVFIOUSER:
-			transType = "vfio-user"
-			opts.HostTransAddr = tgt.AddrFam
-		case spdkclient.TransportTypeTCP:
-			transType = "tcp"
-		}
-		out, err := nvmeCli.ConnectTarget(transType, tgt.Address, tgt.SvcID, tgt.SubsysNQN, opts)
-		if err != nil {
-			klog.Errorf("ConnectTarget returns %s, err %+v", string(out), err)
-			return "", err
+		for idx, path := range getTargetPaths(tgt) {
+			if idx < len(states) && states[idx].State != v1.PathStateDisconnected {
+				continue
+			}
+
+			var transType string
+			var pathOpts = opts
+			switch path.TransType {
+			case spdkclient.TransportTypeVFIOUSER:
+				transType = "vfio-user"
+				pathOpts.HostTransAddr = path.AddrFam
+			case spdkclient.TransportTypeTCP:
+				transType = "tcp"
+			case spdkclient.TransportTypeRDMA:
+				transType = "rdma"
+			}
+			out, errConn := nvmeCli.ConnectTarget(transType, path.Address, path.SvcID, tgt.SubsysNQN, pathOpts)
+			if errConn != nil {
+				klog.Errorf("ConnectTarget %s:%s returns %s, err %+v", path.Address, path.SvcID, string(out), errConn)
+				connErr = errConn
+				continue
+			}
+			connected++
 		}
+
 		// TODO: defer (if need disconnect { do disconnect })
 		// connect is async operation, so wait for some time before listing devices
-		time.Sleep(8 * time.Second)
-		devices, err := nvmeCli.ListNvmeDisk()
-		if err != nil {
-			klog.Error(err)
-			return "", err
+		if connected > 0 {
+			time.Sleep(8 * time.Second)
 		}
-		// find by SerialNumber
-		for _, item := range devices {
-			if item.SerialNumber == tgt.SerialNum {
-				devicePath = item.DevicePath
+		if devicePath == "" {
+			if connected == 0 {
+				return "", connErr
+			}
+			devices, err := nvmeCli.ListNvmeDisk()
+			if err != nil {
+				klog.Error(err)
+				return "", err
+			}
+			// find by SerialNumber
+			for _, item := range devices {
+				if item.SerialNumber == tgt.SerialNum {
+					devicePath = item.DevicePath
+				}
 			}
 		}
 	}
@@ -739,6 +926,85 @@ func connectSpdkTarget(tgt *v1.SpdkTarget) (devicePath string, err error) {
 	return
 }
 
+// getTargetPaths returns all paths of the target. If Paths is empty, it returns the only path of Address and SvcID.
+func getTargetPaths(tgt *v1.SpdkTarget) (paths []v1.TargetPath) {
+	if len(tgt.Paths) > 0 {
+		return tgt.Paths
+	}
+	return []v1.TargetPath{
+		{
+			Address:   tgt.Address,
+			SvcID:     tgt.SvcID,
+			TransType: tgt.TransType,
+			AddrFam:   tgt.AddrFam,
+		},
+	}
+}
+
+// getHostPathStates returns states of all paths of the target on this host, in the order of getTargetPaths.
+func getHostPathStates(nvmeCli *nvme.CmdClient, tgt *v1.SpdkTarget) (states []v1.PathState, err error) {
+	var (
+		subsysList nvme.SubsystemList
+		pathStates = make(map[string]string)
+	)
+	subsysList, err = nvmeCli.ListSubsystems()
+	if err != nil {
+		return
+	}
+	for _, subsys := range subsysList.Subsystems {
+		if subsys.NQN != tgt.SubsysNQN {
+			continue
+		}
+		for _, path := range subsys.Paths {
+			trAddr, svcID := nvme.ParseNvmePathAddress(path.Address)
+			pathStates[trAddr+":"+svcID] = path.State
+		}
+	}
+
+	for _, path := range getTargetPaths(tgt) {
+		state, has := pathStates[path.Address+":"+path.SvcID]
+		if !has {
+			state = v1.PathStateDisconnected
+		}
+		states = append(states, v1.PathState{
+			Address: path.Address,
+			SvcID:   path.SvcID,
+			State:   state,
+		})
+	}
+	return
+}
+
+// reportHostPathStatus records states of the paths on this host in the volume status.
+// Errors are only logged, because they should not block staging the volume.
+func (ns *NodeServer) reportHostPathStatus(volumeID, nodeID string, tgt *v1.SpdkTarget) {
+	var (
+		nvmeCli = nvme.NewClientWithCmdPath(nvmeClientFilePath)
+		st      = v1.HostPathStatus{
+			LastUpdateTime: metav1.Now(),
+		}
+		err error
+	)
+	st.Paths, err = getHostPathStates(nvmeCli, tgt)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	for _, path := range st.Paths {
+		if path.State != v1.PathStateLive {
+			st.Degraded = true
+		}
+	}
+	if st.Degraded {
+		klog.Warningf("volume %s has degraded paths on node %s: %+v", volumeID, nodeID, st.Paths)
+	}
+
+	err = ns.cli.SetPvHostPathStatus(volumeID, nodeID, &st)
+	if err != nil {
+		klog.Error(err)
+	}
+}
+
 func getBlockDeviceSize(devicePath string) (int64, error) {
 	output, err := exec.New().Command("blockdev", "--getsize64", devicePath).CombinedOutput()
 	if err != nil {
@@ -763,3 +1029,22 @@ func isForRund(volAnnotations map[string]string) bool {
 
 	return false
 }
+
+// getLuksName returns the name of LUKS device of the volume
+func getLuksName(volumeID string) string {
+	return "liteio-" + volumeID
+}
+
+// getEncryptionKey reads the encryption key from the Secret. The whole key is used as LUKS passphrase.
+func (ns *NodeServer) getEncryptionKey(enc *v1.VolumeEncryption) (key []byte, err error) {
+	secret, err := ns.kubeCli.CoreV1().Secrets(enc.SecretNamespace).Get(context.Background(), enc.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+	if _, _, err = v1.ParseEncryptionKey(secret.Data[v1.EncryptionKeySecretKey]); err != nil {
+		err = fmt.Errorf("invalid Secret %s/%s, %w", enc.SecretNamespace, enc.SecretName, err)
+		return
+	}
+	key = bytes.TrimSpace(secret.Data[v1.EncryptionKeySecretKey])
+	return
+}
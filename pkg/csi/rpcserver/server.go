@@ -74,6 +74,13 @@ func (s *gRPCServer) serve(endpoint string, ids csi.IdentityServer, cs csi.Contr
 	}
 	if cs != nil {
 		csi.RegisterControllerServer(server, cs)
+		if gcs, ok := cs.(csi.GroupControllerServer); ok {
+			csi.RegisterGroupControllerServer(server, gcs)
+		}
+		// SnapshotMetadata is only served if the capability is enabled
+		if sms, ok := cs.(*ControllerServer); ok && sms.driver.ValidatePluginCapabilityService(csi.PluginCapability_Service_SNAPSHOT_METADATA_SERVICE) {
+			csi.RegisterSnapshotMetadataServer(server, sms)
+		}
 	}
 	if ns != nil {
 		csi.RegisterNodeServer(server, ns)
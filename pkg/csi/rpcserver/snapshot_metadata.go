@@ -0,0 +1,159 @@
+package rpcserver
+
+import (
+	"fmt"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-csi/csi-lib-utils/protosanitizer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/csi/client"
+	"lite.io/liteio/pkg/util/objstore"
+)
+
+var _ csi.SnapshotMetadataServer = &ControllerServer{}
+
+const (
+	// default number of BlockMetadata in one response
+	defaultMetadataMaxResults = 256
+)
+
+// GetMetadataAllocated streams the allocated blocks of the snapshot, starting from StartingOffset.
+// Blocks are computed from the image exported to object storage, so the snapshot must be exported.
+func (cs *ControllerServer) GetMetadataAllocated(req *csi.GetMetadataAllocatedRequest, stream csi.SnapshotMetadata_GetMetadataAllocatedServer) (err error) {
+	klog.Infof("GetMetadataAllocated Req=%s", protosanitizer.StripSecrets(req))
+	if req.SnapshotId == "" || req.StartingOffset < 0 || req.MaxResults < 0 {
+		return status.Error(codes.InvalidArgument, "GetMetadataAllocatedRequest is invalid")
+	}
+
+	var ctx = stream.Context()
+	snap, store, err := cs.getExportedSnapshot(req.SnapshotId)
+	if err != nil {
+		return
+	}
+	img, err := objstore.ResolveImage(ctx, store, snap.Status.Export.ManifestKey)
+	if err != nil {
+		klog.Error(err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if req.StartingOffset >= img.SizeBytes {
+		return status.Errorf(codes.OutOfRange, "starting offset %d exceeds volume size %d", req.StartingOffset, img.SizeBytes)
+	}
+
+	return sendBlockMetadata(img.AllocatedExtents(), req.StartingOffset, req.MaxResults, func(blocks []*csi.BlockMetadata) error {
+		return stream.Send(&csi.GetMetadataAllocatedResponse{
+			BlockMetadataType:   csi.BlockMetadataType_VARIABLE_LENGTH,
+			VolumeCapacityBytes: img.SizeBytes,
+			BlockMetadata:       blocks,
+		})
+	})
+}
+
+// GetMetadataDelta streams the blocks changed between two snapshots of the same volume, starting from StartingOffset.
+// Chunks of the two exported images are compared by checksum. Both snapshots must be exported to the same object storage.
+func (cs *ControllerServer) GetMetadataDelta(req *csi.GetMetadataDeltaRequest, stream csi.SnapshotMetadata_GetMetadataDeltaServer) (err error) {
+	klog.Infof("GetMetadataDelta Req=%s", protosanitizer.StripSecrets(req))
+	if req.BaseSnapshotId == "" || req.TargetSnapshotId == "" || req.StartingOffset < 0 || req.MaxResults < 0 {
+		return status.Error(codes.InvalidArgument, "GetMetadataDeltaRequest is invalid")
+	}
+
+	var ctx = stream.Context()
+	base, _, err := cs.getExportedSnapshot(req.BaseSnapshotId)
+	if err != nil {
+		return
+	}
+	target, store, err := cs.getExportedSnapshot(req.TargetSnapshotId)
+	if err != nil {
+		return
+	}
+	if base.Spec.OriginVolName != target.Spec.OriginVolName || base.Spec.OriginVolNamespace != target.Spec.OriginVolNamespace {
+		return status.Error(codes.InvalidArgument, "base snapshot and target snapshot have different source volumes")
+	}
+	if base.Spec.Export.Endpoint != target.Spec.Export.Endpoint || base.Spec.Export.Bucket != target.Spec.Export.Bucket {
+		return status.Error(codes.FailedPrecondition, "base snapshot and target snapshot are exported to different object storage")
+	}
+
+	baseImg, err := objstore.ResolveImage(ctx, store, base.Status.Export.ManifestKey)
+	if err != nil {
+		klog.Error(err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	targetImg, err := objstore.ResolveImage(ctx, store, target.Status.Export.ManifestKey)
+	if err != nil {
+		klog.Error(err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	if req.StartingOffset >= targetImg.SizeBytes {
+		return status.Errorf(codes.OutOfRange, "starting offset %d exceeds volume size %d", req.StartingOffset, targetImg.SizeBytes)
+	}
+
+	delta, err := objstore.DeltaExtents(baseImg, targetImg)
+	if err != nil {
+		klog.Error(err)
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return sendBlockMetadata(delta, req.StartingOffset, req.MaxResults, func(blocks []*csi.BlockMetadata) error {
+		return stream.Send(&csi.GetMetadataDeltaResponse{
+			BlockMetadataType:   csi.BlockMetadataType_VARIABLE_LENGTH,
+			VolumeCapacityBytes: targetImg.SizeBytes,
+			BlockMetadata:       blocks,
+		})
+	})
+}
+
+// getExportedSnapshot returns the snapshot whose export is finished, and the object storage of it
+func (cs *ControllerServer) getExportedSnapshot(snapID string) (snap *client.Snapshot, store objstore.ObjectStoreIface, err error) {
+	snap, err = cs.cli.GetSnapshotByID(snapID)
+	if err != nil {
+		klog.Error(err)
+		if err == client.ErrorNotFoundResource {
+			return nil, nil, status.Errorf(codes.NotFound, "snapshot %s not found", snapID)
+		}
+		return nil, nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if snap.Spec.Export == nil || snap.Status.Export == nil || snap.Status.Export.Phase != v1.TransferFinished {
+		err = fmt.Errorf("snapshot %s/%s has not been exported to object storage yet", snap.Namespace, snap.Name)
+		return nil, nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	store, err = objstore.NewObjectStore(cs.kubeCli, snap.Spec.Export.ObjectStore)
+	if err != nil {
+		return nil, nil, status.Error(codes.Internal, err.Error())
+	}
+	return
+}
+
+// sendBlockMetadata sends extents which end after startingOffset, at most maxResults in one response
+func sendBlockMetadata(extents []objstore.Extent, startingOffset int64, maxResults int32, send func([]*csi.BlockMetadata) error) (err error) {
+	if maxResults == 0 {
+		maxResults = defaultMetadataMaxResults
+	}
+
+	var blocks = make([]*csi.BlockMetadata, 0, maxResults)
+	for _, item := range extents {
+		if item.Offset+item.Length <= startingOffset {
+			continue
+		}
+		blocks = append(blocks, &csi.BlockMetadata{
+			ByteOffset: item.Offset,
+			SizeBytes:  item.Length,
+		})
+		if len(blocks) == int(maxResults) {
+			if err = send(blocks); err != nil {
+				return
+			}
+			blocks = make([]*csi.BlockMetadata, 0, maxResults)
+		}
+	}
+
+	if len(blocks) > 0 {
+		err = send(blocks)
+	}
+	return
+}
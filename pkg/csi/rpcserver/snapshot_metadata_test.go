@@ -0,0 +1,244 @@
+package rpcserver
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+	"lite.io/liteio/pkg/csi/client"
+	"lite.io/liteio/pkg/csi/driver"
+	"lite.io/liteio/pkg/util/objstore"
+)
+
+// memS3 is a S3-compatible server which keeps objects in memory and ignores authorization
+type memS3 struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *memS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		bs, _ := io.ReadAll(r.Body)
+		s.objects[r.URL.Path] = bs
+	case http.MethodGet:
+		bs, has := s.objects[r.URL.Path]
+		if !has {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(bs)
+	}
+}
+
+func TestSnapshotMetadata(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		chunkSize   int64 = 1024
+		srv               = httptest.NewServer(&memS3{objects: make(map[string][]byte)})
+		objectStore       = v1.ObjectStore{
+			Endpoint:        srv.URL,
+			Bucket:          "backup",
+			SecretName:      "s3-key",
+			SecretNamespace: v1.DefaultNamespace,
+		}
+		kubeCli = kubefake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "s3-key"},
+			Data: map[string][]byte{
+				v1.ObjectStoreAccessKeyIDSecretKey:     []byte("ak"),
+				v1.ObjectStoreSecretAccessKeySecretKey: []byte("sk"),
+			},
+		})
+		cli = newFakeAntstorClient()
+	)
+	defer srv.Close()
+
+	store, err := objstore.NewObjectStore(kubeCli, objectStore)
+	assert.NoError(t, err)
+
+	// base has chunk 0 and 2. target changes chunk 2 and adds chunk 3.
+	var (
+		baseData   = make([]byte, 4*chunkSize)
+		targetData = make([]byte, 4*chunkSize)
+	)
+	copy(baseData, bytes.Repeat([]byte("a"), int(chunkSize)))
+	copy(baseData[2*chunkSize:], bytes.Repeat([]byte("b"), int(chunkSize)))
+	copy(targetData, baseData)
+	copy(targetData[2*chunkSize:], bytes.Repeat([]byte("c"), int(chunkSize)))
+	copy(targetData[3*chunkSize:], bytes.Repeat([]byte("d"), 10))
+
+	addSnapshot := func(name string, data []byte, exported bool) {
+		snap := &client.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: name},
+			Spec: v1.AntstorSnapshotSpec{
+				OriginVolName:      "vol-1",
+				OriginVolNamespace: v1.DefaultNamespace,
+				Export:             &v1.SnapshotExportSpec{ObjectStore: objectStore},
+			},
+		}
+		if exported {
+			_, err := objstore.ExportImage(ctx, store, bytes.NewReader(data), objstore.ExportImageRequest{
+				Source:    name,
+				Prefix:    name,
+				SizeBytes: int64(len(data)),
+				ChunkSize: chunkSize,
+			})
+			assert.NoError(t, err)
+			snap.Status.Export = &v1.SnapshotExportStatus{
+				Phase:       v1.TransferFinished,
+				ManifestKey: objstore.ManifestKey(name),
+			}
+		}
+		cli.snapshots[name] = snap
+	}
+	addSnapshot("base", baseData, true)
+	addSnapshot("target", targetData, true)
+	addSnapshot("unexported", targetData, false)
+
+	// serve ControllerServer by gRPC, and SnapshotMetadata service must be registered if the capability is enabled
+	var (
+		sock = filepath.Join(t.TempDir(), "csi.sock")
+		s    = NewGRPCServer()
+		cs   = NewControllerServer(driver.NewCSIDriver(driver.NewCSIDriverOption{
+			Name:      "test",
+			NodeID:    "node-1",
+			PluginCap: []*csi.PluginCapability{driver.SnapshotMetadataPluginCapability},
+		}), cli, kubeCli)
+	)
+	s.Start("unix://"+sock, nil, cs, nil)
+	conn, err := grpc.DialContext(ctx, "unix://"+sock, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Stop()
+	defer conn.Close()
+	var smc = csi.NewSnapshotMetadataClient(conn)
+
+	allocated := func(req *csi.GetMetadataAllocatedRequest) (resps []*csi.GetMetadataAllocatedResponse, err error) {
+		stream, err := smc.GetMetadataAllocated(ctx, req)
+		if err != nil {
+			return
+		}
+		for {
+			var resp *csi.GetMetadataAllocatedResponse
+			resp, err = stream.Recv()
+			if err == io.EOF {
+				return resps, nil
+			}
+			if err != nil {
+				return
+			}
+			resps = append(resps, resp)
+		}
+	}
+	delta := func(req *csi.GetMetadataDeltaRequest) (resps []*csi.GetMetadataDeltaResponse, err error) {
+		stream, err := smc.GetMetadataDelta(ctx, req)
+		if err != nil {
+			return
+		}
+		for {
+			var resp *csi.GetMetadataDeltaResponse
+			resp, err = stream.Recv()
+			if err == io.EOF {
+				return resps, nil
+			}
+			if err != nil {
+				return
+			}
+			resps = append(resps, resp)
+		}
+	}
+
+	// allocated blocks of target are chunk 0, 2 and 3, one block in each response
+	resps, err := allocated(&csi.GetMetadataAllocatedRequest{SnapshotId: "target", MaxResults: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, resps, 3) {
+		assert.Equal(t, csi.BlockMetadataType_VARIABLE_LENGTH, resps[0].BlockMetadataType)
+		assert.Equal(t, 4*chunkSize, resps[0].VolumeCapacityBytes)
+		assert.Equal(t, int64(0), resps[0].BlockMetadata[0].ByteOffset)
+		assert.Equal(t, 2*chunkSize, resps[1].BlockMetadata[0].ByteOffset)
+		assert.Equal(t, 3*chunkSize, resps[2].BlockMetadata[0].ByteOffset)
+	}
+
+	// blocks ending before starting offset are skipped
+	resps, err = allocated(&csi.GetMetadataAllocatedRequest{SnapshotId: "target", StartingOffset: chunkSize})
+	assert.NoError(t, err)
+	if assert.Len(t, resps, 1) {
+		assert.Len(t, resps[0].BlockMetadata, 2)
+	}
+
+	// delta is chunk 2 and 3
+	deltaResps, err := delta(&csi.GetMetadataDeltaRequest{BaseSnapshotId: "base", TargetSnapshotId: "target"})
+	assert.NoError(t, err)
+	if assert.Len(t, deltaResps, 1) {
+		assert.Equal(t, []*csi.BlockMetadata{
+			{ByteOffset: 2 * chunkSize, SizeBytes: chunkSize},
+			{ByteOffset: 3 * chunkSize, SizeBytes: chunkSize},
+		}, toBlocks(deltaResps[0].BlockMetadata))
+	}
+
+	// errors
+	_, err = allocated(&csi.GetMetadataAllocatedRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = allocated(&csi.GetMetadataAllocatedRequest{SnapshotId: "not-exist"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = allocated(&csi.GetMetadataAllocatedRequest{SnapshotId: "unexported"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = allocated(&csi.GetMetadataAllocatedRequest{SnapshotId: "target", StartingOffset: 4 * chunkSize})
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+	_, err = delta(&csi.GetMetadataDeltaRequest{BaseSnapshotId: "base", TargetSnapshotId: "unexported"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestSnapshotMetadataDisabled(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		sock = filepath.Join(t.TempDir(), "csi.sock")
+		s    = NewGRPCServer()
+		cs   = NewControllerServer(driver.NewCSIDriver(driver.NewCSIDriverOption{
+			Name:      "test",
+			NodeID:    "node-1",
+			PluginCap: driver.DefaultPluginCapability,
+		}), newFakeAntstorClient(), kubefake.NewSimpleClientset())
+	)
+	s.Start("unix://"+sock, nil, cs, nil)
+	conn, err := grpc.DialContext(ctx, "unix://"+sock, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Stop()
+	defer conn.Close()
+
+	// SnapshotMetadata service is not registered by default
+	stream, err := csi.NewSnapshotMetadataClient(conn).GetMetadataAllocated(ctx, &csi.GetMetadataAllocatedRequest{SnapshotId: "target"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+// toBlocks copies offsets and sizes, so that blocks are compared without internal states of messages
+func toBlocks(list []*csi.BlockMetadata) (blocks []*csi.BlockMetadata) {
+	for _, item := range list {
+		blocks = append(blocks, &csi.BlockMetadata{ByteOffset: item.ByteOffset, SizeBytes: item.SizeBytes})
+	}
+	return
+}
@@ -22,7 +22,7 @@ func StartServer(endpoint string, driver *driver.CSIDriver, mounter *mount.SafeF
 
 	idendity := NewIdentityServer(driver)
 	controller := NewControllerServer(driver, cloudMgr, kubeCli)
-	node := NewNodeServer(driver, mounter, cloudMgr)
+	node := NewNodeServer(driver, mounter, cloudMgr, kubeCli)
 
 	s := NewGRPCServer()
 	s.Start(endpoint, idendity, controller, node)
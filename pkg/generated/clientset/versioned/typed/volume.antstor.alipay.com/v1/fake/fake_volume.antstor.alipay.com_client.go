@@ -43,6 +43,18 @@ func (c *FakeVolumeV1) AntstorVolumeGroups(namespace string) v1.AntstorVolumeGro
 	return &FakeAntstorVolumeGroups{c, namespace}
 }
 
+func (c *FakeVolumeV1) AntstorVolumeGroupSnapshots(namespace string) v1.AntstorVolumeGroupSnapshotInterface {
+	return &FakeAntstorVolumeGroupSnapshots{c, namespace}
+}
+
+func (c *FakeVolumeV1) RebalanceReports(namespace string) v1.RebalanceReportInterface {
+	return &FakeRebalanceReports{c, namespace}
+}
+
+func (c *FakeVolumeV1) SnapshotPolicies(namespace string) v1.SnapshotPolicyInterface {
+	return &FakeSnapshotPolicies{c, namespace}
+}
+
 func (c *FakeVolumeV1) StoragePools(namespace string) v1.StoragePoolInterface {
 	return &FakeStoragePools{c, namespace}
 }
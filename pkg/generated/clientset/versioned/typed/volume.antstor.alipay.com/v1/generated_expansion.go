@@ -25,6 +25,12 @@ type AntstorVolumeExpansion interface{}
 
 type AntstorVolumeGroupExpansion interface{}
 
+type AntstorVolumeGroupSnapshotExpansion interface{}
+
+type RebalanceReportExpansion interface{}
+
+type SnapshotPolicyExpansion interface{}
+
 type StoragePoolExpansion interface{}
 
 type VolumeMigrationExpansion interface{}
@@ -31,6 +31,9 @@ type VolumeV1Interface interface {
 	AntstorSnapshotsGetter
 	AntstorVolumesGetter
 	AntstorVolumeGroupsGetter
+	AntstorVolumeGroupSnapshotsGetter
+	RebalanceReportsGetter
+	SnapshotPoliciesGetter
 	StoragePoolsGetter
 	VolumeMigrationsGetter
 }
@@ -56,6 +59,18 @@ func (c *VolumeV1Client) AntstorVolumeGroups(namespace string) AntstorVolumeGrou
 	return newAntstorVolumeGroups(c, namespace)
 }
 
+func (c *VolumeV1Client) AntstorVolumeGroupSnapshots(namespace string) AntstorVolumeGroupSnapshotInterface {
+	return newAntstorVolumeGroupSnapshots(c, namespace)
+}
+
+func (c *VolumeV1Client) RebalanceReports(namespace string) RebalanceReportInterface {
+	return newRebalanceReports(c, namespace)
+}
+
+func (c *VolumeV1Client) SnapshotPolicies(namespace string) SnapshotPolicyInterface {
+	return newSnapshotPolicies(c, namespace)
+}
+
 func (c *VolumeV1Client) StoragePools(namespace string) StoragePoolInterface {
 	return newStoragePools(c, namespace)
 }
@@ -60,6 +60,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Volume().V1().AntstorVolumes().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("antstorvolumegroups"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Volume().V1().AntstorVolumeGroups().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("antstorvolumegroupsnapshots"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Volume().V1().AntstorVolumeGroupSnapshots().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("rebalancereports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Volume().V1().RebalanceReports().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("snapshotpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Volume().V1().SnapshotPolicies().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("storagepools"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Volume().V1().StoragePools().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("volumemigrations"):
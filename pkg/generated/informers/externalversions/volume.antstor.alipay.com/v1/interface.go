@@ -31,6 +31,12 @@ type Interface interface {
 	AntstorVolumes() AntstorVolumeInformer
 	// AntstorVolumeGroups returns a AntstorVolumeGroupInformer.
 	AntstorVolumeGroups() AntstorVolumeGroupInformer
+	// AntstorVolumeGroupSnapshots returns a AntstorVolumeGroupSnapshotInformer.
+	AntstorVolumeGroupSnapshots() AntstorVolumeGroupSnapshotInformer
+	// RebalanceReports returns a RebalanceReportInformer.
+	RebalanceReports() RebalanceReportInformer
+	// SnapshotPolicies returns a SnapshotPolicyInformer.
+	SnapshotPolicies() SnapshotPolicyInformer
 	// StoragePools returns a StoragePoolInformer.
 	StoragePools() StoragePoolInformer
 	// VolumeMigrations returns a VolumeMigrationInformer.
@@ -68,6 +74,21 @@ func (v *version) AntstorVolumeGroups() AntstorVolumeGroupInformer {
 	return &antstorVolumeGroupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// AntstorVolumeGroupSnapshots returns a AntstorVolumeGroupSnapshotInformer.
+func (v *version) AntstorVolumeGroupSnapshots() AntstorVolumeGroupSnapshotInformer {
+	return &antstorVolumeGroupSnapshotInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// RebalanceReports returns a RebalanceReportInformer.
+func (v *version) RebalanceReports() RebalanceReportInformer {
+	return &rebalanceReportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// SnapshotPolicies returns a SnapshotPolicyInformer.
+func (v *version) SnapshotPolicies() SnapshotPolicyInformer {
+	return &snapshotPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // StoragePools returns a StoragePoolInformer.
 func (v *version) StoragePools() StoragePoolInformer {
 	return &storagePoolInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
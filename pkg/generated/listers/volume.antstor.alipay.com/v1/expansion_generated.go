@@ -49,6 +49,30 @@ type AntstorVolumeGroupListerExpansion interface{}
 // AntstorVolumeGroupNamespaceLister.
 type AntstorVolumeGroupNamespaceListerExpansion interface{}
 
+// AntstorVolumeGroupSnapshotListerExpansion allows custom methods to be added to
+// AntstorVolumeGroupSnapshotLister.
+type AntstorVolumeGroupSnapshotListerExpansion interface{}
+
+// AntstorVolumeGroupSnapshotNamespaceListerExpansion allows custom methods to be added to
+// AntstorVolumeGroupSnapshotNamespaceLister.
+type AntstorVolumeGroupSnapshotNamespaceListerExpansion interface{}
+
+// RebalanceReportListerExpansion allows custom methods to be added to
+// RebalanceReportLister.
+type RebalanceReportListerExpansion interface{}
+
+// RebalanceReportNamespaceListerExpansion allows custom methods to be added to
+// RebalanceReportNamespaceLister.
+type RebalanceReportNamespaceListerExpansion interface{}
+
+// SnapshotPolicyListerExpansion allows custom methods to be added to
+// SnapshotPolicyLister.
+type SnapshotPolicyListerExpansion interface{}
+
+// SnapshotPolicyNamespaceListerExpansion allows custom methods to be added to
+// SnapshotPolicyNamespaceLister.
+type SnapshotPolicyNamespaceListerExpansion interface{}
+
 // StoragePoolListerExpansion allows custom methods to be added to
 // StoragePoolLister.
 type StoragePoolListerExpansion interface{}
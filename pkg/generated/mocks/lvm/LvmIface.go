@@ -12,6 +12,20 @@ type LvmIface struct {
 	mock.Mock
 }
 
+// ActivateLV provides a mock function with given fields: vgName, lvName
+func (_m *LvmIface) ActivateLV(vgName string, lvName string) error {
+	ret := _m.Called(vgName, lvName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(vgName, lvName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateLinearLV provides a mock function with given fields: vgName, lvName, opt
 func (_m *LvmIface) CreateLinearLV(vgName string, lvName string, opt lvm.LvOption) (lvm.LV, error) {
 	ret := _m.Called(vgName, lvName, opt)
@@ -50,6 +64,27 @@ func (_m *LvmIface) CreatePV(pvs []string) error {
 	return r0
 }
 
+// CreateRaidLV provides a mock function with given fields: vgName, lvName, opt
+func (_m *LvmIface) CreateRaidLV(vgName string, lvName string, opt lvm.RaidLvOption) (lvm.LV, error) {
+	ret := _m.Called(vgName, lvName, opt)
+
+	var r0 lvm.LV
+	if rf, ok := ret.Get(0).(func(string, string, lvm.RaidLvOption) lvm.LV); ok {
+		r0 = rf(vgName, lvName, opt)
+	} else {
+		r0 = ret.Get(0).(lvm.LV)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, lvm.RaidLvOption) error); ok {
+		r1 = rf(vgName, lvName, opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateSnapshotLinear provides a mock function with given fields: vgName, snapName, originVol, sizeByte
 func (_m *LvmIface) CreateSnapshotLinear(vgName string, snapName string, originVol string, sizeByte uint64) error {
 	ret := _m.Called(vgName, snapName, originVol, sizeByte)
@@ -102,6 +137,54 @@ func (_m *LvmIface) CreateStripeLV(vgName string, lvName string, sizeByte uint64
 	return r0, r1
 }
 
+// CreateThinLV provides a mock function with given fields: vgName, poolName, lvName, sizeByte
+func (_m *LvmIface) CreateThinLV(vgName string, poolName string, lvName string, sizeByte uint64) (lvm.LV, error) {
+	ret := _m.Called(vgName, poolName, lvName, sizeByte)
+
+	var r0 lvm.LV
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, uint64) (lvm.LV, error)); ok {
+		return rf(vgName, poolName, lvName, sizeByte)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, uint64) lvm.LV); ok {
+		r0 = rf(vgName, poolName, lvName, sizeByte)
+	} else {
+		r0 = ret.Get(0).(lvm.LV)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, uint64) error); ok {
+		r1 = rf(vgName, poolName, lvName, sizeByte)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateThinPool provides a mock function with given fields: vgName, poolName, sizeByte
+func (_m *LvmIface) CreateThinPool(vgName string, poolName string, sizeByte uint64) (lvm.LV, error) {
+	ret := _m.Called(vgName, poolName, sizeByte)
+
+	var r0 lvm.LV
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, uint64) (lvm.LV, error)); ok {
+		return rf(vgName, poolName, sizeByte)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, uint64) lvm.LV); ok {
+		r0 = rf(vgName, poolName, sizeByte)
+	} else {
+		r0 = ret.Get(0).(lvm.LV)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, uint64) error); ok {
+		r1 = rf(vgName, poolName, sizeByte)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateVG provides a mock function with given fields: name, pvs
 func (_m *LvmIface) CreateVG(name string, pvs []string) (lvm.VG, error) {
 	ret := _m.Called(name, pvs)
@@ -140,6 +223,20 @@ func (_m *LvmIface) ExpandVolume(deltaBytes int64, targetVol string) error {
 	return r0
 }
 
+// ExtendVG provides a mock function with given fields: vgName, pvs
+func (_m *LvmIface) ExtendVG(vgName string, pvs []string) error {
+	ret := _m.Called(vgName, pvs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(vgName, pvs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ListLVInVG provides a mock function with given fields: vgName
 func (_m *LvmIface) ListLVInVG(vgName string) ([]lvm.LV, error) {
 	ret := _m.Called(vgName)
@@ -232,6 +329,20 @@ func (_m *LvmIface) MergeSnapshot(vgName string, snapName string) error {
 	return r0
 }
 
+// ReduceVGMissing provides a mock function with given fields: vgName
+func (_m *LvmIface) ReduceVGMissing(vgName string) error {
+	ret := _m.Called(vgName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(vgName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RemoveLV provides a mock function with given fields: vgName, lvName
 func (_m *LvmIface) RemoveLV(vgName string, lvName string) error {
 	ret := _m.Called(vgName, lvName)
@@ -274,6 +385,48 @@ func (_m *LvmIface) RemoveVG(vgName string) error {
 	return r0
 }
 
+// RepairLV provides a mock function with given fields: vgName, lvName, pvs
+func (_m *LvmIface) RepairLV(vgName string, lvName string, pvs []string) error {
+	ret := _m.Called(vgName, lvName, pvs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, []string) error); ok {
+		r0 = rf(vgName, lvName, pvs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResumeLV provides a mock function with given fields: vgName, lvName
+func (_m *LvmIface) ResumeLV(vgName string, lvName string) error {
+	ret := _m.Called(vgName, lvName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(vgName, lvName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SuspendLV provides a mock function with given fields: vgName, lvName
+func (_m *LvmIface) SuspendLV(vgName string, lvName string) error {
+	ret := _m.Called(vgName, lvName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(vgName, lvName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewLvmIface interface {
 	mock.TestingT
 	Cleanup(func())
@@ -12,6 +12,104 @@ type SPDKClientIface struct {
 	mock.Mock
 }
 
+// AccelCryptoKeyCreate provides a mock function with given fields: req
+func (_m *SPDKClientIface) AccelCryptoKeyCreate(req client.AccelCryptoKeyCreateReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.AccelCryptoKeyCreateReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.AccelCryptoKeyCreateReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.AccelCryptoKeyCreateReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccelCryptoKeyDestroy provides a mock function with given fields: req
+func (_m *SPDKClientIface) AccelCryptoKeyDestroy(req client.AccelCryptoKeyDestroyReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.AccelCryptoKeyDestroyReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.AccelCryptoKeyDestroyReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.AccelCryptoKeyDestroyReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccelCryptoKeysGet provides a mock function with given fields: req
+func (_m *SPDKClientIface) AccelCryptoKeysGet(req client.AccelCryptoKeysGetReq) ([]client.AccelCryptoKey, error) {
+	ret := _m.Called(req)
+
+	var r0 []client.AccelCryptoKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.AccelCryptoKeysGetReq) ([]client.AccelCryptoKey, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.AccelCryptoKeysGetReq) []client.AccelCryptoKey); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]client.AccelCryptoKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(client.AccelCryptoKeysGetReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddBaseBdevRaid provides a mock function with given fields: req
+func (_m *SPDKClientIface) AddBaseBdevRaid(req client.AddBaseBdevRaidRequest) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.AddBaseBdevRaidRequest) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.AddBaseBdevRaidRequest) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.AddBaseBdevRaidRequest) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AttachController provides a mock function with given fields: req
 func (_m *SPDKClientIface) AttachController(req client.AttachControllerRequest) ([]string, error) {
 	ret := _m.Called(req)
@@ -110,6 +208,54 @@ func (_m *SPDKClientIface) BdevAioResize(req client.BdevAioResizeReq) (bool, err
 	return r0, r1
 }
 
+// BdevCryptoCreate provides a mock function with given fields: req
+func (_m *SPDKClientIface) BdevCryptoCreate(req client.BdevCryptoCreateReq) (string, error) {
+	ret := _m.Called(req)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.BdevCryptoCreateReq) (string, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.BdevCryptoCreateReq) string); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.BdevCryptoCreateReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BdevCryptoDelete provides a mock function with given fields: req
+func (_m *SPDKClientIface) BdevCryptoDelete(req client.BdevCryptoDeleteReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.BdevCryptoDeleteReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.BdevCryptoDeleteReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.BdevCryptoDeleteReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BdevGetBdevs provides a mock function with given fields: req
 func (_m *SPDKClientIface) BdevGetBdevs(req client.BdevGetBdevsReq) ([]client.Bdev, error) {
 	ret := _m.Called(req)
@@ -160,6 +306,30 @@ func (_m *SPDKClientIface) BdevGetIostat(req client.BdevGetIostatReq) (client.Bd
 	return r0, r1
 }
 
+// BdevSetQdSamplingPeriod provides a mock function with given fields: req
+func (_m *SPDKClientIface) BdevSetQdSamplingPeriod(req client.BdevSetQdSamplingPeriodReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.BdevSetQdSamplingPeriodReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.BdevSetQdSamplingPeriodReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.BdevSetQdSamplingPeriodReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BdevLVolClone provides a mock function with given fields: req
 func (_m *SPDKClientIface) BdevLVolClone(req client.BdevLVolCloneReq) (string, error) {
 	ret := _m.Called(req)
@@ -256,6 +426,30 @@ func (_m *SPDKClientIface) BdevLVolDelete(req client.BdevLVolDeleteReq) (bool, e
 	return r0, r1
 }
 
+// BdevLVolGetFragmap provides a mock function with given fields: req
+func (_m *SPDKClientIface) BdevLVolGetFragmap(req client.BdevLVolGetFragmapReq) (client.LvolFragmap, error) {
+	ret := _m.Called(req)
+
+	var r0 client.LvolFragmap
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.BdevLVolGetFragmapReq) (client.LvolFragmap, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.BdevLVolGetFragmapReq) client.LvolFragmap); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(client.LvolFragmap)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.BdevLVolGetFragmapReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BdevLVolGetLVStores provides a mock function with given fields: req
 func (_m *SPDKClientIface) BdevLVolGetLVStores(req client.BdevLVolGetLVStoresReq) ([]client.LVStoreInfo, error) {
 	ret := _m.Called(req)
@@ -306,6 +500,30 @@ func (_m *SPDKClientIface) BdevLVolInflate(req client.BdevLVolInflateReq) (bool,
 	return r0, r1
 }
 
+// BdevLVolRename provides a mock function with given fields: req
+func (_m *SPDKClientIface) BdevLVolRename(req client.BdevLVolRenameReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.BdevLVolRenameReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.BdevLVolRenameReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.BdevLVolRenameReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BdevLVolResize provides a mock function with given fields: req
 func (_m *SPDKClientIface) BdevLVolResize(req client.BdevLVolResizeReq) (bool, error) {
 	ret := _m.Called(req)
@@ -422,6 +640,30 @@ func (_m *SPDKClientIface) BdevMigrateStart(req client.BdevMigrateStartRequest)
 	return r0
 }
 
+// BdevSetQosLimit provides a mock function with given fields: req
+func (_m *SPDKClientIface) BdevSetQosLimit(req client.BdevSetQosLimitReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.BdevSetQosLimitReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.BdevSetQosLimitReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.BdevSetQosLimitReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateBdevMalloc provides a mock function with given fields: req
 func (_m *SPDKClientIface) CreateBdevMalloc(req client.CreateBdevMallocReq) (string, error) {
 	ret := _m.Called(req)
@@ -494,6 +736,30 @@ func (_m *SPDKClientIface) DeleteBdevMalloc(req client.DeleteBdevMallocReq) (boo
 	return r0, r1
 }
 
+// DeleteBdevRaid provides a mock function with given fields: req
+func (_m *SPDKClientIface) DeleteBdevRaid(req client.DeleteBdevRaidRequest) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.DeleteBdevRaidRequest) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.DeleteBdevRaidRequest) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.DeleteBdevRaidRequest) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DetachController provides a mock function with given fields: req
 func (_m *SPDKClientIface) DetachController(req client.DetachControllerRequest) error {
 	ret := _m.Called(req)
@@ -560,6 +826,32 @@ func (_m *SPDKClientIface) FrameworkGetSubsystems() ([]client.FrameworkGetSubsys
 	return r0, r1
 }
 
+// GetBdevRaids provides a mock function with given fields: req
+func (_m *SPDKClientIface) GetBdevRaids(req client.ListBdevRaidRequest) ([]client.RaidBdevInfo, error) {
+	ret := _m.Called(req)
+
+	var r0 []client.RaidBdevInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.ListBdevRaidRequest) ([]client.RaidBdevInfo, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.ListBdevRaidRequest) []client.RaidBdevInfo); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]client.RaidBdevInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(client.ListBdevRaidRequest) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRawClient provides a mock function with given fields:
 func (_m *SPDKClientIface) GetRawClient() client.JsonRpcClientIface {
 	ret := _m.Called()
@@ -600,6 +892,80 @@ func (_m *SPDKClientIface) GetSpdkVersion() (client.SpdkVersion, error) {
 	return r0, r1
 }
 
+// KeyringFileAddKey provides a mock function with given fields: req
+func (_m *SPDKClientIface) KeyringFileAddKey(req client.KeyringFileAddKeyReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.KeyringFileAddKeyReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.KeyringFileAddKeyReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.KeyringFileAddKeyReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// KeyringFileRemoveKey provides a mock function with given fields: req
+func (_m *SPDKClientIface) KeyringFileRemoveKey(req client.KeyringFileRemoveKeyReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.KeyringFileRemoveKeyReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.KeyringFileRemoveKeyReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.KeyringFileRemoveKeyReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// KeyringGetKeys provides a mock function with given fields:
+func (_m *SPDKClientIface) KeyringGetKeys() ([]client.KeyringKey, error) {
+	ret := _m.Called()
+
+	var r0 []client.KeyringKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]client.KeyringKey, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []client.KeyringKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]client.KeyringKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListBdevRaid provides a mock function with given fields: req
 func (_m *SPDKClientIface) ListBdevRaid(req client.ListBdevRaidRequest) ([]string, error) {
 	ret := _m.Called(req)
@@ -872,6 +1238,176 @@ func (_m *SPDKClientIface) NVMFSubsystemAddNS(req client.NVMFSubsystemAddNSReq)
 	return r0, r1
 }
 
+// NVMFSubsystemAllowAnyHost provides a mock function with given fields: req
+func (_m *SPDKClientIface) NVMFSubsystemAllowAnyHost(req client.NVMFSubsystemAllowAnyHostReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.NVMFSubsystemAllowAnyHostReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.NVMFSubsystemAllowAnyHostReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.NVMFSubsystemAllowAnyHostReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NVMFSubsystemListenerSetANAState provides a mock function with given fields: req
+func (_m *SPDKClientIface) NVMFSubsystemListenerSetANAState(req client.NVMFSubsystemListenerSetANAStateReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.NVMFSubsystemListenerSetANAStateReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.NVMFSubsystemListenerSetANAStateReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.NVMFSubsystemListenerSetANAStateReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NVMFSubsystemRemoveHost provides a mock function with given fields: req
+func (_m *SPDKClientIface) NVMFSubsystemRemoveHost(req client.NVMFSubsystemRemoveHostReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.NVMFSubsystemRemoveHostReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.NVMFSubsystemRemoveHostReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.NVMFSubsystemRemoveHostReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NbdGetDisks provides a mock function with given fields: req
+func (_m *SPDKClientIface) NbdGetDisks(req client.NbdGetDisksReq) ([]client.NbdDisk, error) {
+	ret := _m.Called(req)
+
+	var r0 []client.NbdDisk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.NbdGetDisksReq) ([]client.NbdDisk, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.NbdGetDisksReq) []client.NbdDisk); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]client.NbdDisk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(client.NbdGetDisksReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NbdStartDisk provides a mock function with given fields: req
+func (_m *SPDKClientIface) NbdStartDisk(req client.NbdStartDiskReq) (string, error) {
+	ret := _m.Called(req)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.NbdStartDiskReq) (string, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.NbdStartDiskReq) string); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.NbdStartDiskReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NbdStopDisk provides a mock function with given fields: req
+func (_m *SPDKClientIface) NbdStopDisk(req client.NbdStopDiskReq) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.NbdStopDiskReq) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.NbdStopDiskReq) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.NbdStopDiskReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveBaseBdevRaid provides a mock function with given fields: req
+func (_m *SPDKClientIface) RemoveBaseBdevRaid(req client.RemoveBaseBdevRaidRequest) (bool, error) {
+	ret := _m.Called(req)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(client.RemoveBaseBdevRaidRequest) (bool, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(client.RemoveBaseBdevRaidRequest) bool); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(client.RemoveBaseBdevRaidRequest) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RpcGetMethods provides a mock function with given fields:
 func (_m *SPDKClientIface) RpcGetMethods() ([]string, error) {
 	ret := _m.Called()
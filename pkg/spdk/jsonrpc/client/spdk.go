@@ -26,6 +26,8 @@ type SPDKClientIface interface {
 	BdevGetBdevs(req BdevGetBdevsReq) (result []Bdev, err error)
 	// bdev_get_iostat
 	BdevGetIostat(req BdevGetIostatReq) (iostats BdevIostats, err error)
+	// bdev_set_qd_sampling_period
+	BdevSetQdSamplingPeriod(req BdevSetQdSamplingPeriodReq) (result bool, err error)
 
 	// BdevAioCreate bdev_aio_create, return the name of bdev
 	BdevAioCreate(req BdevAioCreateReq) (name string, err error)
@@ -33,6 +35,8 @@ type SPDKClientIface interface {
 	BdevAioDelete(req BdevAioDeleteReq) (result bool, err error)
 	// bdev_aio_resize
 	BdevAioResize(req BdevAioResizeReq) (result bool, err error)
+	// bdev_set_qos_limit
+	BdevSetQosLimit(req BdevSetQosLimitReq) (result bool, err error)
 
 	// framework_get_config
 	FrameworkGetConfig(req FrameworkGetConfigReq) (result []FrameworkGetConfigItem, err error)
@@ -46,6 +50,9 @@ type SPDKClientIface interface {
 	SpdkBdevRaidIface
 	SpdkMigrateIface
 	SpdkMallocIface
+	SpdkKeyringIface
+	SpdkCryptoIface
+	SpdkNbdIface
 }
 
 type SPDK struct {
@@ -93,6 +100,15 @@ func (s *SPDK) BdevGetIostat(req BdevGetIostatReq) (iostats BdevIostats, err err
 	return
 }
 
+func (s *SPDK) BdevSetQdSamplingPeriod(req BdevSetQdSamplingPeriodReq) (res bool, err error) {
+	result, err := s.rawCli.Call("bdev_set_qd_sampling_period", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(result, &res)
+	return
+}
+
 func (s *SPDK) RpcGetMethods() (methods []string, err error) {
 	result, err := s.rawCli.Call("rpc_get_methods", nil)
 	if err != nil {
@@ -140,6 +156,16 @@ func (s *SPDK) BdevAioResize(req BdevAioResizeReq) (res bool, err error) {
 	return
 }
 
+// bdev_set_qos_limit
+func (s *SPDK) BdevSetQosLimit(req BdevSetQosLimitReq) (res bool, err error) {
+	result, err := s.rawCli.Call("bdev_set_qos_limit", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(result, &res)
+	return
+}
+
 // framework_get_config
 func (s *SPDK) FrameworkGetConfig(req FrameworkGetConfigReq) (result []FrameworkGetConfigItem, err error) {
 	bs, err := s.rawCli.Call("framework_get_config", req)
@@ -21,6 +21,10 @@ type SpdkLvolIface interface {
 	BdevLVolClone(req BdevLVolCloneReq) (uuid string, err error)
 	// bdev_lvol_inflate
 	BdevLVolInflate(req BdevLVolInflateReq) (ok bool, err error)
+	// bdev_lvol_rename
+	BdevLVolRename(req BdevLVolRenameReq) (ok bool, err error)
+	// bdev_lvol_get_fragmap
+	BdevLVolGetFragmap(req BdevLVolGetFragmapReq) (fragmap LvolFragmap, err error)
 }
 
 type BdevLVolGetLVStoresReq struct {
@@ -86,6 +90,38 @@ type BdevLVolInflateReq struct {
 	Name string `json:"name"`
 }
 
+type BdevLVolGetFragmapReq struct {
+	// required
+	Name string `json:"name"`
+	// optional, offset and size of the range in bytes. Default is the whole lvol
+	Offset uint64 `json:"offset,omitempty"`
+	Size   uint64 `json:"size,omitempty"`
+}
+
+// LvolFragmap is the bitmap of clusters allocated by the lvol itself, excluding clusters of its parent snapshots
+type LvolFragmap struct {
+	ClusterSize          uint64 `json:"cluster_size"`
+	NumClusters          uint64 `json:"num_clusters"`
+	NumAllocatedClusters uint64 `json:"num_allocated_clusters"`
+	// base64 encoded bitmap, bit i is cluster i
+	Fragmap []byte `json:"fragmap"`
+}
+
+// IsAllocated returns true if the cluster is allocated
+func (f LvolFragmap) IsAllocated(cluster uint64) bool {
+	if cluster/8 >= uint64(len(f.Fragmap)) {
+		return false
+	}
+	return f.Fragmap[cluster/8]&(1<<(cluster%8)) != 0
+}
+
+type BdevLVolRenameReq struct {
+	// full name of lvol, lvs_name/lvol_name
+	OldName string `json:"old_name"`
+	// new lvol name without lvs_name
+	NewName string `json:"new_name"`
+}
+
 func (s *SPDK) BdevLVolCreateLVStore(req BdevLVolCreateLVStoreReq) (uuid string, err error) {
 	result, err := s.rawCli.Call("bdev_lvol_create_lvstore", req)
 	if err != nil {
@@ -158,3 +194,21 @@ func (s *SPDK) BdevLVolInflate(req BdevLVolInflateReq) (ok bool, err error) {
 	err = json.Unmarshal(result, &ok)
 	return
 }
+
+func (s *SPDK) BdevLVolRename(req BdevLVolRenameReq) (ok bool, err error) {
+	result, err := s.rawCli.Call("bdev_lvol_rename", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(result, &ok)
+	return
+}
+
+func (s *SPDK) BdevLVolGetFragmap(req BdevLVolGetFragmapReq) (fragmap LvolFragmap, err error) {
+	result, err := s.rawCli.Call("bdev_lvol_get_fragmap", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(result, &fragmap)
+	return
+}
@@ -11,6 +11,14 @@ type SpdkBdevRaidIface interface {
 	CreateBdevRaid(req CreateBdevRaidRequest) (ok bool, err error)
 	// bdev_raid_get_bdevs
 	ListBdevRaid(req ListBdevRaidRequest) (names []string, err error)
+	// bdev_raid_get_bdevs, for SPDK which returns detailed info of raid bdevs
+	GetBdevRaids(req ListBdevRaidRequest) (list []RaidBdevInfo, err error)
+	// bdev_raid_delete
+	DeleteBdevRaid(req DeleteBdevRaidRequest) (ok bool, err error)
+	// bdev_raid_add_base_bdev
+	AddBaseBdevRaid(req AddBaseBdevRaidRequest) (ok bool, err error)
+	// bdev_raid_remove_base_bdev
+	RemoveBaseBdevRaid(req RemoveBaseBdevRaidRequest) (ok bool, err error)
 }
 
 type CreateBdevRaidRequest struct {
@@ -24,6 +32,57 @@ type ListBdevRaidRequest struct {
 	Category string `json:"category"`
 }
 
+type DeleteBdevRaidRequest struct {
+	Name string `json:"name"`
+}
+
+type AddBaseBdevRaidRequest struct {
+	RaidBdev string `json:"raid_bdev"`
+	BaseBdev string `json:"base_bdev"`
+}
+
+type RemoveBaseBdevRaidRequest struct {
+	// name of base bdev
+	Name string `json:"name"`
+}
+
+type RaidBdevInfo struct {
+	Name        string `json:"name"`
+	UUID        string `json:"uuid"`
+	StripSizeKB int    `json:"strip_size_kb"`
+	// online, configuring or offline
+	State                   string `json:"state"`
+	RaidLevel               string `json:"raid_level"`
+	NumBaseBdevs            int    `json:"num_base_bdevs"`
+	NumBaseBdevsDiscovered  int    `json:"num_base_bdevs_discovered"`
+	NumBaseBdevsOperational int    `json:"num_base_bdevs_operational"`
+	// Process is the running background process, e.g. rebuilding a base bdev
+	Process       *RaidProcess   `json:"process,omitempty"`
+	BaseBdevsList []RaidBaseBdev `json:"base_bdevs_list"`
+}
+
+type RaidBaseBdev struct {
+	// name is empty if the slot of base bdev is removed
+	Name         string `json:"name"`
+	UUID         string `json:"uuid"`
+	IsConfigured bool   `json:"is_configured"`
+	DataOffset   uint64 `json:"data_offset"`
+	DataSize     uint64 `json:"data_size"`
+}
+
+type RaidProcess struct {
+	// rebuild
+	Type string `json:"type"`
+	// name of base bdev which is being rebuilt
+	Target   string              `json:"target"`
+	Progress RaidProcessProgress `json:"progress"`
+}
+
+type RaidProcessProgress struct {
+	Blocks  uint64 `json:"blocks"`
+	Percent int    `json:"percent"`
+}
+
 func (s *SPDK) CreateBdevRaid(req CreateBdevRaidRequest) (ok bool, err error) {
 	bs, err := s.rawCli.Call("bdev_raid_create", req)
 	if err != nil {
@@ -41,3 +100,39 @@ func (s *SPDK) ListBdevRaid(req ListBdevRaidRequest) (names []string, err error)
 	err = json.Unmarshal(bs, &names)
 	return
 }
+
+func (s *SPDK) GetBdevRaids(req ListBdevRaidRequest) (list []RaidBdevInfo, err error) {
+	bs, err := s.rawCli.Call("bdev_raid_get_bdevs", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &list)
+	return
+}
+
+func (s *SPDK) DeleteBdevRaid(req DeleteBdevRaidRequest) (ok bool, err error) {
+	bs, err := s.rawCli.Call("bdev_raid_delete", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &ok)
+	return
+}
+
+func (s *SPDK) AddBaseBdevRaid(req AddBaseBdevRaidRequest) (ok bool, err error) {
+	bs, err := s.rawCli.Call("bdev_raid_add_base_bdev", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &ok)
+	return
+}
+
+func (s *SPDK) RemoveBaseBdevRaid(req RemoveBaseBdevRaidRequest) (ok bool, err error) {
+	bs, err := s.rawCli.Call("bdev_raid_remove_base_bdev", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &ok)
+	return
+}
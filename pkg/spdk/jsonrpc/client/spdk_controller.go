@@ -32,7 +32,7 @@ type AttachControllerRequest struct {
 	TrSvcId string `json:"trsvcid,omitempty"`
 	// NVMe-oF target subnqn
 	SubNQN string `json:"subnqn,omitempty"`
-	// NVMe-oF target hostnqn, NOT USED
+	// NVMe-oF target hostnqn
 	HostNQN string `json:"hostnqn,omitempty"`
 	// NVMe-oF host address: ip address, NOT USED
 	HostAddr string `json:"hostaddr,omitempty"`
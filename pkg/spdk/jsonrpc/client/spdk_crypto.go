@@ -0,0 +1,106 @@
+package client
+
+import "encoding/json"
+
+const (
+	CipherAESXTS = "AES_XTS"
+)
+
+type SpdkCryptoIface interface {
+	// accel_crypto_key_create
+	AccelCryptoKeyCreate(req AccelCryptoKeyCreateReq) (result bool, err error)
+	// accel_crypto_key_destroy
+	AccelCryptoKeyDestroy(req AccelCryptoKeyDestroyReq) (result bool, err error)
+	// accel_crypto_keys_get
+	AccelCryptoKeysGet(req AccelCryptoKeysGetReq) (result []AccelCryptoKey, err error)
+	// bdev_crypto_create, return the name of crypto bdev
+	BdevCryptoCreate(req BdevCryptoCreateReq) (name string, err error)
+	// bdev_crypto_delete
+	BdevCryptoDelete(req BdevCryptoDeleteReq) (result bool, err error)
+}
+
+type AccelCryptoKeyCreateReq struct {
+	// required, AES_XTS
+	Cipher string `json:"cipher"`
+	// required, key in hex
+	Key string `json:"key"`
+	// required for AES_XTS, the second key in hex
+	Key2 string `json:"key2,omitempty"`
+	// required
+	Name string `json:"name"`
+}
+
+type AccelCryptoKeyDestroyReq struct {
+	// required
+	KeyName string `json:"key_name"`
+}
+
+type AccelCryptoKeysGetReq struct {
+	// optional
+	KeyName string `json:"key_name,omitempty"`
+}
+
+// AccelCryptoKey does not contain key values, which are hidden by SPDK
+type AccelCryptoKey struct {
+	Name   string `json:"name"`
+	Cipher string `json:"cipher"`
+}
+
+type BdevCryptoCreateReq struct {
+	// required
+	BaseBdevName string `json:"base_bdev_name"`
+	// required
+	Name string `json:"name"`
+	// required, name of key created by accel_crypto_key_create
+	KeyName string `json:"key_name"`
+}
+
+type BdevCryptoDeleteReq struct {
+	// required
+	Name string `json:"name"`
+}
+
+func (s *SPDK) AccelCryptoKeyCreate(req AccelCryptoKeyCreateReq) (ok bool, err error) {
+	bs, err := s.rawCli.Call("accel_crypto_key_create", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &ok)
+	return
+}
+
+func (s *SPDK) AccelCryptoKeyDestroy(req AccelCryptoKeyDestroyReq) (ok bool, err error) {
+	bs, err := s.rawCli.Call("accel_crypto_key_destroy", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &ok)
+	return
+}
+
+func (s *SPDK) AccelCryptoKeysGet(req AccelCryptoKeysGetReq) (list []AccelCryptoKey, err error) {
+	bs, err := s.rawCli.Call("accel_crypto_keys_get", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &list)
+	return
+}
+
+func (s *SPDK) BdevCryptoCreate(req BdevCryptoCreateReq) (name string, err error) {
+	bs, err := s.rawCli.Call("bdev_crypto_create", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &name)
+	return
+}
+
+func (s *SPDK) BdevCryptoDelete(req BdevCryptoDeleteReq) (ok bool, err error) {
+	bs, err := s.rawCli.Call("bdev_crypto_delete", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &ok)
+	return
+}
@@ -0,0 +1,59 @@
+package client
+
+import "encoding/json"
+
+type SpdkKeyringIface interface {
+	// keyring_file_add_key
+	KeyringFileAddKey(req KeyringFileAddKeyReq) (result bool, err error)
+	// keyring_file_remove_key
+	KeyringFileRemoveKey(req KeyringFileRemoveKeyReq) (result bool, err error)
+	// keyring_get_keys
+	KeyringGetKeys() (result []KeyringKey, err error)
+}
+
+type KeyringFileAddKeyReq struct {
+	// required
+	Name string `json:"name"`
+	// Path of the key file. The file must be only accessible by its owner (mode 0600).
+	Path string `json:"path"`
+}
+
+type KeyringFileRemoveKeyReq struct {
+	// required
+	Name string `json:"name"`
+}
+
+type KeyringKey struct {
+	Name    string `json:"name"`
+	Removed bool   `json:"removed"`
+	Probed  bool   `json:"probed"`
+	RefCnt  int    `json:"refcnt"`
+	Path    string `json:"path"`
+}
+
+func (s *SPDK) KeyringFileAddKey(req KeyringFileAddKeyReq) (ok bool, err error) {
+	bs, err := s.rawCli.Call("keyring_file_add_key", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &ok)
+	return
+}
+
+func (s *SPDK) KeyringFileRemoveKey(req KeyringFileRemoveKeyReq) (ok bool, err error) {
+	bs, err := s.rawCli.Call("keyring_file_remove_key", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &ok)
+	return
+}
+
+func (s *SPDK) KeyringGetKeys() (list []KeyringKey, err error) {
+	bs, err := s.rawCli.Call("keyring_get_keys", nil)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &list)
+	return
+}
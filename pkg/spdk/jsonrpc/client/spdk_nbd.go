@@ -0,0 +1,61 @@
+package client
+
+import "encoding/json"
+
+type SpdkNbdIface interface {
+	// nbd_start_disk, return the path of nbd device
+	NbdStartDisk(req NbdStartDiskReq) (devPath string, err error)
+	// nbd_stop_disk
+	NbdStopDisk(req NbdStopDiskReq) (result bool, err error)
+	// nbd_get_disks
+	NbdGetDisks(req NbdGetDisksReq) (result []NbdDisk, err error)
+}
+
+type NbdStartDiskReq struct {
+	// required
+	BdevName string `json:"bdev_name"`
+	// optional, e.g. /dev/nbd0. SPDK picks a free nbd device if it is empty.
+	NbdDevice string `json:"nbd_device,omitempty"`
+}
+
+type NbdStopDiskReq struct {
+	// required
+	NbdDevice string `json:"nbd_device"`
+}
+
+type NbdGetDisksReq struct {
+	// optional
+	NbdDevice string `json:"nbd_device,omitempty"`
+}
+
+type NbdDisk struct {
+	NbdDevice string `json:"nbd_device"`
+	BdevName  string `json:"bdev_name"`
+}
+
+func (s *SPDK) NbdStartDisk(req NbdStartDiskReq) (devPath string, err error) {
+	bs, err := s.rawCli.Call("nbd_start_disk", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &devPath)
+	return
+}
+
+func (s *SPDK) NbdStopDisk(req NbdStopDiskReq) (ok bool, err error) {
+	bs, err := s.rawCli.Call("nbd_stop_disk", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &ok)
+	return
+}
+
+func (s *SPDK) NbdGetDisks(req NbdGetDisksReq) (list []NbdDisk, err error) {
+	bs, err := s.rawCli.Call("nbd_get_disks", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &list)
+	return
+}
@@ -23,6 +23,12 @@ type SpdkSubsystemIface interface {
 	NVMFGetStats() (result SubsystemStat, err error)
 	// nvmf_subsystem_add_host
 	NVMFSubsystemAddHost(req NVMFSubsystemAddHostReq) (result bool, err error)
+	// nvmf_subsystem_remove_host
+	NVMFSubsystemRemoveHost(req NVMFSubsystemRemoveHostReq) (result bool, err error)
+	// nvmf_subsystem_allow_any_host
+	NVMFSubsystemAllowAnyHost(req NVMFSubsystemAllowAnyHostReq) (result bool, err error)
+	// nvmf_subsystem_listener_set_ana_state
+	NVMFSubsystemListenerSetANAState(req NVMFSubsystemListenerSetANAStateReq) (result bool, err error)
 }
 
 // nvmf_get_subsystems
@@ -103,3 +109,30 @@ func (s *SPDK) NVMFSubsystemAddHost(req NVMFSubsystemAddHostReq) (res bool, err
 	err = json.Unmarshal(bs, &res)
 	return
 }
+
+func (s *SPDK) NVMFSubsystemRemoveHost(req NVMFSubsystemRemoveHostReq) (res bool, err error) {
+	bs, err := s.rawCli.Call("nvmf_subsystem_remove_host", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &res)
+	return
+}
+
+func (s *SPDK) NVMFSubsystemAllowAnyHost(req NVMFSubsystemAllowAnyHostReq) (res bool, err error) {
+	bs, err := s.rawCli.Call("nvmf_subsystem_allow_any_host", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &res)
+	return
+}
+
+func (s *SPDK) NVMFSubsystemListenerSetANAState(req NVMFSubsystemListenerSetANAStateReq) (res bool, err error) {
+	bs, err := s.rawCli.Call("nvmf_subsystem_listener_set_ana_state", req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &res)
+	return
+}
@@ -114,6 +114,22 @@ type NVMFSubsystemAddListenerReq struct {
 	ListenAddress ListenAddress `json:"listen_address,omitempty"`
 }
 
+type NVMFSubsystemListenerSetANAStateReq struct {
+	// required
+	NQN           string        `json:"nqn"`
+	ListenAddress ListenAddress `json:"listen_address"`
+	// optimized, non_optimized or inaccessible
+	ANAState string `json:"ana_state"`
+	// opt
+	TargetName string `json:"tgt_name,omitempty"`
+}
+
+const (
+	ANAStateOptimized    = "optimized"
+	ANAStateNonOptimized = "non_optimized"
+	ANAStateInaccessible = "inaccessible"
+)
+
 type NVMFCreateTransportReq struct {
 	// required
 	TrType string `json:"trtype"`
@@ -159,7 +175,8 @@ type Bdev struct {
 }
 
 type DriverSpecific struct {
-	AIO AIODriver `json:"aio"`
+	AIO  AIODriver  `json:"aio"`
+	Lvol LvolDriver `json:"lvol"`
 }
 
 type AssignedRateLimits struct {
@@ -173,6 +190,20 @@ type AIODriver struct {
 	Filename string `json:"filename"`
 }
 
+type LvolDriver struct {
+	LVStoreUUID          string `json:"lvol_store_uuid"`
+	BaseBdev             string `json:"base_bdev"`
+	ThinProvision        bool   `json:"thin_provision"`
+	NumAllocatedClusters int    `json:"num_allocated_clusters"`
+	// lvol is a snapshot
+	Snapshot bool `json:"snapshot"`
+	// lvol is a clone of BaseSnapshot
+	Clone        bool   `json:"clone"`
+	BaseSnapshot string `json:"base_snapshot,omitempty"`
+	// names of clones, if lvol is a snapshot
+	Clones []string `json:"clones,omitempty"`
+}
+
 type Subsystem struct {
 	NQN             string          `json:""`
 	ListenAddresses []ListenAddress `json:"listen_addresses"`
@@ -250,10 +281,27 @@ type BdevGetBdevsReq struct {
 	BdevName string `json:"name,omitempty"`
 }
 
+// BdevSetQosLimitReq sets all limits of the bdev. 0 means unlimited.
+type BdevSetQosLimitReq struct {
+	// required
+	Name string `json:"name"`
+	// IOPS must be multiple of 1000
+	RWIOPS   uint64 `json:"rw_ios_per_sec"`
+	RWMbytes uint64 `json:"rw_mbytes_per_sec"`
+	RMbytes  uint64 `json:"r_mbytes_per_sec"`
+	WMbytes  uint64 `json:"w_mbytes_per_sec"`
+}
+
 type BdevGetIostatReq struct {
 	BdevName string `json:"name,omitempty"`
 }
 
+type BdevSetQdSamplingPeriodReq struct {
+	Name string `json:"name"`
+	// Period in microseconds, 0 to disable
+	Period uint64 `json:"period"`
+}
+
 type BdevIostat struct {
 	Name              string `json:"name"` // bdev uuid
 	BytesRead         uint64 `json:"bytes_read"`
@@ -266,6 +314,13 @@ type BdevIostat struct {
 	WriteLatencyTicks uint64 `json:"write_latency_ticks"`
 	UnmapLatencyTicks uint64 `json:"unmap_latency_ticks"`
 	TimeInQueue       uint64 `json:"time_in_queue"`
+	// fields below are reported if queue depth sampling is enabled by bdev_set_qd_sampling_period
+	// QueueDepthPollingPeriod is the sampling period of queue depth in microseconds, 0 means disabled
+	QueueDepthPollingPeriod uint64 `json:"queue_depth_polling_period"`
+	QueueDepth              uint64 `json:"queue_depth"`
+	// IoTime is the time in microseconds during which the queue depth of bdev is not zero
+	IoTime         uint64 `json:"io_time"`
+	WeightedIoTime uint64 `json:"weighted_io_time"`
 }
 
 type BdevIostats struct {
@@ -302,6 +357,13 @@ type Ios struct {
 	ReadLatencyTicks  uint64 `json:"read_latency_ticks"`
 	WriteLatencyTicks uint64 `json:"write_latency_ticks"`
 	TimeInQueue       uint64 `json:"time_in_queue"`
+	// fields below are reported if queue depth sampling is enabled by bdev_set_qd_sampling_period
+	// QueueDepthPollingPeriod is the sampling period of queue depth in microseconds, 0 means disabled
+	QueueDepthPollingPeriod uint64 `json:"queue_depth_polling_period"`
+	QueueDepth              uint64 `json:"queue_depth"`
+	// IoTime is the time in microseconds during which the queue depth of bdev is not zero
+	IoTime         uint64 `json:"io_time"`
+	WeightedIoTime uint64 `json:"weighted_io_time"`
 }
 
 type NVMFSubsystemAddHostReq struct {
@@ -310,4 +372,22 @@ type NVMFSubsystemAddHostReq struct {
 	// optional
 	TargetName  string `json:"tgt_name,omitempty"`
 	PSKFilePath string `json:"psk,omitempty"`
+	// name of keyring key for DH-HMAC-CHAP authentication of the host
+	DHChapKey string `json:"dhchap_key,omitempty"`
+	// name of keyring key for bidirectional authentication of the controller
+	DHChapCtrlrKey string `json:"dhchap_ctrlr_key,omitempty"`
+}
+
+type NVMFSubsystemAllowAnyHostReq struct {
+	NQN          string `json:"nqn"`
+	AllowAnyHost bool   `json:"allow_any_host"`
+	// optional
+	TargetName string `json:"tgt_name,omitempty"`
+}
+
+type NVMFSubsystemRemoveHostReq struct {
+	NQN     string `json:"nqn"`
+	HostNQN string `json:"host"`
+	// optional
+	TargetName string `json:"tgt_name,omitempty"`
 }
@@ -84,11 +84,17 @@ type ConnectTargetOpts struct {
 	CtrlLossTMO       int
 	// hostTrAddr: only used in VFIOUSER mode, INTRA_HOST or LOCAL_COPY(set in opts)
 	HostTransAddr string
+	// HostNQN is the nqn of host. If it is empty, nvme uses /etc/nvme/hostnqn
+	HostNQN string
+	// DH-HMAC-CHAP secret of host, in the format of "DHHC-1:xx:<base64>:"
+	DHChapSecret string
+	// DH-HMAC-CHAP secret of controller, for bidirectional authentication
+	DHChapCtrlSecret string
 }
 
 // nvme connect -t tcp -a 100.100.100.1 -s 4450 -n nqn.2021-03.com.alipay.ob:test-aio2
 // opts: --reconnect-delay 2 --ctrl-loss-tmo 10
-// transType: tcp, vfio-user
+// transType: tcp, rdma, vfio-user. rdma requires kernel module nvme-rdma and RDMA capable NICs (or soft-RoCE rxe)
 // transAddr: target ip for remote voluem, socket path for local vfio volume
 func (cli *CmdClient) ConnectTarget(transType, transAddr, svcID, nqn string, opt ConnectTargetOpts) (output []byte, err error) {
 
@@ -104,9 +110,22 @@ func (cli *CmdClient) ConnectTarget(transType, transAddr, svcID, nqn string, opt
 	if len(opt.HostTransAddr) > 0 {
 		args = append(args, "-w", opt.HostTransAddr)
 	}
+	if len(opt.HostNQN) > 0 {
+		args = append(args, "--hostnqn", opt.HostNQN)
+	}
+	// do not print secrets
+	var printArgs = append([]string{}, args...)
+	if len(opt.DHChapSecret) > 0 {
+		args = append(args, "--dhchap-secret", opt.DHChapSecret)
+		printArgs = append(printArgs, "--dhchap-secret", "******")
+	}
+	if len(opt.DHChapCtrlSecret) > 0 {
+		args = append(args, "--dhchap-ctrl-secret", opt.DHChapCtrlSecret)
+		printArgs = append(printArgs, "--dhchap-ctrl-secret", "******")
+	}
 
 	output, err = exec.Command(cli.NvmeCmdPath, args...).CombinedOutput()
-	fmt.Println("connect command: ", args)
+	fmt.Println("connect command: ", printArgs)
 	return
 }
 
@@ -123,3 +123,24 @@ func TestGetNvmeTcpVersion(t *testing.T) {
 		assert.Equal(t, test.expect, ver)
 	}
 }
+
+func TestConnectTargetArgs(t *testing.T) {
+	// echo prints the arguments of nvme connect
+	cli := &CmdClient{NvmeCmdPath: "echo"}
+	out, err := cli.ConnectTarget("tcp", "100.100.100.1", "4420", "nqn-1", ConnectTargetOpts{
+		ReconnectDelaySec: 2,
+		HostNQN:           "host-nqn-1",
+		DHChapSecret:      "DHHC-1:00:aG9zdA==:",
+		DHChapCtrlSecret:  "DHHC-1:00:Y3RybA==:",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "connect -t tcp -a 100.100.100.1 -s 4420 -n nqn-1 --reconnect-delay 2 --hostnqn host-nqn-1 "+
+		"--dhchap-secret DHHC-1:00:aG9zdA==: --dhchap-ctrl-secret DHHC-1:00:Y3RybA==:\n", string(out))
+}
+
+func TestConnectTargetRDMA(t *testing.T) {
+	cli := &CmdClient{NvmeCmdPath: "echo"}
+	out, err := cli.ConnectTarget("rdma", "100.100.100.1", "4420", "nqn-1", ConnectTargetOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, "connect -t rdma -a 100.100.100.1 -s 4420 -n nqn-1\n", string(out))
+}
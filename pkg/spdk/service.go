@@ -31,6 +31,7 @@ var (
 
 type BdevGetBdevsReq = client.BdevGetBdevsReq
 type BdevGetIostatReq = client.BdevGetIostatReq
+type BdevSetQdSamplingPeriodReq = client.BdevSetQdSamplingPeriodReq
 type Bdev = client.Bdev
 type BdevIostats = client.BdevIostats
 
@@ -43,6 +44,12 @@ type SpdkServiceIface interface {
 	SpdkVersionIface
 	MallocServiceIface
 	BdevServiceIface
+	KeyringServiceIface
+	TransportServiceIface
+	QoSServiceIface
+	CryptoServiceIface
+	RaidServiceIface
+	NbdServiceIface
 }
 
 type Reconnector interface {
@@ -52,6 +59,7 @@ type Reconnector interface {
 type BdevServiceIface interface {
 	BdevGetBdevs(req BdevGetBdevsReq) (list []Bdev, err error)
 	BdevGetIostat(req BdevGetIostatReq) (result BdevIostats, err error)
+	BdevSetQdSamplingPeriod(req BdevSetQdSamplingPeriodReq) (err error)
 }
 
 type ClientGeneratorFnType func() (client.SPDKClientIface, error)
@@ -68,6 +76,8 @@ func NewWithDefaultSock() (client.SPDKClientIface, error) {
 type SpdkServiceConfig struct {
 	CliGenFn     ClientGeneratorFnType
 	AllowAnyHost bool
+	// if EnableRDMA is true, RDMA transport is created
+	EnableRDMA bool
 }
 
 type SpdkService struct {
@@ -137,6 +147,22 @@ func (svc *SpdkService) BdevGetIostat(req BdevGetIostatReq) (iostats BdevIostats
 	return
 }
 
+func (svc *SpdkService) BdevSetQdSamplingPeriod(req BdevSetQdSamplingPeriodReq) (err error) {
+	var cli client.SPDKClientIface
+	cli, err = svc.client()
+	if err != nil {
+		err = fmt.Errorf("client is nil, try to reconnect failed, %w", err)
+		klog.Error(err)
+		return
+	}
+	_, err = cli.BdevSetQdSamplingPeriod(req)
+	if err != nil {
+		err = fmt.Errorf("set queue depth sampling period of bdev %s failed, %w", req.Name, err)
+		klog.Error(err)
+	}
+	return
+}
+
 func (svc *SpdkService) client() (client.SPDKClientIface, error) {
 	if svc.cli == nil {
 		err := svc.Reconnect()
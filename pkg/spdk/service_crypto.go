@@ -0,0 +1,136 @@
+package spdk
+
+import (
+	"lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"k8s.io/klog/v2"
+)
+
+// CryptoBdevCreateRequest creates a crypto bdev over BaseBdevName, encrypted by AES-XTS with Key and Key2 in hex.
+type CryptoBdevCreateRequest struct {
+	BdevName     string
+	BaseBdevName string
+	KeyName      string
+	Key          string
+	Key2         string
+}
+
+type CryptoBdevDeleteRequest struct {
+	BdevName string
+	KeyName  string
+}
+
+type CryptoServiceIface interface {
+	// CreateCryptoBdev creates the crypto key and the crypto bdev. It is idempotent.
+	CreateCryptoBdev(req CryptoBdevCreateRequest) (err error)
+	// DeleteCryptoBdev deletes the crypto bdev and destroys the crypto key. It returns nil if they do not exist.
+	DeleteCryptoBdev(req CryptoBdevDeleteRequest) (err error)
+}
+
+func (svc *SpdkService) CreateCryptoBdev(req CryptoBdevCreateRequest) (err error) {
+	svc.cli, err = svc.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	var hasKey bool
+	hasKey, err = svc.hasCryptoKey(req.KeyName)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	if !hasKey {
+		klog.Infof("creating crypto key %s", req.KeyName)
+		_, err = svc.cli.AccelCryptoKeyCreate(client.AccelCryptoKeyCreateReq{
+			Cipher: client.CipherAESXTS,
+			Key:    req.Key,
+			Key2:   req.Key2,
+			Name:   req.KeyName,
+		})
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
+	list, err := svc.cli.BdevGetBdevs(client.BdevGetBdevsReq{BdevName: req.BdevName})
+	if err != nil {
+		if !IsNotFoundDeviceError(err) {
+			klog.Error(err)
+			return
+		}
+		err = nil
+	}
+	for _, item := range list {
+		if item.Name == req.BdevName {
+			klog.Infof("crypto bdev %s already exists", req.BdevName)
+			return
+		}
+	}
+
+	klog.Infof("creating crypto bdev %s over %s", req.BdevName, req.BaseBdevName)
+	_, err = svc.cli.BdevCryptoCreate(client.BdevCryptoCreateReq{
+		BaseBdevName: req.BaseBdevName,
+		Name:         req.BdevName,
+		KeyName:      req.KeyName,
+	})
+	if err != nil {
+		klog.Error(err)
+	}
+	return
+}
+
+func (svc *SpdkService) DeleteCryptoBdev(req CryptoBdevDeleteRequest) (err error) {
+	svc.cli, err = svc.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	list, err := svc.cli.BdevGetBdevs(client.BdevGetBdevsReq{BdevName: req.BdevName})
+	if err != nil {
+		if !IsNotFoundDeviceError(err) {
+			klog.Error(err)
+			return
+		}
+		err = nil
+	}
+	for _, item := range list {
+		if item.Name == req.BdevName {
+			klog.Infof("deleting crypto bdev %s", req.BdevName)
+			_, err = svc.cli.BdevCryptoDelete(client.BdevCryptoDeleteReq{Name: req.BdevName})
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+			break
+		}
+	}
+
+	// key could be destroyed only if no bdev uses it
+	var hasKey bool
+	hasKey, err = svc.hasCryptoKey(req.KeyName)
+	if err != nil || !hasKey {
+		return
+	}
+	klog.Infof("destroying crypto key %s", req.KeyName)
+	_, err = svc.cli.AccelCryptoKeyDestroy(client.AccelCryptoKeyDestroyReq{KeyName: req.KeyName})
+	if err != nil {
+		klog.Error(err)
+	}
+	return
+}
+
+func (svc *SpdkService) hasCryptoKey(name string) (has bool, err error) {
+	var keys []client.AccelCryptoKey
+	keys, err = svc.cli.AccelCryptoKeysGet(client.AccelCryptoKeysGetReq{})
+	if err != nil {
+		return
+	}
+	for _, key := range keys {
+		if key.Name == name {
+			return true, nil
+		}
+	}
+	return
+}
@@ -0,0 +1,78 @@
+package spdk
+
+import (
+	"fmt"
+
+	spdkrpc "lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"k8s.io/klog/v2"
+)
+
+type KeyringServiceIface interface {
+	// AddFileKey registers the key file to keyring with the name. It is idempotent.
+	AddFileKey(name, path string) (err error)
+	// RemoveFileKey removes the key from keyring. It returns nil if the key does not exist.
+	RemoveFileKey(name string) (err error)
+}
+
+func (ss *SpdkService) AddFileKey(name, path string) (err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	var keys []spdkrpc.KeyringKey
+	keys, err = ss.cli.KeyringGetKeys()
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	for _, key := range keys {
+		if key.Name == name {
+			if key.Path == path {
+				return nil
+			}
+			err = fmt.Errorf("keyring key %s already exists with path %s", name, key.Path)
+			return
+		}
+	}
+
+	klog.Infof("adding keyring key %s, path %s", name, path)
+	_, err = ss.cli.KeyringFileAddKey(spdkrpc.KeyringFileAddKeyReq{
+		Name: name,
+		Path: path,
+	})
+	return
+}
+
+func (ss *SpdkService) RemoveFileKey(name string) (err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	var (
+		keys  []spdkrpc.KeyringKey
+		found bool
+	)
+	keys, err = ss.cli.KeyringGetKeys()
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	for _, key := range keys {
+		if key.Name == name {
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	klog.Infof("removing keyring key %s", name)
+	_, err = ss.cli.KeyringFileRemoveKey(spdkrpc.KeyringFileRemoveKeyReq{
+		Name: name,
+	})
+	return
+}
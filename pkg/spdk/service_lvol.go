@@ -25,6 +25,12 @@ type LVolServiceIface interface {
 	DeleteLvol(req DeleteLvolReq) (err error)
 	ResizeLvol(req ResizeLvolReq) (err error)
 
+	// GetLvol returns the lvol bdev by full name. found is false if the lvol does not exist.
+	GetLvol(lvolFullName string) (lvol client.Bdev, found bool, err error)
+	RenameLvol(req RenameLvolReq) (err error)
+	// GetLvolFragmap returns the bitmap of clusters allocated by the lvol itself
+	GetLvolFragmap(lvolFullName string) (fragmap client.LvolFragmap, err error)
+
 	CreateLvolSnapshot(req CreateLvolSnapReq) (uuid string, err error)
 	CreateLvolClone(req CreateLvolCloneReq) (uuid string, err error)
 	InflateLvol(req InflateLvolReq) (err error)
@@ -55,6 +61,8 @@ type CreateLvolReq struct {
 	LVStore  string
 	LvolName string
 	SizeByte int
+	// clusters of thin provisioned lvol are allocated on writing
+	ThinProvision bool
 }
 
 type CreateLvolSnapReq struct {
@@ -74,6 +82,12 @@ type InflateLvolReq struct {
 	LvolName string
 }
 
+type RenameLvolReq struct {
+	LVStore string
+	OldName string
+	NewName string
+}
+
 func (req CreateLvolReq) BdevName() string {
 	return fmt.Sprintf("%s/%s", req.LVStore, req.LvolName)
 }
@@ -153,9 +167,10 @@ func (ss *SpdkService) CreateLvol(req CreateLvolReq) (uuid string, err error) {
 	// do create
 	if len(list) == 0 {
 		uuid, err = ss.cli.BdevLVolCreate(client.BdevLVolCreateReq{
-			LVolName: req.LvolName,
-			Size:     req.SizeByte,
-			LvsName:  req.LVStore,
+			LVolName:      req.LvolName,
+			Size:          req.SizeByte,
+			LvsName:       req.LVStore,
+			ThinProvision: req.ThinProvision,
 		})
 		if err != nil {
 			return
@@ -347,16 +362,114 @@ func (ss *SpdkService) InflateLvol(req InflateLvolReq) (err error) {
 		return
 	}
 
-	// TODO: prevent from re-inflating any lvol
+	// inflating a thin provisioned lvol, which is not a clone, allocates all its clusters. So only clones are inflated.
+	var (
+		lvol     client.Bdev
+		found    bool
+		fullName = req.LVStore + "/" + req.LvolName
+	)
+	lvol, found, err = ss.GetLvol(fullName)
+	if err != nil {
+		return
+	}
+	if !found {
+		err = fmt.Errorf("lvol %s not found", fullName)
+		klog.Error(err)
+		return
+	}
+	if !lvol.Driver.Lvol.Clone {
+		klog.Infof("lvol %s is not a clone, skip inflating", fullName)
+		return
+	}
+
 	var ok bool
 	ok, err = ss.cli.BdevLVolInflate(client.BdevLVolInflateReq{
-		Name: req.LVStore + "/" + req.LvolName,
+		Name: fullName,
 	})
 	if err != nil {
 		klog.Error(err)
 	}
 
-	klog.Infof("CreateLvolClone %+v, %t", req, ok)
+	klog.Infof("InflateLvol %+v, %t", req, ok)
+	return
+}
+
+func (ss *SpdkService) GetLvol(lvolFullName string) (lvol client.Bdev, found bool, err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	var list []client.Bdev
+	list, err = ss.cli.BdevGetBdevs(client.BdevGetBdevsReq{BdevName: lvolFullName})
+	if err != nil {
+		if !IsNotFoundDeviceError(err) {
+			klog.Error(err)
+			return
+		}
+		err = nil
+	}
+
+	if len(list) > 0 {
+		lvol = list[0]
+		found = true
+	}
+	return
+}
+
+func (ss *SpdkService) GetLvolFragmap(lvolFullName string) (fragmap client.LvolFragmap, err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	fragmap, err = ss.cli.BdevLVolGetFragmap(client.BdevLVolGetFragmapReq{Name: lvolFullName})
+	if err != nil {
+		klog.Error(err)
+	}
+	return
+}
+
+// RenameLvol renames lvol OldName to NewName. It returns nil if OldName does not exist and NewName exists.
+func (ss *SpdkService) RenameLvol(req RenameLvolReq) (err error) {
+	var (
+		oldFullName = fmt.Sprintf("%s/%s", req.LVStore, req.OldName)
+		newFullName = fmt.Sprintf("%s/%s", req.LVStore, req.NewName)
+		oldFound    bool
+		newFound    bool
+	)
+
+	_, oldFound, err = ss.GetLvol(oldFullName)
+	if err != nil {
+		return
+	}
+	if !oldFound {
+		_, newFound, err = ss.GetLvol(newFullName)
+		if err != nil {
+			return
+		}
+		if newFound {
+			klog.Infof("lvol %s is already renamed to %s", oldFullName, newFullName)
+			return
+		}
+		err = fmt.Errorf("lvol %s not found", oldFullName)
+		klog.Error(err)
+		return
+	}
+
+	var ok bool
+	ok, err = ss.cli.BdevLVolRename(client.BdevLVolRenameReq{
+		OldName: oldFullName,
+		NewName: req.NewName,
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	klog.Infof("RenameLvol %+v, %t", req, ok)
 	return
 }
 
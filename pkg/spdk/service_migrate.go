@@ -34,6 +34,8 @@ type SpdkTargetInfo struct {
 type AttachDestBdevRequest struct {
 	ControllerName string
 	Target         SpdkTargetInfo
+	// optional, NQN of the host which connects to Target. It must be allowed by the subsystem.
+	HostNQN string
 }
 
 type GetMigrationTaskRequest spdkrpc.BdevMigrateQueryRequest
@@ -76,6 +78,7 @@ func (ss *SpdkService) EnsureMigrationDestBdev(req AttachDestBdevRequest) (err e
 		AdrFam:  req.Target.AddrFam,
 		SubNQN:  req.Target.NQN,
 		TrSvcId: req.Target.SvcID,
+		HostNQN: req.HostNQN,
 	})
 	if err != nil {
 		klog.Error(err)
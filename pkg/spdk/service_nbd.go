@@ -0,0 +1,65 @@
+package spdk
+
+import (
+	spdkrpc "lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"k8s.io/klog/v2"
+)
+
+type NbdServiceIface interface {
+	// StartNbdDisk exports the bdev as a nbd device, and returns the path of the device. It is idempotent.
+	StartNbdDisk(bdevName string) (devPath string, err error)
+	// StopNbdDisk stops the nbd device of the bdev. It returns nil if the bdev is not exported.
+	StopNbdDisk(bdevName string) (err error)
+}
+
+func (ss *SpdkService) StartNbdDisk(bdevName string) (devPath string, err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	var disks []spdkrpc.NbdDisk
+	disks, err = ss.cli.NbdGetDisks(spdkrpc.NbdGetDisksReq{})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	for _, disk := range disks {
+		if disk.BdevName == bdevName {
+			return disk.NbdDevice, nil
+		}
+	}
+
+	klog.Infof("starting nbd disk of bdev %s", bdevName)
+	devPath, err = ss.cli.NbdStartDisk(spdkrpc.NbdStartDiskReq{
+		BdevName: bdevName,
+	})
+	return
+}
+
+func (ss *SpdkService) StopNbdDisk(bdevName string) (err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	var disks []spdkrpc.NbdDisk
+	disks, err = ss.cli.NbdGetDisks(spdkrpc.NbdGetDisksReq{})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	for _, disk := range disks {
+		if disk.BdevName == bdevName {
+			klog.Infof("stopping nbd disk %s of bdev %s", disk.NbdDevice, bdevName)
+			_, err = ss.cli.NbdStopDisk(spdkrpc.NbdStopDiskReq{
+				NbdDevice: disk.NbdDevice,
+			})
+			return
+		}
+	}
+
+	return
+}
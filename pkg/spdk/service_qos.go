@@ -0,0 +1,67 @@
+package spdk
+
+import (
+	"fmt"
+
+	"lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"k8s.io/klog/v2"
+)
+
+// BdevQoSLimitRequest contains all rate limits of the bdev. 0 means unlimited.
+type BdevQoSLimitRequest struct {
+	BdevName string
+	RWIOPS   uint64
+	RWMbytes uint64
+	RMbytes  uint64
+	WMbytes  uint64
+}
+
+type QoSServiceIface interface {
+	// SetBdevQoSLimit applies rate limits to the bdev. It does nothing if the limits are not changed.
+	SetBdevQoSLimit(req BdevQoSLimitRequest) (err error)
+}
+
+func (svc *SpdkService) SetBdevQoSLimit(req BdevQoSLimitRequest) (err error) {
+	svc.cli, err = svc.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	var list []client.Bdev
+	list, err = svc.cli.BdevGetBdevs(client.BdevGetBdevsReq{BdevName: req.BdevName})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	if len(list) == 0 {
+		err = fmt.Errorf("not found bdev %s", req.BdevName)
+		return
+	}
+
+	var (
+		current = list[0].RateLimits
+		desired = client.AssignedRateLimits{
+			RWIops:   req.RWIOPS,
+			RWMbytes: req.RWMbytes,
+			RMbytes:  req.RMbytes,
+			WMbytes:  req.WMbytes,
+		}
+	)
+	if current == desired {
+		return
+	}
+
+	klog.Infof("set qos limit of bdev %s from %+v to %+v", req.BdevName, current, desired)
+	_, err = svc.cli.BdevSetQosLimit(client.BdevSetQosLimitReq{
+		Name:     req.BdevName,
+		RWIOPS:   req.RWIOPS,
+		RWMbytes: req.RWMbytes,
+		RMbytes:  req.RMbytes,
+		WMbytes:  req.WMbytes,
+	})
+	if err != nil {
+		klog.Error(err)
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package spdk
+
+import (
+	"fmt"
+
+	"lite.io/liteio/pkg/spdk/jsonrpc/client"
+	"k8s.io/klog/v2"
+)
+
+const (
+	RaidLevel1 = "raid1"
+
+	RaidProcessRebuild = "rebuild"
+)
+
+type RaidBdev = client.RaidBdevInfo
+
+type RaidServiceIface interface {
+	// CreateRaid1Bdev creates a RAID1 bdev over BdevNames. It is idempotent.
+	CreateRaid1Bdev(req CreateBdevRaidReq) (err error)
+	// GetRaidBdev returns the RAID bdev by name. found is false if it does not exist.
+	GetRaidBdev(name string) (raid RaidBdev, found bool, err error)
+	// DeleteRaidBdev deletes the RAID bdev. It returns nil if the bdev does not exist.
+	DeleteRaidBdev(name string) (err error)
+	// AddRaidBaseBdev adds a base bdev to the RAID bdev. Data is rebuilt to the new base bdev in background.
+	AddRaidBaseBdev(raidName, baseBdev string) (err error)
+	// RemoveRaidBaseBdev removes the base bdev from its RAID bdev
+	RemoveRaidBaseBdev(baseBdev string) (err error)
+}
+
+func (ss *SpdkService) CreateRaid1Bdev(req CreateBdevRaidReq) (err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	_, found, err := ss.GetRaidBdev(req.RaidName)
+	if err != nil || found {
+		return
+	}
+
+	klog.Infof("creating raid1 bdev %s over %v", req.RaidName, req.BdevNames)
+	ok, err := ss.cli.CreateBdevRaid(client.CreateBdevRaidRequest{
+		Name:      req.RaidName,
+		RaidLevel: RaidLevel1,
+		BaseBdevs: req.BdevNames,
+	})
+	if err != nil || !ok {
+		err = fmt.Errorf("create raid1 bdev %s failed, err %+v, ok %t", req.RaidName, err, ok)
+		klog.Error(err)
+	}
+	return
+}
+
+func (ss *SpdkService) GetRaidBdev(name string) (raid RaidBdev, found bool, err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	list, err := ss.cli.GetBdevRaids(client.ListBdevRaidRequest{
+		Category: client.RaidBdevCategoryAll,
+	})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	for _, item := range list {
+		if item.Name == name {
+			return item, true, nil
+		}
+	}
+	return
+}
+
+func (ss *SpdkService) DeleteRaidBdev(name string) (err error) {
+	_, found, err := ss.GetRaidBdev(name)
+	if err != nil || !found {
+		return
+	}
+
+	klog.Infof("deleting raid bdev %s", name)
+	_, err = ss.cli.DeleteBdevRaid(client.DeleteBdevRaidRequest{Name: name})
+	if err != nil {
+		klog.Error(err)
+	}
+	return
+}
+
+func (ss *SpdkService) AddRaidBaseBdev(raidName, baseBdev string) (err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	klog.Infof("adding base bdev %s to raid bdev %s", baseBdev, raidName)
+	_, err = ss.cli.AddBaseBdevRaid(client.AddBaseBdevRaidRequest{
+		RaidBdev: raidName,
+		BaseBdev: baseBdev,
+	})
+	if err != nil {
+		klog.Error(err)
+	}
+	return
+}
+
+func (ss *SpdkService) RemoveRaidBaseBdev(baseBdev string) (err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	klog.Infof("removing base bdev %s from raid bdev", baseBdev)
+	_, err = ss.cli.RemoveBaseBdevRaid(client.RemoveBaseBdevRaidRequest{Name: baseBdev})
+	if err != nil {
+		klog.Error(err)
+	}
+	return
+}
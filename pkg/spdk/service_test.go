@@ -70,6 +70,151 @@ func TestSpdkService(t *testing.T) {
 	assert.Equal(t, 1, len(list))
 }
 
+func TestSpdkServiceSubsysHost(t *testing.T) {
+	svc, fakeCli := newSpdkServiceWithFakeClient(t)
+	fakeCli.On("NVMFSubsystemAddHost", mock.Anything).Return(true, nil).
+		On("NVMFSubsystemRemoveHost", client.NVMFSubsystemRemoveHostReq{NQN: "nqn-1", HostNQN: "host-1"}).Return(true, nil).
+		On("NVMFSubsystemRemoveHost", client.NVMFSubsystemRemoveHostReq{NQN: "nqn-2", HostNQN: "host-1"}).Return(false, nil)
+
+	err := svc.SubsysAddHost(SubsystemAddHostRequest{NQN: "nqn-1", HostNQN: "host-1"})
+	assert.NoError(t, err)
+
+	err = svc.SubsysRemoveHost(SubsystemRemoveHostRequest{NQN: "nqn-1", HostNQN: "host-1"})
+	assert.NoError(t, err)
+
+	err = svc.SubsysRemoveHost(SubsystemRemoveHostRequest{NQN: "nqn-2", HostNQN: "host-1"})
+	assert.Error(t, err)
+}
+
+func TestSpdkServiceKeyring(t *testing.T) {
+	svc, fakeCli := newSpdkServiceWithFakeClient(t)
+	fakeCli.On("KeyringGetKeys").Return([]client.KeyringKey{
+		{Name: "key-1", Path: "/usr/tmp/dhchap/key-1"},
+	}, nil).
+		On("KeyringFileAddKey", client.KeyringFileAddKeyReq{Name: "key-2", Path: "/usr/tmp/dhchap/key-2"}).Return(true, nil).
+		On("KeyringFileRemoveKey", client.KeyringFileRemoveKeyReq{Name: "key-1"}).Return(true, nil)
+
+	// key-1 already exists
+	err := svc.AddFileKey("key-1", "/usr/tmp/dhchap/key-1")
+	assert.NoError(t, err)
+
+	err = svc.AddFileKey("key-1", "/usr/tmp/dhchap/other")
+	assert.Error(t, err)
+
+	err = svc.AddFileKey("key-2", "/usr/tmp/dhchap/key-2")
+	assert.NoError(t, err)
+
+	err = svc.RemoveFileKey("key-1")
+	assert.NoError(t, err)
+
+	// not found key is ignored
+	err = svc.RemoveFileKey("key-3")
+	assert.NoError(t, err)
+}
+
+func TestSpdkServiceTargetMultipath(t *testing.T) {
+	svc, fakeCli := newSpdkServiceWithFakeClient(t)
+	fakeCli.On("NVMFCreateSubsystem", mock.MatchedBy(func(req client.NVMFCreateSubsystemReq) bool {
+		return req.ANAReporting
+	})).Return(true, nil).
+		On("NVMFSubsystemAddNS", mock.Anything).Return(1, nil).
+		On("NVMFSubsystemAddListener", mock.Anything).Return(true, nil).Twice().
+		On("NVMFSubsystemListenerSetANAState", mock.MatchedBy(func(req client.NVMFSubsystemListenerSetANAStateReq) bool {
+			return req.ListenAddress.TrAddr == "10.0.0.1" && req.ANAState == client.ANAStateOptimized
+		})).Return(true, nil).Once().
+		On("NVMFSubsystemListenerSetANAState", mock.MatchedBy(func(req client.NVMFSubsystemListenerSetANAStateReq) bool {
+			return req.ListenAddress.TrAddr == "10.0.1.1" && req.ANAState == client.ANAStateNonOptimized
+		})).Return(true, nil).Once()
+
+	tgt, err := svc.CreateTarget(TargetCreateRequest{
+		BdevName: "bdev-1",
+		TargetInfo: Target{
+			NQN:       "nqn-1",
+			TransAddr: "10.0.0.1",
+			SvcID:     "4420",
+			AddrFam:   string(client.AddrFamilyIPv4),
+			ANAState:  client.ANAStateOptimized,
+			Paths: []TargetPath{
+				{TransAddr: "10.0.1.1", ANAState: client.ANAStateNonOptimized},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "4420", tgt.SvcID)
+	assert.Len(t, tgt.Paths, 1)
+}
+
+func TestSpdkServiceQoS(t *testing.T) {
+	svc, fakeCli := newSpdkServiceWithFakeClient(t)
+	fakeCli.On("BdevGetBdevs", client.BdevGetBdevsReq{BdevName: "lvs/lvol-1"}).Return([]client.Bdev{
+		{Name: "lvol-1", RateLimits: client.AssignedRateLimits{RWIops: 1000}},
+	}, nil).
+		On("BdevSetQosLimit", client.BdevSetQosLimitReq{Name: "lvs/lvol-1", RWIOPS: 2000, WMbytes: 100}).Return(true, nil).Once()
+
+	// not changed
+	err := svc.SetBdevQoSLimit(BdevQoSLimitRequest{BdevName: "lvs/lvol-1", RWIOPS: 1000})
+	assert.NoError(t, err)
+
+	err = svc.SetBdevQoSLimit(BdevQoSLimitRequest{BdevName: "lvs/lvol-1", RWIOPS: 2000, WMbytes: 100})
+	assert.NoError(t, err)
+}
+
+func TestSpdkServiceCrypto(t *testing.T) {
+	svc, fakeCli := newSpdkServiceWithFakeClient(t)
+	fakeCli.On("AccelCryptoKeysGet", mock.Anything).Return(nil, nil).Once().
+		On("AccelCryptoKeyCreate", client.AccelCryptoKeyCreateReq{Cipher: client.CipherAESXTS, Key: "k1", Key2: "k2", Name: "key-1"}).Return(true, nil).Once().
+		On("BdevGetBdevs", client.BdevGetBdevsReq{BdevName: "crypto-1"}).Return(nil, client.RPCError{Code: client.ErrorCodeNoDevice}).Once().
+		On("BdevCryptoCreate", client.BdevCryptoCreateReq{BaseBdevName: "lvs/lvol-1", Name: "crypto-1", KeyName: "key-1"}).Return("crypto-1", nil).Once()
+
+	err := svc.CreateCryptoBdev(CryptoBdevCreateRequest{
+		BdevName:     "crypto-1",
+		BaseBdevName: "lvs/lvol-1",
+		KeyName:      "key-1",
+		Key:          "k1",
+		Key2:         "k2",
+	})
+	assert.NoError(t, err)
+
+	fakeCli.On("BdevGetBdevs", client.BdevGetBdevsReq{BdevName: "crypto-1"}).Return([]client.Bdev{{Name: "crypto-1"}}, nil).Once().
+		On("BdevCryptoDelete", client.BdevCryptoDeleteReq{Name: "crypto-1"}).Return(true, nil).Once().
+		On("AccelCryptoKeysGet", mock.Anything).Return([]client.AccelCryptoKey{{Name: "key-1"}}, nil).Once().
+		On("AccelCryptoKeyDestroy", client.AccelCryptoKeyDestroyReq{KeyName: "key-1"}).Return(true, nil).Once()
+
+	err = svc.DeleteCryptoBdev(CryptoBdevDeleteRequest{BdevName: "crypto-1", KeyName: "key-1"})
+	assert.NoError(t, err)
+}
+
+func TestSpdkServiceLvolSnapshot(t *testing.T) {
+	svc, fakeCli := newSpdkServiceWithFakeClient(t)
+	fakeCli.On("BdevGetBdevs", client.BdevGetBdevsReq{BdevName: "lvs/lvol-1"}).Return([]client.Bdev{
+		{Name: "lvol-1", Driver: client.DriverSpecific{Lvol: client.LvolDriver{ThinProvision: true}}},
+	}, nil).
+		On("BdevGetBdevs", client.BdevGetBdevsReq{BdevName: "lvs/lvol-2"}).Return([]client.Bdev{
+		{Name: "lvol-2", Driver: client.DriverSpecific{Lvol: client.LvolDriver{Clone: true, BaseSnapshot: "snap-1"}}},
+	}, nil).
+		On("BdevGetBdevs", client.BdevGetBdevsReq{BdevName: "lvs/lvol-3"}).Return(nil, client.RPCError{Code: client.ErrorCodeNoDevice}).
+		On("BdevLVolInflate", client.BdevLVolInflateReq{Name: "lvs/lvol-2"}).Return(true, nil).Once().
+		On("BdevLVolRename", client.BdevLVolRenameReq{OldName: "lvs/lvol-2", NewName: "lvol-3"}).Return(true, nil).Once()
+
+	// thin lvol which is not a clone is not inflated
+	err := svc.InflateLvol(InflateLvolReq{LVStore: "lvs", LvolName: "lvol-1"})
+	assert.NoError(t, err)
+
+	err = svc.InflateLvol(InflateLvolReq{LVStore: "lvs", LvolName: "lvol-2"})
+	assert.NoError(t, err)
+
+	err = svc.RenameLvol(RenameLvolReq{LVStore: "lvs", OldName: "lvol-2", NewName: "lvol-3"})
+	assert.NoError(t, err)
+
+	// already renamed
+	err = svc.RenameLvol(RenameLvolReq{LVStore: "lvs", OldName: "lvol-3", NewName: "lvol-1"})
+	assert.NoError(t, err)
+
+	_, found, err := svc.GetLvol("lvs/lvol-3")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
 func newSpdkServiceWithFakeClient(t *testing.T) (*SpdkService, *spdkmock.SPDKClientIface) {
 	fakeCli := spdkmock.NewSPDKClientIface(t)
 	fakeCli.On("NVMFGetTransports").Return(nil, nil).
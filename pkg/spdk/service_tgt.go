@@ -40,11 +40,28 @@ type Target struct {
 	// SvcID is needed for recovering target
 	SvcID   string
 	AddrFam string
+	// ANAState of the listener of TransAddr. It is only set if Paths is not empty.
+	ANAState string
+	// Paths are additional listeners for NVMe multipath. They share TransType, SvcID and AddrFam with the primary listener.
+	Paths []TargetPath
+}
+
+type TargetPath struct {
+	TransAddr string
+	ANAState  string
 }
 
 type SubsystemAddHostRequest struct {
 	NQN     string
 	HostNQN string
+	// optional, names of keyring keys for DH-HMAC-CHAP authentication
+	DHChapKey      string
+	DHChapCtrlrKey string
+}
+
+type SubsystemRemoveHostRequest struct {
+	NQN     string
+	HostNQN string
 }
 
 type Subsystem = client.Subsystem
@@ -54,6 +71,7 @@ type TargetServiceIface interface {
 	DeleteTarget(nqn string) (err error)
 	GetTargetStats() (result []SubsystemStatResp, err error)
 	SubsysAddHost(req SubsystemAddHostRequest) (err error)
+	SubsysRemoveHost(req SubsystemRemoveHostRequest) (err error)
 	// GetSubsystemByNQN
 	GetSubsystemByNQN(nqn string) (subsys Subsystem, err error)
 }
@@ -126,6 +144,19 @@ func (ss *SpdkService) CreateTarget(req TargetCreateRequest) (result Target, err
 	}
 
 	if foundSubsystem {
+		// subsystem may be created with allow_any_host, make it consistent with config
+		if subsystem.AllowAnyHost != allowAnyHost {
+			klog.Infof("set allow_any_host of subsystem %s to %t", nqn, allowAnyHost)
+			_, err = ss.cli.NVMFSubsystemAllowAnyHost(client.NVMFSubsystemAllowAnyHostReq{
+				NQN:          nqn,
+				AllowAnyHost: allowAnyHost,
+			})
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+		}
+
 		if len(subsystem.Namespaces) == 0 {
 			// 添加 bdev
 			_, err = ss.cli.NVMFSubsystemAddNS(client.NVMFSubsystemAddNSReq{
@@ -176,6 +207,8 @@ func (ss *SpdkService) CreateTarget(req TargetCreateRequest) (result Target, err
 			AllowAnyHost: allowAnyHost,
 			SerialNumber: serialNumber,
 			ModelNumber:  modelNumber,
+			// ANA reporting is required by host to choose the optimized path
+			ANAReporting: len(req.TargetInfo.Paths) > 0,
 		})
 		if err != nil {
 			klog.Error(err)
@@ -225,6 +258,16 @@ func (ss *SpdkService) CreateTarget(req TargetCreateRequest) (result Target, err
 
 	}
 
+	if len(req.TargetInfo.Paths) > 0 {
+		tgt := result
+		tgt.TransType = transType
+		err = ss.ensureTargetPaths(subsystem.ListenAddresses, tgt)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+	}
+
 	err = ss.idAlloc.SyncFromTruth()
 	if err != nil {
 		klog.Error(err)
@@ -235,6 +278,54 @@ func (ss *SpdkService) CreateTarget(req TargetCreateRequest) (result Target, err
 	return
 }
 
+// ensureTargetPaths adds listeners of multipath to the subsystem and sets their ANA states.
+func (ss *SpdkService) ensureTargetPaths(existing []client.ListenAddress, tgt Target) (err error) {
+	var (
+		listened = make(map[string]bool, len(existing))
+		paths    = append([]TargetPath{{TransAddr: tgt.TransAddr, ANAState: tgt.ANAState}}, tgt.Paths...)
+	)
+	for _, laddr := range existing {
+		if laddr.TrType == tgt.TransType && laddr.TrSvcID == tgt.SvcID {
+			listened[laddr.TrAddr] = true
+		}
+	}
+
+	for _, path := range paths {
+		laddr := client.ListenAddress{
+			TrType:  tgt.TransType,
+			AdrFam:  client.AddressFamily(tgt.AddrFam),
+			TrAddr:  path.TransAddr,
+			TrSvcID: tgt.SvcID,
+		}
+		// the primary listener is already added
+		if !listened[path.TransAddr] && path.TransAddr != tgt.TransAddr {
+			klog.Infof("adding listener %+v to subsystem %s", laddr, tgt.NQN)
+			_, err = ss.cli.NVMFSubsystemAddListener(client.NVMFSubsystemAddListenerReq{
+				NQN:           tgt.NQN,
+				ListenAddress: laddr,
+			})
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+		}
+
+		if path.ANAState != "" {
+			// subsystem created without ana_reporting does not support setting ANA state, so only log the error
+			_, errANA := ss.cli.NVMFSubsystemListenerSetANAState(client.NVMFSubsystemListenerSetANAStateReq{
+				NQN:           tgt.NQN,
+				ListenAddress: laddr,
+				ANAState:      path.ANAState,
+			})
+			if errANA != nil {
+				klog.Errorf("set ana state of listener %s of %s to %s failed: %+v", path.TransAddr, tgt.NQN, path.ANAState, errANA)
+			}
+		}
+	}
+
+	return
+}
+
 func (ss *SpdkService) DeleteTarget(nqn string) (err error) {
 	ss.cli, err = ss.client()
 	if err != nil {
@@ -319,8 +410,10 @@ func (ss *SpdkService) SubsysAddHost(req SubsystemAddHostRequest) (err error) {
 
 	var result bool
 	result, err = ss.cli.NVMFSubsystemAddHost(client.NVMFSubsystemAddHostReq{
-		NQN:     req.NQN,
-		HostNQN: req.HostNQN,
+		NQN:            req.NQN,
+		HostNQN:        req.HostNQN,
+		DHChapKey:      req.DHChapKey,
+		DHChapCtrlrKey: req.DHChapCtrlrKey,
 	})
 	if err != nil {
 		klog.Error("subsystem addhost failed", err)
@@ -334,6 +427,30 @@ func (ss *SpdkService) SubsysAddHost(req SubsystemAddHostRequest) (err error) {
 	return
 }
 
+func (ss *SpdkService) SubsysRemoveHost(req SubsystemRemoveHostRequest) (err error) {
+	ss.cli, err = ss.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	var result bool
+	result, err = ss.cli.NVMFSubsystemRemoveHost(client.NVMFSubsystemRemoveHostReq{
+		NQN:     req.NQN,
+		HostNQN: req.HostNQN,
+	})
+	if err != nil {
+		klog.Error("subsystem removehost failed", err)
+		return
+	}
+
+	if !result {
+		err = fmt.Errorf("subsystem removehost failed, %t %w", result, err)
+	}
+
+	return
+}
+
 func (ss *SpdkService) GetSubsystemByNQN(nqn string) (subsys Subsystem, err error) {
 	ss.cli, err = ss.client()
 	if err != nil {
@@ -2,11 +2,18 @@ package spdk
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"lite.io/liteio/pkg/spdk/jsonrpc/client"
 	"k8s.io/klog/v2"
 )
 
+type TransportServiceIface interface {
+	// ListTransportTypes returns types of transports created in nvmf_tgt, e.g. TCP, RDMA
+	ListTransportTypes() (types []string, err error)
+}
+
 func (svc *SpdkService) InitTransport() (err error) {
 	cli, err := svc.client()
 	if err != nil {
@@ -22,6 +29,7 @@ func (svc *SpdkService) InitTransport() (err error) {
 	// TODO: check whether nvmf_tgt has the capability to create VFIO transport
 	hasTCPTransport := false
 	hasVFIOTransport := false
+	hasRDMATransport := false
 	for _, trans := range list {
 		if trans.TransType == client.TransportTypeTCP {
 			hasTCPTransport = true
@@ -29,6 +37,9 @@ func (svc *SpdkService) InitTransport() (err error) {
 		if trans.TransType == client.TransportTypeVFIOUSER {
 			hasVFIOTransport = true
 		}
+		if trans.TransType == client.TransportTypeRDMA {
+			hasRDMATransport = true
+		}
 	}
 	if !hasTCPTransport {
 		result, err := cli.NVMFCreateTransport(client.NVMFCreateTransportReq{
@@ -66,5 +77,41 @@ func (svc *SpdkService) InitTransport() (err error) {
 		}
 
 	}
+	if svc.Cfg.EnableRDMA && !hasRDMATransport {
+		// create rdma transport
+		// do not return error, so that TCP transport still works if the node has no RDMA device
+		result, err := cli.NVMFCreateTransport(client.NVMFCreateTransportReq{
+			TrType: client.TransportTypeRDMA,
+			// MaxIOQPairsPerCtrlr is the same as TCP transport
+			MaxIOQPairsPerCtrlr: 4,
+			InCapsuleDataSize:   4096,
+			IOUnitSize:          131072,
+		})
+		if err != nil {
+			klog.Error(err)
+		}
+		if !result {
+			klog.Errorf("SPDK init RDMA transport result is false")
+		}
+	}
+	return
+}
+
+func (svc *SpdkService) ListTransportTypes() (types []string, err error) {
+	cli, err := svc.client()
+	if err != nil {
+		klog.Error("spdk client is nil, try to reconnect spdk socket", err)
+		return
+	}
+
+	list, err := cli.NVMFGetTransports()
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	for _, trans := range list {
+		types = append(types, strings.ToUpper(trans.TransType))
+	}
+	sort.Strings(types)
 	return
 }
@@ -0,0 +1,119 @@
+package cgroup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// DefaultRoot is the mount point of cgroup v2 unified hierarchy
+	DefaultRoot = "/sys/fs/cgroup"
+
+	ioMaxFile = "io.max"
+)
+
+// IOMax is the limits in io.max of cgroup v2. 0 means unlimited.
+type IOMax struct {
+	RBPS  uint64
+	WBPS  uint64
+	RIOPS uint64
+	WIOPS uint64
+}
+
+// NewIOMax returns limits of IOPS and bandwidth in MiB/s. io.max has no limit of read and write in total,
+// so rw IOPS and bandwidth are per-direction limits, which are applied to read and write separately.
+// The smaller one of rw and r/w bandwidth is used.
+func NewIOMax(rwIOPS, rwMBytes, rMBytes, wMBytes uint64) IOMax {
+	return IOMax{
+		RIOPS: rwIOPS,
+		WIOPS: rwIOPS,
+		RBPS:  minNonZero(rwMBytes, rMBytes) * 1024 * 1024,
+		WBPS:  minNonZero(rwMBytes, wMBytes) * 1024 * 1024,
+	}
+}
+
+// minNonZero returns the smaller one of non-zero values. 0 means unlimited.
+func minNonZero(a, b uint64) uint64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// Line returns the line to write to io.max for the device, e.g. "253:1 rbps=1048576 wbps=max riops=max wiops=max"
+func (l IOMax) Line(major, minor uint32) string {
+	return fmt.Sprintf("%d:%d rbps=%s wbps=%s riops=%s wiops=%s", major, minor,
+		formatLimit(l.RBPS), formatLimit(l.WBPS), formatLimit(l.RIOPS), formatLimit(l.WIOPS))
+}
+
+func formatLimit(val uint64) string {
+	if val == 0 {
+		return "max"
+	}
+	return strconv.FormatUint(val, 10)
+}
+
+// IsV2 returns true if the cgroup mounted at root is the unified hierarchy of cgroup v2
+func IsV2(root string) bool {
+	_, err := os.Stat(filepath.Join(root, "cgroup.controllers"))
+	return err == nil
+}
+
+// FindPodCgroup returns the cgroup directory of the pod. Both systemd and cgroupfs drivers of kubelet are supported.
+func FindPodCgroup(root, podUID string) (dir string, err error) {
+	var (
+		// systemd driver escapes "-" in the uid to "_"
+		escapedUID = strings.ReplaceAll(podUID, "-", "_")
+		candidates = []string{
+			// systemd driver
+			filepath.Join(root, "kubepods.slice", fmt.Sprintf("kubepods-pod%s.slice", escapedUID)),
+			filepath.Join(root, "kubepods.slice", "kubepods-burstable.slice", fmt.Sprintf("kubepods-burstable-pod%s.slice", escapedUID)),
+			filepath.Join(root, "kubepods.slice", "kubepods-besteffort.slice", fmt.Sprintf("kubepods-besteffort-pod%s.slice", escapedUID)),
+			// cgroupfs driver
+			filepath.Join(root, "kubepods", "pod"+podUID),
+			filepath.Join(root, "kubepods", "burstable", "pod"+podUID),
+			filepath.Join(root, "kubepods", "besteffort", "pod"+podUID),
+		}
+	)
+
+	for _, item := range candidates {
+		if info, errStat := os.Stat(item); errStat == nil && info.IsDir() {
+			return item, nil
+		}
+	}
+
+	err = fmt.Errorf("not found cgroup of pod %s in %s", podUID, root)
+	return
+}
+
+// SetIOMax writes the limits of the device to io.max in the cgroup directory
+func SetIOMax(dir string, major, minor uint32, limits IOMax) (err error) {
+	return os.WriteFile(filepath.Join(dir, ioMaxFile), []byte(limits.Line(major, minor)), 0644)
+}
+
+// SetPodIOMax sets io.max of the device in the cgroup of the pod. devPath could be a symlink, e.g. /dev/vg/lv.
+func SetPodIOMax(root, podUID, devPath string, limits IOMax) (err error) {
+	var (
+		stat unix.Stat_t
+		dir  string
+	)
+	if !IsV2(root) {
+		return fmt.Errorf("cgroup v2 is not mounted at %s", root)
+	}
+	// stat follows the symlink of /dev/vg/lv to the dm device
+	err = unix.Stat(devPath, &stat)
+	if err != nil {
+		return fmt.Errorf("stat %s failed, %w", devPath, err)
+	}
+	dir, err = FindPodCgroup(root, podUID)
+	if err != nil {
+		return
+	}
+
+	return SetIOMax(dir, unix.Major(uint64(stat.Rdev)), unix.Minor(uint64(stat.Rdev)), limits)
+}
@@ -0,0 +1,44 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIOMax(t *testing.T) {
+	l := IOMax{RBPS: 1048576, RIOPS: 1000, WIOPS: 1000}
+	assert.Equal(t, "253:1 rbps=1048576 wbps=max riops=1000 wiops=1000", l.Line(253, 1))
+
+	root := t.TempDir()
+	podDir := filepath.Join(root, "kubepods.slice", "kubepods-burstable.slice", "kubepods-burstable-poda_b_c.slice")
+	assert.NoError(t, os.MkdirAll(podDir, 0755))
+
+	dir, err := FindPodCgroup(root, "a-b-c")
+	assert.NoError(t, err)
+	assert.Equal(t, podDir, dir)
+
+	_, err = FindPodCgroup(root, "x-y-z")
+	assert.Error(t, err)
+
+	err = SetIOMax(dir, 253, 1, l)
+	assert.NoError(t, err)
+	bs, err := os.ReadFile(filepath.Join(dir, "io.max"))
+	assert.NoError(t, err)
+	assert.Equal(t, l.Line(253, 1), string(bs))
+}
+
+func TestNewIOMax(t *testing.T) {
+	// rw limits are applied to read and write separately
+	l := NewIOMax(2000, 10, 0, 0)
+	assert.Equal(t, IOMax{RIOPS: 2000, WIOPS: 2000, RBPS: 10 << 20, WBPS: 10 << 20}, l)
+
+	// the smaller one of rw and r/w bandwidth is used
+	l = NewIOMax(0, 10, 5, 20)
+	assert.Equal(t, IOMax{RBPS: 5 << 20, WBPS: 10 << 20}, l)
+
+	l = NewIOMax(0, 0, 5, 0)
+	assert.Equal(t, "253:1 rbps=5242880 wbps=max riops=max wiops=max", l.Line(253, 1))
+}
@@ -0,0 +1,175 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression of 5 fields: minute, hour, day of month, month and day of week.
+// Each field supports "*", lists "1,2", ranges "1-5" and steps "*/15" or "1-30/5".
+// Descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are also supported.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// day of month or day of week is not "*". If both are restricted, either matches, as in Vixie cron.
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	min, max uint
+}
+
+var (
+	minuteBounds = bounds{0, 59}
+	hourBounds   = bounds{0, 23}
+	domBounds    = bounds{1, 31}
+	monthBounds  = bounds{1, 12}
+	dowBounds    = bounds{0, 6}
+
+	descriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// Parse parses the cron expression
+func Parse(spec string) (sched *Schedule, err error) {
+	spec = strings.TrimSpace(spec)
+	if val, has := descriptors[spec]; has {
+		spec = val
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		err = fmt.Errorf("expected 5 fields in cron expression %q, found %d", spec, len(fields))
+		return
+	}
+
+	sched = &Schedule{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}
+	for i, item := range []struct {
+		bits *uint64
+		b    bounds
+	}{
+		{&sched.minute, minuteBounds},
+		{&sched.hour, hourBounds},
+		{&sched.dom, domBounds},
+		{&sched.month, monthBounds},
+		{&sched.dow, dowBounds},
+	} {
+		*item.bits, err = parseField(fields[i], item.b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// parseField returns bits of values matching the field
+func parseField(field string, b bounds) (bits uint64, err error) {
+	for _, expr := range strings.Split(field, ",") {
+		var (
+			rangeAndStep = strings.SplitN(expr, "/", 2)
+			lowAndHigh   = strings.SplitN(rangeAndStep[0], "-", 2)
+			start, end   uint
+			step         uint = 1
+		)
+
+		switch {
+		case rangeAndStep[0] == "*" || rangeAndStep[0] == "?":
+			start, end = b.min, b.max
+		default:
+			if start, err = parseUint(lowAndHigh[0], b); err != nil {
+				return
+			}
+			end = start
+			if len(lowAndHigh) == 2 {
+				if end, err = parseUint(lowAndHigh[1], b); err != nil {
+					return
+				}
+			} else if len(rangeAndStep) == 2 {
+				// "N/step" means from N to max
+				end = b.max
+			}
+		}
+
+		if len(rangeAndStep) == 2 {
+			var val int
+			val, err = strconv.Atoi(rangeAndStep[1])
+			if err != nil || val <= 0 {
+				err = fmt.Errorf("invalid step in %q", expr)
+				return
+			}
+			step = uint(val)
+		}
+
+		if start > end {
+			err = fmt.Errorf("beginning of range is beyond the end in %q", expr)
+			return
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << i
+		}
+	}
+	return
+}
+
+func parseUint(str string, b bounds) (val uint, err error) {
+	num, err := strconv.Atoi(str)
+	if err != nil || num < int(b.min) || num > int(b.max) {
+		err = fmt.Errorf("value %q is out of range [%d, %d]", str, b.min, b.max)
+		return
+	}
+	val = uint(num)
+	return
+}
+
+// Next returns the first time matching the schedule, which is after t. Zero time is returned if no time matches in 5 years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	// start from the next whole minute
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	var yearLimit = t.Year() + 5
+
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	var (
+		domMatch = s.dom&(1<<uint(t.Day())) > 0
+		dowMatch = s.dow&(1<<uint(t.Weekday())) > 0
+	)
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,51 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "*/15 1-5 * * 1,3", "0 0 1 1 *", "@daily", "5/10 * ? * *"} {
+		_, err := Parse(spec)
+		assert.NoError(t, err, spec)
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 1h"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNext(t *testing.T) {
+	var base = time.Date(2024, 2, 28, 10, 20, 30, 0, time.UTC)
+
+	for _, item := range []struct {
+		spec   string
+		expect time.Time
+	}{
+		{"* * * * *", time.Date(2024, 2, 28, 10, 21, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 2, 28, 10, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 2, 28, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, 2, 29, 3, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		// 2024-03-03 is Sunday
+		{"@weekly", time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// either day of month or day of week matches
+		{"0 0 15 * 5", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		sched, err := Parse(item.spec)
+		assert.NoError(t, err)
+		assert.Equal(t, item.expect, sched.Next(base), item.spec)
+	}
+
+	// exactly on the scheduled time, next run is the following one
+	sched, _ := Parse("0 * * * *")
+	assert.Equal(t, time.Date(2024, 2, 28, 11, 0, 0, 0, time.UTC), sched.Next(time.Date(2024, 2, 28, 10, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,90 @@
+package luks
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"lite.io/liteio/pkg/util/misc"
+	"k8s.io/klog/v2"
+)
+
+const (
+	cryptsetupCmd = "cryptsetup"
+	mapperDir     = "/dev/mapper"
+)
+
+// MapperPath returns the path of the opened LUKS device
+func MapperPath(name string) string {
+	return filepath.Join(mapperDir, name)
+}
+
+// IsLuks checks if the device has LUKS header
+func IsLuks(devPath string) (isLuks bool, err error) {
+	err = exec.Command(cryptsetupCmd, "isLuks", devPath).Run()
+	if err == nil {
+		return true, nil
+	}
+	// exit code 1 means the device is not LUKS
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("cryptsetup isLuks %s failed, %w", devPath, err)
+}
+
+// FormatAndOpen formats the device by LUKS2 with AES-XTS if it has no LUKS header, and opens it with the name.
+// It returns the path of the mapper device. It is idempotent.
+func FormatAndOpen(devPath, name string, passphrase []byte) (mapperPath string, err error) {
+	mapperPath = MapperPath(name)
+	if exist, _ := misc.FileExists(mapperPath); exist {
+		klog.Infof("LUKS device %s is already opened", mapperPath)
+		return
+	}
+
+	var isLuks bool
+	isLuks, err = IsLuks(devPath)
+	if err != nil {
+		return
+	}
+	if !isLuks {
+		klog.Infof("device %s has no LUKS header, formatting it", devPath)
+		err = run(passphrase, "luksFormat", "-q", "--type", "luks2", "--cipher", "aes-xts-plain64", "--key-size", "512",
+			"--key-file", "-", devPath)
+		if err != nil {
+			return
+		}
+	}
+
+	klog.Infof("opening LUKS device %s as %s", devPath, name)
+	err = run(passphrase, "luksOpen", "--key-file", "-", devPath, name)
+	return
+}
+
+// Close closes the LUKS device. It returns nil if the device is not opened.
+func Close(name string) (err error) {
+	if exist, _ := misc.FileExists(MapperPath(name)); !exist {
+		return nil
+	}
+	klog.Infof("closing LUKS device %s", name)
+	return run(nil, "luksClose", name)
+}
+
+// Resize resizes the opened LUKS device to the size of the underlying device
+func Resize(name string, passphrase []byte) (err error) {
+	klog.Infof("resizing LUKS device %s", name)
+	return run(passphrase, "resize", "--key-file", "-", name)
+}
+
+func run(passphrase []byte, args ...string) (err error) {
+	var cmd = exec.Command(cryptsetupCmd, args...)
+	if passphrase != nil {
+		cmd.Stdin = bytes.NewReader(passphrase)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		err = fmt.Errorf("cryptsetup %s failed: %w, output: %s", args[0], err, string(out))
+		klog.Error(err)
+	}
+	return
+}
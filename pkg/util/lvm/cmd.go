@@ -48,7 +48,7 @@ var (
 	// --noheadings -o lv_all,vg_name,segtype --units b --reportformat json
 	lvsCmdJson = cmdArgs{
 		cmd:  "lvs",
-		args: []string{"--noheadings", "--units", "B", "-o", "lv_uuid,lv_name,lv_size,lv_path,lv_full_name,vg_name,lv_layout,lv_attr,lv_device_open,origin,origin_uuid,origin_size,vg_name,segtype", "--reportformat", "json"},
+		args: []string{"--noheadings", "--units", "B", "-o", "lv_uuid,lv_name,lv_size,lv_path,lv_full_name,vg_name,lv_layout,lv_attr,lv_device_open,origin,origin_uuid,origin_size,pool_lv,data_percent,metadata_percent,copy_percent,vg_name,segtype", "--reportformat", "json"},
 	}
 )
 
@@ -106,6 +106,13 @@ type reportLV struct {
 	OriginUUID string `json:"origin_uuid"`
 	// value example: "107374182400B"
 	OriginSize string `json:"origin_size"`
+	// thin pool of thin LV
+	PoolLV string `json:"pool_lv"`
+	// value example: "45.20" or ""
+	DataPercent     string `json:"data_percent"`
+	MetadataPercent string `json:"metadata_percent"`
+	// sync progress of raid LV, e.g. "100.00"
+	CopyPercent string `json:"copy_percent"`
 }
 
 type cmd struct {
@@ -297,6 +304,11 @@ func (c *cmd) listLVInVGJSON(vgName string) (lvs []LV, err error) {
 			Origin:     item.Origin,
 			OriginUUID: item.OriginUUID,
 			OriginSize: item.OriginSize,
+			// thin
+			PoolLV:          item.PoolLV,
+			DataPercent:     parsePercent(item.DataPercent),
+			MetadataPercent: parsePercent(item.MetadataPercent),
+			CopyPercent:     parsePercent(item.CopyPercent),
 		}
 	}
 
@@ -546,6 +558,72 @@ func (c *cmd) CreateLinearLV(vgName, lvName string, opt LvOption) (vol LV, err e
 	return
 }
 
+// CreateRaidLV command is lvcreate -y --type raid1 -m 1 -l 100%FREE -n lv vg
+func (c *cmd) CreateRaidLV(vgName, lvName string, opt RaidLvOption) (vol LV, err error) {
+	var out []byte
+	var createCmd cmdArgs
+	createCmd, err = getRaidLVCreateCmd(vgName, lvName, opt)
+	if err != nil {
+		return
+	}
+	var cmd = filepath.Join(c.binDir, createCmd.cmd)
+	out, err = c.exec.ExecCmd(cmd, createCmd.args)
+	if err != nil {
+		klog.Errorf("err %+v, output: %s", err, string(out))
+		return
+	}
+
+	vol.Name = lvName
+	vol.VGName = vgName
+	vol.DevPath = fmt.Sprintf("/dev/%s/%s", vgName, lvName)
+	vol.SizeByte = opt.Size
+	return
+}
+
+// ExtendVG command is vgextend vg /dev/nvme1n1
+func (c *cmd) ExtendVG(vgName string, pvs []string) (err error) {
+	var out []byte
+	var cmd = filepath.Join(c.binDir, "vgextend")
+	out, err = c.exec.ExecCmd(cmd, append([]string{vgName}, pvs...))
+	if err != nil {
+		klog.Errorf("err %+v, output: %s", err, string(out))
+		return
+	}
+
+	klog.Infof("vgextend %s %+v, stdout: %s", vgName, pvs, string(out))
+	return
+}
+
+// ReduceVGMissing command is vgreduce --removemissing vg
+func (c *cmd) ReduceVGMissing(vgName string) (err error) {
+	var out []byte
+	var cmd = filepath.Join(c.binDir, "vgreduce")
+	out, err = c.exec.ExecCmd(cmd, []string{"--removemissing", vgName})
+	if err != nil {
+		klog.Errorf("err %+v, output: %s", err, string(out))
+		return
+	}
+
+	klog.Infof("vgreduce --removemissing %s, stdout: %s", vgName, string(out))
+	return
+}
+
+// RepairLV command is lvconvert -y --repair vg/lv /dev/nvme1n1
+// It replaces the images on missing PVs of raid LV with new images allocated on pvs.
+func (c *cmd) RepairLV(vgName, lvName string, pvs []string) (err error) {
+	var out []byte
+	var cmd = filepath.Join(c.binDir, "lvconvert")
+	var args = append([]string{"-y", "--repair", fmt.Sprintf("%s/%s", vgName, lvName)}, pvs...)
+	out, err = c.exec.ExecCmd(cmd, args)
+	if err != nil {
+		klog.Errorf("err %+v, output: %s", err, string(out))
+		return
+	}
+
+	klog.Infof("lvconvert --repair %s/%s %+v, stdout: %s", vgName, lvName, pvs, string(out))
+	return
+}
+
 func (c *cmd) RemoveLV(vgName, lvName string) (err error) {
 	var out []byte
 	var removeCmd = getLvRemoveCmd(vgName, lvName)
@@ -611,6 +689,43 @@ func (c *cmd) MergeSnapshot(vgName, snapName string) (err error) {
 	return
 }
 
+// ActivateLV command is lvchange -ay antstore-vg/lvol
+func (c *cmd) ActivateLV(vgName, lvName string) (err error) {
+	var out []byte
+	var activateCmd = getLvActivateCmd(vgName, lvName)
+	var cmd = filepath.Join(c.binDir, activateCmd.cmd)
+	out, err = c.exec.ExecCmd(cmd, activateCmd.args)
+	if err != nil {
+		klog.Errorf("err %+v, output: %s", err, string(out))
+		return
+	}
+	return
+}
+
+// SuspendLV command is dmsetup suspend antstore--vg-lvol
+func (c *cmd) SuspendLV(vgName, lvName string) (err error) {
+	var out []byte
+	var suspendCmd = getDmSuspendCmd(vgName, lvName, true)
+	out, err = c.exec.ExecCmd(suspendCmd.cmd, suspendCmd.args)
+	if err != nil {
+		klog.Errorf("err %+v, output: %s", err, string(out))
+		return
+	}
+	return
+}
+
+// ResumeLV command is dmsetup resume antstore--vg-lvol
+func (c *cmd) ResumeLV(vgName, lvName string) (err error) {
+	var out []byte
+	var resumeCmd = getDmSuspendCmd(vgName, lvName, false)
+	out, err = c.exec.ExecCmd(resumeCmd.cmd, resumeCmd.args)
+	if err != nil {
+		klog.Errorf("err %+v, output: %s", err, string(out))
+		return
+	}
+	return
+}
+
 // ExpandVolume command is lvextend --size +104857600B antstore-vg/lvol
 // Format of targetVol could be /dev/vg/lvol or vg/lvol
 func (c *cmd) ExpandVolume(deltaBytes int64, targetVol string) (err error) {
@@ -624,6 +739,82 @@ func (c *cmd) ExpandVolume(deltaBytes int64, targetVol string) (err error) {
 	return
 }
 
+// CreateThinPool command is lvcreate -y --type thin-pool -L 104857600B -n pool antstore-vg
+func (c *cmd) CreateThinPool(vgName, poolName string, sizeByte uint64) (pool LV, err error) {
+	var out []byte
+	var createCmd = getThinPoolCreateCmd(vgName, poolName, sizeByte)
+	var cmd = filepath.Join(c.binDir, createCmd.cmd)
+	out, err = c.exec.ExecCmd(cmd, createCmd.args)
+	if err != nil {
+		klog.Errorf("err %+v, output: %s", err, string(out))
+		return
+	}
+
+	pool.Name = poolName
+	pool.VGName = vgName
+	pool.SizeByte = sizeByte
+	pool.LvLayout = "thin,pool"
+	return
+}
+
+// CreateThinLV command is lvcreate -y -V 104857600B --thin -n lvol antstore-vg/pool
+func (c *cmd) CreateThinLV(vgName, poolName, lvName string, sizeByte uint64) (vol LV, err error) {
+	var out []byte
+	var createCmd = getThinLVCreateCmd(vgName, poolName, lvName, sizeByte)
+	var cmd = filepath.Join(c.binDir, createCmd.cmd)
+	out, err = c.exec.ExecCmd(cmd, createCmd.args)
+	if err != nil {
+		klog.Errorf("err %+v, output: %s", err, string(out))
+		return
+	}
+
+	vol.Name = lvName
+	vol.VGName = vgName
+	vol.DevPath = fmt.Sprintf("/dev/%s/%s", vgName, lvName)
+	vol.SizeByte = sizeByte
+	vol.PoolLV = poolName
+	return
+}
+
+func getThinPoolCreateCmd(vg, pool string, sizeByte uint64) cmdArgs {
+	return cmdArgs{
+		cmd: "lvcreate",
+		args: []string{
+			"-y",
+			"--type", "thin-pool",
+			"-L", fmt.Sprintf("%dB", sizeByte),
+			"-n", pool,
+			vg,
+		},
+	}
+}
+
+func getThinLVCreateCmd(vg, pool, lv string, sizeByte uint64) cmdArgs {
+	return cmdArgs{
+		cmd: "lvcreate",
+		args: []string{
+			"-y",
+			"-V", fmt.Sprintf("%dB", sizeByte),
+			"--thin",
+			"-n", lv,
+			fmt.Sprintf("%s/%s", vg, pool),
+		},
+	}
+}
+
+// parsePercent parses percent value like "45.20". Empty or invalid value is 0.
+func parsePercent(val string) (pct float64) {
+	if val == "" {
+		return
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil {
+		klog.Errorf("invalid percent value %q, %+v", val, err)
+		return 0
+	}
+	return
+}
+
 // cmd example: lvcreate -i 1 -I 128k -L 1GB -s -n name_snap antstore-vg/origin-lv
 func getCreateSnapshotStripeCmd(vg, snapName, originName string, sizeByte uint64, pvCnt int) cmdArgs {
 	return cmdArgs{
@@ -661,6 +852,29 @@ func getMergeSnapshotCmd(vg, snapName string) cmdArgs {
 	}
 }
 
+// dmsetup is not in binDir of LVM
+func getDmSuspendCmd(vg, lv string, suspend bool) cmdArgs {
+	var action = "resume"
+	if suspend {
+		action = "suspend"
+	}
+	return cmdArgs{
+		cmd: "dmsetup",
+		args: []string{
+			action, DmName(vg, lv),
+		},
+	}
+}
+
+func getLvActivateCmd(vg, lv string) cmdArgs {
+	return cmdArgs{
+		cmd: "lvchange",
+		args: []string{
+			"-ay", fmt.Sprintf("%s/%s", vg, lv),
+		},
+	}
+}
+
 func getStripeLVCreateCmd(vg, lv string, sizeByte uint64, pvNum int) cmdArgs {
 	return cmdArgs{
 		cmd: "lvcreate",
@@ -699,6 +913,44 @@ func getLvCreateCmd(vg, lv string, sizeByte uint64, logicSize string) cmdArgs {
 	}
 }
 
+func getRaidLVCreateCmd(vg, lv string, opt RaidLvOption) (args cmdArgs, err error) {
+	args = cmdArgs{
+		cmd:  "lvcreate",
+		args: []string{"-y", "--type", opt.Level},
+	}
+	switch opt.Level {
+	case "raid1":
+		if opt.PVCount < 2 {
+			err = fmt.Errorf("raid1 requires at least 2 PVs, got %d", opt.PVCount)
+			return
+		}
+		args.args = append(args.args, "-m", strconv.Itoa(opt.PVCount-1))
+	case "raid5":
+		if opt.PVCount < 3 {
+			err = fmt.Errorf("raid5 requires at least 3 PVs, got %d", opt.PVCount)
+			return
+		}
+		args.args = append(args.args, "-i", strconv.Itoa(opt.PVCount-1))
+	case "raid6":
+		if opt.PVCount < 5 {
+			err = fmt.Errorf("raid6 requires at least 5 PVs, got %d", opt.PVCount)
+			return
+		}
+		args.args = append(args.args, "-i", strconv.Itoa(opt.PVCount-2))
+	default:
+		err = fmt.Errorf("unsupported raid level %s", opt.Level)
+		return
+	}
+
+	if opt.LogicSize != "" {
+		args.args = append(args.args, "-l", opt.LogicSize)
+	} else {
+		args.args = append(args.args, "-L", fmt.Sprintf("%dB", opt.Size))
+	}
+	args.args = append(args.args, "-n", lv, vg)
+	return
+}
+
 func getLvRemoveCmd(vg, lv string) cmdArgs {
 	return cmdArgs{
 		cmd: "lvremove",
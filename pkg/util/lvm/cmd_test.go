@@ -88,3 +88,59 @@ func TestLvmCmd(t *testing.T) {
 	assert.Equal(t, uint64(1073741824), lvs[0].SizeByte)
 
 }
+
+func TestThinPool(t *testing.T) {
+	mockExec := utilmock.NewShellExec(t)
+	poolCmd := getThinPoolCreateCmd("vg", "pool", 4194304)
+	thinCmd := getThinLVCreateCmd("vg", "pool", "lv", 8388608)
+	mockExec.On("ExecCmd", poolCmd.cmd, poolCmd.args).Return([]byte(""), nil)
+	mockExec.On("ExecCmd", thinCmd.cmd, thinCmd.args).Return([]byte(""), nil)
+
+	assert.Equal(t, []string{"-y", "--type", "thin-pool", "-L", "4194304B", "-n", "pool", "vg"}, poolCmd.args)
+	assert.Equal(t, []string{"-y", "-V", "8388608B", "--thin", "-n", "lv", "vg/pool"}, thinCmd.args)
+
+	cmdObj := &cmd{
+		exec: mockExec,
+	}
+
+	pool, err := cmdObj.CreateThinPool("vg", "pool", 4194304)
+	assert.NoError(t, err)
+	assert.Equal(t, "pool", pool.Name)
+
+	vol, err := cmdObj.CreateThinLV("vg", "pool", "lv", 8388608)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/vg/lv", vol.DevPath)
+	assert.Equal(t, "pool", vol.PoolLV)
+
+	assert.Equal(t, 45.2, parsePercent("45.20"))
+	assert.Equal(t, float64(0), parsePercent(""))
+}
+
+func TestRaidLVCmd(t *testing.T) {
+	args, err := getRaidLVCreateCmd("vg", "lv", RaidLvOption{
+		LvOption: LvOption{LogicSize: "100%FREE"},
+		Level:    "raid1",
+		PVCount:  2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-y", "--type", "raid1", "-m", "1", "-l", "100%FREE", "-n", "lv", "vg"}, args.args)
+
+	args, err = getRaidLVCreateCmd("vg", "lv", RaidLvOption{
+		LvOption: LvOption{Size: 4194304},
+		Level:    "raid5",
+		PVCount:  4,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-y", "--type", "raid5", "-i", "3", "-L", "4194304B", "-n", "lv", "vg"}, args.args)
+
+	args, err = getRaidLVCreateCmd("vg", "lv", RaidLvOption{
+		LvOption: LvOption{LogicSize: "100%FREE"},
+		Level:    "raid6",
+		PVCount:  5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-y", "--type", "raid6", "-i", "3", "-l", "100%FREE", "-n", "lv", "vg"}, args.args)
+
+	_, err = getRaidLVCreateCmd("vg", "lv", RaidLvOption{Level: "raid5", PVCount: 2})
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package lvm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// magic of the header of persistent exception store, "SnAp"
+	cowMagic       = 0x70416e53
+	sectorSize     = 512
+	exceptionBytes = 16
+	// number of header chunks before the first metadata area
+	cowHeaderChunks = 1
+)
+
+// DmName returns the device mapper name of LV. Device mapper escapes "-" in names by "--".
+func DmName(vgName, lvName string) string {
+	var escape = func(name string) string {
+		return strings.ReplaceAll(name, "-", "--")
+	}
+	return fmt.Sprintf("%s-%s", escape(vgName), escape(lvName))
+}
+
+// CowDevPath returns the path of COW device of classic snapshot.
+func CowDevPath(vgName, snapName string) string {
+	return filepath.Join("/dev/mapper", DmName(vgName, snapName)+"-cow")
+}
+
+// ReadSnapshotExceptions reads the persistent exception store of the classic snapshot.
+// It returns the chunk size in bytes, and the chunks of origin which are copied to the snapshot,
+// i.e. chunks of origin changed after the snapshot is taken.
+func ReadSnapshotExceptions(cowDevPath string) (chunkSize int64, chunks []uint64, err error) {
+	f, err := os.Open(cowDevPath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	return readExceptions(f)
+}
+
+// readExceptions parses the layout of dm-snapshot persistent store:
+// chunk 0 is the header, followed by areas. Each area is a metadata chunk of exceptions and the data chunks of them.
+// Exception is a pair of little endian uint64 {old_chunk, new_chunk}. new_chunk is 0 after the last exception.
+func readExceptions(r io.ReaderAt) (chunkSize int64, chunks []uint64, err error) {
+	var header = make([]byte, 16)
+	if _, err = r.ReadAt(header, 0); err != nil {
+		err = fmt.Errorf("read header of COW failed, %w", err)
+		return
+	}
+
+	var (
+		magic   = binary.LittleEndian.Uint32(header[0:4])
+		valid   = binary.LittleEndian.Uint32(header[4:8])
+		sectors = binary.LittleEndian.Uint32(header[12:16])
+	)
+	if magic != cowMagic {
+		err = fmt.Errorf("invalid magic %x of COW", magic)
+		return
+	}
+	if valid == 0 {
+		err = fmt.Errorf("snapshot is invalidated")
+		return
+	}
+	if sectors == 0 {
+		err = fmt.Errorf("invalid chunk size of COW")
+		return
+	}
+
+	chunkSize = int64(sectors) * sectorSize
+	var (
+		perArea = chunkSize / exceptionBytes
+		area    = make([]byte, chunkSize)
+	)
+	for idx := int64(0); ; idx++ {
+		var offset = (cowHeaderChunks + idx*(perArea+1)) * chunkSize
+		if _, err = r.ReadAt(area, offset); err != nil {
+			err = fmt.Errorf("read metadata area %d of COW failed, %w", idx, err)
+			return
+		}
+		for i := int64(0); i < perArea; i++ {
+			var (
+				oldChunk = binary.LittleEndian.Uint64(area[i*exceptionBytes:])
+				newChunk = binary.LittleEndian.Uint64(area[i*exceptionBytes+8:])
+			)
+			if newChunk == 0 {
+				return
+			}
+			chunks = append(chunks, oldChunk)
+		}
+	}
+}
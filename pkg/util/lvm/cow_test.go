@@ -0,0 +1,47 @@
+package lvm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadExceptions(t *testing.T) {
+	var (
+		chunkSize int64 = 4096
+		perArea         = chunkSize / exceptionBytes
+		// header, area 0 with full exceptions and data chunks, area 1 with 2 exceptions
+		cow = make([]byte, (1+perArea+1+2)*chunkSize)
+	)
+	binary.LittleEndian.PutUint32(cow[0:], cowMagic)
+	binary.LittleEndian.PutUint32(cow[4:], 1)
+	binary.LittleEndian.PutUint32(cow[8:], 1)
+	binary.LittleEndian.PutUint32(cow[12:], uint32(chunkSize/sectorSize))
+
+	var putException = func(area, idx int64, oldChunk, newChunk uint64) {
+		offset := (1+area*(perArea+1))*chunkSize + idx*exceptionBytes
+		binary.LittleEndian.PutUint64(cow[offset:], oldChunk)
+		binary.LittleEndian.PutUint64(cow[offset+8:], newChunk)
+	}
+	for i := int64(0); i < perArea; i++ {
+		putException(0, i, uint64(i*2), uint64(2+i))
+	}
+	putException(1, 0, 1000, uint64(perArea+3))
+	putException(1, 1, 1001, uint64(perArea+4))
+
+	size, chunks, err := readExceptions(bytes.NewReader(cow))
+	assert.NoError(t, err)
+	assert.Equal(t, chunkSize, size)
+	assert.Len(t, chunks, int(perArea)+2)
+	assert.Equal(t, uint64(2), chunks[1])
+	assert.Equal(t, uint64(1001), chunks[len(chunks)-1])
+
+	// invalidated snapshot
+	binary.LittleEndian.PutUint32(cow[4:], 0)
+	_, _, err = readExceptions(bytes.NewReader(cow))
+	assert.Error(t, err)
+
+	assert.Equal(t, "/dev/mapper/obnvmf--vg-vol--1_snap-cow", CowDevPath("obnvmf-vg", "vol-1_snap"))
+}
@@ -36,6 +36,13 @@ type LV struct {
 	Origin     string
 	OriginUUID string
 	OriginSize string
+	// thin pool of thin LV
+	PoolLV string
+	// usage of thin pool or thin LV, e.g. 45.20
+	DataPercent     float64
+	MetadataPercent float64
+	// sync progress of raid LV, e.g. 100.00
+	CopyPercent float64
 }
 
 type LvOption struct {
@@ -43,6 +50,14 @@ type LvOption struct {
 	LogicSize string
 }
 
+type RaidLvOption struct {
+	LvOption
+	// raid1, raid5 or raid6
+	Level string
+	// number of PVs in the VG
+	PVCount int
+}
+
 type LvmIface interface {
 	CreateVG(name string, pvs []string) (VG, error)
 	CreatePV(pvs []string) error
@@ -59,4 +74,24 @@ type LvmIface interface {
 	CreateSnapshotLinear(vgName, snapName, originVol string, sizeByte uint64) (err error)
 	CreateSnapshotStripe(vgName, snapName, originVol string, sizeByte uint64) (err error)
 	MergeSnapshot(vgName, snapName string) (err error)
+	// ActivateLV activates the LV, e.g. to start the deferred merging of snapshot
+	ActivateLV(vgName, lvName string) (err error)
+	// SuspendLV suspends the device of LV. IO is flushed and blocked, and filesystem on it is frozen.
+	SuspendLV(vgName, lvName string) (err error)
+	// ResumeLV resumes the suspended device of LV
+	ResumeLV(vgName, lvName string) (err error)
+
+	// CreateThinPool creates a thin pool LV with size of sizeByte in the VG
+	CreateThinPool(vgName, poolName string, sizeByte uint64) (pool LV, err error)
+	// CreateThinLV creates a thin LV with virtual size of sizeByte in the thin pool
+	CreateThinLV(vgName, poolName, lvName string, sizeByte uint64) (vol LV, err error)
+
+	// CreateRaidLV creates a raid LV over all PVs in the VG
+	CreateRaidLV(vgName, lvName string, opt RaidLvOption) (vol LV, err error)
+	// ExtendVG adds PVs to the VG
+	ExtendVG(vgName string, pvs []string) (err error)
+	// ReduceVGMissing removes missing PVs from the VG
+	ReduceVGMissing(vgName string) (err error)
+	// RepairLV replaces images of raid LV on missing PVs with new images on pvs. Data is rebuilt in background.
+	RepairLV(vgName, lvName string, pvs []string) (err error)
 }
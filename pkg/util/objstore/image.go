@@ -0,0 +1,479 @@
+package objstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	ManifestVersion   = 1
+	ManifestFileName  = "manifest.json"
+	CompressionGzip   = "gzip"
+	DefaultChunkSize  = 4 << 20
+	chunkNameTemplate = "chunk-%08d.gz"
+	// max length of manifest chain
+	maxChainLength = 1024
+)
+
+// ChunkRetryBackoff limits retries of putting or getting a chunk
+var ChunkRetryBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    5,
+	Cap:      30 * time.Second,
+}
+
+// Manifest describes a block device image which is split into chunks and stored in object storage.
+// A full image has no Base, and chunks whose bytes are all zero are not stored.
+// An incremental image only lists chunks changed after its Base, and unlisted chunks are the same as Base.
+type Manifest struct {
+	Version int `json:"version"`
+	// Source is the name of exported object, e.g. namespace/name of snapshot
+	Source string `json:"source"`
+	// Base is the manifest key of the base image
+	Base        string    `json:"base,omitempty"`
+	SizeBytes   int64     `json:"sizeBytes"`
+	ChunkSize   int64     `json:"chunkSize"`
+	Compression string    `json:"compression"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// Chunks are non-zero chunks of full image, or changed chunks of incremental image
+	Chunks []ChunkInfo `json:"chunks"`
+}
+
+type ChunkInfo struct {
+	Index int64 `json:"index"`
+	// Zero is true if the changed chunk of incremental image is zero, which is not stored
+	Zero bool   `json:"zero,omitempty"`
+	Key  string `json:"key,omitempty"`
+	// StoredBytes is the size of compressed chunk
+	StoredBytes int64 `json:"storedBytes,omitempty"`
+	// SHA256 of uncompressed chunk
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Extent is a range of image in bytes
+type Extent struct {
+	Offset int64
+	Length int64
+}
+
+// Image is the full view of an image, which is resolved from its manifest chain
+type Image struct {
+	SizeBytes int64
+	ChunkSize int64
+	// Chunks are non-zero chunks, key is index of chunk
+	Chunks map[int64]ChunkInfo
+}
+
+// TotalChunks returns the count of chunks, including zero chunks
+func (m Manifest) TotalChunks() int64 {
+	if m.ChunkSize <= 0 {
+		return 0
+	}
+	return (m.SizeBytes + m.ChunkSize - 1) / m.ChunkSize
+}
+
+// Progress is called after each chunk is handled
+type Progress func(doneChunks, totalChunks int64, storedBytes int64)
+
+type ExportImageRequest struct {
+	Source    string
+	Prefix    string
+	SizeBytes int64
+	ChunkSize int64
+	// Base is the manifest key of base image. If it is set, only chunks overlapped with Changed are exported,
+	// and ChunkSize is the same as the base image.
+	Base     string
+	Changed  []Extent
+	Progress Progress
+}
+
+type ImportImageRequest struct {
+	ManifestKey string
+	// SizeBytes of destination device, which must not be smaller than the image
+	SizeBytes int64
+	// DstZeroed is true if destination device reads zero before writing, so zero chunks are skipped
+	DstZeroed bool
+	Progress  Progress
+}
+
+// ManifestKey returns the key of manifest under the prefix
+func ManifestKey(prefix string) string {
+	return path.Join(prefix, ManifestFileName)
+}
+
+// ExportImage reads src chunk by chunk, and puts each non-zero chunk compressed by gzip to store.
+// The manifest is put at last, so an image without manifest is incomplete.
+func ExportImage(ctx context.Context, store ObjectStoreIface, src io.ReaderAt, req ExportImageRequest) (manifest Manifest, err error) {
+	// changed chunks of incremental image, nil for full image
+	var changed map[int64]bool
+	if req.Base != "" {
+		var base Manifest
+		base, err = GetManifest(ctx, store, req.Base)
+		if err != nil {
+			return
+		}
+		req.ChunkSize = base.ChunkSize
+		changed = changedChunks(req.Changed, req.ChunkSize)
+		// volume is expanded after base image
+		if req.SizeBytes != base.SizeBytes {
+			for idx := base.SizeBytes / req.ChunkSize; idx*req.ChunkSize < req.SizeBytes; idx++ {
+				changed[idx] = true
+			}
+		}
+	}
+	if req.ChunkSize <= 0 {
+		req.ChunkSize = DefaultChunkSize
+	}
+	manifest = Manifest{
+		Version:     ManifestVersion,
+		Source:      req.Source,
+		Base:        req.Base,
+		SizeBytes:   req.SizeBytes,
+		ChunkSize:   req.ChunkSize,
+		Compression: CompressionGzip,
+		CreatedAt:   time.Now(),
+	}
+
+	var (
+		total       = manifest.TotalChunks()
+		buf         = make([]byte, req.ChunkSize)
+		storedBytes int64
+	)
+	for idx := int64(0); idx < total; idx++ {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if changed != nil && !changed[idx] {
+			if req.Progress != nil {
+				req.Progress(idx+1, total, storedBytes)
+			}
+			continue
+		}
+
+		var (
+			offset = idx * req.ChunkSize
+			length = req.ChunkSize
+			n      int
+		)
+		if offset+length > req.SizeBytes {
+			length = req.SizeBytes - offset
+		}
+		n, err = src.ReadAt(buf[:length], offset)
+		if err != nil && !(err == io.EOF && int64(n) == length) {
+			err = fmt.Errorf("read chunk %d at offset %d failed, %w", idx, offset, err)
+			return
+		}
+		err = nil
+
+		data := buf[:length]
+		if !isZero(data) {
+			var chunk ChunkInfo
+			chunk, err = putChunk(ctx, store, req.Prefix, idx, data)
+			if err != nil {
+				return
+			}
+			storedBytes += chunk.StoredBytes
+			manifest.Chunks = append(manifest.Chunks, chunk)
+		} else if changed != nil {
+			manifest.Chunks = append(manifest.Chunks, ChunkInfo{Index: idx, Zero: true})
+		}
+
+		if req.Progress != nil {
+			req.Progress(idx+1, total, storedBytes)
+		}
+	}
+
+	var bs []byte
+	bs, err = json.Marshal(manifest)
+	if err != nil {
+		return
+	}
+	err = store.PutObject(ctx, ManifestKey(req.Prefix), bs)
+	if err != nil {
+		return
+	}
+
+	klog.Infof("exported image %s to %s, base %q, %d/%d chunks stored, %d bytes",
+		req.Source, req.Prefix, req.Base, len(manifest.Chunks), total, storedBytes)
+	return
+}
+
+// GetManifest reads the manifest from store
+func GetManifest(ctx context.Context, store ObjectStoreIface, manifestKey string) (manifest Manifest, err error) {
+	var bs []byte
+	bs, err = store.GetObject(ctx, manifestKey)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bs, &manifest)
+	if err != nil {
+		return
+	}
+	if manifest.Version != ManifestVersion || manifest.Compression != CompressionGzip || manifest.ChunkSize <= 0 {
+		err = fmt.Errorf("unsupported manifest %s, version %d, compression %s, chunkSize %d",
+			manifestKey, manifest.Version, manifest.Compression, manifest.ChunkSize)
+	}
+	return
+}
+
+// ResolveImage reads the manifest chain from manifestKey to the full image, and merges chunks of them
+func ResolveImage(ctx context.Context, store ObjectStoreIface, manifestKey string) (img Image, err error) {
+	var chain []Manifest
+	for key := manifestKey; key != ""; {
+		if len(chain) >= maxChainLength {
+			err = fmt.Errorf("manifest chain of %s is too long", manifestKey)
+			return
+		}
+		var manifest Manifest
+		manifest, err = GetManifest(ctx, store, key)
+		if err != nil {
+			return
+		}
+		if len(chain) > 0 && manifest.ChunkSize != chain[0].ChunkSize {
+			err = fmt.Errorf("chunk size %d of base %s is different from %d", manifest.ChunkSize, key, chain[0].ChunkSize)
+			return
+		}
+		chain = append(chain, manifest)
+		key = manifest.Base
+	}
+
+	img = Image{
+		SizeBytes: chain[0].SizeBytes,
+		ChunkSize: chain[0].ChunkSize,
+		Chunks:    make(map[int64]ChunkInfo),
+	}
+	// apply from the full image to the leaf
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, chunk := range chain[i].Chunks {
+			if chunk.Zero {
+				delete(img.Chunks, chunk.Index)
+			} else {
+				img.Chunks[chunk.Index] = chunk
+			}
+		}
+	}
+	// chunks out of the leaf image
+	for idx := range img.Chunks {
+		if idx*img.ChunkSize >= img.SizeBytes {
+			delete(img.Chunks, idx)
+		}
+	}
+	return
+}
+
+// TotalChunks returns the count of chunks, including zero chunks
+func (img Image) TotalChunks() int64 {
+	return Manifest{SizeBytes: img.SizeBytes, ChunkSize: img.ChunkSize}.TotalChunks()
+}
+
+// chunkExtent returns the range of the chunk. The last chunk may be shorter than ChunkSize.
+func (img Image) chunkExtent(idx int64) Extent {
+	var ext = Extent{Offset: idx * img.ChunkSize, Length: img.ChunkSize}
+	if ext.Offset+ext.Length > img.SizeBytes {
+		ext.Length = img.SizeBytes - ext.Offset
+	}
+	return ext
+}
+
+// AllocatedExtents returns extents of non-zero chunks, sorted by offset
+func (img Image) AllocatedExtents() (extents []Extent) {
+	var indexes = make([]int64, 0, len(img.Chunks))
+	for idx := range img.Chunks {
+		indexes = append(indexes, idx)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	for _, idx := range indexes {
+		extents = append(extents, img.chunkExtent(idx))
+	}
+	return
+}
+
+// DeltaExtents returns extents of chunks whose data in target is different from base, sorted by offset.
+// Both images must have the same chunk size.
+func DeltaExtents(base, target Image) (extents []Extent, err error) {
+	if base.ChunkSize != target.ChunkSize {
+		err = fmt.Errorf("chunk size of base %d and target %d are different", base.ChunkSize, target.ChunkSize)
+		return
+	}
+
+	for idx := int64(0); idx < target.TotalChunks(); idx++ {
+		var (
+			baseChunk, inBase     = base.Chunks[idx]
+			targetChunk, inTarget = target.Chunks[idx]
+			// the last chunk of base is shorter if volume is expanded
+			lengthChanged = base.chunkExtent(idx).Length != target.chunkExtent(idx).Length
+		)
+		if inBase != inTarget || baseChunk.SHA256 != targetChunk.SHA256 || (inTarget && lengthChanged) {
+			extents = append(extents, target.chunkExtent(idx))
+		}
+	}
+	return
+}
+
+// ImportImage writes the image of manifest chain to dst. Zero chunks are written as zero, unless dst is already zeroed.
+func ImportImage(ctx context.Context, store ObjectStoreIface, dst io.WriterAt, req ImportImageRequest) (img Image, err error) {
+	img, err = ResolveImage(ctx, store, req.ManifestKey)
+	if err != nil {
+		return
+	}
+	if req.SizeBytes < img.SizeBytes {
+		err = fmt.Errorf("size of destination %d is smaller than image %d", req.SizeBytes, img.SizeBytes)
+		return
+	}
+
+	var (
+		total       = img.TotalChunks()
+		zero        = make([]byte, img.ChunkSize)
+		storedBytes int64
+	)
+	for idx := int64(0); idx < total; idx++ {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		var (
+			ext  = img.chunkExtent(idx)
+			data []byte
+		)
+		if chunk, has := img.Chunks[idx]; has {
+			data, err = getChunk(ctx, store, chunk)
+			if err != nil {
+				return
+			}
+			if int64(len(data)) != ext.Length {
+				err = fmt.Errorf("size of chunk %d is %d, expected %d", idx, len(data), ext.Length)
+				return
+			}
+			storedBytes += chunk.StoredBytes
+		} else if !req.DstZeroed {
+			data = zero[:ext.Length]
+		}
+
+		if data != nil {
+			_, err = dst.WriteAt(data, ext.Offset)
+			if err != nil {
+				err = fmt.Errorf("write chunk %d at offset %d failed, %w", idx, ext.Offset, err)
+				return
+			}
+		}
+
+		if req.Progress != nil {
+			req.Progress(idx+1, total, storedBytes)
+		}
+	}
+
+	return
+}
+
+func putChunk(ctx context.Context, store ObjectStoreIface, prefix string, idx int64, data []byte) (chunk ChunkInfo, err error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err = zw.Write(data); err != nil {
+		return
+	}
+	if err = zw.Close(); err != nil {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	chunk = ChunkInfo{
+		Index:       idx,
+		Key:         path.Join(prefix, fmt.Sprintf(chunkNameTemplate, idx)),
+		StoredBytes: int64(buf.Len()),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+	err = retryChunk(ctx, "put chunk "+chunk.Key, func() error {
+		return store.PutObject(ctx, chunk.Key, buf.Bytes())
+	})
+	return
+}
+
+// getChunk retries fetching and verifying the chunk, because a corrupted transfer could be fixed by fetching again
+func getChunk(ctx context.Context, store ObjectStoreIface, chunk ChunkInfo) (data []byte, err error) {
+	err = retryChunk(ctx, "get chunk "+chunk.Key, func() (err error) {
+		data, err = fetchChunk(ctx, store, chunk)
+		return
+	})
+	return
+}
+
+func fetchChunk(ctx context.Context, store ObjectStoreIface, chunk ChunkInfo) (data []byte, err error) {
+	var bs []byte
+	bs, err = store.GetObject(ctx, chunk.Key)
+	if err != nil {
+		return
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(bs))
+	if err != nil {
+		return
+	}
+	defer zr.Close()
+	data, err = io.ReadAll(zr)
+	if err != nil {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != chunk.SHA256 {
+		err = fmt.Errorf("checksum of chunk %s mismatched", chunk.Key)
+	}
+	return
+}
+
+// retryChunk calls fn until it succeeds, ChunkRetryBackoff is exhausted or ctx is done. ErrNotFound is not retried.
+func retryChunk(ctx context.Context, op string, fn func() error) (err error) {
+	var backoff = ChunkRetryBackoff
+	for {
+		err = fn()
+		if err == nil || errors.Is(err, ErrNotFound) || backoff.Steps <= 1 {
+			return
+		}
+		delay := backoff.Step()
+		klog.Warningf("%s failed, retry in %s: %v", op, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("%s failed, %w, last error: %v", op, ctx.Err(), err)
+		}
+	}
+}
+
+// changedChunks returns indexes of chunks overlapped with extents
+func changedChunks(extents []Extent, chunkSize int64) (chunks map[int64]bool) {
+	chunks = make(map[int64]bool)
+	for _, ext := range extents {
+		if ext.Length <= 0 {
+			continue
+		}
+		for idx := ext.Offset / chunkSize; idx*chunkSize < ext.Offset+ext.Length; idx++ {
+			chunks[idx] = true
+		}
+	}
+	return
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
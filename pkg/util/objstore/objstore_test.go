@@ -0,0 +1,276 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// fakeS3 is a minimal S3-compatible server which keeps objects in memory
+type fakeS3 struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), signAlgorithm+" Credential=ak/") ||
+		r.Header.Get("x-amz-date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		bs, _ := io.ReadAll(r.Body)
+		if sha256Hex(bs) != r.Header.Get("x-amz-content-sha256") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.objects[r.URL.Path] = bs
+	case http.MethodGet:
+		bs, has := s.objects[r.URL.Path]
+		if !has {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(bs)
+	}
+}
+
+type memBlock struct {
+	data []byte
+}
+
+func (m *memBlock) ReadAt(p []byte, off int64) (int, error) {
+	return copy(p, m.data[off:]), nil
+}
+
+func (m *memBlock) WriteAt(p []byte, off int64) (int, error) {
+	return copy(m.data[off:], p), nil
+}
+
+func TestSigningKey(t *testing.T) {
+	// example of AWS docs
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
+
+func TestExportImportImage(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store, err := NewS3Client(S3Config{
+		Endpoint:        srv.URL,
+		Bucket:          "backup",
+		AccessKeyID:     "ak",
+		SecretAccessKey: "sk",
+	})
+	assert.NoError(t, err)
+
+	_, err = store.GetObject(context.Background(), "not-exist")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// 3 full chunks and 1 partial chunk, the second chunk is zero
+	var (
+		chunkSize int64 = 1024
+		src             = &memBlock{data: make([]byte, 3*chunkSize+100)}
+	)
+	copy(src.data, bytes.Repeat([]byte("a"), int(chunkSize)))
+	copy(src.data[2*chunkSize:], bytes.Repeat([]byte("b"), int(chunkSize)+100))
+
+	var lastDone int64
+	manifest, err := ExportImage(context.Background(), store, src, ExportImageRequest{
+		Source:    "default/snap-1",
+		Prefix:    "cluster/snap-1",
+		SizeBytes: int64(len(src.data)),
+		ChunkSize: chunkSize,
+		Progress: func(done, total, stored int64) {
+			lastDone = done
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), manifest.TotalChunks())
+	assert.Equal(t, int64(4), lastDone)
+	assert.Len(t, manifest.Chunks, 3)
+	assert.Contains(t, fake.objects, "/backup/cluster/snap-1/manifest.json")
+	assert.NotContains(t, fake.objects, "/backup/cluster/snap-1/chunk-00000001.gz")
+
+	// destination has garbage data, zero chunk should be overwritten
+	dst := &memBlock{data: bytes.Repeat([]byte("x"), len(src.data)+10)}
+	_, err = ImportImage(context.Background(), store, dst, ImportImageRequest{
+		ManifestKey: ManifestKey("cluster/snap-1"),
+		SizeBytes:   int64(len(dst.data)),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, src.data, dst.data[:len(src.data)])
+
+	// destination is too small
+	_, err = ImportImage(context.Background(), store, &memBlock{data: make([]byte, 10)}, ImportImageRequest{
+		ManifestKey: ManifestKey("cluster/snap-1"),
+		SizeBytes:   10,
+	})
+	assert.Error(t, err)
+
+	// corrupted chunk
+	ChunkRetryBackoff.Duration = time.Millisecond
+	defer func() { ChunkRetryBackoff.Duration = time.Second }()
+	fake.objects["/backup/cluster/snap-1/chunk-00000000.gz"] = fake.objects["/backup/cluster/snap-1/chunk-00000002.gz"]
+	_, err = ImportImage(context.Background(), store, dst, ImportImageRequest{
+		ManifestKey: ManifestKey("cluster/snap-1"),
+		SizeBytes:   int64(len(dst.data)),
+	})
+	assert.Error(t, err)
+}
+
+func TestIncrementalImage(t *testing.T) {
+	var (
+		store           = &memStore{objects: make(map[string][]byte)}
+		chunkSize int64 = 1024
+		src             = &memBlock{data: make([]byte, 4*chunkSize)}
+		ctx             = context.Background()
+	)
+	copy(src.data, bytes.Repeat([]byte("a"), 2*int(chunkSize)))
+
+	_, err := ExportImage(ctx, store, src, ExportImageRequest{
+		Prefix:    "snap-1",
+		SizeBytes: int64(len(src.data)),
+		ChunkSize: chunkSize,
+	})
+	assert.NoError(t, err)
+
+	// chunk 0 is zeroed, chunk 2 is written, and volume is expanded by half chunk
+	src.data = append(src.data, bytes.Repeat([]byte("c"), int(chunkSize/2))...)
+	copy(src.data, make([]byte, chunkSize))
+	copy(src.data[2*chunkSize:], bytes.Repeat([]byte("b"), 10))
+
+	manifest, err := ExportImage(ctx, store, src, ExportImageRequest{
+		Prefix:    "snap-2",
+		SizeBytes: int64(len(src.data)),
+		Base:      ManifestKey("snap-1"),
+		Changed:   []Extent{{Offset: 0, Length: 100}, {Offset: 2*chunkSize + 1, Length: 5}},
+	})
+	assert.NoError(t, err)
+	// chunk 0, 2 and the expanded chunk 4
+	assert.Len(t, manifest.Chunks, 3)
+	assert.True(t, manifest.Chunks[0].Zero)
+
+	dst := &memBlock{data: bytes.Repeat([]byte("x"), len(src.data))}
+	_, err = ImportImage(ctx, store, dst, ImportImageRequest{
+		ManifestKey: ManifestKey("snap-2"),
+		SizeBytes:   int64(len(dst.data)),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, src.data, dst.data)
+
+	base, err := ResolveImage(ctx, store, ManifestKey("snap-1"))
+	assert.NoError(t, err)
+	target, err := ResolveImage(ctx, store, ManifestKey("snap-2"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Extent{{0, chunkSize}, {chunkSize, chunkSize}}, base.AllocatedExtents())
+	assert.Equal(t, []Extent{{chunkSize, chunkSize}, {2 * chunkSize, chunkSize}, {4 * chunkSize, chunkSize / 2}}, target.AllocatedExtents())
+
+	delta, err := DeltaExtents(base, target)
+	assert.NoError(t, err)
+	assert.Equal(t, []Extent{{0, chunkSize}, {2 * chunkSize, chunkSize}, {4 * chunkSize, chunkSize / 2}}, delta)
+}
+
+type memStore struct {
+	objects map[string][]byte
+}
+
+func (m *memStore) PutObject(ctx context.Context, key string, data []byte) error {
+	m.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	if data, has := m.objects[key]; has {
+		return data, nil
+	}
+	return nil, ErrNotFound
+}
+
+// flakyStore fails the first failures calls of each method
+type flakyStore struct {
+	memStore
+	failures int
+	puts     int
+	gets     int
+}
+
+func (f *flakyStore) PutObject(ctx context.Context, key string, data []byte) error {
+	if f.puts++; f.puts <= f.failures {
+		return errors.New("connection reset")
+	}
+	return f.memStore.PutObject(ctx, key, data)
+}
+
+func (f *flakyStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	if f.gets++; f.gets <= f.failures {
+		return nil, errors.New("connection reset")
+	}
+	return f.memStore.GetObject(ctx, key)
+}
+
+func TestRetryChunk(t *testing.T) {
+	var backoff = ChunkRetryBackoff
+	defer func() { ChunkRetryBackoff = backoff }()
+	ChunkRetryBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3}
+	var ctx = context.Background()
+	var data = bytes.Repeat([]byte("a"), 1024)
+
+	// transient errors are retried
+	store := &flakyStore{memStore: memStore{objects: map[string][]byte{}}, failures: 2}
+	chunk, err := putChunk(ctx, store, "img", 0, data)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, store.puts)
+	got, err := getChunk(ctx, store, chunk)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.Equal(t, 3, store.gets)
+
+	// retries are bounded
+	store = &flakyStore{memStore: memStore{objects: map[string][]byte{}}, failures: 3}
+	_, err = putChunk(ctx, store, "img", 0, data)
+	assert.Error(t, err)
+	assert.Equal(t, 3, store.puts)
+
+	// missing chunk is not retried
+	store = &flakyStore{memStore: memStore{objects: map[string][]byte{}}}
+	_, err = getChunk(ctx, store, chunk)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 1, store.gets)
+
+	// corrupted chunk is fetched again
+	store = &flakyStore{memStore: memStore{objects: map[string][]byte{}}}
+	_, err = putChunk(ctx, store, "img", 0, data)
+	assert.NoError(t, err)
+	bad := chunk
+	bad.SHA256 = "bad"
+	_, err = getChunk(ctx, store, bad)
+	assert.Error(t, err)
+	assert.Equal(t, 3, store.gets)
+
+	// canceled context stops retries
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	ChunkRetryBackoff.Duration = time.Hour
+	store = &flakyStore{memStore: memStore{objects: map[string][]byte{}}, failures: 1}
+	_, err = putChunk(cctx, store, "img", 0, data)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, store.puts)
+}
@@ -0,0 +1,194 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRegion = "us-east-1"
+
+	signAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat = "20060102T150405Z"
+	service       = "s3"
+)
+
+// ErrNotFound is returned by GetObject if the object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ObjectStoreIface is the minimal set of object operations used by snapshot export and import
+type ObjectStoreIface interface {
+	PutObject(ctx context.Context, key string, data []byte) (err error)
+	GetObject(ctx context.Context, key string) (data []byte, err error)
+}
+
+type S3Config struct {
+	// Endpoint of S3-compatible service, e.g. http://minio.example.com:9000
+	Endpoint string
+	Region   string
+	Bucket   string
+	// credentials
+	AccessKeyID     string
+	SecretAccessKey string
+	// skip verifying TLS certificate of Endpoint
+	InsecureSkipVerify bool
+}
+
+// S3Client accesses objects by path-style URL, e.g. http://endpoint/bucket/key, and signs requests by AWS Signature V4.
+// It is compatible with AWS S3, MinIO and Ceph RGW.
+type S3Client struct {
+	cfg      S3Config
+	endpoint *url.URL
+	httpCli  *http.Client
+}
+
+func NewS3Client(cfg S3Config) (cli *S3Client, err error) {
+	if cfg.Bucket == "" {
+		err = fmt.Errorf("bucket is empty")
+		return
+	}
+	if cfg.Region == "" {
+		cfg.Region = DefaultRegion
+	}
+
+	var endpoint *url.URL
+	endpoint, err = url.Parse(cfg.Endpoint)
+	if err != nil {
+		return
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		err = fmt.Errorf("invalid endpoint %s, scheme should be http or https", cfg.Endpoint)
+		return
+	}
+
+	var transport = http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	cli = &S3Client{
+		cfg:      cfg,
+		endpoint: endpoint,
+		httpCli: &http.Client{
+			Transport: transport,
+			Timeout:   5 * time.Minute,
+		},
+	}
+	return
+}
+
+func (c *S3Client) PutObject(ctx context.Context, key string, data []byte) (err error) {
+	resp, err := c.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = readError(resp, key)
+	}
+	return
+}
+
+func (c *S3Client) GetObject(ctx context.Context, key string) (data []byte, err error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		data, err = io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		err = fmt.Errorf("%w: %s", ErrNotFound, key)
+	default:
+		err = readError(resp, key)
+	}
+	return
+}
+
+func (c *S3Client) do(ctx context.Context, method, key string, body []byte) (resp *http.Response, err error) {
+	var (
+		u       = *c.endpoint
+		now     = time.Now().UTC()
+		payload = sha256Hex(body)
+		req     *http.Request
+	)
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + c.cfg.Bucket + "/" + strings.TrimPrefix(key, "/")
+
+	req, err = http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("x-amz-date", now.Format(amzDateFormat))
+	req.Header.Set("x-amz-content-sha256", payload)
+	req.Header.Set("Authorization", c.authorization(req, now, payload))
+
+	return c.httpCli.Do(req)
+}
+
+// authorization returns the Authorization header of AWS Signature V4
+func (c *S3Client) authorization(req *http.Request, now time.Time, payloadHash string) string {
+	var (
+		date          = now.Format("20060102")
+		scope         = strings.Join([]string{date, c.cfg.Region, service, "aws4_request"}, "/")
+		signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+		canonicalReq  = strings.Join([]string{
+			req.Method,
+			req.URL.EscapedPath(),
+			req.URL.Query().Encode(),
+			"host:" + req.URL.Host,
+			"x-amz-content-sha256:" + payloadHash,
+			"x-amz-date:" + req.Header.Get("x-amz-date"),
+			"",
+			signedHeaders,
+			payloadHash,
+		}, "\n")
+		stringToSign = strings.Join([]string{
+			signAlgorithm,
+			req.Header.Get("x-amz-date"),
+			scope,
+			sha256Hex([]byte(canonicalReq)),
+		}, "\n")
+		signature = hex.EncodeToString(hmacSHA256(signingKey(c.cfg.SecretAccessKey, date, c.cfg.Region, service), stringToSign))
+	)
+
+	return fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, c.cfg.AccessKeyID, scope, signedHeaders, signature)
+}
+
+func signingKey(secret, date, region, svc string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, svc)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func readError(resp *http.Response, key string) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("request object %s failed, status %d, %s", key, resp.StatusCode, string(msg))
+}
@@ -0,0 +1,42 @@
+package objstore
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	v1 "lite.io/liteio/pkg/api/volume.antstor.alipay.com/v1"
+)
+
+// NewObjectStore creates S3 client with the credentials in Secret
+func NewObjectStore(kubeCli kubernetes.Interface, store v1.ObjectStore) (cli ObjectStoreIface, err error) {
+	var secret *corev1.Secret
+	secret, err = kubeCli.CoreV1().Secrets(store.SecretNamespace).Get(context.Background(), store.SecretName, metav1.GetOptions{})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	var (
+		ak = secret.Data[v1.ObjectStoreAccessKeyIDSecretKey]
+		sk = secret.Data[v1.ObjectStoreSecretAccessKeySecretKey]
+	)
+	if len(ak) == 0 || len(sk) == 0 {
+		err = fmt.Errorf("Secret %s/%s has no %s or %s", store.SecretNamespace, store.SecretName,
+			v1.ObjectStoreAccessKeyIDSecretKey, v1.ObjectStoreSecretAccessKeySecretKey)
+		return
+	}
+
+	return NewS3Client(S3Config{
+		Endpoint:           store.Endpoint,
+		Region:             store.Region,
+		Bucket:             store.Bucket,
+		AccessKeyID:        string(ak),
+		SecretAccessKey:    string(sk),
+		InsecureSkipVerify: store.InsecureSkipVerify,
+	})
+}